@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+// AuthCmd represents the auth commands
+var AuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Calls subcommands like `ls` to show the ceph auth entities",
+	Args:  cobra.ExactArgs(1),
+}
+
+var authLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Print the ceph auth entities and their caps, the keys are masked unless --show-keys is passed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		entity, _ := cmd.Flags().GetString("entity")
+		showKeys, _ := cmd.Flags().GetBool("show-keys")
+		auth.List(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, entity, showKeys)
+	},
+}
+
+// KeyCmd represents the key commands
+var KeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Calls subcommands like `rotate <entity>` to manage the ceph keys",
+	Args:  cobra.ExactArgs(1),
+}
+
+var keyRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the key of a csi ceph entity and update its kubernetes secret, in dry-run unless --dry-run=false is passed",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		auth.RotateKey(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], dryRun)
+	},
+}
+
+func init() {
+	AuthCmd.AddCommand(authLsCmd)
+	authLsCmd.Flags().String("entity", "", "only print the entities with this name or name prefix, such as client.csi")
+	authLsCmd.Flags().Bool("show-keys", false, "print the keys instead of masking them")
+
+	KeyCmd.AddCommand(keyRotateCmd)
+	keyRotateCmd.Flags().Bool("dry-run", true, "print the changes without rotating the key")
+}
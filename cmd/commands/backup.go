@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/backup"
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd represents the backup commands
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Calls subcommands like `crs` to back up the config of the cluster",
+	Args:  cobra.ExactArgs(1),
+}
+
+var backupCrsCmd = &cobra.Command{
+	Use:   "crs <file>",
+	Short: "Save the rook custom resources of the cluster namespace to a yaml file, without their status, to re-apply them",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		backup.Crs(cmd.Context(), c.Clientsets.Dynamic, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	BackupCmd.AddCommand(backupCrsCmd)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/balancer"
+	"github.com/spf13/cobra"
+)
+
+// BalancerCmd represents the balancer commands
+var BalancerCmd = &cobra.Command{
+	Use:   "balancer",
+	Short: "Calls subcommands like `status`, `on`, `off` and `mode <mode>` to show and manage the ceph balancer",
+	Args:  cobra.ExactArgs(1),
+}
+
+var balancerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the mode, the state and the last optimization of the balancer",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		balancer.Status(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+var balancerOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn the balancer on",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		balancer.SetActive(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, true)
+	},
+}
+
+var balancerOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn the balancer off",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		balancer.SetActive(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, false)
+	},
+}
+
+var balancerModeCmd = &cobra.Command{
+	Use:   "mode",
+	Short: "Set the balancer mode: none, crush-compat, upmap, read or upmap-read",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		balancer.SetMode(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	BalancerCmd.AddCommand(balancerStatusCmd)
+	BalancerCmd.AddCommand(balancerOnCmd)
+	BalancerCmd.AddCommand(balancerOffCmd)
+	BalancerCmd.AddCommand(balancerModeCmd)
+}
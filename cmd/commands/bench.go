@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/bench"
+	"github.com/spf13/cobra"
+)
+
+// BenchCmd represents the bench command
+var BenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Run a rados write and read benchmark against a scratch pool that is deleted afterwards",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		pool, _ := cmd.Flags().GetString("pool")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		objectSize, _ := cmd.Flags().GetString("object-size")
+		bench.RadosBench(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, pool, duration, objectSize)
+	},
+}
+
+func init() {
+	BenchCmd.Flags().String("pool", "rook-ceph-bench", "name of the scratch pool to create, the pool must not exist")
+	BenchCmd.Flags().Duration("duration", 10*time.Second, "duration of each of the write and read benchmarks")
+	BenchCmd.Flags().String("object-size", "4Mi", "size of the objects to write")
+}
@@ -17,8 +17,15 @@ limitations under the License.
 package command
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -29,9 +36,70 @@ var CephCmd = &cobra.Command{
 	DisableFlagParsing: true,
 	Args:               cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		args, envelope := extractFlag(args, "--envelope")
+		args, err := appendFormatFlag(args, getOutputFormat(cmd))
+		if err != nil {
+			logging.Fatal(err)
+		}
 		logging.Info("running 'ceph' command with args: %v", args)
-		exec.RunCommandInOperatorPod(cmd.Context(), clientsets, cmd.Use, args, OperatorNamespace, CephClusterNamespace, false, true)
+		if envelope {
+			runWithEnvelope(cmd.Context(), c, cmd.Use, args)
+			return
+		}
+		exec.RunCommandInOperatorPod(cmd.Context(), c.Clientsets, cmd.Use, args, c.OperatorNamespace, c.CephClusterNamespace, false, true)
 	},
 }
+
+// runWithEnvelope prints the json envelope of the command and exits with the exit code of the command
+func runWithEnvelope(ctx context.Context, c *k8sutil.Context, command string, args []string) {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, c.Clientsets, command, args, c.OperatorNamespace, c.CephClusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	formatter, err := output.NewFormatter(output.JSON, os.Stdout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	err = formatter.Render(exec.NewEnvelope(command, args, result))
+	if err != nil {
+		logging.Fatal(err)
+	}
+	os.Exit(result.ExitCode)
+}
+
+// extractFlag removes the boolean flag from the args of a command with DisableFlagParsing, and returns whether it was set
+func extractFlag(args []string, flag string) ([]string, bool) {
+	var remaining []string
+	found := false
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, found
+}
+
+// appendFormatFlag appends the --format flag to the args unless the format was already passed by the user
+func appendFormatFlag(args []string, format string) ([]string, error) {
+	if format == "" || hasFormatFlag(args) {
+		return args, nil
+	}
+	if format != "plain" && format != "json" && format != "yaml" {
+		return nil, fmt.Errorf("invalid format %q, supported formats are plain, json and yaml", format)
+	}
+	return append(args, "--format", format), nil
+}
+
+func hasFormatFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--format" || arg == "-f" || strings.HasPrefix(arg, "--format=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/cleanup"
+	"github.com/spf13/cobra"
+)
+
+// CleanupCmd represents the cleanup commands
+var CleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Calls subcommands like `stuck-pods` to clean up resources blocking the reconcile",
+	Args:  cobra.ExactArgs(1),
+}
+
+var stuckPodsCmd = &cobra.Command{
+	Use:   "stuck-pods",
+	Short: "Force delete the Rook daemon pods stuck in terminating state beyond the grace period",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		gracePeriod, _ := cmd.Flags().GetDuration("grace-period")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		cleanup.StuckPods(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace, gracePeriod, dryRun)
+	},
+}
+
+func init() {
+	CleanupCmd.AddCommand(stuckPodsCmd)
+	stuckPodsCmd.Flags().Duration("grace-period", 5*time.Minute, "time a pod must be terminating before it is considered stuck")
+	stuckPodsCmd.Flags().Bool("dry-run", false, "print the stuck pods without deleting them")
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+// ClientCmd represents the client commands
+var ClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Calls subcommands like `test <name>` to troubleshoot the ceph clients",
+	Args:  cobra.ExactArgs(1),
+}
+
+var clientTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Print the caps of a ceph client and test them by reading the mon map and its pools with its key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		pool, _ := cmd.Flags().GetString("pool")
+		auth.VerifyClient(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], pool)
+	},
+}
+
+func init() {
+	ClientCmd.AddCommand(clientTestCmd)
+	clientTestCmd.Flags().String("pool", "", "pool to test the access to, the pools of the osd caps by default")
+}
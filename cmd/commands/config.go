@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd represents the config commands
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Calls subcommands like `dump`, `snapshot` and `compare` to show the ceph config and its changes",
+	Args:  cobra.ExactArgs(1),
+}
+
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the ceph config options with the section and the scope each option is set at",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		who, _ := cmd.Flags().GetString("who")
+		config.Dump(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, who)
+	},
+}
+
+var configSnapshotCmd = &cobra.Command{
+	Use:   "snapshot <file>",
+	Short: "Save the ceph config dump to the file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		config.Snapshot(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+var configCompareCmd = &cobra.Command{
+	Use:   "compare <file>",
+	Short: "Print the ceph config options added, removed and changed since the snapshot of the file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		config.Compare(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(configDumpCmd)
+	ConfigCmd.AddCommand(configSnapshotCmd)
+	ConfigCmd.AddCommand(configCompareCmd)
+	configDumpCmd.Flags().String("who", "", "only print the options of a section, such as osd or osd.0. The options of the daemons are included for a daemon type")
+}
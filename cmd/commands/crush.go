@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/crush"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// CrushCmd represents the crush commands
+var CrushCmd = &cobra.Command{
+	Use:   "crush",
+	Short: "Calls subcommands like `dump` and `set <file>` to back up and edit the crush map",
+	Args:  cobra.ExactArgs(1),
+}
+
+var crushDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the decompiled crush map, or the crush map as json or yaml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		outputFormat, _ := cmd.Flags().GetString("output")
+		var formatter output.Formatter
+		if outputFormat != "" {
+			var err error
+			formatter, err = output.NewFormatter(outputFormat, os.Stdout)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+		crush.Dump(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, formatter)
+	},
+}
+
+var crushSetCmd = &cobra.Command{
+	Use:   "set <file>",
+	Short: "Compile the decompiled crush map of the file and set it, once the current crush map is saved to the --backup file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		backup, _ := cmd.Flags().GetString("backup")
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		crush.Set(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], backup)
+	},
+}
+
+func init() {
+	CrushCmd.AddCommand(crushDumpCmd)
+	CrushCmd.AddCommand(crushSetCmd)
+	crushDumpCmd.Flags().StringP("output", "o", "", "output format of the crush map: json or yaml, the decompiled crush map is printed by default")
+	crushSetCmd.Flags().String("backup", "", "file the current crush map is saved to before the new one is set, it must not exist")
+	_ = crushSetCmd.MarkFlagRequired("backup")
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/csi"
+	"github.com/spf13/cobra"
+)
+
+// CsiCmd represents the csi commands
+var CsiCmd = &cobra.Command{
+	Use:   "csi",
+	Short: "Calls subcommands like `check-config` to troubleshoot the ceph CSI drivers",
+	Args:  cobra.ExactArgs(1),
+}
+
+var csiCheckConfigCmd = &cobra.Command{
+	Use:   "check-config",
+	Short: "Verify the CSI secrets, service accounts, RBAC and storageclasses reference the CephCluster namespace",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		csi.CheckConfig(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+var csiCheckImagesCmd = &cobra.Command{
+	Use:   "check-images",
+	Short: "Verify the CSI pods run the images of the operator config and of their daemonset or deployment",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		csi.CheckImages(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace)
+	},
+}
+
+func init() {
+	CsiCmd.AddCommand(csiCheckConfigCmd)
+	CsiCmd.AddCommand(csiCheckImagesCmd)
+}
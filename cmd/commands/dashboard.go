@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+// DashboardCmd represents the dashboard command
+var DashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Print the ceph dashboard URLs and the admin credentials",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		showPassword, _ := cmd.Flags().GetBool("show-password")
+		dashboard.Dashboard(cmd.Context(), c.Clientsets, c.CephClusterNamespace, showPassword)
+	},
+}
+
+var dashboardEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable the dashboard mgr module and print the dashboard URLs once its service is up",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		dashboard.SetEnabled(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, true, timeout)
+	},
+}
+
+var dashboardDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable the dashboard mgr module",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		dashboard.SetEnabled(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, false, timeout)
+	},
+}
+
+func init() {
+	DashboardCmd.Flags().Bool("show-password", false, "print the dashboard admin password instead of masking it")
+	DashboardCmd.AddCommand(dashboardEnableCmd)
+	DashboardCmd.AddCommand(dashboardDisableCmd)
+	for _, cmd := range []*cobra.Command{dashboardEnableCmd, dashboardDisableCmd} {
+		cmd.Flags().Duration("timeout", 2*time.Minute, "time to wait for the active mgr to start or stop the dashboard service")
+	}
+}
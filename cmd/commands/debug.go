@@ -34,10 +34,10 @@ var startDebugCmd = &cobra.Command{
 	Short: "Start debugging a deployment with an optional alternative ceph container image",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
 		alternateImage := cmd.Flag("alternate-image").Value.String()
-		debug.StartDebug(cmd.Context(), clientsets.Kube, CephClusterNamespace, args[0], alternateImage)
+		debug.StartDebug(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace, args[0], alternateImage)
 	},
 }
 
@@ -46,9 +46,9 @@ var stopDebugCmd = &cobra.Command{
 	Short: "Stop debugging a deployment",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
-		debug.StopDebug(cmd.Context(), clientsets.Kube, CephClusterNamespace, args[0])
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		debug.StopDebug(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace, args[0])
 	},
 }
 
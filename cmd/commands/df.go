@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/df"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// DfCmd represents the df command
+var DfCmd = &cobra.Command{
+	Use:   "df",
+	Short: "Print the capacity of the cluster by device class and by pool",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		outputFormat, _ := cmd.Flags().GetString("output")
+		var formatter output.Formatter
+		if outputFormat != "" {
+			var err error
+			formatter, err = output.NewFormatter(outputFormat, os.Stdout)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+		df.Df(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, formatter)
+	},
+}
+
+func init() {
+	DfCmd.Flags().StringP("output", "o", "", "output format of the capacity: json or yaml")
+}
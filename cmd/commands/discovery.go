@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/discovery"
+	"github.com/spf13/cobra"
+)
+
+// DiscoveryCmd represents the discovery commands
+var DiscoveryCmd = &cobra.Command{
+	Use:   "discovery",
+	Short: "Calls subcommands like `status` to verify the discovery of the devices by the rook discover daemon",
+	Args:  cobra.ExactArgs(1),
+}
+
+var discoveryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the discover daemon pods and the devices they discovered on each node",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		discovery.Status(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace)
+	},
+}
+
+func init() {
+	DiscoveryCmd.AddCommand(discoveryStatusCmd)
+}
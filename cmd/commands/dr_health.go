@@ -18,9 +18,9 @@ var healthCmd = &cobra.Command{
 	DisableFlagParsing: true,
 	Args:               cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
-		dr.Health(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace, args)
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		dr.Health(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args)
 	},
 }
 
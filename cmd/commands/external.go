@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/external"
+	"github.com/spf13/cobra"
+)
+
+// ExternalInfoCmd represents the external-info command
+var ExternalInfoCmd = &cobra.Command{
+	Use:   "external-info",
+	Short: "Print the mon endpoints, fsid and keyrings to bootstrap an external cluster consumer",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+		external.Info(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace, showSecrets)
+	},
+}
+
+func init() {
+	ExternalInfoCmd.Flags().Bool("show-secrets", false, "print the keys instead of masking them")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// FsCmd represents the fs commands
+var FsCmd = &cobra.Command{
+	Use:   "fs",
+	Short: "Calls subcommands like `status` for CephFS troubleshooting",
+	Args:  cobra.ExactArgs(1),
+}
+
+var fsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print MDS state, clients, cache usage and pool usage per filesystem. Optionally pass a filesystem name",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		filesystem.Status(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args)
+	},
+}
+
+func init() {
+	FsCmd.AddCommand(fsStatusCmd)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+// FsidCmd represents the fsid command
+var FsidCmd = &cobra.Command{
+	Use:   "fsid",
+	Short: "Print the ceph fsid, the ceph cluster name and the CephCluster CR of the cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		cluster.Fsid(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
@@ -17,18 +17,157 @@ limitations under the License.
 package command
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
 	"github.com/spf13/cobra"
 )
 
 var Health = &cobra.Command{
-	Use:                "health",
-	Short:              "check health of the cluster and common configuration issues",
-	DisableFlagParsing: true,
-	Args:               cobra.NoArgs,
+	Use:   "health",
+	Short: "check health of the cluster and common configuration issues",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, _ []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
-		health.Health(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
+		opts := health.NewHealthOptions()
+		for daemonType := range opts.Selectors {
+			opts.Selectors[daemonType], _ = cmd.Flags().GetString(daemonType + "-selector")
+		}
+		opts.MinPgsPerOsd, _ = cmd.Flags().GetInt("min-pgs-per-osd")
+		opts.MaxPgsPerOsd, _ = cmd.Flags().GetInt("max-pgs-per-osd")
+		opts.ClusterName, _ = cmd.Flags().GetString("cluster-name")
+		opts.ScanOperatorLogs, _ = cmd.Flags().GetBool("operator-logs")
+		opts.BatchExec, _ = cmd.Flags().GetBool("batch-exec")
+		fromFile, _ := cmd.Flags().GetString("from-file")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		var formatter output.Formatter
+		if outputFormat != "" {
+			var err error
+			formatter, err = output.NewFormatter(outputFormat, os.Stdout)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+		webhook, _ := cmd.Flags().GetString("webhook")
+		webhookOn, _ := cmd.Flags().GetString("webhook-on")
+		if webhook != "" {
+			err := health.ValidateWebhookOn(webhookOn)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+
+		// with a structured output only the report is written to stdout, the check details go to stderr
+		if formatter != nil {
+			opts.Output = os.Stderr
+		}
+		var report *health.HealthReport
+		if fromFile != "" {
+			// the captured status is evaluated without connecting to the cluster
+			clusterNamespace, _ := cmd.Root().PersistentFlags().GetString("namespace")
+			report = health.HealthFromFile(clusterNamespace, fromFile, opts)
+		} else {
+			c := GetContext(cmd)
+			VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+			report = health.Health(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, opts)
+		}
+
+		if formatter != nil {
+			err := formatter.Render(report)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+
+		historyFile, _ := cmd.Flags().GetString("history-file")
+		if historyFile != "" {
+			err := health.AppendHistory(report, historyFile)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+
+		if webhook != "" {
+			err := health.SendWebhook(report, webhook, webhookOn)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+
+		// the failing checks exit with a non-zero code, once the report is rendered, recorded and sent
+		err := report.Err()
+		if err != nil {
+			logging.Fatal(err)
+		}
 	},
 }
+
+var healthDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Print the changes between two health reports saved with --output json",
+	Args:  cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		health.Diff(args[0], args[1])
+	},
+}
+
+var healthMutesCmd = &cobra.Command{
+	Use:   "mutes",
+	Short: "Print the muted ceph health checks and whether they are raised",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		health.Mutes(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+var healthMuteCmd = &cobra.Command{
+	Use:   "mute <code>",
+	Short: "Mute a ceph health check, such as OSD_DOWN, for the --ttl or until it's unmuted",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		sticky, _ := cmd.Flags().GetBool("sticky")
+		health.Mute(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], ttl, sticky)
+	},
+}
+
+var healthUnmuteCmd = &cobra.Command{
+	Use:   "unmute <code>",
+	Short: "Unmute a ceph health check",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		health.Unmute(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	Health.AddCommand(healthDiffCmd)
+	Health.AddCommand(healthMutesCmd)
+	Health.AddCommand(healthMuteCmd)
+	Health.AddCommand(healthUnmuteCmd)
+	healthMuteCmd.Flags().Duration("ttl", time.Hour, "time the health check stays muted, 0 mutes it until it's unmuted")
+	healthMuteCmd.Flags().Bool("sticky", false, "keep the mute when the health check clears, so it stays muted when it's raised again")
+	defaults := health.NewHealthOptions()
+	for daemonType, selector := range defaults.Selectors {
+		Health.Flags().String(daemonType+"-selector", selector, fmt.Sprintf("label selector of the %s pods", daemonType))
+	}
+	Health.Flags().StringP("output", "o", "", "output format of the health report: json or yaml")
+	Health.Flags().Bool("operator-logs", false, "scan the operator logs of the last hour for reconcile errors and panics")
+	Health.Flags().Int("min-pgs-per-osd", defaults.MinPgsPerOsd, "warn when an osd has fewer pgs")
+	Health.Flags().Int("max-pgs-per-osd", defaults.MaxPgsPerOsd, "warn when an osd has more pgs")
+	Health.Flags().String("cluster-name", "", "name of the CephCluster to check, the CephCluster of the namespace by default")
+	Health.Flags().Bool("batch-exec", false, "run the ceph queries of the checks in a single exec in the operator pod instead of an exec per query")
+	Health.Flags().String("from-file", "", "evaluate the ceph status captured with `ceph status --format json` in the file instead of the live cluster")
+	Health.Flags().String("history-file", "", "path of a json lines file the status and the check counts of the run are appended to")
+	Health.Flags().String("webhook", "", "URL to POST the json health report to when the health status is not ok")
+	Health.Flags().String("webhook-on", "warn", "minimum health status to POST the report to the webhook: 'warn' or 'err'")
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+	"github.com/spf13/cobra"
+)
+
+// MgrCmd represents the mgr commands
+var MgrCmd = &cobra.Command{
+	Use:   "mgr",
+	Short: "Calls subcommands like `failover` to manage the ceph mgrs",
+	Args:  cobra.ExactArgs(1),
+}
+
+var mgrFailoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "Fail the active mgr over to a standby mgr and wait for the standby to become active",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		mgr.Failover(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, timeout)
+	},
+}
+
+func init() {
+	MgrCmd.AddCommand(mgrFailoverCmd)
+	mgrFailoverCmd.Flags().Duration("timeout", 2*time.Minute, "time to wait for a standby mgr to become active")
+}
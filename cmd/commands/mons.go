@@ -18,7 +18,10 @@ package command
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 	"github.com/rook/kubectl-rook-ceph/pkg/mons"
 	"github.com/spf13/cobra"
 )
@@ -31,8 +34,8 @@ var MonCmd = &cobra.Command{
 	Args:               cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
-			clientsets := GetClientsets(cmd.Context())
-			fmt.Println(mons.GetMonEndpoint(cmd.Context(), clientsets.Kube, CephClusterNamespace))
+			c := GetContext(cmd)
+			fmt.Println(mons.GetMonEndpoint(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace))
 		}
 	},
 }
@@ -44,11 +47,131 @@ var RestoreQuorum = &cobra.Command{
 	DisableFlagParsing: true,
 	Args:               cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		mons.RestoreQuorum(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace, args[0])
+		c := GetContext(cmd)
+		mons.RestoreQuorum(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+// RebuildStore represents the mons command
+var RebuildStore = &cobra.Command{
+	Use:   "rebuild-store",
+	Short: "Rebuild the corrupted store of a mon from the monmap of the surviving mons in quorum",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		mons.RebuildStore(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], dryRun)
+	},
+}
+
+// CheckQuorum represents the mons command
+var CheckQuorum = &cobra.Command{
+	Use:   "check-quorum",
+	Short: "Cross-check the mons in the ceph quorum and monmap against the mon endpoints configmap and the running mon pods",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		mons.CheckQuorum(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+// CheckDataDirs represents the mons command
+var CheckDataDirs = &cobra.Command{
+	Use:   "check-datadirs",
+	Short: "List the mon data dirs in the dataDirHostPath of every node and flag the stale and duplicate ones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		mons.CheckDataDirs(cmd.Context(), c.Clientsets, c.CephClusterNamespace)
+	},
+}
+
+// CheckStorage represents the mons command
+var CheckStorage = &cobra.Command{
+	Use:   "check-storage",
+	Short: "Check that all the mon PVCs use the same storage class and size",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		mons.CheckStorage(cmd.Context(), c.Clientsets.Kube, c.CephClusterNamespace)
+	},
+}
+
+// RecreateMon represents the mons command
+var RecreateMon = &cobra.Command{
+	Use:   "recreate",
+	Short: "Remove a mon from quorum with its pod, pvc and data dir, and let the operator re-create it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		mons.RecreateMon(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+// SyncEndpoints represents the mons command
+var SyncEndpoints = &cobra.Command{
+	Use:   "sync-endpoints",
+	Short: "Rewrite the mon endpoints configmap from the ceph monmap, in dry-run unless --dry-run=false is passed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		mons.SyncEndpoints(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, dryRun)
+	},
+}
+
+// MonCount represents the mons command
+var MonCount = &cobra.Command{
+	Use:   "count [count]",
+	Short: "Print the mon count of the CephCluster and the mons in quorum, or set the mon count of the CephCluster",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		if len(args) == 0 {
+			VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+			mons.ShowCount(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+			return
+		}
+		count, err := strconv.Atoi(args[0])
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid mon count %q. %v", args[0], err))
+		}
+		mons.SetCount(cmd.Context(), c.Clientsets, c.CephClusterNamespace, count)
+	},
+}
+
+// ScaleMons represents the mons command
+var ScaleMons = &cobra.Command{
+	Use:   "scale <count>",
+	Short: "Raise the mon count of the CephCluster, wait for the new mons to join the quorum and roll the count back if they don't",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		count, err := strconv.Atoi(args[0])
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid mon count %q. %v", args[0], err))
+		}
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		mons.Scale(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, count, timeout)
 	},
 }
 
 func init() {
 	MonCmd.AddCommand(RestoreQuorum)
+	MonCmd.AddCommand(RebuildStore)
+	MonCmd.AddCommand(CheckQuorum)
+	MonCmd.AddCommand(CheckDataDirs)
+	MonCmd.AddCommand(CheckStorage)
+	MonCmd.AddCommand(RecreateMon)
+	MonCmd.AddCommand(SyncEndpoints)
+	MonCmd.AddCommand(MonCount)
+	MonCmd.AddCommand(ScaleMons)
+	RebuildStore.Flags().Bool("dry-run", false, "print the steps to rebuild the mon store without making any changes")
+	SyncEndpoints.Flags().Bool("dry-run", true, "print the changes without updating the configmap")
+	ScaleMons.Flags().Duration("timeout", 15*time.Minute, "time to wait for all the new mons to join the quorum before rolling back the mon count")
 }
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/spf13/cobra"
+)
+
+// NetworkCmd represents the network commands
+var NetworkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Calls subcommands like `test` to check the network between the osds",
+	Args:  cobra.ExactArgs(1),
+}
+
+var networkTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Print the heartbeat ping times between the osds on the front and back networks, and report the slow and stale links",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		thresholdMs, _ := cmd.Flags().GetFloat64("threshold-ms")
+		all, _ := cmd.Flags().GetBool("all")
+		osd.NetworkTest(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, thresholdMs, all)
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(networkTestCmd)
+	networkTestCmd.Flags().Float64("threshold-ms", 1000, "report the links with an average ping time over the last minute above this threshold, in milliseconds")
+	networkTestCmd.Flags().Bool("all", false, "print all the links, not only the slow and stale ones")
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/node"
+	"github.com/spf13/cobra"
+)
+
+// NodeCmd represents the node commands
+var NodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Calls subcommands like `drain-osds` and `undrain-osds` for the node maintenance",
+	Args:  cobra.ExactArgs(1),
+}
+
+var drainOsdsCmd = &cobra.Command{
+	Use:   "drain-osds",
+	Short: "Set noout on the osds of the node and mark them out, or scale them down with --mode scale-down",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		mode, _ := cmd.Flags().GetString("mode")
+		node.DrainOsds(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0], mode)
+	},
+}
+
+var undrainOsdsCmd = &cobra.Command{
+	Use:   "undrain-osds",
+	Short: "Reverse drain-osds by scaling up and marking in the osds of the node and unsetting noout",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		node.UndrainOsds(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	NodeCmd.AddCommand(drainOsdsCmd)
+	NodeCmd.AddCommand(undrainOsdsCmd)
+	drainOsdsCmd.Flags().String("mode", node.ModeOut, "how to drain the osds: 'out' to mark them out, or 'scale-down' to scale down their deployments")
+}
@@ -17,14 +17,17 @@ limitations under the License.
 package command
 
 import (
+	"time"
+
 	k8sutil "github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/rook"
 	"github.com/spf13/cobra"
 )
 
 // OperatorCmd represents the operator commands
 var OperatorCmd = &cobra.Command{
 	Use:                "operator",
-	Short:              "Calls subcommands like `restart`  and `set <key> <value>` to update  rook-ceph-operator-config configmap",
+	Short:              "Calls subcommands like `restart`, `set <key> <value>` to update rook-ceph-operator-config configmap, `settings` to print it and `reconciles` to time the reconciles",
 	DisableFlagParsing: true,
 	Args:               cobra.ExactArgs(1),
 }
@@ -34,9 +37,9 @@ var restartCmd = &cobra.Command{
 	Short: "Restart rook-ceph-operator pod",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, _ []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
-		k8sutil.RestartDeployment(cmd.Context(), clientsets.Kube, OperatorNamespace, "rook-ceph-operator")
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		k8sutil.RestartDeployment(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace, "rook-ceph-operator")
 	},
 }
 
@@ -45,12 +48,36 @@ var setCmd = &cobra.Command{
 	Short: "Set the property in the rook-ceph-operator-config configmap.",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		k8sutil.UpdateConfigMap(cmd.Context(), clientsets.Kube, OperatorNamespace, "rook-ceph-operator-config", args[0], args[1])
+		c := GetContext(cmd)
+		k8sutil.UpdateConfigMap(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace, "rook-ceph-operator-config", args[0], args[1])
+	},
+}
+
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Print the operator settings of the rook-ceph-operator-config configmap and the operator env",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		rook.Settings(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace)
+	},
+}
+
+var reconcilesCmd = &cobra.Command{
+	Use:   "reconciles",
+	Short: "Print the duration and the failures of the recent reconciles from the operator logs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		since, _ := cmd.Flags().GetDuration("since")
+		rook.Reconciles(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace, since)
 	},
 }
 
 func init() {
 	OperatorCmd.AddCommand(restartCmd)
 	OperatorCmd.AddCommand(setCmd)
+	OperatorCmd.AddCommand(settingsCmd)
+	OperatorCmd.AddCommand(reconcilesCmd)
+	reconcilesCmd.Flags().Duration("since", time.Hour, "how far back the operator logs are scanned")
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/osd"
+	"github.com/spf13/cobra"
+)
+
+// OsdCmd represents the osd commands
+var OsdCmd = &cobra.Command{
+	Use:   "osd",
+	Short: "Calls subcommands like `perf`, `bench`, `tell-all`, `fsck` and `reweight` to troubleshoot OSDs",
+	Args:  cobra.ExactArgs(1),
+}
+
+var osdPerfCmd = &cobra.Command{
+	Use:   "perf",
+	Short: "Print the commit and apply latency per OSD and flag the OSDs above the latency threshold",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		threshold, _ := cmd.Flags().GetInt("threshold")
+		osd.Perf(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, sortBy, threshold)
+	},
+}
+
+var osdBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Run `ceph tell osd.<id> bench` on each OSD and rank the OSDs by throughput to find the slow OSDs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		deviceClass, _ := cmd.Flags().GetString("device-class")
+		size, _ := cmd.Flags().GetString("size")
+		blockSize, _ := cmd.Flags().GetString("block-size")
+		osd.Bench(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, deviceClass, size, blockSize)
+	},
+}
+
+var osdTellAllCmd = &cobra.Command{
+	Use:                "tell-all <args>",
+	Short:              "Run `ceph tell osd.<id> <args>` on each OSD and print the result of each OSD",
+	DisableFlagParsing: true,
+	Args:               cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		osd.TellAll(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args)
+	},
+}
+
+var osdFsckCmd = &cobra.Command{
+	Use:   "fsck <osd-id>",
+	Short: "Stop the OSD and check its bluestore with `ceph-bluestore-tool fsck` in its debug pod",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		osdID, err := strconv.Atoi(args[0])
+		if err != nil {
+			logging.Fatal(fmt.Errorf("invalid osd id %q. %v", args[0], err))
+		}
+		deep, _ := cmd.Flags().GetBool("deep")
+		osd.Fsck(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, osdID, deep)
+	},
+}
+
+var osdCleanupDownCmd = &cobra.Command{
+	Use:   "cleanup-down",
+	Short: "Purge the OSDs down and out for longer than --down-for and delete their deployments, in dry-run unless --dry-run=false is passed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		downFor, _ := cmd.Flags().GetDuration("down-for")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		osd.CleanupDown(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, downFor, dryRun, force)
+	},
+}
+
+var osdReweightCmd = &cobra.Command{
+	Use:   "reweight",
+	Short: "Calls subcommands like `ls` and `reset` to audit and clear the manual reweights of the OSDs",
+	Args:  cobra.ExactArgs(1),
+}
+
+var osdReweightLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Print the crush weight and the override reweight of each OSD and flag the manual reweights",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		osd.ReweightList(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+var osdReweightResetCmd = &cobra.Command{
+	Use:   "reset [osd-id...]",
+	Short: "Reset the manual reweight of the OSDs, or of the given OSDs, back to 1",
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		var osdIds []int
+		for _, arg := range args {
+			osdID, err := strconv.Atoi(arg)
+			if err != nil {
+				logging.Fatal(fmt.Errorf("invalid osd id %q. %v", arg, err))
+			}
+			osdIds = append(osdIds, osdID)
+		}
+		osd.ReweightReset(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, osdIds)
+	},
+}
+
+func init() {
+	OsdCmd.AddCommand(osdPerfCmd)
+	OsdCmd.AddCommand(osdBenchCmd)
+	OsdCmd.AddCommand(osdTellAllCmd)
+	OsdCmd.AddCommand(osdFsckCmd)
+	OsdCmd.AddCommand(osdCleanupDownCmd)
+	OsdCmd.AddCommand(osdReweightCmd)
+	osdReweightCmd.AddCommand(osdReweightLsCmd)
+	osdReweightCmd.AddCommand(osdReweightResetCmd)
+	osdFsckCmd.Flags().Bool("deep", false, "also read and verify the checksums of all the data of the OSD, which can take hours")
+	osdPerfCmd.Flags().String("sort-by", "commit", "sort the OSDs by 'commit' latency, 'apply' latency or 'id'")
+	osdCleanupDownCmd.Flags().Duration("down-for", 24*time.Hour, "purge the OSDs down and out for longer than this duration")
+	osdCleanupDownCmd.Flags().Bool("dry-run", true, "print the OSDs to purge without purging them")
+	osdCleanupDownCmd.Flags().Bool("force", false, "purge the OSDs that are not safe to destroy, and don't wait for rook to find them safe")
+	osdPerfCmd.Flags().Int("threshold", 100, "latency in milliseconds above which an OSD is flagged as slow")
+	osdBenchCmd.Flags().String("device-class", "", "only bench the OSDs of the device class, such as hdd or ssd")
+	osdBenchCmd.Flags().String("size", "1Gi", "total size written to each OSD")
+	osdBenchCmd.Flags().String("block-size", "4Mi", "size of each write")
+}
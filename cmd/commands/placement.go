@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/placement"
+	"github.com/spf13/cobra"
+)
+
+// PlacementCmd represents the placement commands
+var PlacementCmd = &cobra.Command{
+	Use:   "placement",
+	Short: "Calls subcommands like `check` and `show` to verify the spread and the placement of the daemons",
+	Args:  cobra.ExactArgs(1),
+}
+
+var placementCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report the nodes and failure domains missing the expected osds and mons",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		failureDomainLabel, _ := cmd.Flags().GetString("failure-domain-label")
+		placement.Check(cmd.Context(), c.Clientsets, c.CephClusterNamespace, failureDomainLabel)
+	},
+}
+
+var placementShowCmd = &cobra.Command{
+	Use:       "show [mon|mgr|osd]...",
+	Short:     "Print the placement of the daemons in the CephCluster spec and the nodes their pods run on",
+	ValidArgs: placement.DaemonTypes,
+	Args:      cobra.OnlyValidArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		placement.Show(cmd.Context(), c.Clientsets, c.CephClusterNamespace, args)
+	},
+}
+
+func init() {
+	PlacementCmd.AddCommand(placementCheckCmd)
+	PlacementCmd.AddCommand(placementShowCmd)
+	placementCheckCmd.Flags().String("failure-domain-label", "kubernetes.io/hostname", "node label of the failure domains, such as topology.kubernetes.io/zone")
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/pool"
+	"github.com/spf13/cobra"
+)
+
+// PoolCmd represents the pool commands
+var PoolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Calls subcommands like `stats` to show the activity of the pools",
+	Args:  cobra.ExactArgs(1),
+}
+
+var poolStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print the client iops and throughput of each pool",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		pool.Stats(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, sortBy)
+	},
+}
+
+func init() {
+	PoolCmd.AddCommand(poolStatsCmd)
+	poolStatsCmd.Flags().String("sort-by", "throughput", "sort the pools by 'throughput', 'iops' or 'name'")
+}
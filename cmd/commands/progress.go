@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+// ProgressCmd represents the progress command
+var ProgressCmd = &cobra.Command{
+	Use:   "progress",
+	Short: "Print the background operations of ceph in progress, such as the pg recovery, with their percent complete",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		showCompleted, _ := cmd.Flags().GetBool("completed")
+		progress.List(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, showCompleted)
+	},
+}
+
+var progressClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the progress events, such as the events left over by an operation that was interrupted",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		progress.Clear(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+func init() {
+	ProgressCmd.Flags().Bool("completed", false, "also print the completed events")
+	ProgressCmd.AddCommand(progressClearCmd)
+}
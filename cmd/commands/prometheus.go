@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+	"github.com/spf13/cobra"
+)
+
+// PrometheusCmd represents the prometheus commands
+var PrometheusCmd = &cobra.Command{
+	Use:   "prometheus",
+	Short: "Calls subcommands like `enable` and `disable` to toggle the prometheus mgr module",
+	Args:  cobra.ExactArgs(1),
+}
+
+var prometheusEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable the prometheus mgr module and print the metrics endpoint once it's up",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		mgr.SetModule(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, mgr.ModulePrometheus, true, timeout)
+	},
+}
+
+var prometheusDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable the prometheus mgr module",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		mgr.SetModule(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, mgr.ModulePrometheus, false, timeout)
+	},
+}
+
+func init() {
+	PrometheusCmd.AddCommand(prometheusEnableCmd)
+	PrometheusCmd.AddCommand(prometheusDisableCmd)
+	for _, cmd := range []*cobra.Command{prometheusEnableCmd, prometheusDisableCmd} {
+		cmd.Flags().Duration("timeout", 2*time.Minute, "time to wait for the active mgr to start or stop the metrics endpoint")
+	}
+}
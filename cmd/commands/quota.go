@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/quota"
+	"github.com/spf13/cobra"
+)
+
+// QuotaCmd represents the quota command
+var QuotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Print the quotas and the usage of the pools and the cephfs subvolumes, and warn when they are near their quota",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		subvolumes, _ := cmd.Flags().GetBool("subvolumes")
+		nearRatio, _ := cmd.Flags().GetFloat64("near-ratio")
+		quota.Quota(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, subvolumes, nearRatio)
+	},
+}
+
+func init() {
+	QuotaCmd.Flags().Bool("subvolumes", false, "also print the quotas of the cephfs subvolumes, such as the csi volumes, which runs a ceph command by subvolume")
+	QuotaCmd.Flags().Float64("near-ratio", 0.8, "warn when the usage reaches this ratio of the quota")
+}
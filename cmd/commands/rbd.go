@@ -17,8 +17,13 @@ limitations under the License.
 package command
 
 import (
+	"fmt"
+
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/rbd"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 // RbdCmd represents the rbd command
@@ -28,8 +33,42 @@ var RbdCmd = &cobra.Command{
 	DisableFlagParsing: true,
 	Args:               cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
-		exec.RunCommandInOperatorPod(cmd.Context(), clientsets, cmd.Use, args, OperatorNamespace, CephClusterNamespace, false, true)
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		// rbd does not support yaml, so the json output is converted instead
+		if getOutputFormat(cmd) == "yaml" && !hasFormatFlag(args) {
+			output := exec.RunCommandInOperatorPod(cmd.Context(), c.Clientsets, cmd.Use, append(args, "--format", "json"), c.OperatorNamespace, c.CephClusterNamespace, true, true)
+			yamlOutput, err := yaml.JSONToYAML([]byte(output))
+			if err != nil {
+				logging.Fatal(fmt.Errorf("failed to convert rbd output to yaml. %v", err))
+			}
+			fmt.Print(string(yamlOutput))
+			return
+		}
+
+		args, err := appendFormatFlag(args, getOutputFormat(cmd))
+		if err != nil {
+			logging.Fatal(err)
+		}
+		exec.RunCommandInOperatorPod(cmd.Context(), c.Clientsets, cmd.Use, args, c.OperatorNamespace, c.CephClusterNamespace, false, true)
 	},
 }
+
+var rbdTopCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Print the rbd images using the most space across the pools",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		limit, _ := cmd.Flags().GetInt("limit")
+		rbd.Top(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, sortBy, limit)
+	},
+}
+
+func init() {
+	RbdCmd.AddCommand(rbdTopCmd)
+	rbdTopCmd.Flags().String("sort-by", rbd.SortByUsed, "sort the images by their used or provisioned size")
+	rbdTopCmd.Flags().Int("limit", 10, "number of images to print")
+}
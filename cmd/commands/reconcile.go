@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/rook"
+	"github.com/spf13/cobra"
+)
+
+// ReconcileCmd represents the reconcile command
+var ReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Restart the operator to reconcile the CephCluster and print the operator logs until the reconcile finishes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		rook.Reconcile(cmd.Context(), c.Clientsets.Kube, c.OperatorNamespace, c.CephClusterNamespace, timeout)
+	},
+}
+
+func init() {
+	ReconcileCmd.Flags().Duration("timeout", 15*time.Minute, "how long to wait for the reconcile to finish")
+}
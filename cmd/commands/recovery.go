@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/tune"
+	"github.com/spf13/cobra"
+)
+
+// RecoveryCmd represents the recovery commands
+var RecoveryCmd = &cobra.Command{
+	Use:   "recovery",
+	Short: "Calls subcommands like `tune` to show and adjust the recovery of the osds",
+	Args:  cobra.ExactArgs(1),
+}
+
+var recoveryTuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Print the recovery throttles of the osds, or set them with a preset or values",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		aggressive, _ := cmd.Flags().GetBool("aggressive")
+		conservative, _ := cmd.Flags().GetBool("conservative")
+		reset, _ := cmd.Flags().GetBool("reset")
+		var preset string
+		switch {
+		case aggressive && conservative:
+			logging.Fatal(fmt.Errorf("--aggressive and --conservative can't be combined"))
+		case aggressive:
+			preset = tune.PresetAggressive
+		case conservative:
+			preset = tune.PresetConservative
+		}
+		values := map[string]string{}
+		for _, name := range tune.RecoveryOptionNames() {
+			if flag := cmd.Flags().Lookup(recoveryFlagName(name)); flag.Changed {
+				values[name] = flag.Value.String()
+			}
+		}
+
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		tune.RecoveryTune(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, preset, values, reset)
+	},
+}
+
+// recoveryFlagName returns the flag of a recovery throttle, such as --osd-max-backfills for osd_max_backfills
+func recoveryFlagName(option string) string {
+	return strings.ReplaceAll(option, "_", "-")
+}
+
+func init() {
+	RecoveryCmd.AddCommand(recoveryTuneCmd)
+	recoveryTuneCmd.Flags().Bool("aggressive", false, "speed the recovery up at the cost of the client io: 4 backfills, 8 active recoveries and no sleep")
+	recoveryTuneCmd.Flags().Bool("conservative", false, "favor the client io: 1 backfill, 1 active recovery and a sleep of 0.1s")
+	recoveryTuneCmd.Flags().Bool("reset", false, "remove the recovery throttles set with `ceph config set` so the osds use their defaults")
+	for _, name := range tune.RecoveryOptionNames() {
+		recoveryTuneCmd.Flags().String(recoveryFlagName(name), "", fmt.Sprintf("value of %s, overriding the preset", name))
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+// ResourcesCmd represents the resources commands
+var ResourcesCmd = &cobra.Command{
+	Use:   "resources",
+	Short: "Calls subcommands like `usage` to show the resources of the ceph daemons",
+	Args:  cobra.ExactArgs(1),
+}
+
+var resourcesUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Print the cpu and memory requests, limits and usage of the ceph daemon pods",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		resources.Usage(cmd.Context(), c.Clientsets, c.CephClusterNamespace)
+	},
+}
+
+func init() {
+	ResourcesCmd.AddCommand(resourcesUsageCmd)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/rgw"
+	"github.com/spf13/cobra"
+)
+
+// RgwCmd represents the rgw commands
+var RgwCmd = &cobra.Command{
+	Use:   "rgw",
+	Short: "Calls subcommands like `sync-status` to show the state of the object stores",
+	Args:  cobra.ExactArgs(1),
+}
+
+var rgwSyncStatusCmd = &cobra.Command{
+	Use:   "sync-status",
+	Short: "Print the multisite sync status and whether the metadata and data sync are caught up",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		rgw.SyncStatus(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+func init() {
+	RgwCmd.AddCommand(rgwSyncStatusCmd)
+}
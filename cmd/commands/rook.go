@@ -40,8 +40,8 @@ var versionCmd = &cobra.Command{
 	Short: "Prints rook version",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, _ []string) {
-		clientsets := GetClientsets(cmd.Context())
-		exec.RunCommandInOperatorPod(cmd.Context(), clientsets, "rook", []string{cmd.Use}, OperatorNamespace, CephClusterNamespace, false, true)
+		c := GetContext(cmd)
+		exec.RunCommandInOperatorPod(cmd.Context(), c.Clientsets, "rook", []string{cmd.Use}, c.OperatorNamespace, c.CephClusterNamespace, false, true)
 	},
 }
 
@@ -50,11 +50,11 @@ var purgeCmd = &cobra.Command{
 	Short: "Permanently remove an OSD from the cluster. Multiple OSDs can be removed with a comma-separated list of IDs, for example, purge-osd 0,1",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientsets := GetClientsets(cmd.Context())
-		VerifyOperatorPodIsRunning(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace)
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
 		forceflagValue := cmd.Flag("force").Value.String()
 		osdID := args[0]
-		rook.PurgeOsd(cmd.Context(), clientsets, OperatorNamespace, CephClusterNamespace, osdID, forceflagValue)
+		rook.PurgeOsd(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, osdID, forceflagValue)
 	},
 }
 
@@ -62,12 +62,13 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Print the phase and conditions of the CephCluster CR",
 	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
 		json := cmd.Flag("json").Value.String()
 		jsonValue, err := strconv.ParseBool(json)
 		if err != nil {
 			logging.Fatal(fmt.Errorf("failed to parse json flag: %v", err))
 		}
-		rook.PrintCustomResourceStatus(CephClusterNamespace, args, jsonValue)
+		rook.PrintCustomResourceStatus(c.CephClusterNamespace, args, jsonValue)
 	},
 }
 
@@ -20,24 +20,18 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
-	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8s "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"k8s.io/client-go/tools/clientcmd"
-)
-
-var (
-	KubeConfig           string
-	OperatorNamespace    string
-	CephClusterNamespace string
-	KubeContext          string
 )
 
 // rookCmd represents the rook command
@@ -46,13 +40,6 @@ var RootCmd = &cobra.Command{
 	Short:            "kubectl rook-ceph provides common management and troubleshooting tools for Ceph.",
 	Args:             cobra.MinimumNArgs(1),
 	TraverseChildren: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		if CephClusterNamespace != "" && OperatorNamespace == "" {
-			OperatorNamespace = CephClusterNamespace
-		}
-		// logging.Info("CephCluster namespace: %q", CephClusterNamespace)
-		// logging.Info("Rook operator namespace: %q", OperatorNamespace)
-	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -65,57 +52,151 @@ func init() {
 
 	// Define your flags and configuration settings.
 
-	RootCmd.PersistentFlags().StringVar(&KubeConfig, "kubeconfig", "", "kubernetes config path")
-	RootCmd.PersistentFlags().StringVar(&OperatorNamespace, "operator-namespace", "", "Kubernetes namespace where rook operator is running")
-	RootCmd.PersistentFlags().StringVarP(&CephClusterNamespace, "namespace", "n", "rook-ceph", "Kubernetes namespace where CephCluster is created")
-	RootCmd.PersistentFlags().StringVar(&KubeContext, "context", "", "Kubernetes context to use")
+	addGlobalFlags(RootCmd.PersistentFlags())
+}
+
+// addGlobalFlags defines the flags of all the commands, the context of the commands is built from them
+func addGlobalFlags(flags *pflag.FlagSet) {
+	flags.String("kubeconfig", "", "kubernetes config path")
+	flags.String("operator-namespace", "", "Kubernetes namespace where rook operator is running")
+	flags.StringP("namespace", "n", k8sutil.DefaultCephClusterNamespace, "Kubernetes namespace where CephCluster is created")
+	flags.String("context", "", "Kubernetes context to use")
+	flags.Duration("request-timeout", 0, "timeout of the requests to the kubernetes api, such as 30s, 0 means no timeout")
+	flags.Duration("context-timeout", 10*time.Second, "timeout of the check that the kubernetes api is reachable and the namespaces exist before running the command")
+	flags.String("format", "", "default output format of the ceph and rbd commands: plain, json or yaml")
+	flags.String("as", "", "username to impersonate for the operation, such as a service account to verify its permissions")
+	flags.StringArray("as-group", []string{}, "group to impersonate for the operation, this flag can be repeated to specify multiple groups")
+	flags.Bool("anonymize", false, "mask the node names, the ips and the fsid in the structured output and the logs, to share them")
+	flags.BoolVar(&logging.AssumeYes, "yes", false, "skip the confirmation of the destructive commands")
+	flags.Bool("in-cluster", false, "use the in-cluster service account config instead of a kubeconfig, when running inside a pod")
+}
+
+// getOptions returns the options of the context from the global flags. The flags are read from the root command
+// since the commands that pass their args to ceph don't parse their own flags.
+func getOptions(flags *pflag.FlagSet) k8sutil.Options {
+	opts := k8sutil.Options{}
+	opts.KubeConfig, _ = flags.GetString("kubeconfig")
+	opts.KubeContext, _ = flags.GetString("context")
+	opts.InCluster, _ = flags.GetBool("in-cluster")
+	opts.Impersonate, _ = flags.GetString("as")
+	opts.ImpersonateGroups, _ = flags.GetStringArray("as-group")
+	opts.Timeout, _ = flags.GetDuration("request-timeout")
+	opts.OperatorNamespace, _ = flags.GetString("operator-namespace")
+	opts.CephClusterNamespace, _ = flags.GetString("namespace")
+	return opts
+}
+
+// getOutputFormat returns the default output format of the ceph and rbd commands
+func getOutputFormat(cmd *cobra.Command) string {
+	format, _ := cmd.Root().PersistentFlags().GetString("format")
+	return format
 }
 
-func GetClientsets(ctx context.Context) *k8sutil.Clientsets {
-	var err error
+// GetContext builds the context of the command from the global flags, and checks the namespaces exist
+func GetContext(cmd *cobra.Command) *k8sutil.Context {
+	flags := cmd.Root().PersistentFlags()
+	c, err := k8sutil.NewContext(getOptions(flags))
+	if err != nil {
+		logging.Fatal(err)
+	}
 
-	clientsets := &k8sutil.Clientsets{}
+	timeout, _ := flags.GetDuration("context-timeout")
+	PreValidationCheck(cmd.Context(), c, timeout)
 
-	congfigOverride := &clientcmd.ConfigOverrides{}
-	if KubeContext != "" {
-		congfigOverride = &clientcmd.ConfigOverrides{CurrentContext: KubeContext}
+	if anonymize, _ := flags.GetBool("anonymize"); anonymize {
+		enableAnonymize(cmd.Context(), c.Clientsets, c.CephClusterNamespace)
 	}
 
-	// 1. Create Kubernetes Client
-	kubeconfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		clientcmd.NewDefaultClientConfigLoadingRules(),
-		congfigOverride,
-	)
+	return c
+}
 
-	clientsets.KubeConfig, err = kubeconfig.ClientConfig()
+// enableAnonymize masks the node names of the cluster and the fsid of the rook-ceph-mon secret, in addition to the ips
+func enableAnonymize(ctx context.Context, clientsets *k8sutil.Clientsets, cephClusterNamespace string) {
+	var nodeNames []string
+	nodes, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
 	if err != nil {
-		logging.Fatal(err)
+		logging.Warning("failed to list the nodes, the node names can't be anonymized. %v", err)
+	} else {
+		for _, node := range nodes.Items {
+			nodeNames = append(nodeNames, node.Name)
+		}
 	}
 
-	clientsets.Rook, err = rookclient.NewForConfig(clientsets.KubeConfig)
+	var fsid string
+	monSecret, err := clientsets.Kube.CoreV1().Secrets(cephClusterNamespace).Get(ctx, "rook-ceph-mon", v1.GetOptions{})
 	if err != nil {
-		logging.Fatal(err)
+		logging.Warning("failed to get the rook-ceph-mon secret, the fsid can't be anonymized. %v", err)
+	} else {
+		fsid = string(monSecret.Data["fsid"])
 	}
 
-	clientsets.Kube, err = k8s.NewForConfig(clientsets.KubeConfig)
+	output.EnableAnonymize(output.NewAnonymizer(nodeNames, fsid))
+}
+
+// PreValidationCheck fails fast when the api server is not reachable within the timeout or a namespace doesn't exist,
+// and warns when the namespaces look swapped
+func PreValidationCheck(ctx context.Context, c *k8sutil.Context, timeout time.Duration) {
+	err := c.Preflight(ctx, timeout)
 	if err != nil {
 		logging.Fatal(err)
 	}
 
-	PreValidationCheck(ctx, clientsets, OperatorNamespace, CephClusterNamespace)
+	for _, hint := range getNamespaceHints(ctx, c.Clientsets.Kube, c.OperatorNamespace, c.CephClusterNamespace) {
+		logging.Warning(hint)
+	}
+}
+
+// getNamespaceHints returns hints when the operator pod is not in the operator namespace or the mon pods are not
+// in the CephCluster namespace, such as when the namespaces are swapped, rather than running a command that
+// finds nothing
+func getNamespaceHints(ctx context.Context, k8sclientset k8s.Interface, operatorNamespace, cephClusterNamespace string) []string {
+	const operatorLabel, monLabel = "app=rook-ceph-operator", "app=rook-ceph-mon"
+	hasPods := func(namespace, label string) bool {
+		// a single pod is enough to know the pods are there
+		pods, err := k8sclientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: label, Limit: 1})
+		// the check is skipped when the pods can't be listed, the command reports the error itself
+		return err != nil || len(pods.Items) != 0
+	}
+
+	operatorFound := hasPods(operatorNamespace, operatorLabel)
+	monsFound := hasPods(cephClusterNamespace, monLabel)
+	if operatorFound && monsFound {
+		return nil
+	}
 
-	return clientsets
+	if !operatorFound && !monsFound && operatorNamespace != cephClusterNamespace &&
+		hasPods(cephClusterNamespace, operatorLabel) && hasPods(operatorNamespace, monLabel) {
+		return []string{fmt.Sprintf("the operator namespace and the CephCluster namespace may be swapped, the operator is running in %q and the mons in %q. Try `--operator-namespace %s --namespace %s`",
+			cephClusterNamespace, operatorNamespace, cephClusterNamespace, operatorNamespace)}
+	}
+
+	var hints []string
+	if !operatorFound {
+		hints = append(hints, fmt.Sprintf("no rook-ceph operator pod found in the operator namespace %q%s", operatorNamespace,
+			findNamespacesHint(ctx, k8sclientset, operatorLabel, "--operator-namespace")))
+	}
+	if !monsFound {
+		hints = append(hints, fmt.Sprintf("no mon pod found in the CephCluster namespace %q%s", cephClusterNamespace,
+			findNamespacesHint(ctx, k8sclientset, monLabel, "--namespace")))
+	}
+	return hints
 }
 
-func PreValidationCheck(ctx context.Context, k8sclientset *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string) {
-	_, err := k8sclientset.Kube.CoreV1().Namespaces().Get(ctx, operatorNamespace, v1.GetOptions{})
-	if err != nil {
-		logging.Fatal(fmt.Errorf("Operator namespace '%s' does not exist. %v", operatorNamespace, err))
+// findNamespacesHint suggests the namespaces of the pods with the label, when the pods of all the namespaces can be listed
+func findNamespacesHint(ctx context.Context, k8sclientset k8s.Interface, label, flag string) string {
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, "", v1.ListOptions{LabelSelector: label})
+	if err != nil || len(pods) == 0 {
+		return ""
 	}
-	_, err = k8sclientset.Kube.CoreV1().Namespaces().Get(ctx, cephClusterNamespace, v1.GetOptions{})
-	if err != nil {
-		logging.Fatal(fmt.Errorf("CephCluster namespace '%s' does not exist. %v", cephClusterNamespace, err))
+	namespaces := map[string]bool{}
+	var found []string
+	for _, pod := range pods {
+		if !namespaces[pod.Namespace] {
+			namespaces[pod.Namespace] = true
+			found = append(found, pod.Namespace)
+		}
 	}
+	return fmt.Sprintf(", they are running in %s. Try `%s %s`", strings.Join(found, ","), flag, found[0])
 }
 
 func VerifyOperatorPodIsRunning(ctx context.Context, k8sclientset *k8sutil.Clientsets, operatorNamespace, cephClusterNamespace string) {
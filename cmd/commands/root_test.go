@@ -15,7 +15,19 @@ limitations under the License.
 
 package command
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
 
 func Test_trimGoVersionFromRookVersion(t *testing.T) {
 	type args struct {
@@ -43,3 +55,84 @@ go: go1.19.10s`
 		})
 	}
 }
+
+func Test_appendFormatFlag(t *testing.T) {
+	args, err := appendFormatFlag([]string{"status"}, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "--format", "json"}, args)
+
+	args, err = appendFormatFlag([]string{"status", "--format=plain"}, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "--format=plain"}, args)
+
+	args, err = appendFormatFlag([]string{"status", "-f", "plain"}, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "-f", "plain"}, args)
+
+	args, err = appendFormatFlag([]string{"status"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status"}, args)
+
+	_, err = appendFormatFlag([]string{"status"}, "xml")
+	assert.Error(t, err)
+}
+
+func Test_extractFlag(t *testing.T) {
+	args, found := extractFlag([]string{"status", "--envelope", "--format", "json"}, "--envelope")
+	assert.True(t, found)
+	assert.Equal(t, []string{"status", "--format", "json"}, args)
+
+	args, found = extractFlag([]string{"status"}, "--envelope")
+	assert.False(t, found)
+	assert.Equal(t, []string{"status"}, args)
+}
+
+func Test_getOptions(t *testing.T) {
+	flags := pflag.NewFlagSet("rook-ceph", pflag.ContinueOnError)
+	addGlobalFlags(flags)
+	err := flags.Parse([]string{"-n", "storage", "--context", "staging", "--request-timeout", "1m", "--as", "admin", "--as-group", "ops"})
+	assert.NoError(t, err)
+
+	opts := getOptions(flags)
+	assert.Equal(t, "storage", opts.CephClusterNamespace)
+	assert.Equal(t, "", opts.OperatorNamespace)
+	assert.Equal(t, "staging", opts.KubeContext)
+	assert.Equal(t, time.Minute, opts.Timeout)
+	assert.Equal(t, "admin", opts.Impersonate)
+	assert.Equal(t, []string{"ops"}, opts.ImpersonateGroups)
+	assert.False(t, opts.InCluster)
+
+	// the in-cluster config ignores the kubeconfig and its context, they are rejected
+	flags = pflag.NewFlagSet("rook-ceph", pflag.ContinueOnError)
+	addGlobalFlags(flags)
+	err = flags.Parse([]string{"--in-cluster", "--context", "staging"})
+	assert.NoError(t, err)
+	opts = getOptions(flags)
+	assert.True(t, opts.InCluster)
+	_, err = k8sutil.NewContext(opts)
+	assert.EqualError(t, err, "--in-cluster uses the service account of the pod, it can't be combined with --kubeconfig or --context")
+}
+
+func Test_getNamespaceHints(t *testing.T) {
+	ctx := context.TODO()
+	newPod := func(name, namespace, app string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}}}
+	}
+	k8s := fake.NewSimpleClientset(
+		newPod("rook-ceph-operator-1", "rook-ceph-system", "rook-ceph-operator"),
+		newPod("rook-ceph-mon-a", "rook-ceph", "rook-ceph-mon"),
+	)
+
+	assert.Empty(t, getNamespaceHints(ctx, k8s, "rook-ceph-system", "rook-ceph"))
+
+	hints := getNamespaceHints(ctx, k8s, "rook-ceph", "rook-ceph-system")
+	assert.Len(t, hints, 1)
+	assert.Contains(t, hints[0], "may be swapped")
+	assert.Contains(t, hints[0], "--operator-namespace rook-ceph-system --namespace rook-ceph")
+
+	hints = getNamespaceHints(ctx, k8s, "rook-ceph-system", "other")
+	assert.Equal(t, []string{`no mon pod found in the CephCluster namespace "other", they are running in rook-ceph. Try ` + "`--namespace rook-ceph`"}, hints)
+
+	hints = getNamespaceHints(ctx, k8s, "rook-ceph", "rook-ceph")
+	assert.Equal(t, []string{`no rook-ceph operator pod found in the operator namespace "rook-ceph", they are running in rook-ceph-system. Try ` + "`--operator-namespace rook-ceph-system`"}, hints)
+}
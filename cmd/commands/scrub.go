@@ -0,0 +1,39 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/scrub"
+	"github.com/spf13/cobra"
+)
+
+// ScrubErrorsCmd represents the scrub-errors command
+var ScrubErrorsCmd = &cobra.Command{
+	Use:   "scrub-errors",
+	Short: "Print the inconsistent pgs and objects reported by the scrubs, and optionally repair the pgs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		repair, _ := cmd.Flags().GetBool("repair")
+		scrub.ScrubErrors(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, repair)
+	},
+}
+
+func init() {
+	ScrubErrorsCmd.Flags().Bool("repair", false, "run 'ceph pg repair' on the inconsistent pgs after confirmation")
+}
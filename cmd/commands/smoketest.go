@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/smoketest"
+	"github.com/spf13/cobra"
+)
+
+// SmokeTestCmd represents the smoke-test command
+var SmokeTestCmd = &cobra.Command{
+	Use:   "smoke-test",
+	Short: "Provision a pvc from a storageclass, write and read it from a pod, and clean up",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		opts := smoketest.Options{StorageClass: args[0]}
+		opts.Type, _ = cmd.Flags().GetString("type")
+		opts.Namespace, _ = cmd.Flags().GetString("test-namespace")
+		opts.Size, _ = cmd.Flags().GetString("size")
+		opts.Image, _ = cmd.Flags().GetString("image")
+		opts.Timeout, _ = cmd.Flags().GetDuration("timeout")
+		smoketest.SmokeTest(cmd.Context(), c.Clientsets, c.OperatorNamespace, opts)
+	},
+}
+
+func init() {
+	SmokeTestCmd.Flags().String("type", smoketest.TypeRbd, "type of the storageclass: 'rbd' or 'cephfs'")
+	SmokeTestCmd.Flags().String("test-namespace", "default", "namespace to create the test pvc and pod in")
+	SmokeTestCmd.Flags().String("size", "1Gi", "size of the test pvc")
+	SmokeTestCmd.Flags().String("image", "", "image of the test pod, the operator image by default")
+	SmokeTestCmd.Flags().Duration("timeout", 5*time.Minute, "timeout of each step of the test")
+}
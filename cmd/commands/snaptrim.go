@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/snaptrim"
+	"github.com/spf13/cobra"
+)
+
+// SnaptrimCmd represents the snaptrim commands
+var SnaptrimCmd = &cobra.Command{
+	Use:   "snaptrim",
+	Short: "Calls subcommands like `status` to monitor the trimming of the deleted snapshots",
+	Args:  cobra.ExactArgs(1),
+}
+
+var snaptrimStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the pgs trimming snapshots and their snap trim queues by pool, and flag a heavy snap trim activity",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		top, _ := cmd.Flags().GetInt("top")
+		snaptrim.Status(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, top)
+	},
+}
+
+func init() {
+	SnaptrimCmd.AddCommand(snaptrimStatusCmd)
+	snaptrimStatusCmd.Flags().Int("top", 10, "number of pgs with the longest snap trim queue to print, 0 to print all of them")
+}
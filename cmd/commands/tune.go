@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/tune"
+	"github.com/spf13/cobra"
+)
+
+// TuneCmd represents the tune commands
+var TuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Calls subcommands like `osd-memory` to show and tune the ceph settings",
+	Args:  cobra.ExactArgs(1),
+}
+
+var osdMemoryCmd = &cobra.Command{
+	Use:   "osd-memory",
+	Short: "Print the osd_memory_target, or set it when a byte count such as 4Gi is passed",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		tune.OsdMemory(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args)
+	},
+}
+
+func init() {
+	TuneCmd.AddCommand(osdMemoryCmd)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/health"
+	"github.com/spf13/cobra"
+)
+
+// UpgradeCmd represents the upgrade commands
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Calls subcommands like `check` to prepare the upgrade of the cluster",
+	Args:  cobra.ExactArgs(1),
+}
+
+var upgradeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the cluster is healthy, recovered, on a single ceph version and has standby daemons, and print whether it can be upgraded",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		acceptedWarnings, _ := cmd.Flags().GetStringSlice("accept-warning")
+		health.UpgradeCheck(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, acceptedWarnings)
+	},
+}
+
+func init() {
+	UpgradeCmd.AddCommand(upgradeCheckCmd)
+	upgradeCheckCmd.Flags().StringSlice("accept-warning", nil, "health warning codes, such as OSD_NEARFULL, that don't block the upgrade in addition to the default ones")
+}
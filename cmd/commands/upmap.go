@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/upmap"
+	"github.com/spf13/cobra"
+)
+
+// UpmapCmd represents the upmap commands
+var UpmapCmd = &cobra.Command{
+	Use:   "upmap",
+	Short: "Calls subcommands like `ls` and `rm <pgid>` to show and remove the pg upmap entries",
+	Args:  cobra.ExactArgs(1),
+}
+
+var upmapLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Print the pg upmap entries of the osd map",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		pool, _ := cmd.Flags().GetString("pool")
+		upmap.List(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, pool)
+	},
+}
+
+var upmapRmCmd = &cobra.Command{
+	Use:   "rm <pgid>",
+	Short: "Remove the pg upmap entries of the pg",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		upmap.Remove(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, args[0])
+	},
+}
+
+func init() {
+	UpmapCmd.AddCommand(upmapLsCmd)
+	UpmapCmd.AddCommand(upmapRmCmd)
+	upmapLsCmd.Flags().String("pool", "", "print only the entries of the pgs of the pool")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+	"github.com/rook/kubectl-rook-ceph/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// VersionCmd represents the version commands
+var VersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version of the plugin, or calls subcommands like `daemons` to show the ceph versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// the version of the plugin doesn't need the cluster, so no context is built
+		outputFormat, _ := cmd.Flags().GetString("output")
+		var formatter output.Formatter
+		if outputFormat != "" {
+			var err error
+			formatter, err = output.NewFormatter(outputFormat, os.Stdout)
+			if err != nil {
+				logging.Fatal(err)
+			}
+		}
+		version.Plugin(formatter)
+	},
+}
+
+var versionDaemonsCmd = &cobra.Command{
+	Use:   "daemons",
+	Short: "Print the ceph versions of the daemons and warn when they are split across versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		version.Daemons(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+	},
+}
+
+func init() {
+	VersionCmd.Flags().StringP("output", "o", "", "output format of the version: json or yaml")
+	VersionCmd.AddCommand(versionDaemonsCmd)
+}
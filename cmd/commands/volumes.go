@@ -0,0 +1,39 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"github.com/rook/kubectl-rook-ceph/pkg/volumes"
+	"github.com/spf13/cobra"
+)
+
+// VolumesCmd represents the volumes command
+var VolumesCmd = &cobra.Command{
+	Use:   "volumes",
+	Short: "Print the pvcs provisioned by the ceph csi drivers with their rbd image or cephfs subvolume and its ceph status",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := GetContext(cmd)
+		VerifyOperatorPodIsRunning(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace)
+		pvcNamespace, _ := cmd.Flags().GetString("pvc-namespace")
+		volumes.Volumes(cmd.Context(), c.Clientsets, c.OperatorNamespace, c.CephClusterNamespace, pvcNamespace)
+	},
+}
+
+func init() {
+	VolumesCmd.Flags().String("pvc-namespace", "", "only print the pvcs of this namespace, the pvcs of all the namespaces by default")
+}
@@ -39,5 +39,44 @@ func addcommands() {
 		command.Health,
 		command.DrCmd,
 		command.RestoreCmd,
+		command.FsCmd,
+		command.OsdCmd,
+		command.CsiCmd,
+		command.DeviceCmd,
+		command.CleanupCmd,
+		command.TuneCmd,
+		command.DashboardCmd,
+		command.BenchCmd,
+		command.AuthCmd,
+		command.VersionCmd,
+		command.ExternalInfoCmd,
+		command.RgwCmd,
+		command.NodeCmd,
+		command.FsidCmd,
+		command.PlacementCmd,
+		command.BalancerCmd,
+		command.ResourcesCmd,
+		command.ConfigCmd,
+		command.ProgressCmd,
+		command.ScrubErrorsCmd,
+		command.KeyCmd,
+		command.DfCmd,
+		command.PoolCmd,
+		command.SmokeTestCmd,
+		command.PrometheusCmd,
+		command.MgrCmd,
+		command.NetworkCmd,
+		command.RecoveryCmd,
+		command.CrushCmd,
+		command.QuotaCmd,
+		command.ReconcileCmd,
+		command.ServicesCmd,
+		command.UpmapCmd,
+		command.DiscoveryCmd,
+		command.BackupCmd,
+		command.VolumesCmd,
+		command.UpgradeCmd,
+		command.ClientCmd,
+		command.SnaptrimCmd,
 	)
 }
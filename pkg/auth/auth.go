@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+type authDump struct {
+	AuthDump []authEntity `json:"auth_dump"`
+}
+
+type authEntity struct {
+	Entity string            `json:"entity"`
+	Key    string            `json:"key"`
+	Caps   map[string]string `json:"caps"`
+}
+
+func List(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, entity string, showKeys bool) {
+	err := list(ctx, clientsets, operatorNamespace, clusterNamespace, entity, showKeys)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func list(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, entity string, showKeys bool) error {
+	authOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"auth", "ls", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	entities, err := unMarshalAuthDump(authOut)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph auth ls output. %v", err)
+	}
+
+	entities = filterEntities(entities, entity)
+	if len(entities) == 0 {
+		return fmt.Errorf("no auth entity matches %q", entity)
+	}
+
+	for _, e := range entities {
+		key := e.Key
+		if !showKeys {
+			key = strings.Repeat("*", 8)
+		}
+		fmt.Println(e.Entity)
+		fmt.Printf("\tkey: %s\n", key)
+		for _, daemonType := range sortedKeys(e.Caps) {
+			fmt.Printf("\tcaps: [%s] %s\n", daemonType, e.Caps[daemonType])
+		}
+	}
+	if !showKeys {
+		fmt.Println()
+		logging.Info("keys are masked, pass --show-keys to print them")
+	}
+	return nil
+}
+
+func unMarshalAuthDump(authOut string) ([]authEntity, error) {
+	var dump authDump
+	err := json.Unmarshal([]byte(authOut), &dump)
+	return dump.AuthDump, err
+}
+
+// filterEntities returns the entities named entity, or prefixed by it such as "client.csi" for all the csi users
+func filterEntities(entities []authEntity, entity string) []authEntity {
+	if entity == "" {
+		return entities
+	}
+
+	var filtered []authEntity
+	for _, e := range entities {
+		if strings.HasPrefix(e.Entity, entity) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func sortedKeys(caps map[string]string) []string {
+	keys := make([]string, 0, len(caps))
+	for key := range caps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterEntities(t *testing.T) {
+	authOut := `{"auth_dump":[
+{"entity":"osd.0","key":"AQAAosd0","caps":{"mgr":"allow profile osd","mon":"allow profile osd","osd":"allow *"}},
+{"entity":"client.csi-rbd-node","key":"AQAArbd","caps":{"mon":"profile rbd","osd":"profile rbd"}},
+{"entity":"client.csi-cephfs-node","key":"AQAAfs","caps":{"mds":"allow rw","mon":"allow r"}}]}`
+
+	entities, err := unMarshalAuthDump(authOut)
+	assert.NoError(t, err)
+	assert.Len(t, entities, 3)
+	assert.Equal(t, "allow *", entities[0].Caps["osd"])
+
+	assert.Len(t, filterEntities(entities, ""), 3)
+	assert.Len(t, filterEntities(entities, "client.csi"), 2)
+	filtered := filterEntities(entities, "osd.0")
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, []string{"mgr", "mon", "osd"}, sortedKeys(filtered[0].Caps))
+	assert.Empty(t, filterEntities(entities, "client.admin"))
+}
+
+func TestBuildKeyring(t *testing.T) {
+	entity := authEntity{Entity: "client.csi-rbd-node", Key: "AQAAold", Caps: map[string]string{"osd": "profile rbd", "mon": "profile rbd", "mgr": "allow rw"}}
+	assert.Equal(t, `[client.csi-rbd-node]
+	key = AQAAnew
+	caps mgr = "allow rw"
+	caps mon = "profile rbd"
+	caps osd = "profile rbd"
+`, buildKeyring(entity, "AQAAnew"))
+
+	assert.Equal(t, []string{"client.csi-cephfs-node", "client.csi-cephfs-provisioner", "client.csi-rbd-node", "client.csi-rbd-provisioner"}, supportedEntities())
+}
+
+func TestParseOsdCapsPools(t *testing.T) {
+	grants, unrestricted := parseOsdCapsPools("profile rbd pool=replicapool, allow rw pool=data namespace=ns-a")
+	assert.Equal(t, []poolGrant{{Pool: "replicapool"}, {Pool: "data", Namespace: "ns-a"}}, grants)
+	assert.False(t, unrestricted)
+
+	grants, unrestricted = parseOsdCapsPools("profile rbd")
+	assert.Empty(t, grants)
+	assert.True(t, unrestricted)
+
+	// the cephfs tag caps are not restricted to a named pool
+	grants, unrestricted = parseOsdCapsPools("allow rw tag cephfs data=myfs")
+	assert.Empty(t, grants)
+	assert.True(t, unrestricted)
+
+	grants, unrestricted = parseOsdCapsPools("")
+	assert.Empty(t, grants)
+	assert.False(t, unrestricted)
+}
+
+func TestGetCapsTests(t *testing.T) {
+	tests, notes := getCapsTests(map[string]string{"mon": "profile rbd", "osd": "profile rbd pool=replicapool", "mgr": "profile rbd pool=replicapool"}, "")
+	assert.Len(t, tests, 2)
+	assert.Equal(t, "mon: read the mon map", tests[0].Description)
+	assert.Equal(t, []string{"--pool", "replicapool", "stat", capsTestObject}, tests[1].Args)
+	assert.Equal(t, []string{"the mgr caps are not tested"}, notes)
+
+	// the pool passed replaces the pools of the caps
+	tests, notes = getCapsTests(map[string]string{"osd": "allow rw"}, "mypool")
+	assert.Len(t, tests, 1)
+	assert.Equal(t, "osd: read pool mypool", tests[0].Description)
+	assert.Equal(t, []string{"the client has no mon caps, it can't connect to the cluster"}, notes)
+}
+
+func TestEvaluateCapsTest(t *testing.T) {
+	rados := &capsTest{Cmd: "rados"}
+	passed, _ := evaluateCapsTest(rados, &exec.CommandResult{ExitCode: 2, Stderr: "error stat-ing replicapool/kubectl-rook-ceph-caps-test: (2) No such file or directory\n"})
+	assert.True(t, passed)
+
+	passed, testErr := evaluateCapsTest(rados, &exec.CommandResult{ExitCode: 1, Stderr: "error stat-ing replicapool/kubectl-rook-ceph-caps-test: (1) Operation not permitted\n"})
+	assert.False(t, passed)
+	assert.Equal(t, "error stat-ing replicapool/kubectl-rook-ceph-caps-test: (1) Operation not permitted", testErr)
+
+	passed, _ = evaluateCapsTest(&capsTest{Cmd: "ceph"}, &exec.CommandResult{ExitCode: 0})
+	assert.True(t, passed)
+
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// capsTestObject is the object stat'ed to test the read access to a pool, it doesn't need to exist since a missing
+// object fails with ENOENT while a denied access fails with EPERM
+const capsTestObject = "kubectl-rook-ceph-caps-test"
+
+// poolGrant is a pool, and optionally a rados namespace, an osd cap grants access to
+type poolGrant struct {
+	Pool      string
+	Namespace string
+}
+
+// capsTest is an operation run with the key of the client to test its caps
+type capsTest struct {
+	Description string
+	Cmd         string
+	Args        []string
+
+	Passed bool
+	Error  string
+}
+
+func VerifyClient(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, name, pool string) {
+	err := verifyClient(ctx, clientsets, operatorNamespace, clusterNamespace, name, pool)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func verifyClient(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, name, pool string) error {
+	entity := name
+	if !strings.HasPrefix(entity, "client.") {
+		entity = "client." + name
+	}
+
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"auth", "get", entity, "--format", "json"}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to get the ceph auth entity %s. %v", entity, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to get the ceph auth entity %s: %s", entity, strings.TrimSpace(result.Stderr))
+	}
+	var entities []authEntity
+	err = json.Unmarshal([]byte(result.Stdout), &entities)
+	if err != nil || len(entities) == 0 {
+		return fmt.Errorf("failed to parse the ceph auth entity %s. %v", entity, err)
+	}
+	client := entities[0]
+
+	fmt.Println(client.Entity)
+	for _, daemonType := range sortedKeys(client.Caps) {
+		fmt.Printf("\tcaps: [%s] %s\n", daemonType, client.Caps[daemonType])
+	}
+	fmt.Println()
+
+	tests, notes := getCapsTests(client.Caps, pool)
+	for _, note := range notes {
+		logging.Info(note)
+	}
+	if len(tests) == 0 {
+		return fmt.Errorf("no operation to test the caps of %s", entity)
+	}
+	keyring := buildKeyring(client, client.Key)
+	for i := range tests {
+		runCapsTest(ctx, clientsets, operatorNamespace, clusterNamespace, entity, keyring, &tests[i])
+	}
+	printCapsTests(os.Stdout, tests)
+
+	fmt.Println()
+	failed := 0
+	for _, test := range tests {
+		if !test.Passed {
+			failed++
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d tests of the caps of %s failed", failed, len(tests), entity)
+	}
+	logging.Info("the %d tests of the caps of %s passed", len(tests), entity)
+	return nil
+}
+
+// getCapsTests returns the operations testing the mon caps and the read access to the pools of the osd caps, or to
+// the pool passed when the osd caps are not restricted to pools. The mds and mgr caps are not tested, they need a
+// mount or a mgr module.
+func getCapsTests(caps map[string]string, pool string) ([]capsTest, []string) {
+	var tests []capsTest
+	var notes []string
+
+	if caps["mon"] != "" {
+		tests = append(tests, capsTest{Description: "mon: read the mon map", Cmd: "ceph", Args: []string{"mon", "dump", "--format", "json"}})
+	} else {
+		notes = append(notes, "the client has no mon caps, it can't connect to the cluster")
+	}
+
+	grants, unrestricted := parseOsdCapsPools(caps["osd"])
+	if pool != "" {
+		grants = []poolGrant{{Pool: pool}}
+	} else if unrestricted {
+		notes = append(notes, "the osd caps are not restricted to pools, pass --pool to test the access to a pool")
+	}
+	for _, grant := range grants {
+		args := []string{"--pool", grant.Pool, "stat", capsTestObject}
+		description := fmt.Sprintf("osd: read pool %s", grant.Pool)
+		if grant.Namespace != "" {
+			args = append(args, "--namespace", grant.Namespace)
+			description = fmt.Sprintf("osd: read pool %s namespace %s", grant.Pool, grant.Namespace)
+		}
+		tests = append(tests, capsTest{Description: description, Cmd: "rados", Args: args})
+	}
+
+	for _, daemonType := range []string{"mds", "mgr"} {
+		if caps[daemonType] != "" {
+			notes = append(notes, fmt.Sprintf("the %s caps are not tested", daemonType))
+		}
+	}
+	return tests, notes
+}
+
+// parseOsdCapsPools returns the pools of the osd caps such as "profile rbd pool=replicapool, allow rw pool=data
+// namespace=ns", and whether a grant is not restricted to a pool
+func parseOsdCapsPools(osdCaps string) ([]poolGrant, bool) {
+	var grants []poolGrant
+	unrestricted := false
+	for _, capGrant := range strings.Split(osdCaps, ",") {
+		fields := strings.Fields(strings.ReplaceAll(capGrant, "=", " "))
+		if len(fields) == 0 {
+			continue
+		}
+		var grant poolGrant
+		for i := 0; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "pool":
+				grant.Pool = strings.Trim(fields[i+1], `"'`)
+			case "namespace":
+				grant.Namespace = strings.Trim(fields[i+1], `"'`)
+			}
+		}
+		if grant.Pool == "" {
+			// the tag caps of cephfs grant the pools of a filesystem by their application metadata
+			unrestricted = true
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	return grants, unrestricted
+}
+
+// runCapsTest runs the operation with the key of the client, the keyring is passed on stdin to a temporary file
+// removed once the command finishes
+func runCapsTest(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, entity, keyring string, test *capsTest) {
+	cmd := []string{test.Cmd, "--name", entity, "--keyring", `"$keyring"`, fmt.Sprintf("--conf=/var/lib/rook/%s/%s.config", clusterNamespace, clusterNamespace)}
+	if test.Cmd == "ceph" {
+		cmd = append(cmd, "--connect-timeout=10")
+	}
+	for _, arg := range test.Args {
+		cmd = append(cmd, shellQuote(arg))
+	}
+	script := fmt.Sprintf(`keyring=$(mktemp) && trap 'rm -f "$keyring"' EXIT && cat > "$keyring" && %s`, strings.Join(cmd, " "))
+
+	result, err := exec.RunCommandInOperatorPodWithInput(ctx, clientsets, "/bin/sh", []string{"-c", script}, operatorNamespace, clusterNamespace, strings.NewReader(keyring))
+	if err != nil {
+		test.Error = err.Error()
+		return
+	}
+	test.Passed, test.Error = evaluateCapsTest(test, result)
+}
+
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func evaluateCapsTest(test *capsTest, result *exec.CommandResult) (bool, string) {
+	stderr := strings.TrimSpace(result.Stderr)
+	if result.ExitCode == 0 {
+		return true, ""
+	}
+	// the test object doesn't exist, the client was allowed to look it up
+	if test.Cmd == "rados" && strings.Contains(stderr, "No such file or directory") {
+		return true, ""
+	}
+	lines := strings.Split(stderr, "\n")
+	return false, lines[len(lines)-1]
+}
+
+func printCapsTests(out io.Writer, tests []capsTest) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RESULT\tTEST\tERROR")
+	for _, test := range tests {
+		result, testErr := "PASS", "-"
+		if !test.Passed {
+			result, testErr = "FAIL", test.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result, test.Description, testErr)
+	}
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// entitySecret is the kubernetes secret of the cluster namespace holding the key of a ceph entity
+type entitySecret struct {
+	name     string
+	keyField string
+}
+
+// entitySecrets are the entities whose key can be rotated. The admin key is not rotated since the
+// operator and the daemons keep using the key they were started with.
+var entitySecrets = map[string]entitySecret{
+	"client.csi-rbd-node":           {name: "rook-csi-rbd-node", keyField: "userKey"},
+	"client.csi-rbd-provisioner":    {name: "rook-csi-rbd-provisioner", keyField: "userKey"},
+	"client.csi-cephfs-node":        {name: "rook-csi-cephfs-node", keyField: "adminKey"},
+	"client.csi-cephfs-provisioner": {name: "rook-csi-cephfs-provisioner", keyField: "adminKey"},
+}
+
+func RotateKey(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, entity string, dryRun bool) {
+	err := rotateKey(ctx, clientsets, operatorNamespace, clusterNamespace, entity, dryRun)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func rotateKey(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, entity string, dryRun bool) error {
+	secretRef, ok := entitySecrets[entity]
+	if !ok {
+		return fmt.Errorf("rotating the key of %q is not supported, the supported entities are %s", entity, strings.Join(supportedEntities(), ", "))
+	}
+
+	authOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"auth", "get", entity, "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var entities []authEntity
+	err := json.Unmarshal([]byte(authOut), &entities)
+	if err != nil || len(entities) == 0 {
+		return fmt.Errorf("failed to get the ceph auth entity %s. %v", entity, err)
+	}
+	current := entities[0]
+
+	secret, err := clientsets.Kube.CoreV1().Secrets(clusterNamespace).Get(ctx, secretRef.name, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the secret %s of entity %s. %v", secretRef.name, entity, err)
+	}
+	if string(secret.Data[secretRef.keyField]) != current.Key {
+		logging.Warning("the %s of secret %s is not the current key of %s", secretRef.keyField, secretRef.name, entity)
+	}
+
+	logging.Info("the key of %s is rotated in ceph, then the %s of secret %s is updated with the new key", entity, secretRef.keyField, secretRef.name)
+	if dryRun {
+		logging.Info("dry-run: no changes were made, pass --dry-run=false to rotate the key")
+		return nil
+	}
+
+	err = logging.Confirm(clusterNamespace, "Rotating the key of %s fails the csi operations using the old key until the secret is updated", entity)
+	if err != nil {
+		return fmt.Errorf("rotating the key of %s cancelled", entity)
+	}
+
+	newKey := strings.TrimSpace(exec.RunCommandInOperatorPod(ctx, clientsets, "ceph-authtool", []string{"--gen-print-key"}, operatorNamespace, clusterNamespace, true, true))
+	if newKey == "" {
+		return fmt.Errorf("failed to generate a new key")
+	}
+
+	// the entity is imported with the new key and its current caps. The keyring is sent on the stdin of the exec,
+	// the key must not be in the command line which shows in the audit logs and the processes of the pod.
+	keyring := strings.NewReader(buildKeyring(current, newKey))
+	result, err := exec.RunCommandInOperatorPodWithInput(ctx, clientsets, "ceph", []string{"auth", "import", "-i", "-"}, operatorNamespace, clusterNamespace, keyring)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import the new key of %s, its key is unchanged. %v", entity, err)
+	}
+	logging.Info("key of %s rotated in ceph", entity)
+
+	secret.Data[secretRef.keyField] = []byte(newKey)
+	_, err = clientsets.Kube.CoreV1().Secrets(clusterNamespace).Update(ctx, secret, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update the secret %s with the new key of %s, set its %s to the key of 'ceph auth get %s'. %v", secretRef.name, entity, secretRef.keyField, entity, err)
+	}
+	logging.Info("secret %s updated with the new key of %s", secretRef.name, entity)
+	return nil
+}
+
+// buildKeyring returns the keyring of the entity with the new key and its caps
+func buildKeyring(entity authEntity, key string) string {
+	var keyring strings.Builder
+	fmt.Fprintf(&keyring, "[%s]\n", entity.Entity)
+	fmt.Fprintf(&keyring, "\tkey = %s\n", key)
+	for _, daemonType := range sortedKeys(entity.Caps) {
+		fmt.Fprintf(&keyring, "\tcaps %s = %q\n", daemonType, entity.Caps[daemonType])
+	}
+	return keyring.String()
+}
+
+func supportedEntities() []string {
+	entities := make([]string, 0, len(entitySecrets))
+	for entity := range entitySecrets {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
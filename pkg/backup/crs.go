@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// CrResources are the rook custom resources backed up, in the order they are re-applied. The CephCluster is
+// first since the other resources are only reconciled once the cluster exists.
+var CrResources = []string{
+	"cephclusters",
+	"cephblockpools",
+	"cephblockpoolradosnamespaces",
+	"cephfilesystems",
+	"cephfilesystemsubvolumegroups",
+	"cephfilesystemmirrors",
+	"cephobjectrealms",
+	"cephobjectzonegroups",
+	"cephobjectzones",
+	"cephobjectstores",
+	"cephobjectstoreusers",
+	"cephbuckettopics",
+	"cephbucketnotifications",
+	"cephnfses",
+	"cephrbdmirrors",
+	"cephclients",
+	"cephcosidrivers",
+}
+
+// runtimeMetadataFields are set by kubernetes on the created resources, they are rejected or ignored when the
+// resources are re-applied
+var runtimeMetadataFields = []string{
+	"managedFields",
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"selfLink",
+	"ownerReferences",
+	"finalizers",
+}
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+func Crs(ctx context.Context, dynamicClient dynamic.Interface, clusterNamespace, path string) {
+	err := crs(ctx, dynamicClient, clusterNamespace, path)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func crs(ctx context.Context, dynamicClient dynamic.Interface, clusterNamespace, path string) error {
+	var objects []unstructured.Unstructured
+	counts := map[string]int{}
+	for _, resource := range CrResources {
+		gvr := schema.GroupVersionResource{Group: "ceph.rook.io", Version: "v1", Resource: resource}
+		list, err := dynamicClient.Resource(gvr).Namespace(clusterNamespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				// the crd is not installed with this version of rook
+				continue
+			}
+			return fmt.Errorf("failed to list %s in namespace %s. %v", resource, clusterNamespace, err)
+		}
+		for _, item := range list.Items {
+			stripRuntimeFields(&item)
+			objects = append(objects, item)
+		}
+		counts[resource] = len(list.Items)
+	}
+	if len(objects) == 0 {
+		return fmt.Errorf("no rook custom resource found in namespace %s", clusterNamespace)
+	}
+
+	content, err := marshalObjects(objects)
+	if err != nil {
+		return err
+	}
+	err = writeBackup(path, content)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range CrResources {
+		if counts[resource] != 0 {
+			logging.Info("backed up %d %s", counts[resource], resource)
+		}
+	}
+	logging.Info("saved %d custom resources to %s, run `kubectl apply -f %s` to re-create them", len(objects), path, path)
+	return nil
+}
+
+// stripRuntimeFields removes the status and the metadata set by kubernetes, so only the declared config is kept
+func stripRuntimeFields(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range runtimeMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[lastAppliedAnnotation]; ok {
+		delete(annotations, lastAppliedAnnotation)
+		if len(annotations) == 0 {
+			unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+		} else {
+			obj.SetAnnotations(annotations)
+		}
+	}
+}
+
+// marshalObjects joins the objects in a multi-document yaml
+func marshalObjects(objects []unstructured.Unstructured) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, obj := range objects {
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s %s. %v", obj.GetKind(), obj.GetName(), err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBackup writes the backup to a new file, an existing file is not overwritten since it may be an earlier backup
+func writeBackup(path string, content []byte) error {
+	err := output.WriteNewFile(path, content)
+	if err != nil {
+		return fmt.Errorf("failed to save the backup. %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newCr(kind, name, namespace string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "ceph.rook.io/v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         namespace,
+			"uid":               "1234",
+			"resourceVersion":   "42",
+			"generation":        int64(3),
+			"creationTimestamp": "2023-10-10T10:00:00Z",
+			"finalizers":        []interface{}{"cephcluster.ceph.rook.io"},
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "rook"}},
+			"labels":            map[string]interface{}{"app": "test"},
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: "{}",
+			},
+		},
+		"spec":   map[string]interface{}{"dataDirHostPath": "/var/lib/rook"},
+		"status": map[string]interface{}{"phase": "Ready"},
+	}}
+}
+
+func TestStripRuntimeFields(t *testing.T) {
+	obj := newCr("CephCluster", "my-cluster", "rook-ceph")
+	stripRuntimeFields(obj)
+
+	assert.Equal(t, map[string]interface{}{
+		"name":      "my-cluster",
+		"namespace": "rook-ceph",
+		"labels":    map[string]interface{}{"app": "test"},
+	}, obj.Object["metadata"])
+	assert.NotContains(t, obj.Object, "status")
+	assert.Equal(t, map[string]interface{}{"dataDirHostPath": "/var/lib/rook"}, obj.Object["spec"])
+
+	// the other annotations are kept
+	obj = newCr("CephBlockPool", "replicapool", "rook-ceph")
+	obj.SetAnnotations(map[string]string{lastAppliedAnnotation: "{}", "owner": "storage-team"})
+	stripRuntimeFields(obj)
+	assert.Equal(t, map[string]string{"owner": "storage-team"}, obj.GetAnnotations())
+}
+
+func TestCrs(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range CrResources {
+		listKinds[schema.GroupVersionResource{Group: "ceph.rook.io", Version: "v1", Resource: resource}] = resource + "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newCr("CephBlockPool", "replicapool", "rook-ceph"),
+		newCr("CephCluster", "my-cluster", "rook-ceph"),
+		newCr("CephBlockPool", "other-pool", "other-ns"),
+	)
+
+	path := filepath.Join(t.TempDir(), "crs.yaml")
+	err := crs(context.TODO(), client, "rook-ceph", path)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	documents := strings.Split(strings.TrimPrefix(string(content), "---\n"), "---\n")
+	assert.Len(t, documents, 2)
+	// the cephcluster is first so it's re-applied before the pools
+	assert.Contains(t, documents[0], "kind: CephCluster")
+	assert.Contains(t, documents[1], "name: replicapool")
+	assert.NotContains(t, string(content), "other-pool")
+	assert.NotContains(t, string(content), "status:")
+	assert.NotContains(t, string(content), "resourceVersion")
+
+	// an existing backup is not overwritten
+	err = crs(context.TODO(), client, "rook-ceph", path)
+	assert.Error(t, err)
+
+	err = crs(context.TODO(), client, "empty-ns", filepath.Join(t.TempDir(), "crs.yaml"))
+	assert.Error(t, err)
+}
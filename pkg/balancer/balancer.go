@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package balancer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+type balancerStatus struct {
+	Active               bool   `json:"active"`
+	Mode                 string `json:"mode"`
+	LastOptimizeStarted  string `json:"last_optimize_started"`
+	LastOptimizeDuration string `json:"last_optimize_duration"`
+	OptimizeResult       string `json:"optimize_result"`
+	NoOptimizationNeeded bool   `json:"no_optimization_needed"`
+}
+
+type osdDump struct {
+	RequireMinCompatClient string `json:"require_min_compat_client"`
+}
+
+// modes are the balancer modes, the upmap modes require luminous or newer clients
+var modes = []string{"none", "crush-compat", "upmap", "read", "upmap-read"}
+
+// preLuminousClients are the releases older than luminous that don't support pg upmap
+var preLuminousClients = []string{"argonaut", "bobtail", "cuttlefish", "dumpling", "emperor", "firefly", "giant", "hammer", "infernalis", "jewel", "kraken"}
+
+func Status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	statusOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"balancer", "status", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var status balancerStatus
+	err := json.Unmarshal([]byte(statusOut), &status)
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to parse ceph balancer status output. %v", err))
+	}
+
+	fmt.Printf("mode: %s\n", status.Mode)
+	fmt.Printf("active: %t\n", status.Active)
+	fmt.Printf("last optimization: %s (took %s)\n", status.LastOptimizeStarted, status.LastOptimizeDuration)
+	fmt.Printf("last optimization result: %s\n", status.OptimizeResult)
+	fmt.Println()
+	if !status.Active {
+		logging.Warning("the balancer is off, the pg distribution is not balanced automatically")
+	} else if status.Mode == "none" {
+		logging.Warning("the balancer is on with mode none, set a mode to balance the pg distribution")
+	}
+}
+
+func SetActive(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, active bool) {
+	state := "off"
+	if active {
+		state = "on"
+	}
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"balancer", state}, operatorNamespace, clusterNamespace, false, true)
+	logging.Info("balancer turned %s", state)
+}
+
+func SetMode(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, mode string) {
+	err := validateMode(mode)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if strings.Contains(mode, "upmap") || mode == "read" {
+		dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+		var dump osdDump
+		err = json.Unmarshal([]byte(dumpOut), &dump)
+		if err != nil {
+			logging.Fatal(fmt.Errorf("failed to parse ceph osd dump output. %v", err))
+		}
+		err = validateMinCompatClient(mode, dump.RequireMinCompatClient)
+		if err != nil {
+			logging.Fatal(err)
+		}
+	}
+
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"balancer", "mode", mode}, operatorNamespace, clusterNamespace, false, true)
+	logging.Info("balancer mode set to %s", mode)
+}
+
+func validateMode(mode string) error {
+	for _, m := range modes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid balancer mode %q, supported modes are %s", mode, strings.Join(modes, ", "))
+}
+
+// validateMinCompatClient checks the clients are recent enough for the pg upmap entries of the mode
+func validateMinCompatClient(mode, minCompatClient string) error {
+	for _, release := range preLuminousClients {
+		if release == minCompatClient {
+			return fmt.Errorf("mode %s requires luminous or newer clients while require_min_compat_client is %s, "+
+				"set it with `ceph osd set-require-min-compat-client luminous` once all the clients are upgraded", mode, minCompatClient)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package balancer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMode(t *testing.T) {
+	assert.NoError(t, validateMode("upmap"))
+	assert.NoError(t, validateMode("crush-compat"))
+	assert.Error(t, validateMode("fast"))
+
+	assert.NoError(t, validateMinCompatClient("upmap", "luminous"))
+	assert.NoError(t, validateMinCompatClient("upmap", "reef"))
+	assert.Error(t, validateMinCompatClient("upmap", "jewel"))
+}
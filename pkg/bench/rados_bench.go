@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// benchResultKeys are the summary lines of the rados bench output
+var benchResultKeys = []string{"Bandwidth (MB/sec):", "Average IOPS:", "Average Latency(s):", "Max latency(s):", "Min latency(s):"}
+
+func RadosBench(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pool string, duration time.Duration, objectSize string) {
+	err := radosBench(ctx, clientsets, operatorNamespace, clusterNamespace, pool, duration, objectSize)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func radosBench(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pool string, duration time.Duration, objectSize string) error {
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		return fmt.Errorf("invalid duration %s, the duration must be at least 1s", duration)
+	}
+	size, err := resource.ParseQuantity(objectSize)
+	if err != nil || size.Value() <= 0 {
+		return fmt.Errorf("invalid object size %q, expected a byte count such as 4Mi", objectSize)
+	}
+
+	// the benchmark must never write to or delete a pool that may contain data
+	poolsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "ls"}, operatorNamespace, clusterNamespace, true, true)
+	for _, existingPool := range strings.Split(poolsOut, "\n") {
+		if strings.TrimSpace(existingPool) == pool {
+			return fmt.Errorf("pool %q already exists, refusing to run the benchmark against an existing pool", pool)
+		}
+	}
+
+	logging.Info("creating the scratch pool %s", pool)
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "create", pool}, operatorNamespace, clusterNamespace, false, true)
+	defer deletePool(ctx, clientsets, operatorNamespace, clusterNamespace, pool)
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "application", "enable", pool, "rados"}, operatorNamespace, clusterNamespace, false, false)
+
+	// the benchmark is interrupted on a signal, while the pool is still deleted with the parent context
+	benchCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logging.Info("running the write benchmark for %s with %s objects", duration, objectSize)
+	writeOut := exec.RunCommandInOperatorPod(benchCtx, clientsets, "rados", []string{"bench", "-p", pool, fmt.Sprint(seconds), "write", "-b", fmt.Sprint(size.Value()), "--no-cleanup"}, operatorNamespace, clusterNamespace, true, false)
+	if benchCtx.Err() != nil {
+		return fmt.Errorf("benchmark interrupted")
+	}
+
+	logging.Info("running the sequential read benchmark for %s", duration)
+	readOut := exec.RunCommandInOperatorPod(benchCtx, clientsets, "rados", []string{"bench", "-p", pool, fmt.Sprint(seconds), "seq"}, operatorNamespace, clusterNamespace, true, false)
+	if benchCtx.Err() != nil {
+		return fmt.Errorf("benchmark interrupted")
+	}
+
+	fmt.Println()
+	logging.Info("write results")
+	printBenchResults(writeOut)
+	fmt.Println()
+	logging.Info("sequential read results")
+	printBenchResults(readOut)
+	fmt.Println()
+	return nil
+}
+
+func printBenchResults(benchOut string) {
+	for _, result := range parseBenchResults(benchOut) {
+		fmt.Println(result)
+	}
+}
+
+// parseBenchResults returns the bandwidth, iops and latency summary lines of the rados bench output
+func parseBenchResults(benchOut string) []string {
+	var results []string
+	for _, line := range strings.Split(benchOut, "\n") {
+		line = strings.TrimSpace(line)
+		for _, key := range benchResultKeys {
+			if strings.HasPrefix(line, key) {
+				results = append(results, fmt.Sprintf("%s %s", key, strings.TrimSpace(strings.TrimPrefix(line, key))))
+			}
+		}
+	}
+	return results
+}
+
+// deletePool deletes the scratch pool, temporarily allowing pool deletion on the mons if needed
+func deletePool(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pool string) {
+	logging.Info("deleting the scratch pool %s", pool)
+	allowPoolDelete := strings.TrimSpace(exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "get", "mon", "mon_allow_pool_delete"}, operatorNamespace, clusterNamespace, true, false))
+	if allowPoolDelete != "true" {
+		exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "set", "mon", "mon_allow_pool_delete", "true"}, operatorNamespace, clusterNamespace, false, false)
+		defer exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "set", "mon", "mon_allow_pool_delete", "false"}, operatorNamespace, clusterNamespace, false, false)
+	}
+
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "rm", pool, pool, "--yes-i-really-really-mean-it"}, operatorNamespace, clusterNamespace, false, false)
+}
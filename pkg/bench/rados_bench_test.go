@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWriteOut = `hints = 1
+Maintaining 16 concurrent writes of 4194304 bytes to objects of size 4194304 for up to 10 seconds or 0 objects
+Object prefix: benchmark_data_rook-ceph-operator-7b8f9c6d4-x2x_42
+  sec Cur ops   started  finished  avg MB/s  cur MB/s last lat(s)  avg lat(s)
+    0       0         0         0         0         0           -           0
+    1      16        28        12   47.9936        48    0.864286    0.663947
+    2      16        58        42   83.9839       120    0.412965    0.611845
+   10      16       301       285   113.986       112    0.491734    0.552528
+Total time run:         10.4576
+Total writes made:      301
+Write size:             4194304
+Object size:            4194304
+Bandwidth (MB/sec):     115.131
+Stddev Bandwidth:       12.3458
+Max bandwidth (MB/sec): 136
+Min bandwidth (MB/sec): 96
+Average IOPS:           28
+Stddev IOPS:            3.08645
+Max IOPS:               34
+Min IOPS:               24
+Average Latency(s):     0.552528
+Stddev Latency(s):      0.197571
+Max latency(s):         1.32674
+Min latency(s):         0.0906717
+`
+
+const testSeqOut = `hints = 1
+  sec Cur ops   started  finished  avg MB/s  cur MB/s last lat(s)  avg lat(s)
+    0       0         0         0         0         0           -           0
+    1      16        70        54   215.789       216    0.194511    0.238784
+    5      16       301       285   222.156       220    0.261448    0.285231
+Total time run:       5.42373
+Total reads made:     301
+Read size:            4194304
+Object size:          4194304
+Bandwidth (MB/sec):   221.987
+Average IOPS:         55
+Stddev IOPS:          4.03733
+Max IOPS:             62
+Min IOPS:             50
+Average Latency(s):   0.285231
+Max latency(s):       0.673214
+Min latency(s):       0.0551674
+`
+
+func TestParseBenchResults(t *testing.T) {
+	assert.Equal(t, []string{
+		"Bandwidth (MB/sec): 115.131",
+		"Average IOPS: 28",
+		"Average Latency(s): 0.552528",
+		"Max latency(s): 1.32674",
+		"Min latency(s): 0.0906717",
+	}, parseBenchResults(testWriteOut))
+
+	assert.Equal(t, []string{
+		"Bandwidth (MB/sec): 221.987",
+		"Average IOPS: 55",
+		"Average Latency(s): 0.285231",
+		"Max latency(s): 0.673214",
+		"Min latency(s): 0.0551674",
+	}, parseBenchResults(testSeqOut))
+
+	// an interrupted benchmark has no summary
+	assert.Empty(t, parseBenchResults("hints = 1\n  sec Cur ops   started  finished  avg MB/s  cur MB/s last lat(s)  avg lat(s)\n"))
+}
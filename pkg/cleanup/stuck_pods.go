@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func StuckPods(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, gracePeriod time.Duration, dryRun bool) {
+	err := stuckPods(ctx, k8sclientset, clusterNamespace, gracePeriod, dryRun)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func stuckPods(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, gracePeriod time.Duration, dryRun bool) error {
+	// rook sets the rook_cluster label on all the ceph daemon pods of the cluster
+	opts := v1.ListOptions{LabelSelector: fmt.Sprintf("rook_cluster=%s", clusterNamespace)}
+	podList, err := k8sutil.ListPods(ctx, k8sclientset, clusterNamespace, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list pods with label %s. %v", opts.LabelSelector, err)
+	}
+
+	pods := findStuckPods(podList, time.Now(), gracePeriod)
+	if len(pods) == 0 {
+		logging.Info("no pods stuck in terminating state for more than %s", gracePeriod)
+		return nil
+	}
+
+	logging.Info("Pods stuck in terminating state for more than %s", gracePeriod)
+	for _, pod := range pods {
+		fmt.Printf("%s\t%s\t%s\tterminating since %s\n", pod.Name, pod.Namespace, pod.Spec.NodeName, pod.DeletionTimestamp.Format(time.RFC3339))
+	}
+	fmt.Println()
+
+	if dryRun {
+		logging.Info("dry-run: no pods were deleted")
+		return nil
+	}
+
+	err = logging.Confirm(clusterNamespace, "Force deleting %d pods skips their graceful shutdown, make sure the nodes of the pods are down", len(pods))
+	if err != nil {
+		return fmt.Errorf("force deleting the stuck pods cancelled")
+	}
+
+	gracePeriodSeconds := int64(0)
+	var failed []string
+	for _, pod := range pods {
+		err := k8sclientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, v1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds})
+		if err != nil && !kerrors.IsNotFound(err) {
+			logging.Error(fmt.Errorf("failed to force delete pod %s. %v", pod.Name, err))
+			failed = append(failed, pod.Name)
+			continue
+		}
+		logging.Info("pod %s force deleted", pod.Name)
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to force delete pods %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// findStuckPods returns the pods that are still terminating gracePeriod after their deletion timestamp
+func findStuckPods(pods []corev1.Pod, now time.Time, gracePeriod time.Duration) []corev1.Pod {
+	var stuck []corev1.Pod
+	for _, pod := range pods {
+		if pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if now.Sub(pod.DeletionTimestamp.Time) > gracePeriod {
+			stuck = append(stuck, pod)
+		}
+	}
+	return stuck
+}
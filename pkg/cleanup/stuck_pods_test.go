@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindStuckPods(t *testing.T) {
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	deletedAt := func(ago time.Duration) *v1.Time {
+		deleted := v1.NewTime(now.Add(-ago))
+		return &deleted
+	}
+	pods := []corev1.Pod{
+		{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-osd-0"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-osd-1", DeletionTimestamp: deletedAt(2 * time.Minute)}},
+		{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-mon-a", DeletionTimestamp: deletedAt(10 * time.Minute)}},
+	}
+
+	stuck := findStuckPods(pods, now, 5*time.Minute)
+	assert.Len(t, stuck, 1)
+	assert.Equal(t, "rook-ceph-mon-a", stuck[0].Name)
+
+	stuck = findStuckPods(pods, now, time.Minute)
+	assert.Len(t, stuck, 2)
+	assert.Equal(t, "rook-ceph-osd-1", stuck[0].Name)
+
+	assert.Empty(t, findStuckPods(pods, now, time.Hour))
+}
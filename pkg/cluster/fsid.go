@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func Fsid(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	err := fsid(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func fsid(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+
+	// the identity is read from the mon secret, which is available even when the mons are down
+	fsid, cephClusterName, err := getMonSecretIdentity(ctx, clientsets.Kube, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	// the exec adds --connect-timeout to the ceph commands, `ceph fsid` gives up after its timeout when the mons
+	// have no quorum
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"fsid"}, operatorNamespace, clusterNamespace)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	if err != nil {
+		logging.Warning("failed to get the fsid from ceph, the mons may be unreachable. Printing the fsid of the rook-ceph-mon secret. %v", err)
+	} else if cephFsid := strings.TrimSpace(result.Stdout); cephFsid != "" && cephFsid != fsid {
+		logging.Warning("the fsid %q reported by ceph differs from the fsid %q of the rook-ceph-mon secret", cephFsid, fsid)
+	}
+
+	fmt.Printf("fsid: %s\n", fsid)
+	fmt.Printf("ceph cluster name: %s\n", cephClusterName)
+	fmt.Printf("CephCluster: %s/%s\n", cephCluster.Namespace, cephCluster.Name)
+	return nil
+}
+
+// getMonSecretIdentity returns the fsid and the ceph cluster name of the rook-ceph-mon secret. The ceph cluster name
+// is the namespace when the secret doesn't set it.
+func getMonSecretIdentity(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) (string, string, error) {
+	monSecret, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, "rook-ceph-mon", v1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the rook-ceph-mon secret. %v", err)
+	}
+	cephClusterName := string(monSecret.Data["cluster-name"])
+	if cephClusterName == "" {
+		cephClusterName = clusterNamespace
+	}
+	return string(monSecret.Data["fsid"]), cephClusterName, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetMonSecretIdentity(t *testing.T) {
+	ctx := context.TODO()
+	newSecret := func(namespace string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-mon", Namespace: namespace}, Data: data}
+	}
+	k8s := fake.NewSimpleClientset(
+		newSecret("rook-ceph", map[string][]byte{"fsid": []byte("b0fa7ae4-1e5c-4a8a-a6a0-2d0e3d7c8f11"), "cluster-name": []byte("my-cluster")}),
+		newSecret("legacy", map[string][]byte{"fsid": []byte("c3a1e9f2-44b0-4d7e-9a3e-5f6b7c8d9e00")}),
+	)
+
+	fsid, name, err := getMonSecretIdentity(ctx, k8s, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, "b0fa7ae4-1e5c-4a8a-a6a0-2d0e3d7c8f11", fsid)
+	assert.Equal(t, "my-cluster", name)
+
+	// the ceph cluster name defaults to the namespace
+	fsid, name, err = getMonSecretIdentity(ctx, k8s, "legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "c3a1e9f2-44b0-4d7e-9a3e-5f6b7c8d9e00", fsid)
+	assert.Equal(t, "legacy", name)
+
+	_, _, err = getMonSecretIdentity(ctx, k8s, "missing")
+	assert.Error(t, err)
+}
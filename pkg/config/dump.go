@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	scopeGlobal = "global"
+	scopeType   = "type"
+	scopeDaemon = "daemon"
+)
+
+type configEntry struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Mask    string `json:"mask"`
+}
+
+func Dump(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, who string) {
+	err := dump(ctx, clientsets, operatorNamespace, clusterNamespace, who)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func dump(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, who string) error {
+	entries, err := parseEntries(getConfigDump(ctx, clientsets, operatorNamespace, clusterNamespace))
+	if err != nil {
+		return err
+	}
+
+	entries = filterEntries(entries, who)
+	if len(entries) == 0 {
+		logging.Info("no config is set for %q", who)
+		return nil
+	}
+	printEntries(os.Stdout, entries)
+	return nil
+}
+
+func getConfigDump(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) string {
+	return exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+}
+
+func parseEntries(dumpOut string) ([]configEntry, error) {
+	var entries []configEntry
+	err := json.Unmarshal([]byte(dumpOut), &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph config dump output. %v", err)
+	}
+	return entries, nil
+}
+
+// filterEntries returns the entries of the section who, including the daemons of a daemon type such as osd.0 for osd
+func filterEntries(entries []configEntry, who string) []configEntry {
+	if who == "" {
+		return entries
+	}
+
+	var filtered []configEntry
+	for _, entry := range entries {
+		if entry.Section == who || strings.HasPrefix(entry.Section, who+".") {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// getScope returns the level the option was set at. A daemon config overrides the config of its
+// daemon type, which overrides the global config.
+func getScope(section string) string {
+	if section == "global" {
+		return scopeGlobal
+	}
+	if strings.Contains(section, ".") {
+		return scopeDaemon
+	}
+	return scopeType
+}
+
+func printEntries(out io.Writer, entries []configEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "WHO\tSCOPE\tMASK\tOPTION\tVALUE")
+	for _, entry := range entries {
+		mask := entry.Mask
+		if mask == "" {
+			mask = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Section, getScope(entry.Section), mask, entry.Name, entry.Value)
+	}
+	w.Flush()
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterEntries(t *testing.T) {
+	entries := []configEntry{
+		{Section: "global", Name: "mon_allow_pool_delete", Value: "true"},
+		{Section: "osd", Name: "osd_memory_target", Value: "4294967296", Mask: "host:node-1"},
+		{Section: "osd.0", Name: "osd_max_backfills", Value: "2"},
+		{Section: "osdx", Name: "unrelated", Value: "1"},
+		{Section: "mon", Name: "mon_data_avail_warn", Value: "10"},
+	}
+
+	assert.Len(t, filterEntries(entries, ""), 5)
+	osdEntries := filterEntries(entries, "osd")
+	assert.Len(t, osdEntries, 2)
+	assert.Equal(t, "osd.0", osdEntries[1].Section)
+	assert.Len(t, filterEntries(entries, "osd.0"), 1)
+	assert.Empty(t, filterEntries(entries, "mds"))
+
+	assert.Equal(t, scopeGlobal, getScope("global"))
+	assert.Equal(t, scopeType, getScope("osd"))
+	assert.Equal(t, scopeDaemon, getScope("client.rgw.my.store.a"))
+
+	var out bytes.Buffer
+	printEntries(&out, osdEntries)
+	assert.Regexp(t, `osd\s+type\s+host:node-1\s+osd_memory_target\s+4294967296`, out.String())
+	assert.Regexp(t, `osd.0\s+daemon\s+-\s+osd_max_backfills\s+2`, out.String())
+}
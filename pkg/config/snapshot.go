@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+type configChange struct {
+	Change   string
+	Section  string
+	Mask     string
+	Name     string
+	OldValue string
+	NewValue string
+}
+
+// configKey identifies an option of the config, the same option can be set in several sections and masks
+type configKey struct {
+	Section string
+	Mask    string
+	Name    string
+}
+
+func Snapshot(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path string) {
+	err := snapshot(ctx, clientsets, operatorNamespace, clusterNamespace, path)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func snapshot(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path string) error {
+	dumpOut := getConfigDump(ctx, clientsets, operatorNamespace, clusterNamespace)
+	entries, err := parseEntries(dumpOut)
+	if err != nil {
+		return err
+	}
+	err = writeSnapshot(path, dumpOut)
+	if err != nil {
+		return err
+	}
+	logging.Info("saved %d config options to %s, run `kubectl rook-ceph config compare %s` to compare them with the config", len(entries), path, path)
+	return nil
+}
+
+// writeSnapshot writes the config dump to a new file, an existing file is not overwritten since it may be an
+// earlier snapshot
+func writeSnapshot(path, dumpOut string) error {
+	err := output.WriteNewFile(path, []byte(dumpOut))
+	if err != nil {
+		return fmt.Errorf("failed to save the config snapshot. %v", err)
+	}
+	return nil
+}
+
+func Compare(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path string) {
+	err := compare(ctx, clientsets, operatorNamespace, clusterNamespace, path)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func compare(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path string) error {
+	snapshotOut, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the config snapshot. %v", err)
+	}
+	oldEntries, err := parseEntries(string(snapshotOut))
+	if err != nil {
+		return fmt.Errorf("failed to parse the config snapshot %s. %v", path, err)
+	}
+	newEntries, err := parseEntries(getConfigDump(ctx, clientsets, operatorNamespace, clusterNamespace))
+	if err != nil {
+		return err
+	}
+
+	changes := diffEntries(oldEntries, newEntries)
+	if len(changes) == 0 {
+		logging.Info("the config matches the snapshot %s", path)
+		return nil
+	}
+	printChanges(os.Stdout, changes)
+	fmt.Println()
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Change]++
+	}
+	logging.Info("%d options added, %d removed and %d changed since the snapshot %s", counts[changeAdded], counts[changeRemoved], counts[changeChanged], path)
+	return nil
+}
+
+// diffEntries returns the options added, removed and changed from the old entries to the new entries, sorted by
+// section and option
+func diffEntries(oldEntries, newEntries []configEntry) []configChange {
+	oldValues := map[configKey]string{}
+	for _, entry := range oldEntries {
+		oldValues[configKey{Section: entry.Section, Mask: entry.Mask, Name: entry.Name}] = entry.Value
+	}
+	newValues := map[configKey]string{}
+	for _, entry := range newEntries {
+		newValues[configKey{Section: entry.Section, Mask: entry.Mask, Name: entry.Name}] = entry.Value
+	}
+
+	var changes []configChange
+	for key, newValue := range newValues {
+		oldValue, ok := oldValues[key]
+		if !ok {
+			changes = append(changes, configChange{Change: changeAdded, Section: key.Section, Mask: key.Mask, Name: key.Name, NewValue: newValue})
+		} else if oldValue != newValue {
+			changes = append(changes, configChange{Change: changeChanged, Section: key.Section, Mask: key.Mask, Name: key.Name, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, oldValue := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			changes = append(changes, configChange{Change: changeRemoved, Section: key.Section, Mask: key.Mask, Name: key.Name, OldValue: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].Mask < changes[j].Mask
+	})
+	return changes
+}
+
+func printChanges(out io.Writer, changes []configChange) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tWHO\tMASK\tOPTION\tSNAPSHOT\tCURRENT")
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Change, change.Section, orDash(change.Mask), change.Name, orDash(change.OldValue), orDash(change.NewValue))
+	}
+	w.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
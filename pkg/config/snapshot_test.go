@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffEntries(t *testing.T) {
+	oldEntries := []configEntry{
+		{Section: "global", Name: "mon_allow_pool_delete", Value: "true"},
+		{Section: "osd", Name: "osd_memory_target", Value: "4294967296", Mask: "host:node-1"},
+		{Section: "osd", Name: "osd_memory_target", Value: "4294967296"},
+		{Section: "osd.0", Name: "osd_max_backfills", Value: "2"},
+	}
+	newEntries := []configEntry{
+		{Section: "global", Name: "mon_allow_pool_delete", Value: "true"},
+		{Section: "osd", Name: "osd_memory_target", Value: "8589934592", Mask: "host:node-1"},
+		{Section: "osd", Name: "osd_memory_target", Value: "4294967296"},
+		{Section: "mon", Name: "mon_data_avail_warn", Value: "10"},
+	}
+	assert.Empty(t, diffEntries(oldEntries, oldEntries))
+
+	changes := diffEntries(oldEntries, newEntries)
+	assert.Equal(t, []configChange{
+		{Change: changeAdded, Section: "mon", Name: "mon_data_avail_warn", NewValue: "10"},
+		{Change: changeChanged, Section: "osd", Mask: "host:node-1", Name: "osd_memory_target", OldValue: "4294967296", NewValue: "8589934592"},
+		{Change: changeRemoved, Section: "osd.0", Name: "osd_max_backfills", OldValue: "2"},
+	}, changes)
+
+	var out bytes.Buffer
+	printChanges(&out, changes)
+	assert.Regexp(t, `added\s+mon\s+-\s+mon_data_avail_warn\s+-\s+10`, out.String())
+	assert.Regexp(t, `changed\s+osd\s+host:node-1\s+osd_memory_target\s+4294967296\s+8589934592`, out.String())
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, writeSnapshot(path, `[{"section": "global", "name": "mon_allow_pool_delete", "value": "true"}]`))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	entries, err := parseEntries(string(content))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// an earlier snapshot is not overwritten
+	assert.Error(t, writeSnapshot(path, "[]"))
+}
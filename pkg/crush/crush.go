@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+// Dump prints the decompiled crush map, or renders the crush map of `ceph osd crush dump` with the formatter
+// when it's not nil
+func Dump(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, formatter output.Formatter) {
+	err := dump(ctx, clientsets, operatorNamespace, clusterNamespace, formatter)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func dump(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, formatter output.Formatter) error {
+	if formatter != nil {
+		dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "crush", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+		var crushMap any
+		err := json.Unmarshal([]byte(dumpOut), &crushMap)
+		if err != nil {
+			return fmt.Errorf("failed to parse ceph osd crush dump output. %v", err)
+		}
+		return formatter.Render(crushMap)
+	}
+
+	paths := newPodPaths()
+	defer paths.cleanup(ctx, clientsets, operatorNamespace, clusterNamespace)
+	crushMap, err := getCrushMap(ctx, clientsets, operatorNamespace, clusterNamespace, paths.current)
+	if err != nil {
+		return err
+	}
+	fmt.Print(crushMap)
+	return nil
+}
+
+// Set compiles the crush map of the file and sets it as the crush map of the cluster, once the current crush
+// map is saved to the backup file
+func Set(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path, backupPath string) {
+	err := set(ctx, clientsets, operatorNamespace, clusterNamespace, path, backupPath)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func set(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path, backupPath string) error {
+	newCrushMap, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the crush map. %v", err)
+	}
+
+	paths := newPodPaths()
+	defer paths.cleanup(ctx, clientsets, operatorNamespace, clusterNamespace)
+	currentCrushMap, err := getCrushMap(ctx, clientsets, operatorNamespace, clusterNamespace, paths.current)
+	if err != nil {
+		return err
+	}
+	err = writeBackup(backupPath, currentCrushMap)
+	if err != nil {
+		return err
+	}
+	logging.Info("the current crush map is saved to %s, run `kubectl rook-ceph crush set %s --backup <file>` to restore it", backupPath, backupPath)
+
+	// the crush map is compiled in the operator pod, so a syntax error is reported before anything changes
+	result, err := exec.RunCommandInOperatorPodWithInput(ctx, clientsets, "crushtool", []string{"-c", "/dev/stdin", "-o", paths.new}, operatorNamespace, clusterNamespace, bytes.NewReader(newCrushMap))
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to compile the crush map of %s. %s", path, strings.TrimSpace(result.Stderr))
+	}
+	compiled, err := runCrushtool(ctx, clientsets, operatorNamespace, clusterNamespace, "-d", paths.new)
+	if err != nil {
+		return err
+	}
+	if compiled == currentCrushMap {
+		logging.Info("the crush map of %s matches the crush map of the cluster, nothing to set", path)
+		return nil
+	}
+
+	err = logging.Confirm(clusterNamespace, "The crush map of the cluster is replaced by the crush map of %s, which can move the data of all the pools", path)
+	if err != nil {
+		return fmt.Errorf("setting the crush map cancelled")
+	}
+	result, err = exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"osd", "setcrushmap", "-i", paths.new}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to set the crush map. %s", strings.TrimSpace(result.Stderr))
+	}
+	logging.Info("crush map of %s set, run `ceph -s` to follow the data movement", path)
+	return nil
+}
+
+// podPaths are the files of the binary crush maps in the operator pod, unique to each run
+type podPaths struct {
+	current string
+	new     string
+}
+
+func newPodPaths() podPaths {
+	prefix := fmt.Sprintf("/tmp/kubectl-rook-ceph-crushmap-%d", time.Now().UnixNano())
+	return podPaths{current: prefix + ".current", new: prefix + ".new"}
+}
+
+func (p podPaths) cleanup(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	_, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "rm", []string{"-f", p.current, p.new}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Warning("failed to remove the crush map files %s and %s from the operator pod. %v", p.current, p.new, err)
+	}
+}
+
+// getCrushMap returns the decompiled crush map of the cluster, its binary map is saved to the path in the operator pod
+func getCrushMap(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, path string) (string, error) {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"osd", "getcrushmap", "-o", path}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get the crush map. %s", strings.TrimSpace(result.Stderr))
+	}
+	return runCrushtool(ctx, clientsets, operatorNamespace, clusterNamespace, "-d", path)
+}
+
+func runCrushtool(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args ...string) (string, error) {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "crushtool", args, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to run crushtool %s. %s", strings.Join(args, " "), strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+// writeBackup writes the crush map to a new backup file, an existing file is not overwritten since it may be the
+// backup of an earlier crush map
+func writeBackup(path, crushMap string) error {
+	err := output.WriteNewFile(path, []byte(crushMap))
+	if err != nil {
+		return fmt.Errorf("failed to save the backup of the crush map. %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	rbdDriverSuffix    = "rbd.csi.ceph.com"
+	cephfsDriverSuffix = "cephfs.csi.ceph.com"
+)
+
+var csiSecrets = []string{
+	"rook-csi-rbd-provisioner",
+	"rook-csi-rbd-node",
+	"rook-csi-cephfs-provisioner",
+	"rook-csi-cephfs-node",
+}
+
+var csiServiceAccounts = []string{
+	"rook-csi-rbd-provisioner-sa",
+	"rook-csi-rbd-plugin-sa",
+	"rook-csi-cephfs-provisioner-sa",
+	"rook-csi-cephfs-plugin-sa",
+}
+
+// storageClassSecretParams are the storageclass parameters referencing a secret name and its namespace
+var storageClassSecretParams = map[string]string{
+	"csi.storage.k8s.io/provisioner-secret-name":       "csi.storage.k8s.io/provisioner-secret-namespace",
+	"csi.storage.k8s.io/controller-expand-secret-name": "csi.storage.k8s.io/controller-expand-secret-namespace",
+	"csi.storage.k8s.io/node-stage-secret-name":        "csi.storage.k8s.io/node-stage-secret-namespace",
+}
+
+func CheckConfig(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string) {
+	logging.Info("Checking CSI secrets, service accounts, RBAC and storageclasses")
+	issues := checkConfig(ctx, k8sclientset, operatorNamespace, clusterNamespace)
+	if len(issues) == 0 {
+		logging.Info("No issues found in the CSI configuration")
+		return
+	}
+
+	for _, issue := range issues {
+		logging.Error(fmt.Errorf(issue))
+	}
+	logging.Warning("%d issues found in the CSI configuration", len(issues))
+}
+
+func checkConfig(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string) []string {
+	var issues []string
+
+	for _, secretName := range csiSecrets {
+		_, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, secretName, v1.GetOptions{})
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("secret %s/%s: %v", clusterNamespace, secretName, err))
+		}
+	}
+
+	issues = append(issues, checkServiceAccountsAndRBAC(ctx, k8sclientset, operatorNamespace)...)
+	issues = append(issues, checkStorageClasses(ctx, k8sclientset, operatorNamespace, clusterNamespace)...)
+	return issues
+}
+
+func checkServiceAccountsAndRBAC(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) []string {
+	var issues []string
+
+	bindings, err := k8sclientset.RbacV1().ClusterRoleBindings().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("failed to list clusterrolebindings: %v", err)}
+	}
+
+	boundServiceAccounts := map[string]bool{}
+	for _, binding := range bindings.Items {
+		for _, subject := range binding.Subjects {
+			if subject.Kind == "ServiceAccount" && subject.Namespace == operatorNamespace {
+				boundServiceAccounts[subject.Name] = true
+			}
+		}
+	}
+
+	for _, saName := range csiServiceAccounts {
+		_, err := k8sclientset.CoreV1().ServiceAccounts(operatorNamespace).Get(ctx, saName, v1.GetOptions{})
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("serviceaccount %s/%s: %v", operatorNamespace, saName, err))
+			continue
+		}
+		if !boundServiceAccounts[saName] {
+			issues = append(issues, fmt.Sprintf("serviceaccount %s/%s is not bound to any clusterrole", operatorNamespace, saName))
+		}
+	}
+	return issues
+}
+
+func checkStorageClasses(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string) []string {
+	var issues []string
+
+	storageClasses, err := k8sclientset.StorageV1().StorageClasses().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("failed to list storageclasses: %v", err)}
+	}
+
+	for _, sc := range storageClasses.Items {
+		if !strings.HasSuffix(sc.Provisioner, rbdDriverSuffix) && !strings.HasSuffix(sc.Provisioner, cephfsDriverSuffix) {
+			continue
+		}
+		logging.Info("checking storageclass %s with provisioner %s", sc.Name, sc.Provisioner)
+
+		if !strings.HasPrefix(sc.Provisioner, operatorNamespace+".") {
+			issues = append(issues, fmt.Sprintf("storageclass %s: provisioner %s does not match the operator namespace %s", sc.Name, sc.Provisioner, operatorNamespace))
+		}
+
+		if clusterID := sc.Parameters["clusterID"]; clusterID != clusterNamespace {
+			issues = append(issues, fmt.Sprintf("storageclass %s: clusterID %q does not match the cluster namespace %s", sc.Name, clusterID, clusterNamespace))
+		}
+
+		for nameParam, namespaceParam := range storageClassSecretParams {
+			secretName, secretNamespace := sc.Parameters[nameParam], sc.Parameters[namespaceParam]
+			if secretName == "" {
+				issues = append(issues, fmt.Sprintf("storageclass %s: parameter %s is not set", sc.Name, nameParam))
+				continue
+			}
+			if secretNamespace != clusterNamespace {
+				issues = append(issues, fmt.Sprintf("storageclass %s: %s %q does not match the cluster namespace %s", sc.Name, namespaceParam, secretNamespace, clusterNamespace))
+			}
+			_, err := k8sclientset.CoreV1().Secrets(secretNamespace).Get(ctx, secretName, v1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				issues = append(issues, fmt.Sprintf("storageclass %s: secret %s/%s referenced by %s not found", sc.Name, secretNamespace, secretName, nameParam))
+			} else if err != nil {
+				issues = append(issues, fmt.Sprintf("storageclass %s: failed to get secret %s/%s: %v", sc.Name, secretNamespace, secretName, err))
+			}
+		}
+	}
+	return issues
+}
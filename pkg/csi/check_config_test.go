@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckConfig(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset()
+
+	binding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "csi"}}
+	for _, saName := range csiServiceAccounts {
+		sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: saName, Namespace: ns}}
+		_, err := k8s.CoreV1().ServiceAccounts(ns).Create(ctx, sa, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		binding.Subjects = append(binding.Subjects, rbacv1.Subject{Kind: "ServiceAccount", Name: saName, Namespace: ns})
+	}
+	_, err := k8s.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	for _, secretName := range csiSecrets {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: ns}}
+		_, err := k8s.CoreV1().Secrets(ns).Create(ctx, secret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "rook-ceph-block"},
+		Provisioner: "rook-ceph.rbd.csi.ceph.com",
+		Parameters: map[string]string{
+			"clusterID": ns,
+			"csi.storage.k8s.io/provisioner-secret-name":            "rook-csi-rbd-provisioner",
+			"csi.storage.k8s.io/provisioner-secret-namespace":       ns,
+			"csi.storage.k8s.io/controller-expand-secret-name":      "rook-csi-rbd-provisioner",
+			"csi.storage.k8s.io/controller-expand-secret-namespace": ns,
+			"csi.storage.k8s.io/node-stage-secret-name":             "rook-csi-rbd-node",
+			"csi.storage.k8s.io/node-stage-secret-namespace":        ns,
+		},
+	}
+	_, err = k8s.StorageV1().StorageClasses().Create(ctx, sc, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	issues := checkConfig(ctx, k8s, ns, ns)
+	assert.Empty(t, issues)
+
+	sc.Name = "wrong-ns"
+	sc.Parameters["clusterID"] = "other"
+	sc.Parameters["csi.storage.k8s.io/node-stage-secret-name"] = "missing"
+	_, err = k8s.StorageV1().StorageClasses().Create(ctx, sc, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	issues = checkConfig(ctx, k8s, ns, ns)
+	assert.Len(t, issues, 2)
+}
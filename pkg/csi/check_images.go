@@ -0,0 +1,239 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const operatorConfigMap = "rook-ceph-operator-config"
+
+// csiImageSettings are the operator settings of the image of each container of the csi pods
+var csiImageSettings = map[string]string{
+	"csi-rbdplugin":       "ROOK_CSI_CEPH_IMAGE",
+	"csi-cephfsplugin":    "ROOK_CSI_CEPH_IMAGE",
+	"csi-nfsplugin":       "ROOK_CSI_CEPH_IMAGE",
+	"csi-omap-generator":  "ROOK_CSI_CEPH_IMAGE",
+	"liveness-prometheus": "ROOK_CSI_CEPH_IMAGE",
+	"driver-registrar":    "ROOK_CSI_REGISTRAR_IMAGE",
+	"csi-provisioner":     "ROOK_CSI_PROVISIONER_IMAGE",
+	"csi-attacher":        "ROOK_CSI_ATTACHER_IMAGE",
+	"csi-snapshotter":     "ROOK_CSI_SNAPSHOTTER_IMAGE",
+	"csi-resizer":         "ROOK_CSI_RESIZER_IMAGE",
+	"csi-addons":          "ROOK_CSIADDONS_IMAGE",
+}
+
+// csiWorkloads are the daemonsets of the csi plugins and the deployments of the csi provisioners, their pods have
+// the app label of their name
+var csiWorkloads = []struct {
+	name      string
+	daemonSet bool
+}{
+	{"csi-rbdplugin", true},
+	{"csi-rbdplugin-provisioner", false},
+	{"csi-cephfsplugin", true},
+	{"csi-cephfsplugin-provisioner", false},
+	{"csi-nfsplugin", true},
+	{"csi-nfsplugin-provisioner", false},
+}
+
+// containerImage is the image of a container of a csi workload in the operator config, in the workload spec and in
+// its pods
+type containerImage struct {
+	Workload   string
+	Container  string
+	Setting    string
+	Configured string
+	Spec       string
+	Pods       int
+	// StalePods are the pods running another image than the spec
+	StalePods []string
+}
+
+func CheckImages(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) {
+	logging.Info("Checking the images of the CSI pods match the operator config")
+	images, issues, err := checkImages(ctx, k8sclientset, operatorNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if len(images) == 0 {
+		logging.Warning("no CSI daemonset or deployment found in namespace %s", operatorNamespace)
+		return
+	}
+	printImages(os.Stdout, images)
+
+	fmt.Println()
+	if len(issues) == 0 {
+		logging.Info("The CSI pods run the images of the operator config")
+		return
+	}
+	for _, issue := range issues {
+		logging.Error(fmt.Errorf(issue))
+	}
+	logging.Warning("%d CSI image mismatches found, restart the operator with `kubectl rook-ceph operator restart` to update the CSI workloads, and delete the stale pods of the daemonsets with the OnDelete update strategy", len(issues))
+}
+
+func checkImages(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) ([]containerImage, []string, error) {
+	settings, err := getImageSettings(ctx, k8sclientset, operatorNamespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var images []containerImage
+	for _, workload := range csiWorkloads {
+		podSpec, err := getWorkloadPodSpec(ctx, k8sclientset, operatorNamespace, workload.name, workload.daemonSet)
+		if kerrors.IsNotFound(err) {
+			// the driver is disabled
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		pods, err := k8sclientset.CoreV1().Pods(operatorNamespace).List(ctx, v1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", workload.name)})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list the pods of %s. %v", workload.name, err)
+		}
+		images = append(images, getContainerImages(workload.name, podSpec, pods.Items, settings)...)
+	}
+	return images, findImageIssues(images), nil
+}
+
+// getImageSettings returns the image settings of the operator, the settings of the configmap take precedence over
+// the env of the operator like the operator does
+func getImageSettings(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) (map[string]string, error) {
+	settings := map[string]string{}
+	deployment, err := k8sclientset.AppsV1().Deployments(operatorNamespace).Get(ctx, "rook-ceph-operator", v1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the rook-ceph-operator deployment. %v", err)
+	}
+	if err == nil && len(deployment.Spec.Template.Spec.Containers) != 0 {
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Value != "" {
+				settings[env.Name] = env.Value
+			}
+		}
+	}
+
+	configMap, err := k8sclientset.CoreV1().ConfigMaps(operatorNamespace).Get(ctx, operatorConfigMap, v1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the %s configmap. %v", operatorConfigMap, err)
+	}
+	if err == nil {
+		for name, value := range configMap.Data {
+			settings[name] = value
+		}
+	}
+	return settings, nil
+}
+
+func getWorkloadPodSpec(ctx context.Context, k8sclientset kubernetes.Interface, namespace, name string, daemonSet bool) (*corev1.PodSpec, error) {
+	if daemonSet {
+		ds, err := k8sclientset.AppsV1().DaemonSets(namespace).Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &ds.Spec.Template.Spec, nil
+	}
+	deployment, err := k8sclientset.AppsV1().Deployments(namespace).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &deployment.Spec.Template.Spec, nil
+}
+
+// getContainerImages returns the image of each csi container of the workload, and its pods running another image
+func getContainerImages(workload string, podSpec *corev1.PodSpec, pods []corev1.Pod, settings map[string]string) []containerImage {
+	var images []containerImage
+	for _, container := range podSpec.Containers {
+		setting, ok := csiImageSettings[container.Name]
+		if !ok {
+			continue
+		}
+		image := containerImage{
+			Workload:   workload,
+			Container:  container.Name,
+			Setting:    setting,
+			Configured: settings[setting],
+			Spec:       container.Image,
+			Pods:       len(pods),
+		}
+		for _, pod := range pods {
+			for _, podContainer := range pod.Spec.Containers {
+				if podContainer.Name == container.Name && podContainer.Image != container.Image {
+					image.StalePods = append(image.StalePods, pod.Name)
+				}
+			}
+		}
+		sort.Strings(image.StalePods)
+		images = append(images, image)
+	}
+	return images
+}
+
+func findImageIssues(images []containerImage) []string {
+	var issues []string
+	for _, image := range images {
+		if image.Configured != "" && !matchesConfiguredImage(image.Spec, image.Configured) {
+			issues = append(issues, fmt.Sprintf("%s container %s: the spec image %s is not the image %s of %s, the operator did not update the CSI driver",
+				image.Workload, image.Container, image.Spec, image.Configured, image.Setting))
+		}
+		if len(image.StalePods) != 0 {
+			issues = append(issues, fmt.Sprintf("%s container %s: the pods %s are not running the spec image %s",
+				image.Workload, image.Container, strings.Join(image.StalePods, ", "), image.Spec))
+		}
+	}
+	return issues
+}
+
+// matchesConfiguredImage checks the image matches the configured image, the operator appends the tag of its default
+// image to a configured image without a tag
+func matchesConfiguredImage(image, configured string) bool {
+	if image == configured {
+		return true
+	}
+	if !strings.Contains(configured, ":") {
+		repository, _, _ := strings.Cut(image, ":")
+		return repository == configured
+	}
+	return false
+}
+
+func printImages(out io.Writer, images []containerImage) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "WORKLOAD\tCONTAINER\tCONFIGURED\tSPEC\tPODS UP TO DATE")
+	for _, image := range images {
+		configured := image.Configured
+		if configured == "" {
+			configured = "default"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\n", image.Workload, image.Container, configured, image.Spec, image.Pods-len(image.StalePods), image.Pods)
+	}
+	w.Flush()
+}
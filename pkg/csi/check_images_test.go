@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newCsiPod(name, app, pluginImage string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph", Labels: map[string]string{"app": app}},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "driver-registrar", Image: "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.8.0"},
+			{Name: "csi-rbdplugin", Image: pluginImage},
+		}},
+	}
+}
+
+func TestCheckImages(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset()
+
+	operator := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-operator", Namespace: ns},
+		Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name: "rook-ceph-operator",
+			Env:  []v1.EnvVar{{Name: "ROOK_CSI_CEPH_IMAGE", Value: "quay.io/cephcsi/cephcsi:v3.8.0"}},
+		}}}}},
+	}
+	_, err := k8s.AppsV1().Deployments(ns).Create(ctx, operator, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	// the configmap takes precedence over the env of the operator
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: operatorConfigMap, Namespace: ns},
+		Data:       map[string]string{"ROOK_CSI_CEPH_IMAGE": "quay.io/cephcsi/cephcsi:v3.9.0"},
+	}
+	_, err = k8s.CoreV1().ConfigMaps(ns).Create(ctx, configMap, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	plugin := newCsiPod("csi-rbdplugin", "csi-rbdplugin", "quay.io/cephcsi/cephcsi:v3.9.0")
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-rbdplugin", Namespace: ns},
+		Spec:       appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: plugin.Spec}},
+	}
+	_, err = k8s.AppsV1().DaemonSets(ns).Create(ctx, ds, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	for _, pod := range []*v1.Pod{
+		newCsiPod("csi-rbdplugin-abcde", "csi-rbdplugin", "quay.io/cephcsi/cephcsi:v3.9.0"),
+		newCsiPod("csi-rbdplugin-fghij", "csi-rbdplugin", "quay.io/cephcsi/cephcsi:v3.9.0"),
+	} {
+		_, err = k8s.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	images, issues, err := checkImages(ctx, k8s, ns)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Len(t, images, 2)
+
+	// a pod of a daemonset with the OnDelete update strategy keeps the previous image
+	_, err = k8s.CoreV1().Pods(ns).Create(ctx, newCsiPod("csi-rbdplugin-klmno", "csi-rbdplugin", "quay.io/cephcsi/cephcsi:v3.8.0"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	// the operator has not updated the daemonset to the new image yet
+	configMap.Data["ROOK_CSI_CEPH_IMAGE"] = "quay.io/cephcsi/cephcsi:v3.10.0"
+	_, err = k8s.CoreV1().ConfigMaps(ns).Update(ctx, configMap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	images, issues, err = checkImages(ctx, k8s, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"csi-rbdplugin container csi-rbdplugin: the spec image quay.io/cephcsi/cephcsi:v3.9.0 is not the image quay.io/cephcsi/cephcsi:v3.10.0 of ROOK_CSI_CEPH_IMAGE, the operator did not update the CSI driver",
+		"csi-rbdplugin container csi-rbdplugin: the pods csi-rbdplugin-klmno are not running the spec image quay.io/cephcsi/cephcsi:v3.9.0",
+	}, issues)
+
+	var out bytes.Buffer
+	printImages(&out, images)
+	assert.Equal(t, `WORKLOAD        CONTAINER          CONFIGURED                        SPEC                                                           PODS UP TO DATE
+csi-rbdplugin   driver-registrar   default                           registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.8.0   3/3
+csi-rbdplugin   csi-rbdplugin      quay.io/cephcsi/cephcsi:v3.10.0   quay.io/cephcsi/cephcsi:v3.9.0                                 2/3
+`, out.String())
+}
+
+func TestMatchesConfiguredImage(t *testing.T) {
+	assert.True(t, matchesConfiguredImage("quay.io/cephcsi/cephcsi:v3.9.0", "quay.io/cephcsi/cephcsi:v3.9.0"))
+	// the operator appends the default tag to an image without a tag
+	assert.True(t, matchesConfiguredImage("quay.io/cephcsi/cephcsi:v3.9.0", "quay.io/cephcsi/cephcsi"))
+	assert.False(t, matchesConfiguredImage("quay.io/cephcsi/cephcsi:v3.9.0", "quay.io/cephcsi/cephcsi:v3.10.0"))
+	assert.False(t, matchesConfiguredImage("quay.io/cephcsi/cephcsi:v3.9.0", "registry.io/private/cephcsi"))
+}
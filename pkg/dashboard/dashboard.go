@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	dashboardServicePrefix = "rook-ceph-mgr-dashboard"
+	dashboardSecret        = "rook-ceph-dashboard-password"
+	dashboardUser          = "admin"
+)
+
+func Dashboard(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, showPassword bool) {
+	err := dashboard(ctx, clientsets, clusterNamespace, showPassword)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func dashboard(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, showPassword bool) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	dashboardSpec := cephCluster.Spec.Dashboard
+	if !dashboardSpec.Enabled {
+		logging.Warning("the dashboard is not enabled in CephCluster %s", cephCluster.Name)
+	}
+
+	urls, err := getDashboardURLs(ctx, clientsets, clusterNamespace, dashboardSpec.SSL, dashboardSpec.URLPrefix)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("no dashboard service found in namespace %s", clusterNamespace)
+	}
+
+	logging.Info("Dashboard URLs")
+	for _, url := range urls {
+		fmt.Println(url)
+	}
+	fmt.Println()
+
+	secret, err := clientsets.Kube.CoreV1().Secrets(clusterNamespace).Get(ctx, dashboardSecret, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the dashboard password secret %s. %v", dashboardSecret, err)
+	}
+	fmt.Printf("user: %s\n", dashboardUser)
+	fmt.Printf("password: %s\n", formatPassword(string(secret.Data["password"]), showPassword))
+	return nil
+}
+
+// formatPassword masks the password unless it's asked for, so it's not printed in a shared terminal by default
+func formatPassword(password string, showPassword bool) string {
+	if !showPassword {
+		return strings.Repeat("*", 8) + " (pass --show-password to print it)"
+	}
+	return password
+}
+
+// SetEnabled enables or disables the dashboard mgr module, and prints the dashboard URLs once its service is up
+func SetEnabled(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, enable bool, timeout time.Duration) {
+	mgr.SetModule(ctx, clientsets, operatorNamespace, clusterNamespace, mgr.ModuleDashboard, enable, timeout)
+	if !enable {
+		return
+	}
+
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		logging.Warning("failed to get the CephCluster to find the dashboard URLs. %v", err)
+		return
+	}
+	dashboardSpec := cephCluster.Spec.Dashboard
+	urls, err := getDashboardURLs(ctx, clientsets, clusterNamespace, dashboardSpec.SSL, dashboardSpec.URLPrefix)
+	if err != nil {
+		logging.Warning("%v", err)
+		return
+	}
+	if len(urls) == 0 {
+		logging.Warning("no dashboard service found in namespace %s, the operator creates it when spec.dashboard.enabled is set in the CephCluster", clusterNamespace)
+		return
+	}
+	logging.Info("Dashboard URLs")
+	for _, url := range urls {
+		fmt.Println(url)
+	}
+}
+
+func getDashboardURLs(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, ssl bool, urlPrefix string) ([]string, error) {
+	var urls []string
+
+	ingresses, err := clientsets.Kube.NetworkingV1().Ingresses(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses. %v", err)
+	}
+	for _, ingress := range ingresses.Items {
+		tlsHosts := map[string]bool{}
+		for _, tls := range ingress.Spec.TLS {
+			for _, host := range tls.Hosts {
+				tlsHosts[host] = true
+			}
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service == nil || !strings.HasPrefix(path.Backend.Service.Name, dashboardServicePrefix) {
+					continue
+				}
+				scheme := "http"
+				if tlsHosts[rule.Host] {
+					scheme = "https"
+				}
+				urls = append(urls, fmt.Sprintf("%s://%s%s (ingress %s)", scheme, rule.Host, path.Path, ingress.Name))
+			}
+		}
+	}
+
+	services, err := clientsets.Kube.CoreV1().Services(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services. %v", err)
+	}
+
+	scheme := "http"
+	if ssl {
+		scheme = "https"
+	}
+	for _, service := range services.Items {
+		if !strings.HasPrefix(service.Name, dashboardServicePrefix) || len(service.Spec.Ports) == 0 {
+			continue
+		}
+		port := service.Spec.Ports[0]
+
+		switch service.Spec.Type {
+		case corev1.ServiceTypeNodePort:
+			nodeIP, err := getNodeIP(ctx, clientsets)
+			if err != nil {
+				logging.Warning("failed to get a node address for service %s. %v", service.Name, err)
+				continue
+			}
+			urls = append(urls, fmt.Sprintf("%s://%s:%d%s (nodeport service %s)", scheme, nodeIP, port.NodePort, urlPrefix, service.Name))
+		case corev1.ServiceTypeLoadBalancer:
+			for _, lb := range service.Status.LoadBalancer.Ingress {
+				host := lb.IP
+				if host == "" {
+					host = lb.Hostname
+				}
+				urls = append(urls, fmt.Sprintf("%s://%s:%d%s (loadbalancer service %s)", scheme, host, port.Port, urlPrefix, service.Name))
+			}
+		default:
+			urls = append(urls, fmt.Sprintf("%s://%s.%s.svc:%d%s (cluster-internal service %s, use 'kubectl -n %s port-forward service/%s %d' to access it locally)",
+				scheme, service.Name, clusterNamespace, port.Port, urlPrefix, service.Name, clusterNamespace, service.Name, port.Port))
+		}
+	}
+	return urls, nil
+}
+
+// getNodeIP returns the external address of a node, or its internal address when it has no external address
+func getNodeIP(ctx context.Context, clientsets *k8sutil.Clientsets) (string, error) {
+	nodes, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(nodes.Items) == 0 {
+		return "", fmt.Errorf("no nodes found")
+	}
+
+	var internalIP string
+	for _, address := range nodes.Items[0].Status.Addresses {
+		if address.Type == corev1.NodeExternalIP {
+			return address.Address, nil
+		}
+		if address.Type == corev1.NodeInternalIP {
+			internalIP = address.Address
+		}
+	}
+	if internalIP == "" {
+		return "", fmt.Errorf("node %s has no address", nodes.Items[0].Name)
+	}
+	return internalIP, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDashboardURLs(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	newService := func(name string, serviceType corev1.ServiceType, port, nodePort int32) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns},
+			Spec:       corev1.ServiceSpec{Type: serviceType, Ports: []corev1.ServicePort{{Port: port, NodePort: nodePort}}},
+		}
+	}
+	loadBalancer := newService("rook-ceph-mgr-dashboard-loadbalancer", corev1.ServiceTypeLoadBalancer, 8443, 31000)
+	loadBalancer.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}, {Hostname: "dashboard.example.com"}}
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-mgr-dashboard", Namespace: ns},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"ceph.example.com"}}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "ceph.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+					{Path: "/ceph", PathType: &pathType, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "rook-ceph-mgr-dashboard"}}},
+					{Path: "/other", PathType: &pathType, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "other-app"}}},
+				}}},
+			}},
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}},
+	}
+
+	clientsets := &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(
+		newService("rook-ceph-mgr-dashboard", corev1.ServiceTypeClusterIP, 8443, 0),
+		newService("rook-ceph-mgr-dashboard-external-https", corev1.ServiceTypeNodePort, 8443, 30443),
+		loadBalancer,
+		newService("rook-ceph-mgr", corev1.ServiceTypeClusterIP, 9283, 0),
+		ingress,
+		node,
+	)}
+	urls, err := getDashboardURLs(ctx, clientsets, ns, true, "/dashboard")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://ceph.example.com/ceph (ingress rook-ceph-mgr-dashboard)",
+		"https://rook-ceph-mgr-dashboard.rook-ceph.svc:8443/dashboard (cluster-internal service rook-ceph-mgr-dashboard, use 'kubectl -n rook-ceph port-forward service/rook-ceph-mgr-dashboard 8443' to access it locally)",
+		"https://10.0.0.1:30443/dashboard (nodeport service rook-ceph-mgr-dashboard-external-https)",
+		"https://203.0.113.10:8443/dashboard (loadbalancer service rook-ceph-mgr-dashboard-loadbalancer)",
+		"https://dashboard.example.com:8443/dashboard (loadbalancer service rook-ceph-mgr-dashboard-loadbalancer)",
+	}, urls)
+
+	// the external address of the node is preferred, and the dashboard is served over http without ssl
+	node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "198.51.100.1"})
+	clientsets = &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(
+		newService("rook-ceph-mgr-dashboard-external-http", corev1.ServiceTypeNodePort, 7000, 30700),
+		node,
+	)}
+	urls, err = getDashboardURLs(ctx, clientsets, ns, false, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://198.51.100.1:30700 (nodeport service rook-ceph-mgr-dashboard-external-http)"}, urls)
+
+	// a nodeport service is skipped when no node has an address
+	clientsets = &k8sutil.Clientsets{Kube: fake.NewSimpleClientset(newService("rook-ceph-mgr-dashboard-external-http", corev1.ServiceTypeNodePort, 7000, 30700))}
+	urls, err = getDashboardURLs(ctx, clientsets, ns, false, "")
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+}
+
+func TestFormatPassword(t *testing.T) {
+	assert.Equal(t, "******** (pass --show-password to print it)", formatPassword("s3cr3t", false))
+	assert.Equal(t, "s3cr3t", formatPassword("s3cr3t", true))
+}
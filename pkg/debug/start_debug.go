@@ -37,6 +37,12 @@ func StartDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterN
 	}
 }
 
+// StartDebugWithError starts the debug deployment like StartDebug, but returns the error instead of exiting so the
+// caller can restore what it changed before the debug pod was started.
+func StartDebugWithError(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, alternateImageValue string) error {
+	return startDebug(ctx, k8sclientset, clusterNamespace, deploymentName, alternateImageValue)
+}
+
 func startDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, alternateImageValue string) error {
 	originalDeployment, err := k8sutil.GetDeployment(ctx, k8sclientset, clusterNamespace, deploymentName)
 	if err != nil {
@@ -101,7 +107,7 @@ func startDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterN
 
 	pod, err := k8sutil.WaitForPodToRun(ctx, k8sclientset, clusterNamespace, labelSelector)
 	if err != nil {
-		logging.Fatal(err)
+		return err
 	}
 
 	logging.Info("pod %s is ready for debugging", pod.Name)
@@ -109,15 +115,18 @@ func startDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterN
 }
 
 func waitForPodDeletion(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, podName string) error {
+	progress := logging.NewProgress("waiting for pod %q to be deleted", podName)
 	for i := 0; i < 60; i++ {
 		_, err := k8sclientset.CoreV1().Pods(clusterNamespace).Get(ctx, podName, v1.GetOptions{})
 		if kerrors.IsNotFound(err) {
+			progress.Done("deleted")
 			return nil
 		}
 
-		logging.Info("waiting for pod %q to be deleted\n", podName)
+		progress.Update("terminating")
 		time.Sleep(time.Second * 5)
 	}
 
+	progress.Done("timed out")
 	return fmt.Errorf("failed to delete pod %s", podName)
 }
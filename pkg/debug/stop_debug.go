@@ -37,6 +37,11 @@ func StopDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterNa
 	}
 }
 
+// StopDebugWithError stops the debug deployment like StopDebug, but returns the error instead of exiting.
+func StopDebugWithError(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) error {
+	return stopDebug(ctx, k8sclientset, clusterNamespace, deploymentName)
+}
+
 func stopDebug(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName string) error {
 	if !strings.HasSuffix(deploymentName, "-debug") {
 		deploymentName = deploymentName + "-debug"
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// lifeExpectancyWarnThreshold is the default of the devicehealth mgr module warn_threshold
+const lifeExpectancyWarnThreshold = 12 * 7 * 24 * time.Hour
+
+type cephDevice struct {
+	DevId             string           `json:"devid"`
+	Location          []deviceLocation `json:"location"`
+	Daemons           []string         `json:"daemons"`
+	WearLevel         *float64         `json:"wear_level,omitempty"`
+	LifeExpectancyMin string           `json:"life_expectancy_min,omitempty"`
+	LifeExpectancyMax string           `json:"life_expectancy_max,omitempty"`
+}
+
+type deviceLocation struct {
+	Host string `json:"host"`
+	Dev  string `json:"dev"`
+}
+
+type smartMetrics struct {
+	SmartStatus struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours int `json:"hours"`
+	} `json:"power_on_time"`
+	NvmeHealthLog *struct {
+		PercentageUsed int `json:"percentage_used"`
+	} `json:"nvme_smart_health_information_log,omitempty"`
+}
+
+var lifeExpectancyLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999-0700", "2006-01-02 15:04:05.999999"}
+
+func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) {
+	err := health(ctx, clientsets, operatorNamespace, clusterNamespace, args)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) error {
+	deviceOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"device", "ls", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+
+	var devices []cephDevice
+	err := json.Unmarshal([]byte(deviceOut), &devices)
+	if err != nil {
+		return fmt.Errorf("failed to list ceph devices. %v", err)
+	}
+
+	filter := ""
+	if len(args) == 1 {
+		filter = args[0]
+		devices = filterDevices(devices, filter)
+		if len(devices) == 0 {
+			return fmt.Errorf("no device found for %q", filter)
+		}
+	}
+
+	fmt.Printf("DEVICE\tHOST:DEV\tDAEMONS\tWEAR_LEVEL\tLIFE_EXPECTANCY\n")
+	for _, device := range devices {
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", device.DevId, device.locations(), strings.Join(device.Daemons, ","), device.wearLevel(), device.lifeExpectancy())
+	}
+	fmt.Println()
+
+	now := time.Now()
+	for _, device := range devices {
+		if device.isFailurePredicted(now) {
+			logging.Warning("device %s used by %s is predicted to fail %s", device.DevId, strings.Join(device.Daemons, ","), device.lifeExpectancy())
+		}
+	}
+
+	// the health metrics are only fetched for a specific device since they are large
+	if filter != "" {
+		for _, device := range devices {
+			printHealthMetrics(ctx, clientsets, operatorNamespace, clusterNamespace, device.DevId)
+		}
+	}
+	return nil
+}
+
+// filterDevices returns the devices matching an osd id such as "0" or "osd.0", a device id or a device name
+func filterDevices(devices []cephDevice, filter string) []cephDevice {
+	daemon := filter
+	if !strings.HasPrefix(daemon, "osd.") {
+		daemon = "osd." + daemon
+	}
+
+	var filtered []cephDevice
+	for _, device := range devices {
+		match := device.DevId == filter
+		for _, d := range device.Daemons {
+			match = match || d == daemon
+		}
+		for _, location := range device.Location {
+			match = match || location.Dev == filter || "/dev/"+location.Dev == filter
+		}
+		if match {
+			filtered = append(filtered, device)
+		}
+	}
+	return filtered
+}
+
+func (d cephDevice) locations() string {
+	var locations []string
+	for _, location := range d.Location {
+		locations = append(locations, fmt.Sprintf("%s:%s", location.Host, location.Dev))
+	}
+	return strings.Join(locations, ",")
+}
+
+func (d cephDevice) wearLevel() string {
+	if d.WearLevel == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.0f%%", *d.WearLevel*100)
+}
+
+func (d cephDevice) lifeExpectancy() string {
+	if d.LifeExpectancyMin == "" && d.LifeExpectancyMax == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("between %s and %s", d.LifeExpectancyMin, d.LifeExpectancyMax)
+}
+
+// isFailurePredicted returns true when the minimum life expectancy is within the warn threshold
+func (d cephDevice) isFailurePredicted(now time.Time) bool {
+	if d.LifeExpectancyMin == "" {
+		return false
+	}
+	for _, layout := range lifeExpectancyLayouts {
+		t, err := time.Parse(layout, d.LifeExpectancyMin)
+		if err == nil {
+			return t.Sub(now) < lifeExpectancyWarnThreshold
+		}
+	}
+	return false
+}
+
+func printHealthMetrics(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, devId string) {
+	metricsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"device", "get-health-metrics", devId, "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+
+	var metrics map[string]smartMetrics
+	err := json.Unmarshal([]byte(metricsOut), &metrics)
+	if err != nil {
+		logging.Error(fmt.Errorf("failed to get health metrics of device %s. %v", devId, err))
+		return
+	}
+	if len(metrics) == 0 {
+		logging.Info("no health metrics collected for device %s", devId)
+		return
+	}
+
+	// the metrics are keyed by the timestamp they were scraped at
+	var stamps []string
+	for stamp := range metrics {
+		stamps = append(stamps, stamp)
+	}
+	sort.Strings(stamps)
+	latest := metrics[stamps[len(stamps)-1]]
+
+	logging.Info("health metrics of device %s scraped at %s", devId, stamps[len(stamps)-1])
+	fmt.Printf("smart status passed: %t\n", latest.SmartStatus.Passed)
+	fmt.Printf("temperature: %d C\n", latest.Temperature.Current)
+	fmt.Printf("power on hours: %d\n", latest.PowerOnTime.Hours)
+	if latest.NvmeHealthLog != nil {
+		fmt.Printf("nvme percentage used: %d%%\n", latest.NvmeHealthLog.PercentageUsed)
+	}
+	if !latest.SmartStatus.Passed {
+		logging.Warning("device %s failed the SMART health check", devId)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testDeviceLs is the output of `ceph device ls --format json` with a device predicted to fail, a healthy device
+// and a device without prediction
+const testDeviceLs = `[
+{"devid":"SAMSUNG_MZ7LH960HAJR-00005_S45NNA0M500123","location":[{"host":"node-1","dev":"sdb","path":"/dev/disk/by-path/pci-0000:00:1f.2-ata-2"}],"daemons":["osd.0"],"wear_level":0.93,"life_expectancy_min":"2023-11-01T00:00:00.000000Z","life_expectancy_max":"2023-12-01T00:00:00.000000Z","life_expectancy_stamp":"2023-10-10T08:00:12.412345Z"},
+{"devid":"INTEL_SSDPE2KX010T8_PHLJ9123004X1P0FGN","location":[{"host":"node-2","dev":"nvme0n1","path":"/dev/disk/by-path/pci-0000:5e:00.0-nvme-1"}],"daemons":["osd.1","osd.2"],"wear_level":0.05,"life_expectancy_min":"2026-01-01T00:00:00.000000+0000","life_expectancy_max":"2027-01-01T00:00:00.000000+0000","life_expectancy_stamp":"2023-10-10T08:00:12.412345+0000"},
+{"devid":"QEMU_HARDDISK_QM00003","location":[{"host":"node-3","dev":"vdb","path":"/dev/disk/by-path/virtio-pci-0000:00:05.0"}],"daemons":["osd.3"]}]`
+
+func TestDeviceHealth(t *testing.T) {
+	var devices []cephDevice
+	assert.NoError(t, json.Unmarshal([]byte(testDeviceLs), &devices))
+	assert.Len(t, devices, 3)
+
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	assert.True(t, devices[0].isFailurePredicted(now))
+	assert.False(t, devices[1].isFailurePredicted(now))
+	assert.False(t, devices[2].isFailurePredicted(now))
+	// a life expectancy within the 12 weeks of the warn threshold is a predicted failure
+	assert.True(t, devices[1].isFailurePredicted(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, cephDevice{LifeExpectancyMin: "soon"}.isFailurePredicted(now))
+
+	assert.Equal(t, "93%", devices[0].wearLevel())
+	assert.Equal(t, "unknown", devices[2].wearLevel())
+	assert.Equal(t, "unknown", devices[2].lifeExpectancy())
+	assert.Equal(t, "node-2:nvme0n1", devices[1].locations())
+
+	devId := func(devices []cephDevice) []string {
+		var ids []string
+		for _, device := range devices {
+			ids = append(ids, device.DevId)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"INTEL_SSDPE2KX010T8_PHLJ9123004X1P0FGN"}, devId(filterDevices(devices, "2")))
+	assert.Equal(t, []string{"INTEL_SSDPE2KX010T8_PHLJ9123004X1P0FGN"}, devId(filterDevices(devices, "osd.1")))
+	assert.Equal(t, []string{"SAMSUNG_MZ7LH960HAJR-00005_S45NNA0M500123"}, devId(filterDevices(devices, "/dev/sdb")))
+	assert.Equal(t, []string{"QEMU_HARDDISK_QM00003"}, devId(filterDevices(devices, "vdb")))
+	assert.Equal(t, []string{"QEMU_HARDDISK_QM00003"}, devId(filterDevices(devices, "QEMU_HARDDISK_QM00003")))
+	assert.Empty(t, filterDevices(devices, "osd.5"))
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package df
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+const barWidth = 20
+
+type cephDfStats struct {
+	TotalBytes        uint64  `json:"total_bytes"`
+	TotalAvailBytes   uint64  `json:"total_avail_bytes"`
+	TotalUsedRawBytes uint64  `json:"total_used_raw_bytes"`
+	TotalUsedRawRatio float64 `json:"total_used_raw_ratio"`
+}
+
+type cephDf struct {
+	Stats        cephDfStats            `json:"stats"`
+	StatsByClass map[string]cephDfStats `json:"stats_by_class"`
+	Pools        []struct {
+		Name  string `json:"name"`
+		Stats struct {
+			Stored      uint64  `json:"stored"`
+			Objects     uint64  `json:"objects"`
+			BytesUsed   uint64  `json:"bytes_used"`
+			PercentUsed float64 `json:"percent_used"`
+			MaxAvail    uint64  `json:"max_avail"`
+		} `json:"stats"`
+	} `json:"pools"`
+}
+
+type osdDfTree struct {
+	Nodes []struct {
+		Name        string  `json:"name"`
+		Type        string  `json:"type"`
+		DeviceClass string  `json:"device_class"`
+		Utilization float64 `json:"utilization"`
+	} `json:"nodes"`
+}
+
+// Capacity is the raw capacity of the cluster or of a device class
+type Capacity struct {
+	SizeBytes  uint64  `json:"sizeBytes"`
+	UsedBytes  uint64  `json:"usedBytes"`
+	AvailBytes uint64  `json:"availBytes"`
+	UsedRatio  float64 `json:"usedRatio"`
+}
+
+// DeviceClassCapacity is the capacity of the osds of a device class, the pools of the class are full as soon
+// as its fullest osd is full
+type DeviceClassCapacity struct {
+	Name string `json:"name"`
+	Capacity
+	Osds            int     `json:"osds"`
+	FullestOsd      string  `json:"fullestOsd,omitempty"`
+	FullestOsdRatio float64 `json:"fullestOsdRatio"`
+}
+
+// PoolCapacity is the usage of a pool, the used bytes include the replicas while the stored bytes don't
+type PoolCapacity struct {
+	Name          string  `json:"name"`
+	StoredBytes   uint64  `json:"storedBytes"`
+	UsedBytes     uint64  `json:"usedBytes"`
+	MaxAvailBytes uint64  `json:"maxAvailBytes"`
+	UsedRatio     float64 `json:"usedRatio"`
+	Objects       uint64  `json:"objects"`
+}
+
+// Report is the capacity of the cluster by device class and by pool
+type Report struct {
+	Total         Capacity              `json:"total"`
+	DeviceClasses []DeviceClassCapacity `json:"deviceClasses"`
+	Pools         []PoolCapacity        `json:"pools"`
+}
+
+// Df prints the capacity of the cluster, or renders it with the formatter when it's not nil
+func Df(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, formatter output.Formatter) {
+	err := df(ctx, clientsets, operatorNamespace, clusterNamespace, formatter)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func df(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, formatter output.Formatter) error {
+	dfOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"df", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var cephDfOut cephDf
+	err := json.Unmarshal([]byte(dfOut), &cephDfOut)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph df output. %v", err)
+	}
+
+	treeOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "df", "tree", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var tree osdDfTree
+	err = json.Unmarshal([]byte(treeOut), &tree)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph osd df tree output. %v", err)
+	}
+
+	report := getReport(cephDfOut, tree)
+	if formatter != nil {
+		return formatter.Render(report)
+	}
+	printReport(os.Stdout, report)
+	return nil
+}
+
+func getReport(cephDfOut cephDf, tree osdDfTree) Report {
+	report := Report{Total: toCapacity(cephDfOut.Stats)}
+
+	for name, stats := range cephDfOut.StatsByClass {
+		report.DeviceClasses = append(report.DeviceClasses, DeviceClassCapacity{Name: name, Capacity: toCapacity(stats)})
+	}
+	sort.Slice(report.DeviceClasses, func(i, j int) bool { return report.DeviceClasses[i].Name < report.DeviceClasses[j].Name })
+
+	for _, node := range tree.Nodes {
+		if node.Type != "osd" {
+			continue
+		}
+		for i := range report.DeviceClasses {
+			class := &report.DeviceClasses[i]
+			if class.Name != node.DeviceClass {
+				continue
+			}
+			class.Osds++
+			// the utilization of ceph osd df is a percentage
+			if ratio := node.Utilization / 100; class.FullestOsd == "" || ratio > class.FullestOsdRatio {
+				class.FullestOsd = node.Name
+				class.FullestOsdRatio = ratio
+			}
+		}
+	}
+
+	for _, pool := range cephDfOut.Pools {
+		report.Pools = append(report.Pools, PoolCapacity{
+			Name:          pool.Name,
+			StoredBytes:   pool.Stats.Stored,
+			UsedBytes:     pool.Stats.BytesUsed,
+			MaxAvailBytes: pool.Stats.MaxAvail,
+			UsedRatio:     pool.Stats.PercentUsed,
+			Objects:       pool.Stats.Objects,
+		})
+	}
+	return report
+}
+
+func toCapacity(stats cephDfStats) Capacity {
+	return Capacity{
+		SizeBytes:  stats.TotalBytes,
+		UsedBytes:  stats.TotalUsedRawBytes,
+		AvailBytes: stats.TotalAvailBytes,
+		UsedRatio:  stats.TotalUsedRawRatio,
+	}
+}
+
+func printReport(out io.Writer, report Report) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CLASS\tSIZE\tUSED\tAVAIL\tFULL\tOSDS\tFULLEST OSD")
+	for _, class := range report.DeviceClasses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", class.Name, output.Bytes(class.SizeBytes), output.Bytes(class.UsedBytes),
+			output.Bytes(class.AvailBytes), output.Bar(class.UsedRatio, barWidth), class.Osds, formatFullestOsd(class))
+	}
+	fmt.Fprintf(w, "TOTAL\t%s\t%s\t%s\t%s\n", output.Bytes(report.Total.SizeBytes), output.Bytes(report.Total.UsedBytes),
+		output.Bytes(report.Total.AvailBytes), output.Bar(report.Total.UsedRatio, barWidth))
+	w.Flush()
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tSTORED\tUSED\tMAX AVAIL\tFULL\tOBJECTS")
+	for _, pool := range report.Pools {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", pool.Name, output.Bytes(pool.StoredBytes), output.Bytes(pool.UsedBytes),
+			output.Bytes(pool.MaxAvailBytes), output.Bar(pool.UsedRatio, barWidth), pool.Objects)
+	}
+	w.Flush()
+}
+
+func formatFullestOsd(class DeviceClassCapacity) string {
+	if class.FullestOsd == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%.1f%%)", class.FullestOsd, class.FullestOsdRatio*100)
+}
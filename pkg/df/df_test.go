@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package df
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReport(t *testing.T) {
+	var cephDfOut cephDf
+	err := json.Unmarshal([]byte(`{"stats":{"total_bytes":32212254720,"total_avail_bytes":21474836480,"total_used_raw_bytes":10737418240,"total_used_raw_ratio":0.333},
+"stats_by_class":{"ssd":{"total_bytes":10737418240,"total_avail_bytes":5368709120,"total_used_raw_bytes":5368709120,"total_used_raw_ratio":0.5},
+"hdd":{"total_bytes":21474836480,"total_avail_bytes":16106127360,"total_used_raw_bytes":5368709120,"total_used_raw_ratio":0.25}},
+"pools":[{"name":"replicapool","id":1,"stats":{"stored":1073741824,"objects":256,"bytes_used":3221225472,"percent_used":0.1,"max_avail":9663676416}}]}`), &cephDfOut)
+	assert.NoError(t, err)
+
+	var tree osdDfTree
+	err = json.Unmarshal([]byte(`{"nodes":[{"id":-1,"name":"default","type":"root"},
+{"id":0,"name":"osd.0","type":"osd","device_class":"hdd","utilization":20.5},
+{"id":1,"name":"osd.1","type":"osd","device_class":"hdd","utilization":29.5},
+{"id":2,"name":"osd.2","type":"osd","device_class":"ssd","utilization":50}]}`), &tree)
+	assert.NoError(t, err)
+
+	report := getReport(cephDfOut, tree)
+	assert.Equal(t, uint64(32212254720), report.Total.SizeBytes)
+	assert.Len(t, report.DeviceClasses, 2)
+	assert.Equal(t, "hdd", report.DeviceClasses[0].Name)
+	assert.Equal(t, 2, report.DeviceClasses[0].Osds)
+	assert.Equal(t, "osd.1", report.DeviceClasses[0].FullestOsd)
+	assert.InDelta(t, 0.295, report.DeviceClasses[0].FullestOsdRatio, 0.0001)
+	assert.Equal(t, "ssd", report.DeviceClasses[1].Name)
+	assert.Equal(t, 1, report.DeviceClasses[1].Osds)
+	assert.Len(t, report.Pools, 1)
+	assert.Equal(t, PoolCapacity{Name: "replicapool", StoredBytes: 1073741824, UsedBytes: 3221225472, MaxAvailBytes: 9663676416, UsedRatio: 0.1, Objects: 256}, report.Pools[0])
+
+	var out bytes.Buffer
+	printReport(&out, report)
+	assert.Contains(t, out.String(), "osd.1 (29.5%)")
+	assert.Contains(t, out.String(), "[=====...............]  25.0%")
+	assert.Contains(t, out.String(), "replicapool")
+
+	assert.Equal(t, "-", formatFullestOsd(DeviceClassCapacity{Name: "nvme"}))
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// discoverApp is the name of the discover daemonset and the app label of its pods and configmaps
+	discoverApp = "rook-discover"
+	// nodeLabel is the label of the discovery configmaps with the name of their node
+	nodeLabel = "rook.io/node"
+	// devicesKey is the key of the discovered devices in the discovery configmaps
+	devicesKey = "devices"
+)
+
+// localDisk is a device of the discovery configmap of a node
+type localDisk struct {
+	Name       string `json:"name"`
+	Size       uint64 `json:"size"`
+	Type       string `json:"type"`
+	Rotational bool   `json:"rotational"`
+	Readonly   bool   `json:"readOnly"`
+	Partitions []struct {
+		Name string `json:"Name"`
+	} `json:"Partitions"`
+	Filesystem     string `json:"filesystem"`
+	Mountpoint     string `json:"mountpoint"`
+	Empty          bool   `json:"empty"`
+	CephVolumeData string `json:"cephVolumeData,omitempty"`
+}
+
+// cephVolumeInventory is the ceph-volume inventory of a device, when the discovery runs ceph-volume
+type cephVolumeInventory struct {
+	Available       bool     `json:"available"`
+	RejectedReasons []string `json:"rejected_reasons"`
+}
+
+type nodeDevice struct {
+	Node      string
+	Device    localDisk
+	Available bool
+	Reason    string
+}
+
+func Status(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) {
+	err := status(ctx, k8sclientset, operatorNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func status(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) error {
+	daemonSet, err := k8sclientset.AppsV1().DaemonSets(operatorNamespace).Get(ctx, discoverApp, v1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		logging.Warning("the discovery daemonset %s is not found in namespace %s, enable it with `kubectl rook-ceph operator set ROOK_ENABLE_DISCOVERY_DAEMON true`", discoverApp, operatorNamespace)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the discovery daemonset %s. %v", discoverApp, err)
+	}
+	logging.Info("discovery daemonset %s: %d/%d pods ready", discoverApp, daemonSet.Status.NumberReady, daemonSet.Status.DesiredNumberScheduled)
+
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, operatorNamespace, v1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", discoverApp)})
+	if err != nil {
+		return fmt.Errorf("failed to list the discovery pods. %v", err)
+	}
+	configMaps, err := k8sclientset.CoreV1().ConfigMaps(operatorNamespace).List(ctx, v1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", discoverApp)})
+	if err != nil {
+		return fmt.Errorf("failed to list the discovery configmaps. %v", err)
+	}
+
+	fmt.Println()
+	printPods(os.Stdout, pods)
+	devices, warnings := getNodeDevices(pods, configMaps.Items)
+	fmt.Println()
+	if len(devices) == 0 {
+		logging.Info("no device discovered")
+	} else {
+		printDevices(os.Stdout, devices)
+	}
+
+	if len(warnings) != 0 {
+		fmt.Println()
+	}
+	for _, warning := range warnings {
+		logging.Warning("%s", warning)
+	}
+	return nil
+}
+
+// getNodeDevices returns the discovered devices by node, and the nodes whose discovery pod is not ready or has not
+// reported any device, since the devices of these nodes are not known to the operator
+func getNodeDevices(pods []corev1.Pod, configMaps []corev1.ConfigMap) ([]nodeDevice, []string) {
+	var devices []nodeDevice
+	var warnings []string
+	discovered := map[string]bool{}
+	for _, cm := range configMaps {
+		node := cm.Labels[nodeLabel]
+		discovered[node] = true
+		var disks []localDisk
+		err := json.Unmarshal([]byte(cm.Data[devicesKey]), &disks)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to parse the devices of node %s in configmap %s. %v", node, cm.Name, err))
+			continue
+		}
+		for _, disk := range disks {
+			available, reason := isAvailable(disk)
+			devices = append(devices, nodeDevice{Node: node, Device: disk, Available: available, Reason: reason})
+		}
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		if devices[i].Node != devices[j].Node {
+			return devices[i].Node < devices[j].Node
+		}
+		return devices[i].Device.Name < devices[j].Device.Name
+	})
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Spec.NodeName < pods[j].Spec.NodeName })
+	for _, pod := range pods {
+		if !isPodReady(pod) {
+			warnings = append(warnings, fmt.Sprintf("discovery pod %s on node %s is not ready, the new devices of the node are not discovered", pod.Name, pod.Spec.NodeName))
+		} else if !discovered[pod.Spec.NodeName] {
+			warnings = append(warnings, fmt.Sprintf("discovery pod %s on node %s has not reported any device, check its logs with `kubectl -n %s logs %s`",
+				pod.Name, pod.Spec.NodeName, pod.Namespace, pod.Name))
+		}
+	}
+	return devices, warnings
+}
+
+// isAvailable returns whether an osd can be created on the device, from its ceph-volume inventory when it's
+// available, and the reason it can't
+func isAvailable(disk localDisk) (bool, string) {
+	if disk.CephVolumeData != "" {
+		var inventory cephVolumeInventory
+		if err := json.Unmarshal([]byte(disk.CephVolumeData), &inventory); err == nil {
+			return inventory.Available, strings.Join(inventory.RejectedReasons, ", ")
+		}
+	}
+	var reasons []string
+	if disk.Filesystem != "" {
+		reasons = append(reasons, fmt.Sprintf("has a %s filesystem", disk.Filesystem))
+	}
+	if len(disk.Partitions) != 0 {
+		reasons = append(reasons, "has partitions")
+	}
+	if disk.Mountpoint != "" {
+		reasons = append(reasons, fmt.Sprintf("mounted on %s", disk.Mountpoint))
+	}
+	if disk.Readonly {
+		reasons = append(reasons, "read-only")
+	}
+	if len(reasons) == 0 && !disk.Empty {
+		reasons = append(reasons, "not empty")
+	}
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func printPods(out io.Writer, pods []corev1.Pod) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tPOD\tPHASE\tREADY\tRESTARTS")
+	for _, pod := range pods {
+		restarts := int32(0)
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%d\n", pod.Spec.NodeName, pod.Name, pod.Status.Phase, isPodReady(pod), restarts)
+	}
+	w.Flush()
+}
+
+func printDevices(out io.Writer, devices []nodeDevice) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tDEVICE\tTYPE\tSIZE\tROTATIONAL\tAVAILABLE\tREASON")
+	for _, d := range devices {
+		reason := d.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%t\t%s\n", d.Node, d.Device.Name, d.Device.Type, output.Bytes(d.Device.Size), d.Device.Rotational, d.Available, reason)
+	}
+	w.Flush()
+}
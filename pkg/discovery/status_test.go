@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNodeDevices(t *testing.T) {
+	newPod := func(name, node string, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "rook-ceph"},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+	}
+	pods := []corev1.Pod{
+		newPod("rook-discover-c", "node-3", corev1.ConditionFalse),
+		newPod("rook-discover-a", "node-1", corev1.ConditionTrue),
+		newPod("rook-discover-b", "node-2", corev1.ConditionTrue),
+	}
+	configMaps := []corev1.ConfigMap{{
+		ObjectMeta: v1.ObjectMeta{Name: "local-device-node-1", Labels: map[string]string{"app": "rook-discover", "rook.io/node": "node-1"}},
+		Data: map[string]string{"devices": `[
+			{"name": "sdc", "size": 107374182400, "type": "disk", "rotational": true, "empty": true},
+			{"name": "sdb", "size": 53687091200, "type": "disk", "rotational": false, "Partitions": [{"Name": "sdb1"}], "empty": false},
+			{"name": "sdd", "size": 53687091200, "type": "disk", "filesystem": "ext4", "mountpoint": "/mnt", "empty": false},
+			{"name": "vdb", "size": 10737418240, "type": "disk", "empty": true, "cephVolumeData": "{\"available\": false, \"rejected_reasons\": [\"Insufficient space (<5GB)\", \"LVM detected\"]}"}
+		]`},
+	}}
+
+	devices, warnings := getNodeDevices(pods, configMaps)
+	assert.Len(t, devices, 4)
+	assert.Equal(t, "sdb", devices[0].Device.Name)
+	assert.False(t, devices[0].Available)
+	assert.Equal(t, "has partitions", devices[0].Reason)
+	assert.Equal(t, "sdc", devices[1].Device.Name)
+	assert.True(t, devices[1].Available)
+	assert.Equal(t, "has a ext4 filesystem, mounted on /mnt", devices[2].Reason)
+	assert.Equal(t, "Insufficient space (<5GB), LVM detected", devices[3].Reason)
+
+	assert.Equal(t, []string{
+		"discovery pod rook-discover-b on node node-2 has not reported any device, check its logs with `kubectl -n rook-ceph logs rook-discover-b`",
+		"discovery pod rook-discover-c on node node-3 is not ready, the new devices of the node are not discovered",
+	}, warnings)
+
+	var out bytes.Buffer
+	printDevices(&out, devices)
+	assert.Regexp(t, `node-1\s+sdc\s+disk\s+100.0 GiB\s+true\s+true\s+-`, out.String())
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Envelope wraps the output of a command with where and how it ran, for automation capturing its provenance
+type Envelope struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Pod      string   `json:"pod"`
+	ExitCode int      `json:"exitCode"`
+	Duration string   `json:"duration"`
+	Output   any      `json:"output"`
+	Stderr   string   `json:"stderr,omitempty"`
+}
+
+// NewEnvelope returns the envelope of the command result, embedding the output as json when it is valid json
+func NewEnvelope(command string, args []string, result *CommandResult) *Envelope {
+	envelope := &Envelope{
+		Command:  command,
+		Args:     args,
+		Pod:      result.Pod,
+		ExitCode: result.ExitCode,
+		Duration: result.Duration.Round(time.Millisecond).String(),
+		Output:   result.Stdout,
+		Stderr:   strings.TrimSpace(result.Stderr),
+	}
+	if stdout := strings.TrimSpace(result.Stdout); stdout != "" && json.Valid([]byte(stdout)) {
+		envelope.Output = json.RawMessage(stdout)
+	}
+	return envelope
+}
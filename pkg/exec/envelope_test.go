@@ -0,0 +1,39 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	result := &CommandResult{Pod: "rook-ceph-operator-abc", Stdout: "{\"fsid\":\"1234\"}\n", ExitCode: 0, Duration: 1234567 * time.Microsecond}
+	out, err := json.Marshal(NewEnvelope("ceph", []string{"status", "--format", "json"}, result))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command":"ceph","args":["status","--format","json"],"pod":"rook-ceph-operator-abc","exitCode":0,"duration":"1.235s","output":{"fsid":"1234"}}`, string(out))
+
+	// a plain output is embedded as a string
+	result = &CommandResult{Pod: "rook-ceph-operator-abc", Stdout: "HEALTH_OK\n", Stderr: "warning\n", ExitCode: 2}
+	envelope := NewEnvelope("ceph", []string{"health"}, result)
+	assert.Equal(t, "HEALTH_OK\n", envelope.Output)
+	assert.Equal(t, "warning", envelope.Stderr)
+	assert.Equal(t, 2, envelope.ExitCode)
+}
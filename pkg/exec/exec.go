@@ -19,9 +19,12 @@ package exec
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
@@ -30,6 +33,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/utils/exec"
 )
 
 var (
@@ -48,7 +52,7 @@ func RunCommandInOperatorPod(ctx context.Context, clientsets *k8sutil.Clientsets
 
 	var stdout, stderr bytes.Buffer
 
-	execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, exitOnError)
+	execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, nil, &stdout, &stderr, returnOutput, exitOnError)
 	if !returnOutput {
 		return ""
 	}
@@ -57,18 +61,71 @@ func RunCommandInOperatorPod(ctx context.Context, clientsets *k8sutil.Clientsets
 	return stdout.String()
 }
 
+// CommandResult is the output and exit code of a command run in a pod
+type CommandResult struct {
+	Pod      string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// RunCommandInOperatorPodWithResult runs the command in the operator pod and returns its result. An error is
+// returned if the command could not be run, while a command that failed has a non-zero exit code.
+func RunCommandInOperatorPodWithResult(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, operatorNamespace, clusterNamespace string) (*CommandResult, error) {
+	return RunCommandInOperatorPodWithInput(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace, nil)
+}
+
+// RunCommandInOperatorPodWithInput runs the command in the operator pod with the stdin, such as a file to upload,
+// and returns its result like RunCommandInOperatorPodWithResult
+func RunCommandInOperatorPodWithInput(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, operatorNamespace, clusterNamespace string, stdin io.Reader) (*CommandResult, error) {
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, operatorNamespace, "app=rook-ceph-operator")
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for operator pod to run: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, stdin, &stdout, &stderr, true, false)
+	result := &CommandResult{Pod: pod.Name, Stdout: stdout.String(), Stderr: stderr.String(), Duration: time.Since(start)}
+	if err != nil {
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result, nil
+}
+
+// StreamCommandInOperatorPod runs the command in the operator pod and writes its output to stdout while it runs,
+// so that a large output doesn't need to be buffered
+func StreamCommandInOperatorPod(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, operatorNamespace, clusterNamespace string, stdout io.Writer) error {
+	pod, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, operatorNamespace, "app=rook-ceph-operator")
+	if err != nil {
+		return fmt.Errorf("failed to wait for operator pod to run: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	err = execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-operator", pod.Namespace, clusterNamespace, args, nil, stdout, &stderr, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to run %s %v. %v %s", cmd, args, err, stderr.String())
+	}
+	return nil
+}
+
 func RunCommandInToolboxPod(ctx context.Context, clientsets *k8sutil.Clientsets, cmd string, args []string, clusterNamespace string, returnOutput, exitOnError bool) string {
 	var pod v1.Pod
 	var err error
 
-	pod, err = k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-tools")
+	pod, err = k8sutil.GetToolboxPod(ctx, clientsets.Kube, clusterNamespace)
 	if err != nil {
 		logging.Fatal(err)
 	}
 
 	var stdout, stderr bytes.Buffer
 
-	execCmdInPod(ctx, clientsets, cmd, pod.Name, "rook-ceph-tools", pod.Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, exitOnError)
+	execCmdInPod(ctx, clientsets, cmd, pod.Name, pod.Spec.Containers[0].Name, pod.Namespace, clusterNamespace, args, nil, &stdout, &stderr, returnOutput, exitOnError)
 	if !returnOutput {
 		return ""
 	}
@@ -81,14 +138,15 @@ func RunCommandInLabeledPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 	var list *v1.PodList
 	var err error
 
-	opts := metav1.ListOptions{LabelSelector: label}
+	// the command can only be executed in a running pod
+	opts := metav1.ListOptions{LabelSelector: label, FieldSelector: "status.phase=Running"}
 	list, err = clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, opts)
 	if err != nil || len(list.Items) == 0 {
 		logging.Fatal(fmt.Errorf("failed to get rook mon pod where the command could be executed. %v", err))
 	}
 	var stdout, stderr bytes.Buffer
 
-	execCmdInPod(ctx, clientsets, cmd, list.Items[0].Name, container, list.Items[0].Namespace, clusterNamespace, args, &stdout, &stderr, returnOutput, exitOnError)
+	execCmdInPod(ctx, clientsets, cmd, list.Items[0].Name, container, list.Items[0].Namespace, clusterNamespace, args, nil, &stdout, &stderr, returnOutput, exitOnError)
 	if !returnOutput {
 		return ""
 	}
@@ -97,20 +155,53 @@ func RunCommandInLabeledPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 	return stdout.String()
 }
 
-// execCmdInPod exec command on specific pod and wait the command's output.
+// RunCommandInLabeledPodWithResult runs the command in the container of the first running pod with the label and
+// returns its result. An error is returned if the command could not be run, while a command that failed has a
+// non-zero exit code.
+func RunCommandInLabeledPodWithResult(ctx context.Context, clientsets *k8sutil.Clientsets, label, container, cmd string, args []string, clusterNamespace string) (*CommandResult, error) {
+	opts := metav1.ListOptions{LabelSelector: label, FieldSelector: "status.phase=Running"}
+	list, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods with label %s. %v", label, err)
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no running pod with label %s", label)
+	}
+	return RunCommandInPodWithResult(ctx, clientsets, list.Items[0].Name, container, cmd, args, clusterNamespace)
+}
+
+// RunCommandInPodWithResult runs the command in the container of the named pod of the cluster namespace and returns
+// its result, like RunCommandInLabeledPodWithResult.
+func RunCommandInPodWithResult(ctx context.Context, clientsets *k8sutil.Clientsets, podName, container, cmd string, args []string, clusterNamespace string) (*CommandResult, error) {
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	err := execCmdInPod(ctx, clientsets, cmd, podName, container, clusterNamespace, clusterNamespace, args, nil, &stdout, &stderr, true, false)
+	result := &CommandResult{Pod: podName, Stdout: stdout.String(), Stderr: stderr.String(), Duration: time.Since(start)}
+	if err != nil {
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result, nil
+}
+
+// execCmdInPod exec command on specific pod and wait the command's output, returning the error of the command.
 func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 	command, podName, containerName, podNamespace, clusterNamespace string,
-	args []string, stdout, stderr io.Writer, returnOutput, exitOnError bool) {
+	args []string, stdin io.Reader, stdout, stderr io.Writer, returnOutput, exitOnError bool) error {
 
 	cmd := []string{}
 	cmd = append(cmd, command)
 	cmd = append(cmd, args...)
 
-	if containerName == "rook-ceph-tools" {
+	// the toolbox has the ceph config of the cluster, the container name differs across the toolbox deployments
+	if strings.HasPrefix(containerName, "rook-ceph-tools") {
 		cmd = append(cmd, "--connect-timeout=10")
 	} else if cmd[0] == "ceph" {
 		cmd = append(cmd, "--connect-timeout=10", fmt.Sprintf("--conf=/var/lib/rook/%s/%s.config", clusterNamespace, clusterNamespace))
-	} else if cmd[0] == "rbd" {
+	} else if cmd[0] == "rbd" || cmd[0] == "rados" || cmd[0] == "radosgw-admin" {
 		cmd = append(cmd, fmt.Sprintf("--conf=/var/lib/rook/%s/%s.config", clusterNamespace, clusterNamespace))
 	}
 
@@ -125,6 +216,7 @@ func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 		VersionedParams(&v1.PodExecOptions{
 			Container: containerName,
 			Command:   cmd,
+			Stdin:     stdin != nil,
 			Stdout:    true,
 			Stderr:    true,
 			TTY:       false,
@@ -149,10 +241,11 @@ func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 				os.Exit(1)
 			}
 		}
-		return
+		return err
 	} else {
 		// Connect this process' std{in,out,err} to the remote shell process.
 		err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdin:  stdin,
 			Stdout: stdout,
 			Stderr: stderr,
 			Tty:    false,
@@ -163,5 +256,6 @@ func execCmdInPod(ctx context.Context, clientsets *k8sutil.Clientsets,
 				os.Exit(1)
 			}
 		}
+		return err
 	}
 }
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mons"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resource is an entry of the json consumed by rook's import-external-cluster.sh, as printed by
+// create-external-cluster-resources.py
+type resource struct {
+	Name string            `json:"name"`
+	Kind string            `json:"kind"`
+	Data map[string]string `json:"data"`
+}
+
+// csiSecret is a csi secret of the cluster namespace and its id and key fields
+type csiSecret struct {
+	name     string
+	idField  string
+	keyField string
+}
+
+var csiSecrets = []csiSecret{
+	{name: "rook-csi-rbd-node", idField: "userID", keyField: "userKey"},
+	{name: "rook-csi-rbd-provisioner", idField: "userID", keyField: "userKey"},
+	{name: "rook-csi-cephfs-node", idField: "adminID", keyField: "adminKey"},
+	{name: "rook-csi-cephfs-provisioner", idField: "adminID", keyField: "adminKey"},
+}
+
+const maskedSecret = "********"
+
+func Info(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, showSecrets bool) {
+	resources, err := getExternalInfo(ctx, k8sclientset, clusterNamespace, showSecrets)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	out, err := json.MarshalIndent(resources, "", "  ")
+	if err != nil {
+		logging.Fatal(fmt.Errorf("failed to marshal the external cluster info. %v", err))
+	}
+	fmt.Println(string(out))
+	if !showSecrets {
+		logging.Info("secrets are masked, pass --show-secrets to print them")
+	}
+}
+
+func getExternalInfo(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, showSecrets bool) ([]resource, error) {
+	mask := func(secret string) string {
+		if showSecrets {
+			return secret
+		}
+		return maskedSecret
+	}
+
+	monCm, err := k8sclientset.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, mons.MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mon configmap %s. %v", mons.MonConfigMap, err)
+	}
+	resources := []resource{{
+		Name: mons.MonConfigMap,
+		Kind: "ConfigMap",
+		Data: map[string]string{"data": monCm.Data["data"], "maxMonId": monCm.Data["maxMonId"], "mapping": "{}"},
+	}}
+
+	monSecret, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, "rook-ceph-mon", v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the rook-ceph-mon secret. %v", err)
+	}
+	resources = append(resources, resource{
+		Name: "rook-ceph-mon",
+		Kind: "Secret",
+		Data: map[string]string{
+			"admin-secret":  "admin-secret",
+			"fsid":          string(monSecret.Data["fsid"]),
+			"mon-secret":    "mon-secret",
+			"ceph-username": string(monSecret.Data["ceph-username"]),
+			"ceph-secret":   mask(string(monSecret.Data["ceph-secret"])),
+		},
+	})
+
+	for _, csi := range csiSecrets {
+		secret, err := k8sclientset.CoreV1().Secrets(clusterNamespace).Get(ctx, csi.name, v1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			logging.Warning("csi secret %s not found, skipping it", csi.name)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get the csi secret %s. %v", csi.name, err)
+		}
+
+		// the consumer cluster expects the user name without the "client." prefix
+		resources = append(resources, resource{
+			Name: csi.name,
+			Kind: "Secret",
+			Data: map[string]string{
+				csi.idField:  strings.TrimPrefix(string(secret.Data[csi.idField]), "client."),
+				csi.keyField: mask(string(secret.Data[csi.keyField])),
+			},
+		})
+	}
+	return resources, nil
+}
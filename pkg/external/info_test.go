@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package external
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetExternalInfo(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-mon-endpoints", Namespace: ns}, Data: map[string]string{"data": "a=10.0.0.1:6789", "maxMonId": "0"}},
+		&corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-mon", Namespace: ns}, Data: map[string][]byte{"fsid": []byte("fsid-1"), "ceph-username": []byte("client.admin"), "ceph-secret": []byte("admin-key")}},
+		&corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "rook-csi-rbd-node", Namespace: ns}, Data: map[string][]byte{"userID": []byte("csi-rbd-node"), "userKey": []byte("rbd-key")}},
+	)
+
+	resources, err := getExternalInfo(ctx, k8s, ns, false)
+	assert.NoError(t, err)
+	assert.Len(t, resources, 3)
+	assert.Equal(t, "a=10.0.0.1:6789", resources[0].Data["data"])
+	assert.Equal(t, "fsid-1", resources[1].Data["fsid"])
+	assert.Equal(t, maskedSecret, resources[1].Data["ceph-secret"])
+	assert.Equal(t, "csi-rbd-node", resources[2].Data["userID"])
+	assert.Equal(t, maskedSecret, resources[2].Data["userKey"])
+
+	resources, err = getExternalInfo(ctx, k8s, ns, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-key", resources[1].Data["ceph-secret"])
+	assert.Equal(t, "rbd-key", resources[2].Data["userKey"])
+
+	_, err = getExternalInfo(ctx, fake.NewSimpleClientset(), ns, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+type fsListEntry struct {
+	Name string `json:"name"`
+}
+
+type fsStatus struct {
+	Clients []fsClients `json:"clients"`
+	MdsMap  []mdsRank   `json:"mdsmap"`
+	Pools   []fsPool    `json:"pools"`
+}
+
+type fsClients struct {
+	Clients int    `json:"clients"`
+	Fs      string `json:"fs"`
+}
+
+// mdsRank is an mds of the mdsmap of `ceph fs status`. The standbys have no rank, and the failed ranks have no mds.
+// The rate of an mds that is not active is the string "0".
+type mdsRank struct {
+	Name  string      `json:"name"`
+	Rank  *int        `json:"rank"`
+	State string      `json:"state"`
+	Rate  json.Number `json:"rate"`
+	Dns   int         `json:"dns"`
+	Inos  int         `json:"inos"`
+	Dirs  int         `json:"dirs"`
+	Caps  int         `json:"caps"`
+}
+
+type fsPool struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Used  int64  `json:"used"`
+	Avail int64  `json:"avail"`
+}
+
+// mdsCacheStatus is the output of `ceph tell mds.<name> cache status`
+type mdsCacheStatus struct {
+	Pool struct {
+		Bytes uint64 `json:"bytes"`
+	} `json:"pool"`
+}
+
+// mdsCache is the memory used by the cache of an mds and its mds_cache_memory_limit
+type mdsCache struct {
+	Bytes uint64
+	Limit uint64
+}
+
+func Status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) {
+	err := status(ctx, clientsets, operatorNamespace, clusterNamespace, args)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) error {
+	fsNames := args
+	if len(fsNames) == 0 {
+		var err error
+		fsNames, err = ListFilesystems(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(fsNames) == 0 {
+		logging.Info("no ceph filesystems found in namespace %q", clusterNamespace)
+		return nil
+	}
+
+	for _, fsName := range fsNames {
+		logging.Info("filesystem %q", fsName)
+		statusOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"fs", "status", fsName, "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+		fsStatus, err := unMarshalFsStatus(statusOut)
+		if err != nil {
+			return fmt.Errorf("failed to parse status of filesystem %s. %v", fsName, err)
+		}
+		caches := map[string]mdsCache{}
+		for _, mds := range fsStatus.MdsMap {
+			if mds.Name == "" || (mds.State != "active" && mds.State != "standby-replay") {
+				continue
+			}
+			cache, err := getMdsCache(ctx, clientsets, operatorNamespace, clusterNamespace, mds.Name)
+			if err != nil {
+				logging.Warning("failed to get the cache usage of mds %s. %v", mds.Name, err)
+				continue
+			}
+			caches[mds.Name] = cache
+		}
+		printFsStatus(os.Stdout, fsName, fsStatus, caches)
+		fmt.Println()
+	}
+	return nil
+}
+
+// ListFilesystems returns the names of the ceph filesystems
+func ListFilesystems(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]string, error) {
+	fsListOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"fs", "ls", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+
+	var fsList []fsListEntry
+	err := json.Unmarshal([]byte(fsListOut), &fsList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ceph filesystems. %v", err)
+	}
+
+	var fsNames []string
+	for _, fs := range fsList {
+		fsNames = append(fsNames, fs.Name)
+	}
+	return fsNames, nil
+}
+
+func unMarshalFsStatus(statusOut string) (*fsStatus, error) {
+	var status *fsStatus
+	err := json.Unmarshal([]byte(statusOut), &status)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, fmt.Errorf("empty filesystem status")
+	}
+	return status, nil
+}
+
+// getMdsCache returns the memory used by the cache of the mds and its limit
+func getMdsCache(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, mdsName string) (mdsCache, error) {
+	cacheOut, err := runCephQuery(ctx, clientsets, operatorNamespace, clusterNamespace, []string{"tell", "mds." + mdsName, "cache", "status", "--format", "json"})
+	if err != nil {
+		return mdsCache{}, err
+	}
+	var cacheStatus mdsCacheStatus
+	err = json.Unmarshal([]byte(cacheOut), &cacheStatus)
+	if err != nil {
+		return mdsCache{}, fmt.Errorf("failed to parse the cache status. %v", err)
+	}
+
+	limitOut, err := runCephQuery(ctx, clientsets, operatorNamespace, clusterNamespace, []string{"config", "get", "mds." + mdsName, "mds_cache_memory_limit"})
+	if err != nil {
+		return mdsCache{}, err
+	}
+	limit, err := strconv.ParseUint(strings.TrimSpace(limitOut), 10, 64)
+	if err != nil {
+		return mdsCache{}, fmt.Errorf("failed to parse mds_cache_memory_limit %q. %v", strings.TrimSpace(limitOut), err)
+	}
+	return mdsCache{Bytes: cacheStatus.Pool.Bytes, Limit: limit}, nil
+}
+
+func runCephQuery(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) (string, error) {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("'ceph %s' failed with exit code %d: %s", strings.Join(args, " "), result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+func printFsStatus(out io.Writer, fsName string, status *fsStatus, caches map[string]mdsCache) {
+	clients := 0
+	for _, c := range status.Clients {
+		if c.Fs == fsName {
+			clients += c.Clients
+		}
+	}
+	fmt.Fprintf(out, "clients: %d\n", clients)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RANK\tSTATE\tMDS\tACTIVITY\tDENTRIES\tINODES\tDIRS\tCAPS\tCACHE")
+	for _, mds := range status.MdsMap {
+		rank, name, rate, cache := formatRank(mds.Rank), "-", "-", "-"
+		if mds.Name != "" {
+			name = mds.Name
+		}
+		if mds.Rate != "" {
+			rate = fmt.Sprintf("%s/s", formatRate(mds.Rate))
+		}
+		if c, ok := caches[mds.Name]; ok {
+			cache = fmt.Sprintf("%s/%s", output.Bytes(c.Bytes), output.Bytes(c.Limit))
+			if c.Limit != 0 {
+				cache += fmt.Sprintf(" (%d%%)", c.Bytes*100/c.Limit)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n", rank, mds.State, name, rate, mds.Dns, mds.Inos, mds.Dirs, mds.Caps, cache)
+	}
+	w.Flush()
+	for _, mds := range status.MdsMap {
+		if mds.State != "active" && mds.State != "standby-replay" && mds.State != "standby" {
+			if mds.Name == "" {
+				logging.Warning("rank %s is in state %q", formatRank(mds.Rank), mds.State)
+			} else {
+				logging.Warning("mds %s is in state %q", mds.Name, mds.State)
+			}
+		}
+		// the mds trims its cache down to the limit, a cache above it is not trimmed fast enough
+		if c, ok := caches[mds.Name]; ok && c.Limit != 0 && c.Bytes > c.Limit {
+			logging.Warning("the cache of mds %s uses %s, more than its mds_cache_memory_limit of %s", mds.Name, output.Bytes(c.Bytes), output.Bytes(c.Limit))
+		}
+	}
+
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tTYPE\tUSED\tAVAIL")
+	for _, pool := range status.Pools {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", pool.Name, pool.Type, pool.Used, pool.Avail)
+	}
+	w.Flush()
+	for _, pool := range status.Pools {
+		if pool.Type == "metadata" && pool.Used+pool.Avail > 0 && float64(pool.Used)/float64(pool.Used+pool.Avail) > 0.8 {
+			logging.Warning("metadata pool %s is more than 80%% full", pool.Name)
+		}
+	}
+}
+
+func formatRank(rank *int) string {
+	if rank == nil {
+		return "-"
+	}
+	return strconv.Itoa(*rank)
+}
+
+func formatRate(rate json.Number) string {
+	value, err := rate.Float64()
+	if err != nil {
+		return rate.String()
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFsStatus is the output of `ceph fs status myfs --format json` with an active mds, a standby-replay mds and a
+// standby mds
+const testFsStatus = `{"clients": [{"clients": 1, "fs": "myfs"}], "mds_version": [{"daemon": ["myfs-a", "myfs-b", "myfs-c"], "version": "ceph version 17.2.6 (d7ff0d10654d2280e08f1ab989c7cdf3064446a5) quincy (stable)"}], "mdsmap": [{"caps": 3, "dirs": 12, "dns": 12, "inos": 15, "name": "myfs-a", "rank": 0, "rate": 0, "state": "active"}, {"caps": 0, "dirs": 3, "dns": 4, "events": 0, "inos": 5, "name": "myfs-b", "rank": 0, "state": "standby-replay"}, {"name": "myfs-c", "state": "standby"}], "pools": [{"avail": 20129155072, "id": 2, "name": "myfs-metadata", "type": "metadata", "used": 98304}, {"avail": 20129155072, "id": 3, "name": "myfs-replicated", "type": "data", "used": 0}]}`
+
+// testFsStatusDegraded has a rank rejoining, whose rate is the string "0", and a failed rank without mds
+const testFsStatusDegraded = `{"clients": [{"clients": 2, "fs": "myfs"}], "mds_version": [], "mdsmap": [{"caps": 10, "dirs": 40, "dns": 120, "inos": 130, "name": "myfs-a", "rank": 0, "rate": 12.5, "state": "active"}, {"caps": 0, "dirs": 1, "dns": 2, "inos": 2, "name": "myfs-b", "rank": 1, "rate": "0", "state": "rejoin"}, {"rank": 2, "state": "failed"}], "pools": [{"avail": 1000, "id": 2, "name": "myfs-metadata", "type": "metadata", "used": 9000}]}`
+
+func TestFsStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		caches   map[string]mdsCache
+		expected string
+	}{
+		{
+			name:   "active with standbys",
+			status: testFsStatus,
+			caches: map[string]mdsCache{
+				"myfs-a": {Bytes: 512 << 20, Limit: 4 << 30},
+				"myfs-b": {Bytes: 1 << 20, Limit: 4 << 30},
+			},
+			expected: `clients: 1
+RANK   STATE            MDS      ACTIVITY   DENTRIES   INODES   DIRS   CAPS   CACHE
+0      active           myfs-a   0/s        12         15       12     3      512.0 MiB/4.0 GiB (12%)
+0      standby-replay   myfs-b   -          4          5        3      0      1.0 MiB/4.0 GiB (0%)
+-      standby          myfs-c   -          0          0        0      0      -
+POOL              TYPE       USED    AVAIL
+myfs-metadata     metadata   98304   20129155072
+myfs-replicated   data       0       20129155072
+`,
+		},
+		{
+			name:   "degraded",
+			status: testFsStatusDegraded,
+			caches: map[string]mdsCache{},
+			expected: `clients: 2
+RANK   STATE    MDS      ACTIVITY   DENTRIES   INODES   DIRS   CAPS   CACHE
+0      active   myfs-a   12/s       120        130      40     10     -
+1      rejoin   myfs-b   0/s        2          2        1      0      -
+2      failed   -        -          0          0        0      0      -
+POOL            TYPE       USED   AVAIL
+myfs-metadata   metadata   9000   1000
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := unMarshalFsStatus(test.status)
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			printFsStatus(&out, "myfs", status, test.caches)
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+
+	_, err := unMarshalFsStatus("null")
+	assert.Error(t, err)
+	_, err = unMarshalFsStatus("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// batchMarker delimits the output of each command of the batch
+const batchMarker = "----kubectl-rook-ceph-batch----"
+
+// batchCommands are the ceph queries of the health checks with fixed arguments, the queries depending on the
+// cluster, such as the erasure code profile of each pool, still run in their own exec
+var batchCommands = [][]string{
+	{"status", "--format", "json"},
+	{"log", "last", fmt.Sprint(clusterLogEntries), "info", "cluster", "--format", "json"},
+	{"config", "get", "mon", "mon_data_size_warn"},
+	{"osd", "ls", "--format", "json"},
+	{"osd", "dump", "--format", "json"},
+	{"osd", "metadata", "--format", "json"},
+	{"osd", "tree", "--format", "json"},
+	{"osd", "df", "--format", "json"},
+	{"pg", "dump", "pgs", "--format", "json"},
+	{"config", "get", "osd", "osd_scrub_max_interval"},
+	{"config", "get", "osd", "osd_deep_scrub_interval"},
+	{"osd", "pool", "ls", "detail", "--format", "json"},
+	{"osd", "crush", "rule", "dump", "--format", "json"},
+	{"osd", "crush", "tree", "--show-shadow", "--format", "json"},
+}
+
+func batchKey(args []string) string {
+	return strings.Join(args, " ")
+}
+
+// loadBatch runs the batched queries and returns the outputs of the queries that succeeded, an error returns no
+// outputs and falls back to an exec per query
+func loadBatch(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) map[string]string {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "/bin/sh", []string{"-c", buildBatchScript(batchCommands, clusterNamespace)}, operatorNamespace, clusterNamespace)
+	var outputs map[string]string
+	if err == nil {
+		outputs, err = parseBatchOutput(result.Stdout, batchCommands)
+	}
+	if err != nil {
+		logging.Warning("failed to run the ceph queries in a single exec, running an exec per query. %v", err)
+		return nil
+	}
+	logging.Info("ran %d ceph queries in a single exec in %s, %d succeeded", len(batchCommands), result.Duration.Round(time.Millisecond), len(outputs))
+	return outputs
+}
+
+// runCephCommand returns the output of the query batched in the run of the check, or runs the query in its own exec
+// when it's not batched or it failed in the batch, so that its error is reported like without the batch
+func runCephCommand(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) string {
+	if check.report != nil {
+		if output, ok := check.report.batchOutputs[batchKey(args)]; ok {
+			return output
+		}
+	}
+	return exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, true, false)
+}
+
+// buildBatchScript returns a shell script running the ceph commands one after the other with the ceph config of
+// the cluster, the output of each command is preceded by a start marker and followed by its exit code
+func buildBatchScript(commands [][]string, clusterNamespace string) string {
+	var script strings.Builder
+	for i, args := range commands {
+		cmd := []string{"ceph"}
+		for _, arg := range args {
+			cmd = append(cmd, batchQuote(arg))
+		}
+		cmd = append(cmd, "--connect-timeout=10", batchQuote(fmt.Sprintf("--conf=/var/lib/rook/%s/%s.config", clusterNamespace, clusterNamespace)))
+		fmt.Fprintf(&script, "printf '%%s start %d\\n' '%s'; %s; printf '\\n%%s end %d %%d\\n' '%s' $?;\n", i, batchMarker, strings.Join(cmd, " "), i, batchMarker)
+	}
+	return script.String()
+}
+
+func batchQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// parseBatchOutput returns the output of each command that succeeded by its arguments. A missing or truncated
+// output fails the whole batch since the outputs can't be told apart.
+func parseBatchOutput(stdout string, commands [][]string) (map[string]string, error) {
+	outputs := map[string]string{}
+	rest := stdout
+	for i, args := range commands {
+		start := fmt.Sprintf("%s start %d\n", batchMarker, i)
+		index := strings.Index(rest, start)
+		if index < 0 {
+			return nil, fmt.Errorf("the output of 'ceph %s' is missing", batchKey(args))
+		}
+		rest = rest[index+len(start):]
+
+		end := fmt.Sprintf("\n%s end %d ", batchMarker, i)
+		index = strings.Index(rest, end)
+		if index < 0 {
+			return nil, fmt.Errorf("the output of 'ceph %s' is truncated", batchKey(args))
+		}
+		output := rest[:index]
+		exitLine, after, _ := strings.Cut(rest[index+len(end):], "\n")
+		rest = after
+		exitCode, err := strconv.Atoi(exitLine)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exit code of 'ceph %s'. %v", batchKey(args), err)
+		}
+		if exitCode == 0 {
+			outputs[batchKey(args)] = output
+		}
+	}
+	return outputs, nil
+}
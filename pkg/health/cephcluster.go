@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+func checkCephClusterStatus(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, check.options().ClusterName)
+	if err != nil {
+		check.Error(err)
+		return
+	}
+
+	cephHealth, _ := unMarshalCephStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	compareCephClusterStatus(check, cephCluster, cephHealth.Status)
+}
+
+// compareCephClusterStatus checks the phase of the CephCluster, and whether the ceph health reported by
+// the operator in the CephCluster status is the live ceph health. A different health means the operator
+// stopped updating the status, such as when its reconcile is stuck.
+func compareCephClusterStatus(check *CheckResult, cephCluster *cephv1.CephCluster, cephHealth string) {
+	status := cephCluster.Status
+	switch status.Phase {
+	case cephv1.ConditionReady:
+		check.Info("CephCluster %s is %s", cephCluster.Name, status.Phase)
+	case cephv1.ConditionProgressing:
+		check.Info("CephCluster %s is %s, the operator is reconciling it", cephCluster.Name, status.Phase)
+	case cephv1.ConditionFailure:
+		check.Error(fmt.Errorf("CephCluster %s is in phase %s: %s", cephCluster.Name, status.Phase, status.Message))
+	default:
+		check.Warning("CephCluster %s is in phase %q: %s", cephCluster.Name, status.Phase, status.Message)
+	}
+
+	if status.CephStatus == nil || status.CephStatus.Health == "" {
+		check.Warning("the operator did not report the ceph health in the status of CephCluster %s", cephCluster.Name)
+		return
+	}
+	if cephHealth == "" {
+		check.Warning("the live ceph health is not available, the CephCluster status %s can't be verified", status.CephStatus.Health)
+		return
+	}
+	if status.CephStatus.Health != cephHealth {
+		check.Warning("CephCluster %s reports %s (last checked %s) while ceph reports %s, the operator may not be reconciling the cluster",
+			cephCluster.Name, status.CephStatus.Health, status.CephStatus.LastChecked, cephHealth)
+		return
+	}
+	check.Info("CephCluster %s reports the live ceph health %s", cephCluster.Name, cephHealth)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+type osdMetadata struct {
+	Id                int    `json:"id"`
+	BluestoreBdevType string `json:"bluestore_bdev_type"`
+	Rotational        string `json:"rotational"`
+	Devices           string `json:"devices"`
+}
+
+type osdTree struct {
+	Nodes []osdTreeNode `json:"nodes"`
+}
+
+type osdTreeNode struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DeviceClass string `json:"device_class"`
+}
+
+func checkOsdDeviceClasses(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	metadataOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "metadata", "--format", "json"})
+	var metadata []osdMetadata
+	err := json.Unmarshal([]byte(metadataOut), &metadata)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd metadata output. %v", err))
+		return
+	}
+
+	treeOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "tree", "--format", "json"})
+	var tree osdTree
+	err = json.Unmarshal([]byte(treeOut), &tree)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd tree output. %v", err))
+		return
+	}
+
+	mismatches := findDeviceClassMismatches(metadata, tree.Nodes)
+	for _, mismatch := range mismatches {
+		check.Warning("%s", mismatch)
+	}
+	if len(mismatches) == 0 {
+		check.Info("the device class of all osds matches their device type")
+	}
+}
+
+// findDeviceClassMismatches returns the osds whose crush device class doesn't match the type of their device,
+// such as an ssd in the hdd class, which places the data of the crush rules of a class on the wrong devices.
+// Custom device classes are not compared since their device type is not known.
+func findDeviceClassMismatches(metadata []osdMetadata, nodes []osdTreeNode) []string {
+	deviceClasses := map[int]string{}
+	for _, node := range nodes {
+		if node.Type == "osd" {
+			deviceClasses[node.Id] = node.DeviceClass
+		}
+	}
+
+	var mismatches []string
+	for _, osd := range metadata {
+		deviceClass, ok := deviceClasses[osd.Id]
+		if !ok || deviceClass == "" {
+			continue
+		}
+		deviceType := getDeviceType(osd)
+		if deviceType == "" {
+			continue
+		}
+		if deviceClass != "hdd" && deviceClass != "ssd" && deviceClass != "nvme" {
+			continue
+		}
+		if (deviceClass == "hdd") != (deviceType == "hdd") {
+			mismatches = append(mismatches, fmt.Sprintf("osd.%d has device class %s but its device %s is %s", osd.Id, deviceClass, osd.Devices, deviceType))
+		}
+	}
+	return mismatches
+}
+
+// getDeviceType returns hdd for a rotational device and ssd otherwise, or an empty type when it's not reported
+func getDeviceType(osd osdMetadata) string {
+	if osd.BluestoreBdevType == "hdd" || osd.BluestoreBdevType == "ssd" {
+		return osd.BluestoreBdevType
+	}
+	switch osd.Rotational {
+	case "1":
+		return "hdd"
+	case "0":
+		return "ssd"
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// CheckDiff is the change of a check between two health reports
+type CheckDiff struct {
+	Name       string
+	FromStatus string
+	ToStatus   string
+	Added      []string
+	Removed    []string
+}
+
+// Diff prints the changes between two health reports saved with --output json
+func Diff(fromPath, toPath string) {
+	err := diff(fromPath, toPath)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func diff(fromPath, toPath string) error {
+	from, err := loadReport(fromPath)
+	if err != nil {
+		return err
+	}
+	to, err := loadReport(toPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("status: %s -> %s (%s -> %s)\n", from.Status, to.Status, from.Timestamp.Format(time.RFC3339), to.Timestamp.Format(time.RFC3339))
+	if from.Cluster != to.Cluster {
+		logging.Warning("the reports are of different clusters: %s and %s", from.Cluster, to.Cluster)
+	}
+
+	checkDiffs := diffReports(from, to)
+	if len(checkDiffs) == 0 {
+		logging.Info("no check changed")
+		return nil
+	}
+	for _, d := range checkDiffs {
+		fmt.Println()
+		switch {
+		case d.FromStatus == "":
+			fmt.Printf("check %s: added with status %s\n", d.Name, d.ToStatus)
+		case d.ToStatus == "":
+			fmt.Printf("check %s: removed, its status was %s\n", d.Name, d.FromStatus)
+		case d.FromStatus != d.ToStatus:
+			fmt.Printf("check %s: %s -> %s\n", d.Name, d.FromStatus, d.ToStatus)
+		default:
+			fmt.Printf("check %s: %s\n", d.Name, d.ToStatus)
+		}
+		for _, message := range d.Removed {
+			fmt.Printf("  - %s\n", message)
+		}
+		for _, message := range d.Added {
+			fmt.Printf("  + %s\n", message)
+		}
+	}
+	return nil
+}
+
+func loadReport(path string) (*HealthReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the health report %s. %v", path, err)
+	}
+	var report HealthReport
+	err = json.Unmarshal(content, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the health report %s, it must be saved with --output json. %v", path, err)
+	}
+	return &report, nil
+}
+
+// diffReports returns the checks whose status or messages changed, in the order of the checks of the reports
+func diffReports(from, to *HealthReport) []CheckDiff {
+	fromChecks := map[string]*CheckResult{}
+	for _, check := range from.Checks {
+		fromChecks[check.Name] = check
+	}
+	toChecks := map[string]*CheckResult{}
+	for _, check := range to.Checks {
+		toChecks[check.Name] = check
+	}
+
+	var diffs []CheckDiff
+	for _, toCheck := range to.Checks {
+		fromCheck, ok := fromChecks[toCheck.Name]
+		if !ok {
+			diffs = append(diffs, CheckDiff{Name: toCheck.Name, ToStatus: toCheck.Status, Added: toCheck.Messages})
+			continue
+		}
+		d := CheckDiff{
+			Name:       toCheck.Name,
+			FromStatus: fromCheck.Status,
+			ToStatus:   toCheck.Status,
+			Added:      subtractMessages(toCheck.Messages, fromCheck.Messages),
+			Removed:    subtractMessages(fromCheck.Messages, toCheck.Messages),
+		}
+		if d.FromStatus != d.ToStatus || len(d.Added) != 0 || len(d.Removed) != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	for _, fromCheck := range from.Checks {
+		if _, ok := toChecks[fromCheck.Name]; !ok {
+			diffs = append(diffs, CheckDiff{Name: fromCheck.Name, FromStatus: fromCheck.Status, Removed: fromCheck.Messages})
+		}
+	}
+	return diffs
+}
+
+// subtractMessages returns the messages that are not in the other messages
+func subtractMessages(messages, other []string) []string {
+	otherSet := map[string]bool{}
+	for _, message := range other {
+		otherSet[message] = true
+	}
+	var remaining []string
+	for _, message := range messages {
+		if !otherSet[message] {
+			remaining = append(remaining, message)
+		}
+	}
+	return remaining
+}
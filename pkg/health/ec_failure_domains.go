@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+// the type of the erasure-coded pools in the ceph osd pool ls detail output
+const erasurePoolType = 3
+
+type crushRule struct {
+	RuleId   int             `json:"rule_id"`
+	RuleName string          `json:"rule_name"`
+	Steps    []crushRuleStep `json:"steps"`
+}
+
+type crushRuleStep struct {
+	Op       string `json:"op"`
+	Num      int    `json:"num"`
+	Type     string `json:"type"`
+	ItemName string `json:"item_name"`
+}
+
+type crushTree struct {
+	Nodes []crushTreeNode `json:"nodes"`
+}
+
+type crushTreeNode struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Children []int  `json:"children"`
+}
+
+type erasureCodeProfile struct {
+	K                  string `json:"k"`
+	M                  string `json:"m"`
+	CrushFailureDomain string `json:"crush-failure-domain"`
+}
+
+func checkErasureCodedFailureDomains(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	poolsOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "pool", "ls", "detail", "--format", "json"})
+	var pools []poolDetail
+	err := json.Unmarshal([]byte(poolsOut), &pools)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd pool ls detail output. %v", err))
+		return
+	}
+	profiles := map[string]erasureCodeProfile{}
+	for _, pool := range pools {
+		if pool.Type != erasurePoolType {
+			continue
+		}
+		if _, ok := profiles[pool.ErasureCodeProfile]; ok {
+			continue
+		}
+		profileOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "erasure-code-profile", "get", pool.ErasureCodeProfile, "--format", "json"})
+		var profile erasureCodeProfile
+		err := json.Unmarshal([]byte(profileOut), &profile)
+		if err != nil {
+			check.Error(fmt.Errorf("failed to parse ceph osd erasure-code-profile get %s output. %v", pool.ErasureCodeProfile, err))
+			return
+		}
+		profiles[pool.ErasureCodeProfile] = profile
+	}
+	if len(profiles) == 0 {
+		check.Info("no erasure-coded pool found")
+		return
+	}
+
+	rulesOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "crush", "rule", "dump", "--format", "json"})
+	var rules []crushRule
+	err = json.Unmarshal([]byte(rulesOut), &rules)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd crush rule dump output. %v", err))
+		return
+	}
+	// the shadow trees are the roots of the rules of a device class, such as default~hdd
+	treeOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "crush", "tree", "--show-shadow", "--format", "json"})
+	var tree crushTree
+	err = json.Unmarshal([]byte(treeOut), &tree)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd crush tree output. %v", err))
+		return
+	}
+
+	warnings, infos := findErasureCodedFailureDomainIssues(pools, profiles, rules, tree.Nodes)
+	for _, info := range infos {
+		check.Info("%s", info)
+	}
+	for _, warning := range warnings {
+		check.Warning("%s", warning)
+	}
+	if len(warnings) == 0 {
+		check.Info("the crush rules of the erasure-coded pools have a failure domain for each of their chunks")
+	}
+}
+
+// findErasureCodedFailureDomainIssues returns the erasure-coded pools whose crush rule can't place their k+m chunks
+// in separate failure domains, so the pgs stay undersized and the pool doesn't tolerate the loss of m failure
+// domains, and the rules placing the chunks on a failure domain other than the one of the profile. The pools whose
+// chunks fill all the failure domains are returned as infos, since they can't recover until a failure domain is back.
+func findErasureCodedFailureDomainIssues(pools []poolDetail, profiles map[string]erasureCodeProfile, rules []crushRule, nodes []crushTreeNode) ([]string, []string) {
+	rulesById := map[int]crushRule{}
+	for _, rule := range rules {
+		rulesById[rule.RuleId] = rule
+	}
+
+	var warnings, infos []string
+	for _, pool := range pools {
+		if pool.Type != erasurePoolType {
+			continue
+		}
+		profile := profiles[pool.ErasureCodeProfile]
+		k, errK := strconv.Atoi(profile.K)
+		m, errM := strconv.Atoi(profile.M)
+		if errK != nil || errM != nil {
+			warnings = append(warnings, fmt.Sprintf("pool %s has an invalid erasure code profile %s with k=%q and m=%q", pool.PoolName, pool.ErasureCodeProfile, profile.K, profile.M))
+			continue
+		}
+		rule, ok := rulesById[pool.CrushRule]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("pool %s uses crush rule %d which doesn't exist", pool.PoolName, pool.CrushRule))
+			continue
+		}
+		root, failureDomain, ok := getRuleFailureDomain(rule)
+		if !ok {
+			infos = append(infos, fmt.Sprintf("pool %s uses crush rule %s with several choose steps, its failure domains are not checked", pool.PoolName, rule.RuleName))
+			continue
+		}
+
+		if profile.CrushFailureDomain != "" && profile.CrushFailureDomain != failureDomain {
+			warnings = append(warnings, fmt.Sprintf("pool %s places its chunks on %s failure domains with crush rule %s while its erasure code profile %s expects %s failure domains",
+				pool.PoolName, failureDomain, rule.RuleName, pool.ErasureCodeProfile, profile.CrushFailureDomain))
+		}
+		domains := countFailureDomains(nodes, root, failureDomain)
+		chunks := k + m
+		switch {
+		case domains < chunks:
+			warnings = append(warnings, fmt.Sprintf("pool %s needs %d %s failure domains for its k=%d m=%d chunks but crush rule %s has %d %ss under %s, its pgs can't place all their chunks so the pool doesn't tolerate the m=%d failures of its profile",
+				pool.PoolName, chunks, failureDomain, k, m, rule.RuleName, domains, failureDomain, root, m))
+		case domains == chunks:
+			infos = append(infos, fmt.Sprintf("pool %s has its k=%d m=%d chunks on all the %d %ss under %s, its pgs can't recover until a lost %s is back",
+				pool.PoolName, k, m, domains, failureDomain, root, failureDomain))
+		}
+	}
+	return warnings, infos
+}
+
+// getRuleFailureDomain returns the root the rule takes and the bucket type it chooses the chunks from, when the rule
+// has a single choose step
+func getRuleFailureDomain(rule crushRule) (string, string, bool) {
+	var root, failureDomain string
+	chooseSteps := 0
+	for _, step := range rule.Steps {
+		switch {
+		case step.Op == "take":
+			root = step.ItemName
+		case strings.HasPrefix(step.Op, "choose"):
+			chooseSteps++
+			failureDomain = step.Type
+		}
+	}
+	return root, failureDomain, chooseSteps == 1 && root != ""
+}
+
+// countFailureDomains returns the number of buckets of the type under the root with at least one osd
+func countFailureDomains(nodes []crushTreeNode, root, failureDomain string) int {
+	nodesById := map[int]crushTreeNode{}
+	var rootNode *crushTreeNode
+	for i := range nodes {
+		nodesById[nodes[i].Id] = nodes[i]
+		if nodes[i].Name == root {
+			rootNode = &nodes[i]
+		}
+	}
+	if rootNode == nil {
+		return 0
+	}
+
+	var hasOsd func(node crushTreeNode) bool
+	hasOsd = func(node crushTreeNode) bool {
+		if node.Type == "osd" {
+			return true
+		}
+		for _, id := range node.Children {
+			if child, ok := nodesById[id]; ok && hasOsd(child) {
+				return true
+			}
+		}
+		return false
+	}
+	var count func(node crushTreeNode) int
+	count = func(node crushTreeNode) int {
+		if node.Type == failureDomain {
+			if hasOsd(node) {
+				return 1
+			}
+			return 0
+		}
+		total := 0
+		for _, id := range node.Children {
+			if child, ok := nodesById[id]; ok {
+				total += count(child)
+			}
+		}
+		return total
+	}
+	return count(*rootNode)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// HealthFromFile evaluates the checks that only need the ceph status against a status captured with
+// `ceph status --format json`, such as for the post-mortem of a cluster that is no longer reachable
+func HealthFromFile(clusterNamespace, path string, opts HealthOptions) *HealthReport {
+	report, err := healthFromFile(clusterNamespace, path, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	return report
+}
+
+func healthFromFile(clusterNamespace, path string, opts HealthOptions) (*HealthReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ceph status file. %v", err)
+	}
+	// the sections are parsed independently, a malformed section only makes its check incomplete
+	if !json.Valid(content) {
+		return nil, fmt.Errorf("the file %s is not the json output of `ceph status --format json`", path)
+	}
+	status, parseErr := parseCephStatus(string(content))
+
+	logging.Info("evaluating the ceph status read from %s, the checks that need the cluster are skipped", path)
+	report := newHealthReport(clusterNamespace, opts)
+
+	fmt.Fprintln(opts.Output)
+	check := report.newCheck("mon-quorum", "Checking mon quorum and ceph health details")
+	if parseErr != nil {
+		check.Warning("failed to parse the ceph status, the check may be incomplete. %v", parseErr)
+	}
+	evaluateCephHealth(check, status.Health)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("pg-status", "Checking placement group status")
+	report.PgStates = evaluatePgStates(check, status.PgMap.PgsByState)
+
+	report.finalize()
+	fmt.Fprintln(opts.Output)
+	report.printSummary()
+	return report, nil
+}
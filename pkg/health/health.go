@@ -18,14 +18,18 @@ package health
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/version"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,10 +39,16 @@ import (
 type cephStatus struct {
 	PgMap  pgMap        `json:"pgmap"`
 	Health healthStatus `json:"health"`
+	// ElectionEpoch is the epoch of the last mon election, QuorumAge the seconds since the quorum formed
+	ElectionEpoch int      `json:"election_epoch"`
+	QuorumAge     int64    `json:"quorum_age"`
+	QuorumNames   []string `json:"quorum_names"`
 }
 
 type healthStatus struct {
-	Status string `json:"status"`
+	Status string                     `json:"status"`
+	Checks map[string]json.RawMessage `json:"checks"`
+	Mutes  []healthMute               `json:"mutes"`
 }
 
 type pgMap struct {
@@ -50,153 +60,425 @@ type PgStateEntry struct {
 	Count     int    `json:"count"`
 }
 
-func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
-	logging.Info("Checking if at least three mon pods are running on different nodes")
-	checkPodsOnNodes(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-mon")
+type pgDump struct {
+	PgStats []pgStat `json:"pg_stats"`
+}
 
-	fmt.Println()
-	logging.Info("Checking mon quorum and ceph health details")
-	checkMonQuorum(ctx, clientsets, operatorNamespace, clusterNamespace)
+type pgStat struct {
+	PgId               string `json:"pgid"`
+	LastScrubStamp     string `json:"last_scrub_stamp"`
+	LastDeepScrubStamp string `json:"last_deep_scrub_stamp"`
+}
 
-	fmt.Println()
-	logging.Info("Checking if at least three osd pods are running on different nodes")
-	checkPodsOnNodes(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-osd")
+const (
+	// cephTimeLayout is the layout of the timestamps in the ceph json output
+	cephTimeLayout = "2006-01-02T15:04:05.999999-0700"
 
-	fmt.Println()
-	CheckAllPodsStatus(ctx, clientsets.Kube, operatorNamespace, clusterNamespace)
+	// default values of osd_scrub_max_interval and osd_deep_scrub_interval
+	defaultScrubMaxInterval  = 7 * 24 * time.Hour
+	defaultDeepScrubInterval = 7 * 24 * time.Hour
+)
 
-	fmt.Println()
-	logging.Info("Checking placement group status")
-	checkPgStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+// HealthOptions are the options of a run of the health checks, they are set from the flags of the health command
+type HealthOptions struct {
+	// Selectors are the label selectors of the pods checked by the health checks, by daemon type.
+	// They can be overridden for deployments that label the daemons differently.
+	Selectors map[string]string
+	// MinPgsPerOsd and MaxPgsPerOsd are the bounds of the number of pgs per osd
+	MinPgsPerOsd int
+	MaxPgsPerOsd int
+	// ClusterName is the name of the CephCluster the checks run against, the CephCluster of the namespace by default
+	ClusterName string
+	// BatchExec runs the ceph queries of the health checks in a single exec in the operator pod, rather than an exec
+	// per query
+	BatchExec bool
+	// ScanOperatorLogs scans the operator logs of the last hour for reconcile errors and panics
+	ScanOperatorLogs bool
+	// Output is where the health checks print the pods they list and the blank lines between the checks, the
+	// messages of the checks are logged to stderr
+	Output io.Writer
+}
 
-	fmt.Println()
-	logging.Info("Checking if at least one mgr pod is running")
-	checkMgrPodsStatusAndCounts(ctx, clientsets.Kube, clusterNamespace)
+// NewHealthOptions returns the default options of the health checks
+func NewHealthOptions() HealthOptions {
+	return HealthOptions{
+		Selectors: map[string]string{
+			"operator": "app=rook-ceph-operator",
+			"mon":      "app=rook-ceph-mon",
+			"mgr":      "app=rook-ceph-mgr",
+			"osd":      "app=rook-ceph-osd",
+		},
+		MinPgsPerOsd: defaultMinPgsPerOsd,
+		MaxPgsPerOsd: defaultMaxPgsPerOsd,
+		Output:       os.Stdout,
+	}
 }
 
-func checkPodsOnNodes(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, label string) {
-	var daemonType string
-	if strings.Contains(label, "osd") {
-		daemonType = "osd"
-	} else if strings.Contains(label, "mon") {
-		daemonType = "mon"
+func Health(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, opts HealthOptions) *HealthReport {
+	if opts.ClusterName != "" {
+		// rook runs a single CephCluster by namespace, the ceph commands use the config of the namespace in the
+		// operator pod, so the named CephCluster must be the CephCluster of the namespace
+		cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, opts.ClusterName)
+		if err != nil {
+			logging.Fatal(err)
+		}
+		logging.Info("running the health checks against CephCluster %s in namespace %s", cephCluster.Name, clusterNamespace)
 	}
+	report := newHealthReport(clusterNamespace, opts)
+	if opts.BatchExec {
+		report.batchOutputs = loadBatch(ctx, clientsets, operatorNamespace, clusterNamespace)
+		fmt.Fprintln(opts.Output)
+	}
+
+	check := report.newCheck("operator", "Checking if the rook-ceph operator is available and ready")
+	checkOperatorHealth(ctx, check, clientsets.Kube, operatorNamespace, opts.ScanOperatorLogs)
+
+	fmt.Fprintln(opts.Output)
+
+	check = report.newCheck("mon-pods-on-nodes", "Checking if at least three mon pods are running on different nodes")
+	checkPodsOnNodes(ctx, check, clientsets.Kube, clusterNamespace, "mon")
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("mon-quorum", "Checking mon quorum and ceph health details")
+	checkMonQuorum(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("mon-quorum-stability", "Checking if the mon quorum is stable")
+	checkMonQuorumStability(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("mon-store-size", "Checking the size of the mon stores")
+	checkMonStoreSize(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("osd-pods-on-nodes", "Checking if at least three osd pods are running on different nodes")
+	checkPodsOnNodes(ctx, check, clientsets.Kube, clusterNamespace, "osd")
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("osd-count", "Checking if the osd count matches the CephCluster spec and the running osd pods")
+	checkOsdCount(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("osd-activation", "Checking if the osd pods activated their osds and the osds are up and in")
+	checkOsdActivation(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("osd-device-classes", "Checking if the device class of the osds matches their device type")
+	checkOsdDeviceClasses(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("pods-status", "Checking the status of all pods")
+	CheckAllPodsStatus(ctx, check, clientsets.Kube, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("daemon-resources", "Checking the memory and cpu requests and limits of the mon, mgr and osd pods")
+	checkDaemonResources(ctx, check, clientsets.Kube, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("pg-status", "Checking placement group status")
+	report.PgStates = checkPgStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("pool-replication", "Checking if any pool has a size or min_size of 1")
+	checkPoolReplication(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("ec-failure-domains", "Checking if the erasure-coded pools have enough failure domains for their chunks")
+	checkErasureCodedFailureDomains(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("mgr-pods", "Checking if at least one mgr pod is running")
+	checkMgrPodsStatusAndCounts(ctx, check, clientsets.Kube, clusterNamespace)
 
-	opts := metav1.ListOptions{LabelSelector: label}
-	podList, err := k8sclientset.CoreV1().Pods(clusterNamespace).List(ctx, opts)
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("mgr-responsive", "Checking if the active mgr is responsive")
+	checkMgrResponsive(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("ceph-versions", "Checking if all daemons are running the same ceph version")
+	checkCephVersions(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Fprintln(opts.Output)
+	check = report.newCheck("cephcluster-status", "Checking if the CephCluster status matches the live ceph status")
+	checkCephClusterStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	report.finalize()
+	fmt.Fprintln(opts.Output)
+	report.printSummary()
+	return report
+}
+
+func checkPodsOnNodes(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, clusterNamespace, daemonType string) {
+	opts := metav1.ListOptions{LabelSelector: check.options().Selectors[daemonType]}
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, clusterNamespace, opts)
 	if err != nil {
-		logging.Error(fmt.Errorf("failed to list %s pods with label %s: %v", daemonType, opts.LabelSelector, err))
+		check.Error(fmt.Errorf("failed to list %s pods with label %s: %v", daemonType, opts.LabelSelector, err))
+		return
 	}
 
-	var nodeList = make(map[string]string)
-	for i := range podList.Items {
-		nodeName := podList.Items[i].Spec.NodeName
-		if _, okay := nodeList[nodeName]; !okay {
-			nodeList[nodeName] = podList.Items[i].Name
+	// the pending pods not scheduled yet and the terminating pods replaced by a new pod don't run the daemon on a
+	// node, they would count as a node or as a second daemon on the node
+	var scheduledPods []v1.Pod
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || !pod.DeletionTimestamp.IsZero() {
+			continue
 		}
+		scheduledPods = append(scheduledPods, pod)
 	}
 
-	if len(nodeList) < 3 {
-		logging.Warning("At least three %s pods should running on different nodes\n", daemonType)
+	podsByNode, nodeNames := k8sutil.GroupPodsByNode(scheduledPods)
+	if len(nodeNames) < 3 {
+		check.Warning("At least three %s pods should running on different nodes\n", daemonType)
 	}
 
-	for i := range podList.Items {
-		fmt.Printf("%s\t%s\t%s\t%s\n", podList.Items[i].Name, podList.Items[i].Status.Phase, podList.Items[i].Namespace, podList.Items[i].Spec.NodeName)
+	// losing a node running multiple mons could break the quorum even if three nodes have mons
+	if daemonType == "mon" {
+		for _, nodeName := range nodeNames {
+			if len(podsByNode[nodeName]) > 1 {
+				check.Warning("node %q is running %d mon pods, losing this node could break the mon quorum", nodeName, len(podsByNode[nodeName]))
+			}
+		}
 	}
+
+	// the pods are listed by node so the nodes running multiple daemons stand out
+	for _, nodeName := range nodeNames {
+		for _, pod := range podsByNode[nodeName] {
+			fmt.Fprintf(check.options().Output, "%s\t%s\t%s\t%s\n", pod.Name, pod.Status.Phase, pod.Namespace, pod.Spec.NodeName)
+		}
+	}
+}
+
+func checkMonQuorum(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	cephHealth, _ := unMarshalCephStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	evaluateCephHealth(check, cephHealth)
 }
 
-func checkMonQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
-	cephHealthDetails, _ := unMarshalCephStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+func evaluateCephHealth(check *CheckResult, cephHealth healthStatus) {
+	// a health check that is muted is not counted in the health status, a muted HEALTH_WARN is reported as HEALTH_OK
+	for _, warning := range getMuteWarnings(cephHealth) {
+		check.Warning(warning)
+	}
+
+	cephHealthDetails := cephHealth.Status
 	if cephHealthDetails == "HEALTH_OK" {
-		logging.Info(cephHealthDetails)
+		check.Info(cephHealthDetails)
 	} else if cephHealthDetails == "HEALTH_WARN" {
-		logging.Warning(cephHealthDetails)
+		check.Warning(cephHealthDetails)
 	} else if cephHealthDetails == "HEALTH_ERR" {
-		logging.Error(fmt.Errorf(cephHealthDetails))
+		check.Error(fmt.Errorf(cephHealthDetails))
 	}
 }
 
-func CheckAllPodsStatus(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string) {
+func CheckAllPodsStatus(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string) {
 	var podNotRunning, podRunning []v1.Pod
-	podRunning, podNotRunning = getPodRunningStatus(ctx, k8sclientset, operatorNamespace)
+	podRunning, podNotRunning = getPodRunningStatus(ctx, check, k8sclientset, operatorNamespace)
 	if operatorNamespace != clusterNamespace {
-		clusterRunningPod, clusterNotRunningPod := getPodRunningStatus(ctx, k8sclientset, clusterNamespace)
+		clusterRunningPod, clusterNotRunningPod := getPodRunningStatus(ctx, check, k8sclientset, clusterNamespace)
 		podRunning = append(podRunning, clusterRunningPod...)
 		podNotRunning = append(podNotRunning, clusterNotRunningPod...)
 	}
 
 	logging.Info("Pods that are in 'Running' or `Succeeded` status")
 	for i := range podRunning {
-		fmt.Printf("%s \t %s \t %s\t %s\n", podRunning[i].Name, podRunning[i].Status.Phase, podRunning[i].Namespace, podRunning[i].Spec.NodeName)
+		fmt.Fprintf(check.options().Output, "%s \t %s \t %s\t %s\n", podRunning[i].Name, podRunning[i].Status.Phase, podRunning[i].Namespace, podRunning[i].Spec.NodeName)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(check.options().Output)
 	logging.Warning("Pods that are 'Not' in 'Running' status")
 	for i := range podNotRunning {
-		fmt.Printf("%s \t %s \t %s \t %s\n", podNotRunning[i].Name, podNotRunning[i].Status.Phase, podNotRunning[i].Namespace, podNotRunning[i].Spec.NodeName)
+		fmt.Fprintf(check.options().Output, "%s \t %s \t %s \t %s\n", podNotRunning[i].Name, podNotRunning[i].Status.Phase, podNotRunning[i].Namespace, podNotRunning[i].Spec.NodeName)
+		check.add(StatusWarning, fmt.Sprintf("pod %s/%s is in %s status", podNotRunning[i].Namespace, podNotRunning[i].Name, podNotRunning[i].Status.Phase))
 	}
 }
 
-func getPodRunningStatus(ctx context.Context, k8sclientset kubernetes.Interface, namespace string) ([]v1.Pod, []v1.Pod) {
+func getPodRunningStatus(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, namespace string) ([]v1.Pod, []v1.Pod) {
 	var podNotRunning, podRunning []v1.Pod
-	podList, err := k8sclientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, namespace, metav1.ListOptions{})
 	if err != nil {
-		logging.Error(fmt.Errorf("\nfailed to list pods in namespace %s: %v\n", namespace, err))
+		check.Error(fmt.Errorf("\nfailed to list pods in namespace %s: %v\n", namespace, err))
+		return podRunning, podNotRunning
 	}
 
-	for i := range podList.Items {
-		if podList.Items[i].Status.Phase != v1.PodRunning && podList.Items[i].Status.Phase != v1.PodSucceeded {
-			podNotRunning = append(podNotRunning, podList.Items[i])
+	for i := range pods {
+		if pods[i].Status.Phase != v1.PodRunning && pods[i].Status.Phase != v1.PodSucceeded {
+			podNotRunning = append(podNotRunning, pods[i])
 		} else {
-			podRunning = append(podRunning, podList.Items[i])
+			podRunning = append(podRunning, pods[i])
 		}
 	}
 	return podRunning, podNotRunning
 }
 
-func checkPgStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
-	_, pgStateEntryList := unMarshalCephStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
-	for _, pgStatus := range pgStateEntryList {
-		if pgStatus.StateName == "active+clean" {
-			logging.Info("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count)
-		} else if strings.Contains(pgStatus.StateName, "down") || strings.Contains(pgStatus.StateName, "incomplete") || strings.Contains(pgStatus.StateName, "snaptrim_error") {
-			logging.Error(fmt.Errorf("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count))
-		} else {
-			logging.Warning("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count)
+func checkPgStatus(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) *PgStateSummary {
+	_, pgStateEntryList := unMarshalCephStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	summary := evaluatePgStates(check, pgStateEntryList)
+
+	checkPgScrubStatus(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	checkPgsPerOsd(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	return summary
+}
+
+func checkPgScrubStatus(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	pgDumpOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"pg", "dump", "pgs", "--format", "json"})
+	pgStats, err := unMarshalPgStats(pgDumpOut)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to get pg scrub status. %v", err))
+		return
+	}
+
+	scrubInterval := getScrubInterval(ctx, check, clientsets, operatorNamespace, clusterNamespace, "osd_scrub_max_interval", defaultScrubMaxInterval)
+	deepScrubInterval := getScrubInterval(ctx, check, clientsets, operatorNamespace, clusterNamespace, "osd_deep_scrub_interval", defaultDeepScrubInterval)
+
+	notScrubbed, notDeepScrubbed := getLaggingScrubPgs(pgStats, time.Now(), scrubInterval, deepScrubInterval)
+	if len(notScrubbed) == 0 && len(notDeepScrubbed) == 0 {
+		check.Info("\tAll pgs were scrubbed within the expected interval")
+		return
+	}
+	if len(notScrubbed) != 0 {
+		check.Warning("\t%d pgs not scrubbed in %s: %s", len(notScrubbed), scrubInterval, joinPgIds(notScrubbed))
+	}
+	if len(notDeepScrubbed) != 0 {
+		check.Warning("\t%d pgs not deep-scrubbed in %s: %s", len(notDeepScrubbed), deepScrubInterval, joinPgIds(notDeepScrubbed))
+	}
+}
+
+// joinPgIds joins the first few pg ids to keep the output readable on large clusters
+func joinPgIds(pgIds []string) string {
+	maxPgIds := 10
+	if len(pgIds) > maxPgIds {
+		return strings.Join(pgIds[:maxPgIds], ",") + ",..."
+	}
+	return strings.Join(pgIds, ",")
+}
+
+func getScrubInterval(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, option string, defaultInterval time.Duration) time.Duration {
+	out := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"config", "get", "osd", option})
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+	if err != nil || seconds <= 0 {
+		return defaultInterval
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// getLaggingScrubPgs returns the pgs that were not scrubbed and not deep-scrubbed within the given intervals
+func getLaggingScrubPgs(pgStats []pgStat, now time.Time, scrubInterval, deepScrubInterval time.Duration) ([]string, []string) {
+	var notScrubbed, notDeepScrubbed []string
+	for _, pg := range pgStats {
+		if isStampOlderThan(pg.LastScrubStamp, now, scrubInterval) {
+			notScrubbed = append(notScrubbed, pg.PgId)
+		}
+		if isStampOlderThan(pg.LastDeepScrubStamp, now, deepScrubInterval) {
+			notDeepScrubbed = append(notDeepScrubbed, pg.PgId)
 		}
 	}
+	return notScrubbed, notDeepScrubbed
+}
+
+func isStampOlderThan(stamp string, now time.Time, interval time.Duration) bool {
+	t, err := time.Parse(cephTimeLayout, stamp)
+	if err != nil {
+		return false
+	}
+	return now.Sub(t) > interval
+}
+
+func unMarshalPgStats(pgDumpOut string) ([]pgStat, error) {
+	// older ceph versions print the list of pg stats without the wrapping object
+	if strings.HasPrefix(strings.TrimSpace(pgDumpOut), "[") {
+		var pgStats []pgStat
+		err := json.Unmarshal([]byte(pgDumpOut), &pgStats)
+		return pgStats, err
+	}
+
+	var dump pgDump
+	err := json.Unmarshal([]byte(pgDumpOut), &dump)
+	return dump.PgStats, err
+}
+
+func checkMgrPodsStatusAndCounts(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, clusterNamespace string) {
+	opts := metav1.ListOptions{LabelSelector: check.options().Selectors["mgr"]}
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, clusterNamespace, opts)
+	if err != nil {
+		check.Error(fmt.Errorf("\nfailed to list mgr pods with label %s: %v\n", opts.LabelSelector, err))
+		return
+	}
+
+	if len(pods) < 1 {
+		check.Warning("At least one mgr pod should be running")
+	}
+
+	for i := range pods {
+		fmt.Fprintf(check.options().Output, "%s\t%s\t%s\t%s\n", pods[i].Name, pods[i].Status.Phase, pods[i].Namespace, pods[i].Spec.NodeName)
+	}
 }
 
-func checkMgrPodsStatusAndCounts(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) {
-	opts := metav1.ListOptions{LabelSelector: "app=rook-ceph-mgr"}
-	podList, err := k8sclientset.CoreV1().Pods(clusterNamespace).List(ctx, opts)
+func checkCephVersions(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	versions, err := version.GetDaemonVersions(ctx, clientsets, operatorNamespace, clusterNamespace)
 	if err != nil {
-		logging.Error(fmt.Errorf("\nfailed to list mgr pods with label %s: %v\n", opts.LabelSelector, err))
+		check.Error(err)
 		return
 	}
 
-	if len(podList.Items) < 1 {
-		logging.Warning("At least one mgr pod should be running")
+	overall := versions.Overall()
+	if len(overall) <= 1 {
+		check.Info("all daemons are running the same ceph version")
+		return
 	}
 
-	for i := range podList.Items {
-		fmt.Printf("%s\t%s\t%s\t%s\n", podList.Items[i].Name, podList.Items[i].Status.Phase, podList.Items[i].Namespace, podList.Items[i].Spec.NodeName)
+	// a split is expected while rook upgrades the daemons, it is only a warning once the upgrade stopped progressing
+	if version.UpgradeInProgress(ctx, clientsets, clusterNamespace) {
+		check.Info("daemons are running %d ceph versions, an upgrade is in progress", len(overall))
+	} else {
+		check.Warning("daemons are running %d ceph versions while the CephCluster is not progressing, the upgrade may be stalled", len(overall))
+	}
+	for _, v := range overall {
+		check.Info("\t%d daemons: %s", versions.OverallCount(v), v)
 	}
 }
 
-func unMarshalCephStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (string, []PgStateEntry) {
-	cephStatusOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"-s", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+func unMarshalCephStatus(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (healthStatus, []PgStateEntry) {
+	cephStatusOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"status", "--format", "json"})
 
-	ecodedText := base64.StdEncoding.EncodeToString([]byte(cephStatusOut))
-	decodeCephStatus, err := base64.StdEncoding.DecodeString(ecodedText)
+	cephStatus, err := parseCephStatus(cephStatusOut)
 	if err != nil {
-		logging.Fatal(err)
+		check.Warning("failed to parse the ceph status, the check may be incomplete. %v", err)
 	}
-	var cephStatus *cephStatus
+	return cephStatus.Health, cephStatus.PgMap.PgsByState
+}
 
-	err = json.Unmarshal(decodeCephStatus, &cephStatus)
+// parseCephStatus decodes each section of the ceph status independently, so the sections that
+// parse are returned even when another one is malformed in an unexpected ceph version
+func parseCephStatus(cephStatusOut string) (*cephStatus, error) {
+	status := &cephStatus{}
+	var sections map[string]json.RawMessage
+	err := json.Unmarshal([]byte(cephStatusOut), &sections)
 	if err != nil {
-		logging.Fatal(err)
+		return status, err
+	}
+
+	var sectionErrors []string
+	if health, ok := sections["health"]; ok {
+		if err := json.Unmarshal(health, &status.Health); err != nil {
+			sectionErrors = append(sectionErrors, fmt.Sprintf("health: %v", err))
+		}
+	}
+	if pgmap, ok := sections["pgmap"]; ok {
+		if err := json.Unmarshal(pgmap, &status.PgMap); err != nil {
+			sectionErrors = append(sectionErrors, fmt.Sprintf("pgmap: %v", err))
+		}
+	}
+
+	for name, value := range map[string]any{"election_epoch": &status.ElectionEpoch, "quorum_age": &status.QuorumAge, "quorum_names": &status.QuorumNames} {
+		if section, ok := sections[name]; ok {
+			if err := json.Unmarshal(section, value); err != nil {
+				sectionErrors = append(sectionErrors, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+	}
+
+	if len(sectionErrors) != 0 {
+		sort.Strings(sectionErrors)
+		return status, fmt.Errorf("failed to parse sections %s", strings.Join(sectionErrors, "; "))
 	}
-	return cephStatus.Health.Status, cephStatus.PgMap.PgsByState
+	return status, nil
 }
@@ -0,0 +1,792 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetLaggingScrubPgs(t *testing.T) {
+	pgDumpOut := `{"pg_ready":true,"pg_stats":[
+{"pgid":"1.0","last_scrub_stamp":"2023-10-10T10:00:00.000000+0000","last_deep_scrub_stamp":"2023-10-01T10:00:00.000000+0000"},
+{"pgid":"1.1","last_scrub_stamp":"2023-09-01T10:00:00.000000+0000","last_deep_scrub_stamp":"2023-09-01T10:00:00.000000+0000"}]}`
+
+	pgStats, err := unMarshalPgStats(pgDumpOut)
+	assert.NoError(t, err)
+	assert.Len(t, pgStats, 2)
+
+	now := time.Date(2023, 10, 11, 10, 0, 0, 0, time.UTC)
+	notScrubbed, notDeepScrubbed := getLaggingScrubPgs(pgStats, now, defaultScrubMaxInterval, defaultDeepScrubInterval)
+	assert.Equal(t, []string{"1.1"}, notScrubbed)
+	assert.Equal(t, []string{"1.0", "1.1"}, notDeepScrubbed)
+
+	pgStats, err = unMarshalPgStats(`[{"pgid":"2.0","last_scrub_stamp":"2023-10-10T10:00:00.000000+0000","last_deep_scrub_stamp":"2023-10-10T10:00:00.000000+0000"}]`)
+	assert.NoError(t, err)
+	notScrubbed, notDeepScrubbed = getLaggingScrubPgs(pgStats, now, defaultScrubMaxInterval, defaultDeepScrubInterval)
+	assert.Empty(t, notScrubbed)
+	assert.Empty(t, notDeepScrubbed)
+}
+
+func TestSendWebhook(t *testing.T) {
+	var received []HealthReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report HealthReport
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		received = append(received, report)
+	}))
+	defer server.Close()
+
+	report := newHealthReport("rook-ceph", NewHealthOptions())
+	check := report.newCheck("mgr-pods", "Checking if at least one mgr pod is running")
+	check.Warning("At least one mgr pod should be running")
+	report.finalize()
+	assert.Equal(t, StatusWarning, report.Status)
+
+	assert.NoError(t, SendWebhook(report, server.URL, "err"))
+	assert.Empty(t, received)
+
+	assert.NoError(t, SendWebhook(report, server.URL, "warn"))
+	assert.Len(t, received, 1)
+	assert.Equal(t, "rook-ceph", received[0].Cluster)
+	assert.Equal(t, []string{"At least one mgr pod should be running"}, received[0].Checks[0].Messages)
+
+	assert.Error(t, SendWebhook(report, server.URL, "info"))
+	assert.Error(t, ValidateWebhookOn("info"))
+	assert.NoError(t, ValidateWebhookOn("err"))
+}
+
+func TestFindOperatorLogErrors(t *testing.T) {
+	logs := `2023-10-10 10:00:00.000000 I | op-mon: parsing mon endpoints: a=10.0.0.1:6789
+2023-10-10 10:00:01.000000 E | ceph-cluster-controller: failed to reconcile CephCluster "rook-ceph/rook-ceph". failed to create cluster
+2023-10-10 10:00:02.000000 I | ceph-spec: parsing mon endpoints
+panic: runtime error: invalid memory address or nil pointer dereference`
+
+	logErrors := findOperatorLogErrors(logs)
+	assert.Len(t, logErrors, 2)
+	assert.Contains(t, logErrors[0], "failed to reconcile")
+	assert.Contains(t, logErrors[1], "panic:")
+
+	assert.Empty(t, findOperatorLogErrors("2023-10-10 10:00:00.000000 I | op-mon: parsing mon endpoints"))
+}
+
+func TestCheckPodsOnNodesSelector(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	newPod := func(name, nodeName string, labels map[string]string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels}, Spec: v1.PodSpec{NodeName: nodeName}}
+	}
+	k8s := fake.NewSimpleClientset(
+		newPod("mon-a", "node-1", map[string]string{"app": "custom-mon"}),
+		newPod("mon-b", "node-2", map[string]string{"app": "custom-mon"}),
+		newPod("mon-c", "node-3", map[string]string{"app": "custom-mon"}),
+	)
+
+	check := &CheckResult{Name: "mon-pods-on-nodes", Status: StatusOK}
+	checkPodsOnNodes(ctx, check, k8s, ns, "mon")
+	assert.Equal(t, StatusWarning, check.Status)
+
+	opts := NewHealthOptions()
+	opts.Selectors["mon"] = "app=custom-mon"
+	report := newHealthReport(ns, opts)
+
+	check = report.newCheck("mon-pods-on-nodes", "Checking if at least three mon pods are running on different nodes")
+	checkPodsOnNodes(ctx, check, k8s, ns, "mon")
+	assert.Equal(t, StatusOK, check.Status)
+
+	// a pending pod and a terminating pod are not counted on their node
+	terminating := newPod("mon-a-old", "node-1", map[string]string{"app": "custom-mon"})
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+	terminating.Finalizers = []string{"kubernetes"}
+	k8s = fake.NewSimpleClientset(
+		newPod("mon-a", "node-1", map[string]string{"app": "custom-mon"}),
+		terminating,
+		newPod("mon-b", "node-2", map[string]string{"app": "custom-mon"}),
+		newPod("mon-c", "node-3", map[string]string{"app": "custom-mon"}),
+		newPod("mon-d", "", map[string]string{"app": "custom-mon"}),
+	)
+	check = report.newCheck("mon-pods-on-nodes", "Checking if at least three mon pods are running on different nodes")
+	checkPodsOnNodes(ctx, check, k8s, ns, "mon")
+	assert.Equal(t, StatusOK, check.Status)
+
+	k8s = fake.NewSimpleClientset(
+		newPod("mon-a", "node-1", map[string]string{"app": "custom-mon"}),
+		terminating,
+		newPod("mon-b", "", map[string]string{"app": "custom-mon"}),
+		newPod("mon-c", "node-3", map[string]string{"app": "custom-mon"}),
+	)
+	check = report.newCheck("mon-pods-on-nodes", "Checking if at least three mon pods are running on different nodes")
+	checkPodsOnNodes(ctx, check, k8s, ns, "mon")
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Equal(t, []string{"At least three mon pods should running on different nodes"}, check.Messages)
+}
+
+func TestParseCephStatus(t *testing.T) {
+	status, err := parseCephStatus(`{"health":{"status":"HEALTH_OK"},"pgmap":{"pgs_by_state":[{"state_name":"active+clean","count":2}]},"fsid":"1234"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEALTH_OK", status.Health.Status)
+	assert.Equal(t, []PgStateEntry{{StateName: "active+clean", Count: 2}}, status.PgMap.PgsByState)
+
+	// a malformed pgmap still returns the health
+	status, err = parseCephStatus(`{"health":{"status":"HEALTH_WARN"},"pgmap":{"pgs_by_state":"unexpected"}}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pgmap")
+	assert.Equal(t, "HEALTH_WARN", status.Health.Status)
+	assert.Empty(t, status.PgMap.PgsByState)
+
+	status, err = parseCephStatus("not json")
+	assert.Error(t, err)
+	assert.Equal(t, "", status.Health.Status)
+}
+
+func TestFindUnsafeReplicationPools(t *testing.T) {
+	pools := []poolDetail{
+		{PoolName: "replicapool", Size: 3, MinSize: 2},
+		{PoolName: "test-pool", Size: 1, MinSize: 1},
+		{PoolName: "min-size-pool", Size: 2, MinSize: 1},
+		{PoolName: ".mgr", Size: 1, MinSize: 1},
+	}
+	blockPools := []cephv1.CephBlockPool{
+		{ObjectMeta: metav1.ObjectMeta{Name: "builtin-mgr", Annotations: map[string]string{intentionalReplicaSizeAnnotation: "true"}}, Spec: cephv1.NamedBlockPoolSpec{Name: ".mgr"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-pool", Annotations: map[string]string{intentionalReplicaSizeAnnotation: "false"}}},
+	}
+
+	intentional := getIntentionalReplicaSizePools(blockPools)
+	assert.Equal(t, map[string]bool{".mgr": true}, intentional)
+
+	unsafePools := findUnsafeReplicationPools(pools, intentional)
+	assert.Len(t, unsafePools, 2)
+	assert.Equal(t, "test-pool", unsafePools[0].PoolName)
+	assert.Equal(t, "min-size-pool", unsafePools[1].PoolName)
+}
+
+func TestGetPgsPerOsdOutOfBounds(t *testing.T) {
+	osds := []osdDfNode{
+		{Name: "osd.0", Reweight: 1, Pgs: 100},
+		{Name: "osd.1", Reweight: 1, Pgs: 20},
+		{Name: "osd.2", Reweight: 1, Pgs: 240},
+		{Name: "osd.3", Reweight: 0, Pgs: 0},
+	}
+	average, outOfBounds := getPgsPerOsdOutOfBounds(osds, 30, 200)
+	assert.Equal(t, 120, average)
+	assert.Len(t, outOfBounds, 2)
+	assert.Equal(t, "osd.1", outOfBounds[0].Name)
+	assert.Equal(t, "osd.2", outOfBounds[1].Name)
+
+	_, outOfBounds = getPgsPerOsdOutOfBounds(osds, 10, 300)
+	assert.Empty(t, outOfBounds)
+
+	average, _ = getPgsPerOsdOutOfBounds([]osdDfNode{{Name: "osd.0", Reweight: 0}}, 30, 200)
+	assert.Equal(t, 0, average)
+}
+
+func TestCompareCephClusterStatus(t *testing.T) {
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"},
+		Status: cephv1.ClusterStatus{
+			Phase:      cephv1.ConditionReady,
+			CephStatus: &cephv1.CephStatus{Health: "HEALTH_OK", LastChecked: "2023-10-10T10:00:00Z"},
+		},
+	}
+
+	check := &CheckResult{Name: "cephcluster-status", Status: StatusOK}
+	compareCephClusterStatus(check, cephCluster, "HEALTH_OK")
+	assert.Equal(t, StatusOK, check.Status)
+
+	check = &CheckResult{Name: "cephcluster-status", Status: StatusOK}
+	compareCephClusterStatus(check, cephCluster, "HEALTH_ERR")
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Contains(t, check.Messages[1], "while ceph reports HEALTH_ERR")
+
+	cephCluster.Status.Phase = cephv1.ConditionFailure
+	cephCluster.Status.CephStatus = nil
+	check = &CheckResult{Name: "cephcluster-status", Status: StatusOK}
+	compareCephClusterStatus(check, cephCluster, "HEALTH_OK")
+	assert.Equal(t, StatusError, check.Status)
+	assert.Len(t, check.Messages, 2)
+}
+
+func TestDiffReports(t *testing.T) {
+	from := &HealthReport{Status: StatusOK, Checks: []*CheckResult{
+		{Name: "mon-quorum", Status: StatusOK, Messages: []string{"HEALTH_OK"}},
+		{Name: "pg-status", Status: StatusOK, Messages: []string{"PgState: active+clean, PgCount: 2"}},
+		{Name: "mgr-pods", Status: StatusOK},
+		{Name: "operator", Status: StatusOK},
+	}}
+	to := &HealthReport{Status: StatusWarning, Checks: []*CheckResult{
+		{Name: "mon-quorum", Status: StatusWarning, Messages: []string{"HEALTH_WARN"}},
+		{Name: "pg-status", Status: StatusOK, Messages: []string{"PgState: active+clean, PgCount: 2"}},
+		{Name: "mgr-pods", Status: StatusOK},
+		{Name: "cephcluster-status", Status: StatusOK, Messages: []string{"CephCluster my-cluster is Ready"}},
+	}}
+
+	diffs := diffReports(from, to)
+	assert.Equal(t, []CheckDiff{
+		{Name: "mon-quorum", FromStatus: StatusOK, ToStatus: StatusWarning, Added: []string{"HEALTH_WARN"}, Removed: []string{"HEALTH_OK"}},
+		{Name: "cephcluster-status", ToStatus: StatusOK, Added: []string{"CephCluster my-cluster is Ready"}},
+		{Name: "operator", FromStatus: StatusOK},
+	}, diffs)
+
+	assert.Empty(t, diffReports(from, from))
+}
+
+func TestFindDeviceClassMismatches(t *testing.T) {
+	metadata := []osdMetadata{
+		{Id: 0, BluestoreBdevType: "hdd", Rotational: "1", Devices: "sdb"},
+		{Id: 1, BluestoreBdevType: "ssd", Rotational: "0", Devices: "sdc"},
+		{Id: 2, Rotational: "0", Devices: "nvme0n1"},
+		{Id: 3, BluestoreBdevType: "ssd", Devices: "sdd"},
+		{Id: 4, Devices: "sde"},
+	}
+	nodes := []osdTreeNode{
+		{Id: -1, Name: "default", Type: "root"},
+		{Id: 0, Name: "osd.0", Type: "osd", DeviceClass: "hdd"},
+		{Id: 1, Name: "osd.1", Type: "osd", DeviceClass: "hdd"},
+		{Id: 2, Name: "osd.2", Type: "osd", DeviceClass: "nvme"},
+		{Id: 3, Name: "osd.3", Type: "osd", DeviceClass: "fast"},
+		{Id: 4, Name: "osd.4", Type: "osd", DeviceClass: "ssd"},
+	}
+
+	mismatches := findDeviceClassMismatches(metadata, nodes)
+	assert.Equal(t, []string{"osd.1 has device class hdd but its device sdc is ssd"}, mismatches)
+}
+
+func TestFindLargeMonStores(t *testing.T) {
+	size, err := parseDuSize("104857600\t/var/lib/ceph/mon/ceph-a/store.db\n")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(104857600), size)
+	_, err = parseDuSize("")
+	assert.Error(t, err)
+
+	warnSize := uint64(defaultMonDataSizeWarn)
+	assert.Empty(t, findLargeMonStores(map[string]uint64{"a": 100 << 20, "b": 300 << 20, "c": 120 << 20}, warnSize))
+
+	warnings := findLargeMonStores(map[string]uint64{"a": 16 << 30, "b": 13 << 30, "c": 2 << 30}, warnSize)
+	assert.Equal(t, []string{
+		"mon.a store is 16.0 GiB, above mon_data_size_warn of 15.0 GiB",
+		"mon.b store is 13.0 GiB, close to mon_data_size_warn of 15.0 GiB",
+		"mon.a store is 16.0 GiB while the smallest mon store is 2.0 GiB, it may not be compacted. Run `ceph tell mon.a compact` to compact it",
+		"mon.b store is 13.0 GiB while the smallest mon store is 2.0 GiB, it may not be compacted. Run `ceph tell mon.b compact` to compact it",
+	}, warnings)
+
+	assert.Empty(t, findLargeMonStores(map[string]uint64{"a": 4 << 30}, warnSize))
+}
+
+func TestReportSummaryAndErr(t *testing.T) {
+	report := newHealthReport("rook-ceph", NewHealthOptions())
+	report.newCheck("operator", "Checking the operator").Info("operator is ready")
+	report.newCheck("osd-count", "Checking the osd count").Warning("1 osd is missing")
+	check := report.newCheck("mon-quorum", "Checking the mon quorum")
+	check.Error(fmt.Errorf("mon a is out of quorum"))
+	check.Error(fmt.Errorf("mon b is out of quorum"))
+	report.newCheck("pg-status", "Checking the pgs").Error(fmt.Errorf("2 pgs are inactive"))
+	report.finalize()
+
+	assert.Equal(t, "1 check passed, 1 warning, 2 errors. warning: osd-count. errors: mon-quorum, pg-status", report.Summary())
+	err := report.Err()
+	assert.EqualError(t, err, "check mon-quorum failed: mon a is out of quorum\nmon b is out of quorum\ncheck pg-status failed: 2 pgs are inactive")
+
+	// the errors of a report read back from json are the names of the failing checks
+	out, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded HealthReport
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.EqualError(t, decoded.Err(), "check mon-quorum failed\ncheck pg-status failed")
+
+	healthy := newHealthReport("rook-ceph", NewHealthOptions())
+	healthy.newCheck("operator", "Checking the operator").Info("operator is ready")
+	healthy.newCheck("mgr-pods", "Checking the mgr pods").Info("mgr is running")
+	healthy.finalize()
+	assert.Equal(t, "2 checks passed, 0 warnings, 0 errors", healthy.Summary())
+	assert.NoError(t, healthy.Err())
+}
+
+func TestAppendHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	timestamp := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	healthy := &HealthReport{Cluster: "rook-ceph", Timestamp: timestamp, Status: StatusOK, Checks: []*CheckResult{
+		{Name: "mon-quorum", Status: StatusOK},
+		{Name: "pg-status", Status: StatusOK},
+	}}
+	degraded := &HealthReport{Cluster: "rook-ceph", Timestamp: timestamp.Add(time.Hour), Status: StatusError, Checks: []*CheckResult{
+		{Name: "mon-quorum", Status: StatusWarning},
+		{Name: "pg-status", Status: StatusError},
+		{Name: "mgr-pods", Status: StatusOK},
+	}}
+	assert.NoError(t, AppendHistory(healthy, path))
+	assert.NoError(t, AppendHistory(degraded, path))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	assert.Equal(t, []string{
+		`{"timestamp":"2023-10-10T10:00:00Z","cluster":"rook-ceph","status":"ok","counts":{"error":0,"ok":2,"warning":0}}`,
+		`{"timestamp":"2023-10-10T11:00:00Z","cluster":"rook-ceph","status":"error","counts":{"error":1,"ok":1,"warning":1},"failing":{"mon-quorum":"warning","pg-status":"error"}}`,
+	}, lines)
+
+	var entry HistoryEntry
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, newHistoryEntry(degraded), entry)
+
+	assert.Error(t, AppendHistory(healthy, filepath.Join(t.TempDir(), "missing", "history.jsonl")))
+}
+
+func TestGetExpectedOsdCount(t *testing.T) {
+	useAllDevices := true
+	tests := []struct {
+		name     string
+		storage  cephv1.StorageScopeSpec
+		expected int
+		known    bool
+	}{
+		{name: "no osds", storage: cephv1.StorageScopeSpec{}, expected: 0, known: true},
+		{
+			name: "device sets",
+			storage: cephv1.StorageScopeSpec{StorageClassDeviceSets: []cephv1.StorageClassDeviceSet{
+				{Name: "set1", Count: 3}, {Name: "set2", Count: 2},
+			}},
+			expected: 5, known: true,
+		},
+		{
+			name: "nodes with devices",
+			storage: cephv1.StorageScopeSpec{
+				Config: map[string]string{"osdsPerDevice": "2"},
+				Nodes: []cephv1.Node{
+					{Name: "node-1", Selection: cephv1.Selection{Devices: []cephv1.Device{{Name: "sdb"}, {Name: "sdc", Config: map[string]string{"osdsPerDevice": "1"}}}}},
+					{Name: "node-2"},
+				},
+				Selection: cephv1.Selection{Devices: []cephv1.Device{{Name: "nvme0n1"}}},
+			},
+			// 2 osds on sdb, 1 on sdc, and 2 on the nvme0n1 of the cluster selection on node-2
+			expected: 5, known: true,
+		},
+		{
+			name:     "all nodes and devices",
+			storage:  cephv1.StorageScopeSpec{UseAllNodes: true, Selection: cephv1.Selection{UseAllDevices: &useAllDevices}},
+			expected: 0, known: false,
+		},
+		{
+			name: "device filter",
+			storage: cephv1.StorageScopeSpec{Nodes: []cephv1.Node{
+				{Name: "node-1", Selection: cephv1.Selection{DeviceFilter: "^sd."}},
+			}},
+			expected: 0, known: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, known := getExpectedOsdCount(tt.storage)
+			assert.Equal(t, tt.expected, expected)
+			assert.Equal(t, tt.known, known)
+		})
+	}
+}
+
+func TestFindMissingOsds(t *testing.T) {
+	assert.Empty(t, findMissingOsds(3, true, []int{0, 1, 2}, map[int]bool{0: true, 1: true, 2: true}))
+
+	warnings := findMissingOsds(4, true, []int{0, 1, 2}, map[int]bool{0: true, 2: true})
+	assert.Equal(t, []string{
+		"1 of the 4 osds expected by the CephCluster spec are missing from ceph, check the logs of the osd prepare pods with label app=rook-ceph-osd-prepare",
+		"1 osds have no running pod: [osd.1]",
+	}, warnings)
+
+	// the missing osds of an unknown count can't be found
+	assert.Empty(t, findMissingOsds(0, false, []int{0}, map[int]bool{0: true}))
+}
+
+func TestGetMgrFailoverHint(t *testing.T) {
+	stat := &mgr.Stat{Epoch: 12, Available: true, ActiveName: "a", NumStandby: 1}
+	assert.Equal(t, "Run `kubectl rook-ceph mgr failover` to fail over to a standby mgr", getMgrFailoverHint(stat))
+
+	stat.NumStandby = 0
+	assert.Equal(t, "Run `kubectl rook-ceph ceph mgr fail a` to restart it, there is no standby mgr to fail over to", getMgrFailoverHint(stat))
+}
+
+func TestHealthFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ceph-status.json")
+	err := os.WriteFile(path, []byte(`{
+"fsid": "b6c2f4a8-1a2b-4c3d-9e8f-0a1b2c3d4e5f",
+"health": {"status": "HEALTH_WARN", "checks": {}},
+"pgmap": {"pgs_by_state": [{"state_name": "active+clean", "count": 30}, {"state_name": "down", "count": 2}]}}`), 0o600)
+	assert.NoError(t, err)
+
+	report, err := healthFromFile("rook-ceph", path, NewHealthOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph", report.Cluster)
+	assert.Equal(t, StatusError, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, "mon-quorum", report.Checks[0].Name)
+	assert.Equal(t, StatusWarning, report.Checks[0].Status)
+	assert.Equal(t, "pg-status", report.Checks[1].Name)
+	assert.Equal(t, StatusError, report.Checks[1].Status)
+	assert.Equal(t, []string{"PgState: active+clean, PgCount: 30", "PgState: down, PgCount: 2"}, report.Checks[1].Messages)
+	assert.Equal(t, 30, report.PgStates.Healthy)
+	assert.Equal(t, 2, report.PgStates.Critical)
+
+	// a malformed section only makes its check incomplete
+	err = os.WriteFile(path, []byte(`{"health": {"status": "HEALTH_OK"}, "pgmap": {"pgs_by_state": "unexpected"}}`), 0o600)
+	assert.NoError(t, err)
+	report, err = healthFromFile("rook-ceph", path, NewHealthOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, StatusWarning, report.Checks[0].Status)
+	assert.Equal(t, StatusOK, report.Checks[1].Status)
+
+	err = os.WriteFile(path, []byte("HEALTH_OK"), 0o600)
+	assert.NoError(t, err)
+	_, err = healthFromFile("rook-ceph", path, NewHealthOptions())
+	assert.Error(t, err)
+
+	_, err = healthFromFile("rook-ceph", filepath.Join(t.TempDir(), "missing.json"), NewHealthOptions())
+	assert.Error(t, err)
+}
+
+func TestHealthMutes(t *testing.T) {
+	var cephHealth healthStatus
+	err := json.Unmarshal([]byte(`{
+"status": "HEALTH_OK",
+"checks": {"OSD_DOWN": {"severity": "HEALTH_WARN", "summary": {"message": "1 osds down", "count": 1}, "muted": true}},
+"mutes": [
+	{"code": "OSD_DOWN", "sticky": false, "summary": "1 osds down", "count": 1},
+	{"code": "MON_CLOCK_SKEW", "sticky": false, "summary": "", "count": 0},
+	{"code": "BLUESTORE_SLOW_OP_ALERT", "sticky": true, "summary": "", "count": 0}
+]}`), &cephHealth)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"health check BLUESTORE_SLOW_OP_ALERT is muted with --sticky, it won't be reported when it's raised again",
+		"health check MON_CLOCK_SKEW is muted",
+		"health check OSD_DOWN is muted while it's raised: 1 osds down",
+	}, getMuteWarnings(cephHealth))
+
+	// a muted HEALTH_WARN is reported as HEALTH_OK, the mutes make the check a warning
+	report := newHealthReport("rook-ceph", NewHealthOptions())
+	check := report.newCheck("mon-quorum", "Checking mon quorum and ceph health details")
+	evaluateCephHealth(check, cephHealth)
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Equal(t, "HEALTH_OK", check.Messages[len(check.Messages)-1])
+
+	var out strings.Builder
+	printMutes(&out, cephHealth)
+	assert.Contains(t, out.String(), "OSD_DOWN                  true     false    1       1 osds down")
+
+	assert.Equal(t, []string{"health", "mute", "OSD_DOWN", "5400s"}, getMuteArgs("osd_down", 90*time.Minute, false))
+	assert.Equal(t, []string{"health", "mute", "OSD_DOWN", "--sticky"}, getMuteArgs("OSD_DOWN", 0, true))
+}
+
+func TestFindOsdActivationIssues(t *testing.T) {
+	newPod := func(id string, phase v1.PodPhase, initStatuses ...v1.ContainerStatus) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-osd-" + id, Labels: map[string]string{"ceph-osd-id": id}},
+			Status:     v1.PodStatus{Phase: phase, InitContainerStatuses: initStatuses},
+		}
+	}
+	completed := v1.ContainerStatus{Name: "activate", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 0}}}
+	crashing := v1.ContainerStatus{Name: "activate", RestartCount: 3, State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}
+
+	pods := []v1.Pod{
+		newPod("0", v1.PodRunning, completed),
+		newPod("1", v1.PodPending, crashing),
+		newPod("2", v1.PodRunning, completed),
+		newPod("3", v1.PodRunning, completed),
+	}
+	osds := []osdDumpEntry{{Osd: 0, Up: 1, In: 1}, {Osd: 1, Up: 0, In: 1}, {Osd: 2, Up: 0, In: 1}, {Osd: 3, Up: 1, In: 0}}
+	assert.Equal(t, []osdActivationIssue{
+		{Id: 1, Pod: "rook-ceph-osd-1", Problem: "pod rook-ceph-osd-1 init container activate is waiting: CrashLoopBackOff", ScanLogs: true},
+		{Id: 2, Pod: "rook-ceph-osd-2", Problem: "pod rook-ceph-osd-2 is running but the osd is down in ceph", ScanLogs: true},
+		{Id: 3, Pod: "rook-ceph-osd-3", Problem: "pod rook-ceph-osd-3 is running and the osd is up, but it's out of ceph"},
+	}, findOsdActivationIssues(pods, osds))
+
+	assert.Empty(t, findOsdActivationIssues(pods[:1], osds))
+}
+
+func TestFindOsdActivateLogErrors(t *testing.T) {
+	logs := `+ ceph-volume raw activate --device /dev/sdb --no-systemd --no-tmpfs
+--> RuntimeError: could not find osd.1 with osd_fsid 5d2a6b7c
++ exit 1`
+	assert.Equal(t, []string{"--> RuntimeError: could not find osd.1 with osd_fsid 5d2a6b7c"}, findOsdActivateLogErrors(logs))
+	assert.Empty(t, findOsdActivateLogErrors("+ ceph-volume raw activate --device /dev/sdb"))
+}
+
+func TestMonQuorumStability(t *testing.T) {
+	status, err := parseCephStatus(`{"health":{"status":"HEALTH_OK"},"election_epoch":48,"quorum_age":120,"quorum_names":["a","b","c"]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 48, status.ElectionEpoch)
+	assert.Equal(t, []string{"a", "b", "c"}, status.QuorumNames)
+	assert.Equal(t, "the mon quorum formed 2m0s ago, a mon restarted or an election was called recently", getQuorumAgeWarning(status))
+	status.QuorumAge = 3600
+	assert.Empty(t, getQuorumAgeWarning(status))
+
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	entries := []clusterLogEntry{
+		{Name: "mon.a", Stamp: "2023-10-10T08:00:00.000000+0000", Message: "mon.a is new leader, mons a,b,c in quorum (ranks 0,1,2)"},
+		{Name: "mon.b", Stamp: "2023-10-10T09:30:00.000000+0000", Message: "mon.b calling monitor election"},
+		{Name: "mon.b", Stamp: "2023-10-10T09:30:05.000000+0000", Message: "mon.b is new leader, mons b,c in quorum (ranks 1,2)"},
+		{Name: "mon.a", Stamp: "2023-10-10T09:45:00.000000+0000", Message: "overall HEALTH_OK"},
+	}
+	assert.Equal(t, []string{"2023-10-10T09:30:05Z mon.b is new leader, mons b,c in quorum (ranks 1,2)"}, findMonElections(entries, now, time.Hour))
+}
+
+func TestFindErasureCodedFailureDomainIssues(t *testing.T) {
+	// two hosts with osds in zone-a, one in zone-b and an empty host in zone-c
+	nodes := []crushTreeNode{
+		{Id: -1, Name: "default", Type: "root", Children: []int{-2, -3, -4}},
+		{Id: -2, Name: "zone-a", Type: "zone", Children: []int{-5, -6}},
+		{Id: -3, Name: "zone-b", Type: "zone", Children: []int{-7}},
+		{Id: -4, Name: "zone-c", Type: "zone", Children: []int{-8}},
+		{Id: -5, Name: "host-1", Type: "host", Children: []int{0, 1}},
+		{Id: -6, Name: "host-2", Type: "host", Children: []int{2}},
+		{Id: -7, Name: "host-3", Type: "host", Children: []int{3}},
+		{Id: -8, Name: "host-4", Type: "host"},
+		{Id: 0, Name: "osd.0", Type: "osd"},
+		{Id: 1, Name: "osd.1", Type: "osd"},
+		{Id: 2, Name: "osd.2", Type: "osd"},
+		{Id: 3, Name: "osd.3", Type: "osd"},
+	}
+	assert.Equal(t, 3, countFailureDomains(nodes, "default", "host"))
+	assert.Equal(t, 2, countFailureDomains(nodes, "default", "zone"))
+	assert.Equal(t, 4, countFailureDomains(nodes, "default", "osd"))
+	assert.Equal(t, 0, countFailureDomains(nodes, "missing", "host"))
+
+	newRule := func(id int, name, failureDomain string) crushRule {
+		return crushRule{RuleId: id, RuleName: name, Steps: []crushRuleStep{
+			{Op: "take", ItemName: "default"}, {Op: "chooseleaf_indep", Type: failureDomain}, {Op: "emit"},
+		}}
+	}
+	rules := []crushRule{newRule(1, "ec-host", "host"), newRule(2, "ec-zone", "zone"), newRule(3, "ec-osd", "osd")}
+	profiles := map[string]erasureCodeProfile{
+		"k2m1-host": {K: "2", M: "1", CrushFailureDomain: "host"},
+		"k2m2-host": {K: "2", M: "2", CrushFailureDomain: "host"},
+		"k2m1-zone": {K: "2", M: "1", CrushFailureDomain: "zone"},
+	}
+
+	// the replicated pools are skipped, the chunks fill all the hosts
+	pools := []poolDetail{
+		{PoolName: "replicapool", Type: 1, CrushRule: 0},
+		{PoolName: "ec-3", Type: erasurePoolType, CrushRule: 1, ErasureCodeProfile: "k2m1-host"},
+	}
+	warnings, infos := findErasureCodedFailureDomainIssues(pools, profiles, rules, nodes)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []string{"pool ec-3 has its k=2 m=1 chunks on all the 3 hosts under default, its pgs can't recover until a lost host is back"}, infos)
+
+	// too few failure domains, a rule not matching the profile and a missing rule
+	pools = []poolDetail{
+		{PoolName: "ec-4", Type: erasurePoolType, CrushRule: 1, ErasureCodeProfile: "k2m2-host"},
+		{PoolName: "ec-zone", Type: erasurePoolType, CrushRule: 2, ErasureCodeProfile: "k2m1-zone"},
+		{PoolName: "ec-osd", Type: erasurePoolType, CrushRule: 3, ErasureCodeProfile: "k2m1-host"},
+		{PoolName: "ec-missing", Type: erasurePoolType, CrushRule: 4, ErasureCodeProfile: "k2m1-host"},
+	}
+	warnings, infos = findErasureCodedFailureDomainIssues(pools, profiles, rules, nodes)
+	assert.Empty(t, infos)
+	assert.Equal(t, []string{
+		"pool ec-4 needs 4 host failure domains for its k=2 m=2 chunks but crush rule ec-host has 3 hosts under default, its pgs can't place all their chunks so the pool doesn't tolerate the m=2 failures of its profile",
+		"pool ec-zone needs 3 zone failure domains for its k=2 m=1 chunks but crush rule ec-zone has 2 zones under default, its pgs can't place all their chunks so the pool doesn't tolerate the m=1 failures of its profile",
+		"pool ec-osd places its chunks on osd failure domains with crush rule ec-osd while its erasure code profile k2m1-host expects host failure domains",
+		"pool ec-missing uses crush rule 4 which doesn't exist",
+	}, warnings)
+}
+
+func TestFindDaemonResourceIssues(t *testing.T) {
+	newPod := func(name string, resources v1.ResourceRequirements) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "log-collector"}, {Name: "osd", Resources: resources}}},
+		}
+	}
+	configured := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("4Gi")},
+		Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+	}
+	assert.Empty(t, findDaemonResourceIssues("osd", []v1.Pod{newPod("rook-ceph-osd-0", configured)}))
+
+	lowLimit := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	oomKilled := newPod("rook-ceph-osd-3", lowLimit)
+	oomKilled.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:                 "osd",
+		RestartCount:         4,
+		LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled"}},
+	}}
+	pods := []v1.Pod{oomKilled, newPod("rook-ceph-osd-2", v1.ResourceRequirements{}), newPod("rook-ceph-osd-1", v1.ResourceRequirements{}), newPod("rook-ceph-osd-0", configured)}
+	assert.Equal(t, []string{
+		"osd pods have no memory request, they are the first evicted when the node runs out of memory: rook-ceph-osd-1, rook-ceph-osd-2",
+		"osd pods have no cpu request, their cpu time is not guaranteed when the node is busy: rook-ceph-osd-1, rook-ceph-osd-2",
+		"osd pods have no memory limit, a memory leak can take the memory of the node: rook-ceph-osd-1, rook-ceph-osd-2",
+		"osd pods have a memory limit of 1.0 GiB, below the 2.0 GiB recommended by rook, the daemons are likely OOM killed: rook-ceph-osd-3",
+		"osd pods were OOM killed, raise their memory limit: rook-ceph-osd-3 (4 restarts)",
+	}, findDaemonResourceIssues("osd", pods))
+}
+
+func TestEvaluateUpgradeHealth(t *testing.T) {
+	check := &CheckResult{Name: "ceph-health", Status: StatusOK}
+	evaluateUpgradeHealth(check, healthStatus{Status: "HEALTH_OK"}, AcceptedUpgradeWarnings)
+	assert.Equal(t, StatusOK, check.Status)
+
+	cephHealth := healthStatus{Status: "HEALTH_WARN", Checks: map[string]json.RawMessage{
+		"RECENT_CRASH": json.RawMessage(`{"severity":"HEALTH_WARN","summary":{"message":"1 daemons have recently crashed"}}`),
+	}}
+	check = &CheckResult{Name: "ceph-health", Status: StatusOK}
+	evaluateUpgradeHealth(check, cephHealth, AcceptedUpgradeWarnings)
+	assert.Equal(t, StatusWarning, check.Status)
+
+	cephHealth.Checks["OSD_DOWN"] = json.RawMessage(`{"severity":"HEALTH_WARN","summary":{"message":"1 osds down"}}`)
+	check = &CheckResult{Name: "ceph-health", Status: StatusOK}
+	evaluateUpgradeHealth(check, cephHealth, AcceptedUpgradeWarnings)
+	assert.Equal(t, StatusError, check.Status)
+	assert.Contains(t, check.Messages, "OSD_DOWN: 1 osds down")
+
+	// a warning is accepted with --accept-warning
+	check = &CheckResult{Name: "ceph-health", Status: StatusOK}
+	evaluateUpgradeHealth(check, cephHealth, append([]string{"OSD_DOWN"}, AcceptedUpgradeWarnings...))
+	assert.Equal(t, StatusWarning, check.Status)
+}
+
+func TestClassifyPgState(t *testing.T) {
+	// pg states seen in the ceph status of clusters while they scrub, recover and lose osds
+	fixture := map[string]string{
+		"active+clean":                                         PgCategoryHealthy,
+		"active+clean+scrubbing":                               PgCategoryHealthy,
+		"active+clean+scrubbing+deep":                          PgCategoryHealthy,
+		"active+clean+snaptrim_wait":                           PgCategoryHealthy,
+		"active+clean+remapped":                                PgCategoryWarning,
+		"active+undersized+degraded":                           PgCategoryWarning,
+		"active+recovery_wait+degraded":                        PgCategoryWarning,
+		"active+remapped+backfill_wait":                        PgCategoryWarning,
+		"active+undersized+degraded+remapped+backfilling":      PgCategoryWarning,
+		"active+recovering+undersized+remapped":                PgCategoryWarning,
+		"active+remapped+backfill_toofull":                     PgCategoryWarning,
+		"active+clean+scrubbing+deep+repair":                   PgCategoryWarning,
+		"active+clean+laggy":                                   PgCategoryWarning,
+		"peering":                                              PgCategoryWarning,
+		"creating+peering":                                     PgCategoryWarning,
+		"activating+undersized+degraded":                       PgCategoryWarning,
+		"active+clean+some_future_state":                       PgCategoryWarning,
+		"active+clean+inconsistent":                            PgCategoryCritical,
+		"active+clean+scrubbing+deep+inconsistent+repair":      PgCategoryCritical,
+		"active+recovery_unfound+undersized+degraded+remapped": PgCategoryCritical,
+		"active+clean+snaptrim_error":                          PgCategoryCritical,
+		"undersized+degraded+peered":                           PgCategoryCritical,
+		"stale+active+clean":                                   PgCategoryCritical,
+		"down":                                                 PgCategoryCritical,
+		"incomplete":                                           PgCategoryCritical,
+		"unknown":                                              PgCategoryCritical,
+		"remapped+peering+down":                                PgCategoryCritical,
+	}
+	for state, category := range fixture {
+		assert.Equal(t, category, classifyPgState(state), state)
+	}
+}
+
+func TestEvaluatePgStates(t *testing.T) {
+	check := &CheckResult{Name: "pg-status", Status: StatusOK}
+	summary := evaluatePgStates(check, []PgStateEntry{
+		{StateName: "active+clean", Count: 120},
+		{StateName: "active+clean+scrubbing+deep", Count: 2},
+		{StateName: "active+undersized+degraded", Count: 3},
+		{StateName: "undersized+degraded+peered", Count: 1},
+	})
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, &PgStateSummary{Healthy: 122, Warning: 3, Critical: 1, States: []PgStateRow{
+		{State: "active+clean", Count: 120, Category: PgCategoryHealthy},
+		{State: "active+clean+scrubbing+deep", Count: 2, Category: PgCategoryHealthy},
+		{State: "active+undersized+degraded", Count: 3, Category: PgCategoryWarning},
+		{State: "undersized+degraded+peered", Count: 1, Category: PgCategoryCritical},
+	}}, summary)
+	assert.Equal(t, []string{"PgState: active+clean, PgCount: 120", "PgState: active+clean+scrubbing+deep, PgCount: 2",
+		"PgState: active+undersized+degraded, PgCount: 3", "PgState: undersized+degraded+peered, PgCount: 1"}, check.Messages)
+}
+
+func TestEvaluateUpgradePgStates(t *testing.T) {
+	check := &CheckResult{Name: "recovery", Status: StatusOK}
+	evaluateUpgradePgStates(check, []PgStateEntry{{StateName: "active+clean", Count: 120}, {StateName: "active+clean+scrubbing+deep", Count: 2}})
+	assert.Equal(t, StatusOK, check.Status)
+
+	check = &CheckResult{Name: "recovery", Status: StatusOK}
+	evaluateUpgradePgStates(check, []PgStateEntry{{StateName: "active+clean", Count: 120}, {StateName: "active+undersized+degraded", Count: 3}, {StateName: "active+clean+remapped", Count: 2}})
+	assert.Equal(t, StatusError, check.Status)
+	assert.Contains(t, check.Messages, "5 pgs are not active+clean, wait for the recovery to finish")
+}
+
+func TestFindFilesystemsWithoutStandby(t *testing.T) {
+	var dump fsDump
+	err := json.Unmarshal([]byte(`{"standbys":[],"filesystems":[
+		{"mdsmap":{"fs_name":"myfs","info":{"gid_1":{"name":"myfs-a","state":"up:active"},"gid_2":{"name":"myfs-b","state":"up:standby-replay"}}}},
+		{"mdsmap":{"fs_name":"otherfs","info":{"gid_3":{"name":"otherfs-a","state":"up:active"}}}}]}`), &dump)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"otherfs"}, findFilesystemsWithoutStandby(dump))
+
+	dump.Standbys = []fsDumpMds{{Name: "otherfs-b", State: "up:standby"}}
+	assert.Empty(t, findFilesystemsWithoutStandby(dump))
+}
+
+func TestParseBatchOutput(t *testing.T) {
+	commands := [][]string{{"status", "--format", "json"}, {"osd", "ls", "--format", "json"}, {"config", "get", "mon", "mon_data_size_warn"}}
+	script := buildBatchScript(commands[:1], "rook-ceph")
+	assert.Equal(t, `printf '%s start 0\n' '----kubectl-rook-ceph-batch----'; ceph 'status' '--format' 'json' --connect-timeout=10 '--conf=/var/lib/rook/rook-ceph/rook-ceph.config'; printf '\n%s end 0 %d\n' '----kubectl-rook-ceph-batch----' $?;
+`, script)
+
+	stdout := `----kubectl-rook-ceph-batch---- start 0
+{"health":{"status":"HEALTH_OK"}}
+----kubectl-rook-ceph-batch---- end 0 0
+----kubectl-rook-ceph-batch---- start 1
+
+----kubectl-rook-ceph-batch---- end 1 110
+----kubectl-rook-ceph-batch---- start 2
+15032385536
+
+----kubectl-rook-ceph-batch---- end 2 0
+`
+	outputs, err := parseBatchOutput(stdout, commands)
+	assert.NoError(t, err)
+	// the failed command is not batched, it runs again in its own exec to report its error
+	assert.Equal(t, map[string]string{
+		"status --format json":              `{"health":{"status":"HEALTH_OK"}}`,
+		"config get mon mon_data_size_warn": "15032385536\n",
+	}, outputs)
+
+	report := newHealthReport("rook-ceph", NewHealthOptions())
+	report.batchOutputs = outputs
+	check := report.newCheck("mon-quorum", "Checking mon quorum and ceph health details")
+	assert.Equal(t, `{"health":{"status":"HEALTH_OK"}}`, runCephCommand(context.TODO(), check, nil, "rook-ceph", "rook-ceph", commands[0]))
+
+	// an exec interrupted in the middle of the batch falls back to an exec per command
+	_, err = parseBatchOutput(stdout[:strings.Index(stdout, "end 2")], commands)
+	assert.EqualError(t, err, "the output of 'ceph config get mon mon_data_size_warn' is truncated")
+	_, err = parseBatchOutput("", commands)
+	assert.EqualError(t, err, "the output of 'ceph status --format json' is missing")
+}
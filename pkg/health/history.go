@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// HistoryEntry is the summary of a health report appended to the history file, one json object per line
+type HistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Cluster   string    `json:"cluster"`
+	Status    string    `json:"status"`
+	// Counts is the number of checks by status
+	Counts map[string]int `json:"counts"`
+	// Failing is the status of the checks that are not ok, by check name
+	Failing map[string]string `json:"failing,omitempty"`
+}
+
+// AppendHistory appends the summary of the report to the history file, the file is created if it doesn't exist
+func AppendHistory(report *HealthReport, path string) error {
+	line, err := json.Marshal(newHistoryEntry(report))
+	if err != nil {
+		return fmt.Errorf("failed to marshal the health history entry. %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the health history file %s. %v", path, err)
+	}
+	// the line is written at once so runs appending at the same time don't interleave their entries
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to append to the health history file %s. %v", path, err)
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close the health history file %s. %v", path, err)
+	}
+	logging.Info("health status %q appended to %s", report.Status, path)
+	return nil
+}
+
+func newHistoryEntry(report *HealthReport) HistoryEntry {
+	entry := HistoryEntry{
+		Timestamp: report.Timestamp,
+		Cluster:   report.Cluster,
+		Status:    report.Status,
+		Counts:    map[string]int{StatusOK: 0, StatusWarning: 0, StatusError: 0},
+	}
+	for _, check := range report.Checks {
+		entry.Counts[check.Status]++
+		if check.Status != StatusOK {
+			if entry.Failing == nil {
+				entry.Failing = map[string]string{}
+			}
+			entry.Failing[check.Name] = check.Status
+		}
+	}
+	return entry
+}
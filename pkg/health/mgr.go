@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+)
+
+const (
+	// mgrResponseTimeout is the time the active mgr has to answer a command it serves before it's reported as hung
+	mgrResponseTimeout = 30 * time.Second
+	// the active mgr is reported as slow when it takes longer to answer
+	mgrSlowResponse = 10 * time.Second
+)
+
+// checkMgrResponsive checks the active mgr answers the commands it serves. A hung mgr keeps its pod running and may
+// keep sending its beacons to the mons, so it looks available while the pg stats, the modules and the orchestration
+// are stuck.
+func checkMgrResponsive(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	stat, err := mgr.GetStat(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		check.Error(err)
+		return
+	}
+	if !stat.Available || stat.ActiveName == "" {
+		check.Error(fmt.Errorf("no mgr is active, check the logs of the mgr pods"))
+		return
+	}
+
+	// `ceph pg stat` is answered by the active mgr
+	pgStatCtx, cancel := context.WithTimeout(ctx, mgrResponseTimeout)
+	defer cancel()
+	result, err := exec.RunCommandInOperatorPodWithResult(pgStatCtx, clientsets, "ceph", []string{"pg", "stat", "--format", "json"}, operatorNamespace, clusterNamespace)
+	switch {
+	case err != nil && pgStatCtx.Err() != nil:
+		check.Error(fmt.Errorf("the active mgr %s did not answer `ceph pg stat` within %s, it may be hung. %s", stat.ActiveName, mgrResponseTimeout, getMgrFailoverHint(stat)))
+	case err != nil:
+		check.Error(fmt.Errorf("failed to run `ceph pg stat`. %v", err))
+	case result.ExitCode != 0:
+		check.Error(fmt.Errorf("the active mgr %s failed to answer `ceph pg stat`: %s. %s", stat.ActiveName, strings.TrimSpace(result.Stderr), getMgrFailoverHint(stat)))
+	case result.Duration > mgrSlowResponse:
+		check.Warning("the active mgr %s took %s to answer `ceph pg stat`, it may be overloaded", stat.ActiveName, result.Duration.Round(time.Second))
+	default:
+		check.Info("the active mgr %s answered in %s, %d standby mgrs", stat.ActiveName, result.Duration.Round(time.Millisecond), stat.NumStandby)
+	}
+}
+
+// getMgrFailoverHint recommends failing the active mgr over to a standby, without a standby the failed mgr restarts
+func getMgrFailoverHint(stat *mgr.Stat) string {
+	if stat.NumStandby == 0 {
+		return fmt.Sprintf("Run `kubectl rook-ceph ceph mgr fail %s` to restart it, there is no standby mgr to fail over to", stat.ActiveName)
+	}
+	return "Run `kubectl rook-ceph mgr failover` to fail over to a standby mgr"
+}
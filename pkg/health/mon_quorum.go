@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+const (
+	// a quorum formed more recently than this may still be settling after a mon restarted or lost its peers
+	minQuorumAge = 5 * time.Minute
+	// the elections of the cluster log in this window are counted to find a flapping quorum
+	electionWindow = time.Hour
+	// maxElections is the number of elections in the window above which the quorum is flapping
+	maxElections = 3
+	// clusterLogEntries is the number of most recent entries of the cluster log the elections are searched in
+	clusterLogEntries = 1000
+)
+
+type clusterLogEntry struct {
+	Name    string `json:"name"`
+	Stamp   string `json:"stamp"`
+	Message string `json:"message"`
+}
+
+func checkMonQuorumStability(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	cephStatusOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"status", "--format", "json"})
+	status, err := parseCephStatus(cephStatusOut)
+	if err != nil {
+		check.Warning("failed to parse the ceph status, the check may be incomplete. %v", err)
+	}
+	quorumAge := time.Duration(status.QuorumAge) * time.Second
+	check.Info("\tmons %s are in quorum since %s, election epoch %d", strings.Join(status.QuorumNames, ","), quorumAge, status.ElectionEpoch)
+	if warning := getQuorumAgeWarning(status); warning != "" {
+		check.Warning("\t%s", warning)
+	}
+
+	logOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"log", "last", fmt.Sprint(clusterLogEntries), "info", "cluster", "--format", "json"})
+	var entries []clusterLogEntry
+	err = json.Unmarshal([]byte(logOut), &entries)
+	if err != nil {
+		check.Warning("\tfailed to parse the cluster log, the recent mon elections are not counted. %v", err)
+		return
+	}
+	elections := findMonElections(entries, time.Now(), electionWindow)
+	if len(elections) > maxElections {
+		check.Warning("\tthe mon quorum is flapping, %d mon elections in the last %s. Check the network and the disk latency of the mons, and the logs of the mons calling the elections", len(elections), electionWindow)
+		for _, election := range elections {
+			check.Warning("\t\t%s", election)
+		}
+	} else {
+		check.Info("\t%d mon elections in the last %s", len(elections), electionWindow)
+	}
+}
+
+// getQuorumAgeWarning warns about a quorum that formed recently, the health may briefly be HEALTH_OK between
+// the elections of a flapping quorum
+func getQuorumAgeWarning(status *cephStatus) string {
+	quorumAge := time.Duration(status.QuorumAge) * time.Second
+	if len(status.QuorumNames) == 0 || quorumAge >= minQuorumAge {
+		return ""
+	}
+	return fmt.Sprintf("the mon quorum formed %s ago, a mon restarted or an election was called recently", quorumAge)
+}
+
+// findMonElections returns the elections won by a leader in the window of the cluster log. Each election logs
+// "mon.a is new leader, mons a,b,c in quorum" once, while all the mons log "calling monitor election".
+func findMonElections(entries []clusterLogEntry, now time.Time, window time.Duration) []string {
+	var elections []string
+	for _, entry := range entries {
+		if !strings.Contains(entry.Message, "is new leader") {
+			continue
+		}
+		stamp, err := time.Parse(cephTimeLayout, entry.Stamp)
+		if err != nil || now.Sub(stamp) > window {
+			continue
+		}
+		elections = append(elections, fmt.Sprintf("%s %s", stamp.UTC().Format(time.RFC3339), entry.Message))
+	}
+	return elections
+}
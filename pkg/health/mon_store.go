@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// default value of mon_data_size_warn, above which ceph raises MON_DISK_BIG
+	defaultMonDataSizeWarn = 15 << 30
+
+	// the store of a mon is reported before ceph raises MON_DISK_BIG, once it reaches this ratio of mon_data_size_warn
+	monStoreNearWarnRatio = 0.8
+
+	// a mon store larger than the smallest one by this ratio is not trimmed or compacted like the others
+	monStoreGrowthRatio = 2
+	// stores smaller than this size are not compared since their size varies a lot with the cluster activity
+	minComparedMonStoreSize = 1 << 30
+)
+
+func checkMonStoreSize(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	// only the stores of the running mons can be measured
+	opts := metav1.ListOptions{LabelSelector: check.options().Selectors["mon"], FieldSelector: "status.phase=Running"}
+	pods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, opts)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to list mon pods with label %s: %v", opts.LabelSelector, err))
+		return
+	}
+
+	storeSizes := map[string]uint64{}
+	for _, pod := range pods {
+		monId := pod.Labels["ceph_daemon_id"]
+		if pod.Status.Phase != v1.PodRunning || monId == "" {
+			continue
+		}
+		if _, ok := storeSizes[monId]; ok {
+			// the debug pod of a mon has the same labels
+			continue
+		}
+		labelSelector := fmt.Sprintf("ceph_daemon_type=mon,ceph_daemon_id=%s", monId)
+		duOut := exec.RunCommandInLabeledPod(ctx, clientsets, labelSelector, "mon", "du", []string{"-sb", fmt.Sprintf("/var/lib/ceph/mon/ceph-%s/store.db", monId)}, clusterNamespace, true, false)
+		size, err := parseDuSize(duOut)
+		if err != nil {
+			check.Warning("\tfailed to get the store size of mon.%s. %v", monId, err)
+			continue
+		}
+		storeSizes[monId] = size
+	}
+	if len(storeSizes) == 0 {
+		check.Warning("no running mon found to get the store size")
+		return
+	}
+
+	warnSize := getMonDataSizeWarn(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+	for _, monId := range sortedMonIds(storeSizes) {
+		check.Info("\tmon.%s store is %s", monId, output.Bytes(storeSizes[monId]))
+	}
+	warnings := findLargeMonStores(storeSizes, warnSize)
+	for _, warning := range warnings {
+		check.Warning("\t%s", warning)
+	}
+	if len(warnings) == 0 {
+		check.Info("\tthe mon stores are below %s, %d%% of mon_data_size_warn", output.Bytes(uint64(float64(warnSize)*monStoreNearWarnRatio)), int(monStoreNearWarnRatio*100))
+	}
+}
+
+// parseDuSize parses the size in bytes of the `du -sb` output
+func parseDuSize(duOut string) (uint64, error) {
+	fields := strings.Fields(duOut)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty du output")
+	}
+	return strconv.ParseUint(fields[0], 10, 64)
+}
+
+func getMonDataSizeWarn(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) uint64 {
+	out := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"config", "get", "mon", "mon_data_size_warn"})
+	size, err := strconv.ParseUint(strings.TrimSpace(out), 10, 64)
+	if err != nil || size == 0 {
+		return defaultMonDataSizeWarn
+	}
+	return size
+}
+
+// findLargeMonStores returns the mon stores close to or above the warn size, and the stores growing unusually
+// compared to the other mons. A store that keeps growing during a long recovery fills the disk of the mon
+// and takes it out of quorum.
+func findLargeMonStores(storeSizes map[string]uint64, warnSize uint64) []string {
+	var warnings []string
+	monIds := sortedMonIds(storeSizes)
+	for _, monId := range monIds {
+		size := storeSizes[monId]
+		if size >= warnSize {
+			warnings = append(warnings, fmt.Sprintf("mon.%s store is %s, above mon_data_size_warn of %s", monId, output.Bytes(size), output.Bytes(warnSize)))
+		} else if float64(size) >= float64(warnSize)*monStoreNearWarnRatio {
+			warnings = append(warnings, fmt.Sprintf("mon.%s store is %s, close to mon_data_size_warn of %s", monId, output.Bytes(size), output.Bytes(warnSize)))
+		}
+	}
+
+	if len(monIds) < 2 {
+		return warnings
+	}
+	smallest := storeSizes[monIds[0]]
+	for _, monId := range monIds {
+		if storeSizes[monId] < smallest {
+			smallest = storeSizes[monId]
+		}
+	}
+	for _, monId := range monIds {
+		size := storeSizes[monId]
+		if size >= minComparedMonStoreSize && size >= smallest*monStoreGrowthRatio {
+			warnings = append(warnings, fmt.Sprintf("mon.%s store is %s while the smallest mon store is %s, it may not be compacted. Run `ceph tell mon.%s compact` to compact it",
+				monId, output.Bytes(size), output.Bytes(smallest), monId))
+		}
+	}
+	return warnings
+}
+
+func sortedMonIds(storeSizes map[string]uint64) []string {
+	var monIds []string
+	for monId := range storeSizes {
+		monIds = append(monIds, monId)
+	}
+	sort.Strings(monIds)
+	return monIds
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// healthMute is a health check muted with `ceph health mute`. A mute is removed once its check clears, unless it's
+// sticky, or once its ttl expires.
+type healthMute struct {
+	Code    string `json:"code"`
+	Sticky  bool   `json:"sticky"`
+	Summary string `json:"summary"`
+	Count   int    `json:"count"`
+}
+
+// Mutes prints the muted health checks, and whether each check is currently raised
+func Mutes(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	err := mutes(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func mutes(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) error {
+	healthOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"health", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var cephHealth healthStatus
+	err := json.Unmarshal([]byte(healthOut), &cephHealth)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph health output. %v", err)
+	}
+	if len(cephHealth.Mutes) == 0 {
+		logging.Info("no health check is muted, the health status is %s", cephHealth.Status)
+		return nil
+	}
+
+	printMutes(os.Stdout, cephHealth)
+	fmt.Println()
+	for _, warning := range getMuteWarnings(cephHealth) {
+		logging.Warning(warning)
+	}
+	logging.Info("unmute a health check with `kubectl rook-ceph health unmute <code>`")
+	return nil
+}
+
+// Mute mutes the health check for the ttl, or until it's unmuted when the ttl is 0
+func Mute(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, code string, ttl time.Duration, sticky bool) {
+	args := getMuteArgs(code, ttl, sticky)
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace, false, true)
+	if ttl == 0 {
+		logging.Warning("health check %s muted until it's unmuted, the health status no longer reports it", code)
+		return
+	}
+	logging.Info("health check %s muted for %s", code, ttl)
+}
+
+// Unmute removes the mute of the health check
+func Unmute(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, code string) {
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"health", "unmute", strings.ToUpper(code)}, operatorNamespace, clusterNamespace, false, true)
+	logging.Info("health check %s unmuted", code)
+}
+
+func getMuteArgs(code string, ttl time.Duration, sticky bool) []string {
+	args := []string{"health", "mute", strings.ToUpper(code)}
+	if ttl > 0 {
+		// ceph parses the ttl as a timespan such as 90s, not as a go duration
+		args = append(args, fmt.Sprintf("%ds", int64(ttl.Seconds())))
+	}
+	if sticky {
+		args = append(args, "--sticky")
+	}
+	return args
+}
+
+// getMuteWarnings returns a warning for each muted health check, since the health status doesn't count the
+// muted checks and a muted HEALTH_WARN is reported as HEALTH_OK
+func getMuteWarnings(cephHealth healthStatus) []string {
+	var warnings []string
+	for _, mute := range sortedMutes(cephHealth.Mutes) {
+		if _, raised := cephHealth.Checks[mute.Code]; raised {
+			warnings = append(warnings, fmt.Sprintf("health check %s is muted while it's raised: %s", mute.Code, mute.Summary))
+		} else if mute.Sticky {
+			warnings = append(warnings, fmt.Sprintf("health check %s is muted with --sticky, it won't be reported when it's raised again", mute.Code))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("health check %s is muted", mute.Code))
+		}
+	}
+	return warnings
+}
+
+func printMutes(out io.Writer, cephHealth healthStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CODE\tRAISED\tSTICKY\tCOUNT\tSUMMARY")
+	for _, mute := range sortedMutes(cephHealth.Mutes) {
+		_, raised := cephHealth.Checks[mute.Code]
+		fmt.Fprintf(w, "%s\t%t\t%t\t%d\t%s\n", mute.Code, raised, mute.Sticky, mute.Count, mute.Summary)
+	}
+	w.Flush()
+}
+
+func sortedMutes(mutes []healthMute) []healthMute {
+	sorted := append([]healthMute{}, mutes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+	return sorted
+}
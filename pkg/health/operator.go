@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	operatorDeploymentName = "rook-ceph-operator"
+	// operatorLogWindow is how far back the operator logs are scanned for errors
+	operatorLogWindow = time.Hour
+	// maxOperatorLogErrors is the number of most recent operator log errors reported
+	maxOperatorLogErrors = 5
+)
+
+func checkOperatorHealth(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, operatorNamespace string, scanLogs bool) {
+	deployment, err := k8sclientset.AppsV1().Deployments(operatorNamespace).Get(ctx, operatorDeploymentName, metav1.GetOptions{})
+	if err != nil {
+		check.Error(fmt.Errorf("failed to get the %s deployment: %v", operatorDeploymentName, err))
+		return
+	}
+	if !isDeploymentAvailable(deployment) {
+		check.Error(fmt.Errorf("deployment %s is not available", operatorDeploymentName))
+	}
+
+	podList, err := k8sclientset.CoreV1().Pods(operatorNamespace).List(ctx, metav1.ListOptions{LabelSelector: check.options().Selectors["operator"]})
+	if err != nil {
+		check.Error(fmt.Errorf("failed to list the operator pods: %v", err))
+		return
+	}
+	if len(podList.Items) == 0 {
+		check.Error(fmt.Errorf("no operator pod found in namespace %s", operatorNamespace))
+		return
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !isPodReady(pod) {
+			check.Warning("operator pod %s is not ready, status %s", pod.Name, pod.Status.Phase)
+			continue
+		}
+		check.Info("operator pod %s is ready", pod.Name)
+
+		if scanLogs {
+			scanOperatorLogs(ctx, check, k8sclientset, pod)
+		}
+	}
+}
+
+func scanOperatorLogs(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, pod *v1.Pod) {
+	sinceSeconds := int64(operatorLogWindow.Seconds())
+	logs, err := k8sclientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{Container: "rook-ceph-operator", SinceSeconds: &sinceSeconds}).DoRaw(ctx)
+	if err != nil {
+		check.Warning("failed to get the logs of the operator pod %s: %v", pod.Name, err)
+		return
+	}
+
+	logErrors := findOperatorLogErrors(string(logs))
+	if len(logErrors) == 0 {
+		check.Info("no reconcile errors or panics in the operator logs of the last %s", operatorLogWindow)
+		return
+	}
+
+	check.Warning("%d reconcile errors or panics in the operator logs of the last %s", len(logErrors), operatorLogWindow)
+	if len(logErrors) > maxOperatorLogErrors {
+		logErrors = logErrors[len(logErrors)-maxOperatorLogErrors:]
+	}
+	for _, line := range logErrors {
+		check.Warning("\t%s", line)
+	}
+}
+
+// findOperatorLogErrors returns the operator log lines logged at error level or reporting a panic
+func findOperatorLogErrors(logs string) []string {
+	var logErrors []string
+	for _, line := range strings.Split(logs, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, " E | ") || strings.HasPrefix(line, "panic:") || strings.Contains(line, "failed to reconcile") {
+			logErrors = append(logErrors, line)
+		}
+	}
+	return logErrors
+}
+
+func isDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
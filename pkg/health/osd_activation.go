@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// osdActivateContainer is the init container of the osd pods running `ceph-volume activate`
+	osdActivateContainer = "activate"
+	// osdActivateLogLines is the number of lines of the activate container logs scanned for errors
+	osdActivateLogLines = 50
+	// maxOsdActivateLogErrors is the number of most recent activation errors reported by osd
+	maxOsdActivateLogErrors = 3
+)
+
+type osdDump struct {
+	Osds []osdDumpEntry `json:"osds"`
+}
+
+type osdDumpEntry struct {
+	Osd int `json:"osd"`
+	Up  int `json:"up"`
+	In  int `json:"in"`
+}
+
+// osdActivationIssue is an osd whose pod didn't activate it, or whose pod runs while ceph reports the osd down or out
+type osdActivationIssue struct {
+	Id      int
+	Pod     string
+	Problem string
+	// ScanLogs is set when the activate container may have logged why the activation failed
+	ScanLogs bool
+}
+
+func checkOsdActivation(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	dumpOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "dump", "--format", "json"})
+	var dump osdDump
+	err := json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd dump output. %v", err))
+		return
+	}
+
+	// the pods stuck in their init containers are pending, they are listed too
+	opts := metav1.ListOptions{LabelSelector: check.options().Selectors["osd"]}
+	pods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, opts)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to list osd pods with label %s: %v", opts.LabelSelector, err))
+		return
+	}
+
+	issues := findOsdActivationIssues(pods, dump.Osds)
+	for _, issue := range issues {
+		check.Warning("\tosd.%d: %s", issue.Id, issue.Problem)
+		if !issue.ScanLogs {
+			continue
+		}
+		tailLines := int64(osdActivateLogLines)
+		logs, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).GetLogs(issue.Pod, &v1.PodLogOptions{Container: osdActivateContainer, TailLines: &tailLines}).DoRaw(ctx)
+		if err != nil {
+			continue
+		}
+		for _, line := range findOsdActivateLogErrors(string(logs)) {
+			check.Warning("\t\t%s", line)
+		}
+	}
+	if len(issues) == 0 {
+		check.Info("\tthe osds of the %d osd pods are activated, and up and in ceph", len(pods))
+	}
+}
+
+// findOsdActivationIssues correlates the osd pods with the osd map. A pod stuck in its init containers never
+// activated its osd, and a running pod whose osd is down or out in ceph may have started without a working osd.
+func findOsdActivationIssues(pods []v1.Pod, osds []osdDumpEntry) []osdActivationIssue {
+	osdStates := map[int]osdDumpEntry{}
+	for _, osd := range osds {
+		osdStates[osd.Osd] = osd
+	}
+
+	var issues []osdActivationIssue
+	for i := range pods {
+		pod := &pods[i]
+		id, err := strconv.Atoi(pod.Labels["ceph-osd-id"])
+		if err != nil || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if problem, failed := getInitContainerProblem(pod); problem != "" {
+			issues = append(issues, osdActivationIssue{Id: id, Pod: pod.Name, Problem: fmt.Sprintf("pod %s %s", pod.Name, problem), ScanLogs: failed})
+			continue
+		}
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		osd, ok := osdStates[id]
+		switch {
+		case !ok:
+			issues = append(issues, osdActivationIssue{Id: id, Pod: pod.Name, Problem: fmt.Sprintf("pod %s is running but the osd is not in the osd map", pod.Name)})
+		case osd.Up == 0:
+			problem := fmt.Sprintf("pod %s is running but the osd is down in ceph", pod.Name)
+			if restarts := getContainerRestarts(pod, "osd"); restarts != 0 {
+				problem = fmt.Sprintf("%s, the osd container restarted %d times", problem, restarts)
+			}
+			issues = append(issues, osdActivationIssue{Id: id, Pod: pod.Name, Problem: problem, ScanLogs: true})
+		case osd.In == 0:
+			issues = append(issues, osdActivationIssue{Id: id, Pod: pod.Name, Problem: fmt.Sprintf("pod %s is running and the osd is up, but it's out of ceph", pod.Name)})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Id < issues[j].Id })
+	return issues
+}
+
+// getInitContainerProblem returns why the init containers of the pod didn't complete, and whether one of them failed
+func getInitContainerProblem(pod *v1.Pod) (string, bool) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil {
+			if terminated.ExitCode == 0 {
+				continue
+			}
+			return fmt.Sprintf("init container %s failed with exit code %d", status.Name, terminated.ExitCode), true
+		}
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "PodInitializing" {
+			return fmt.Sprintf("init container %s is waiting: %s", status.Name, waiting.Reason), status.RestartCount != 0
+		}
+		return fmt.Sprintf("is still running init container %s", status.Name), false
+	}
+	return "", false
+}
+
+func getContainerRestarts(pod *v1.Pod, name string) int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == name {
+			return status.RestartCount
+		}
+	}
+	return 0
+}
+
+// findOsdActivateLogErrors returns the most recent errors of the activate container logs, such as the
+// RuntimeError of ceph-volume when the osd device is missing
+func findOsdActivateLogErrors(logs string) []string {
+	var logErrors []string
+	for _, line := range strings.Split(logs, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "error") || strings.Contains(lower, "failed") || strings.Contains(lower, "unable to") {
+			logErrors = append(logErrors, line)
+		}
+	}
+	if len(logErrors) > maxOsdActivateLogErrors {
+		logErrors = logErrors[len(logErrors)-maxOsdActivateLogErrors:]
+	}
+	return logErrors
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func checkOsdCount(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, check.options().ClusterName)
+	if err != nil {
+		check.Error(err)
+		return
+	}
+
+	lsOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "ls", "--format", "json"})
+	var osdIds []int
+	err = json.Unmarshal([]byte(lsOut), &osdIds)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd ls output. %v", err))
+		return
+	}
+
+	opts := metav1.ListOptions{LabelSelector: check.options().Selectors["osd"], FieldSelector: "status.phase=Running"}
+	pods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, opts)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to list osd pods with label %s: %v", opts.LabelSelector, err))
+		return
+	}
+	runningOsds := map[int]bool{}
+	for _, pod := range pods {
+		if id, err := strconv.Atoi(pod.Labels["ceph-osd-id"]); err == nil && pod.DeletionTimestamp.IsZero() {
+			runningOsds[id] = true
+		}
+	}
+
+	expected, known := getExpectedOsdCount(cephCluster.Spec.Storage)
+	if known {
+		check.Info("\tthe CephCluster spec expects %d osds, ceph has %d osds and %d osd pods are running", expected, len(osdIds), len(runningOsds))
+	} else {
+		check.Info("\tceph has %d osds and %d osd pods are running, the expected osd count is not known since the CephCluster uses all the nodes or devices, or device filters", len(osdIds), len(runningOsds))
+	}
+	for _, warning := range findMissingOsds(expected, known, osdIds, runningOsds) {
+		check.Warning("\t%s", warning)
+	}
+}
+
+// getExpectedOsdCount returns the number of osds the storage spec creates. Only the devices listed by name and
+// the device sets can be counted, the count is not known when the osds are created on all the nodes or all the
+// devices, or on the devices matching a filter.
+func getExpectedOsdCount(storage cephv1.StorageScopeSpec) (int, bool) {
+	count := 0
+	for _, deviceSet := range storage.StorageClassDeviceSets {
+		// each pvc of a device set is a single osd
+		count += deviceSet.Count
+	}
+
+	if storage.UseAllNodes {
+		if hasDeviceSelection(storage.Selection) {
+			return count, false
+		}
+		return count, true
+	}
+	for _, node := range storage.Nodes {
+		// the node inherits the devices of the cluster when it doesn't select its own devices
+		selection := node.Selection
+		if !hasDeviceSelection(selection) {
+			selection = storage.Selection
+		}
+		if (selection.UseAllDevices != nil && *selection.UseAllDevices) || selection.DeviceFilter != "" || selection.DevicePathFilter != "" {
+			return count, false
+		}
+		for _, device := range selection.Devices {
+			count += getOsdsPerDevice(device.Config, node.Config, storage.Config)
+		}
+	}
+	return count, true
+}
+
+func hasDeviceSelection(selection cephv1.Selection) bool {
+	return (selection.UseAllDevices != nil && *selection.UseAllDevices) || selection.DeviceFilter != "" ||
+		selection.DevicePathFilter != "" || len(selection.Devices) != 0
+}
+
+// getOsdsPerDevice returns the osdsPerDevice setting of the most specific config, 1 by default
+func getOsdsPerDevice(configs ...map[string]string) int {
+	for _, config := range configs {
+		if value, ok := config["osdsPerDevice"]; ok {
+			if osds, err := strconv.Atoi(value); err == nil && osds > 0 {
+				return osds
+			}
+		}
+	}
+	return 1
+}
+
+// findMissingOsds returns the gaps between the osds the CephCluster expects, the osds of ceph and their running pods.
+// An osd is missing from ceph when its disk failed or its prepare job failed, and an osd has no running pod when it
+// crashes or can't be scheduled.
+func findMissingOsds(expected int, known bool, osdIds []int, runningOsds map[int]bool) []string {
+	var warnings []string
+	if known && len(osdIds) < expected {
+		warnings = append(warnings, fmt.Sprintf("%d of the %d osds expected by the CephCluster spec are missing from ceph, check the logs of the osd prepare pods with label app=rook-ceph-osd-prepare",
+			expected-len(osdIds), expected))
+	}
+
+	var notRunning []string
+	for _, id := range osdIds {
+		if !runningOsds[id] {
+			notRunning = append(notRunning, fmt.Sprintf("osd.%d", id))
+		}
+	}
+	if len(notRunning) != 0 {
+		warnings = append(warnings, fmt.Sprintf("%d osds have no running pod: %v", len(notRunning), notRunning))
+	}
+	return warnings
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	PgCategoryHealthy  = "healthy"
+	PgCategoryWarning  = "warning"
+	PgCategoryCritical = "critical"
+)
+
+// pgStateCategories is the category of each state of a pg state such as "active+clean+scrubbing+deep". The
+// category of a pg is the most severe category of its states, and a state missing from the table is a warning.
+var pgStateCategories = map[string]string{
+	"active":        PgCategoryHealthy,
+	"clean":         PgCategoryHealthy,
+	"scrubbing":     PgCategoryHealthy,
+	"deep":          PgCategoryHealthy,
+	"scrub_wait":    PgCategoryHealthy,
+	"snaptrim":      PgCategoryHealthy,
+	"snaptrim_wait": PgCategoryHealthy,
+
+	// the pg is available while its data is recovered, moved or repaired
+	"degraded":         PgCategoryWarning,
+	"undersized":       PgCategoryWarning,
+	"remapped":         PgCategoryWarning,
+	"recovering":       PgCategoryWarning,
+	"recovery_wait":    PgCategoryWarning,
+	"recovery_toofull": PgCategoryWarning,
+	"forced_recovery":  PgCategoryWarning,
+	"backfilling":      PgCategoryWarning,
+	"backfill_wait":    PgCategoryWarning,
+	"backfill_toofull": PgCategoryWarning,
+	"forced_backfill":  PgCategoryWarning,
+	"repair":           PgCategoryWarning,
+	"wait":             PgCategoryWarning,
+	"laggy":            PgCategoryWarning,
+	"premerge":         PgCategoryWarning,
+	"creating":         PgCategoryWarning,
+	"peering":          PgCategoryWarning,
+	"activating":       PgCategoryWarning,
+	"unknown":          PgCategoryCritical,
+	"down":             PgCategoryCritical,
+	"incomplete":       PgCategoryCritical,
+	"stale":            PgCategoryCritical,
+	"peered":           PgCategoryCritical,
+	"inconsistent":     PgCategoryCritical,
+	"failed_repair":    PgCategoryCritical,
+	"snaptrim_error":   PgCategoryCritical,
+	"recovery_unfound": PgCategoryCritical,
+	"backfill_unfound": PgCategoryCritical,
+}
+
+// transientInactiveStates are the states of a pg becoming active, it's not critical while it's not active yet
+var transientInactiveStates = map[string]bool{
+	"creating":   true,
+	"peering":    true,
+	"activating": true,
+}
+
+// PgStateSummary is the number of pgs of each category, and the category of each pg state of the ceph status
+type PgStateSummary struct {
+	Healthy  int          `json:"healthy"`
+	Warning  int          `json:"warning"`
+	Critical int          `json:"critical"`
+	States   []PgStateRow `json:"states,omitempty"`
+}
+
+type PgStateRow struct {
+	State    string `json:"state"`
+	Count    int    `json:"count"`
+	Category string `json:"category"`
+}
+
+// classifyPgState returns the category of a pg state. A pg which is neither active nor becoming active is
+// critical since its io is blocked, and an active pg which is not clean is at least a warning.
+func classifyPgState(stateName string) string {
+	category := PgCategoryHealthy
+	hasState := map[string]bool{}
+	for _, state := range strings.Split(stateName, "+") {
+		hasState[state] = true
+		stateCategory, ok := pgStateCategories[state]
+		if !ok {
+			stateCategory = PgCategoryWarning
+		}
+		category = mostSeverePgCategory(category, stateCategory)
+	}
+
+	if !hasState["active"] {
+		transient := false
+		for state := range hasState {
+			if transientInactiveStates[state] {
+				transient = true
+			}
+		}
+		if !transient {
+			return PgCategoryCritical
+		}
+		return mostSeverePgCategory(category, PgCategoryWarning)
+	}
+	if !hasState["clean"] {
+		return mostSeverePgCategory(category, PgCategoryWarning)
+	}
+	return category
+}
+
+func mostSeverePgCategory(a, b string) string {
+	severity := map[string]int{PgCategoryHealthy: 0, PgCategoryWarning: 1, PgCategoryCritical: 2}
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// evaluatePgStates reports the healthy pg states as info, the warning states as warnings and the critical states
+// as errors, and returns the number of pgs of each category
+func evaluatePgStates(check *CheckResult, pgStateEntryList []PgStateEntry) *PgStateSummary {
+	summary := &PgStateSummary{}
+	for _, pgStatus := range pgStateEntryList {
+		category := classifyPgState(pgStatus.StateName)
+		summary.States = append(summary.States, PgStateRow{State: pgStatus.StateName, Count: pgStatus.Count, Category: category})
+		switch category {
+		case PgCategoryHealthy:
+			summary.Healthy += pgStatus.Count
+			check.Info("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count)
+		case PgCategoryWarning:
+			summary.Warning += pgStatus.Count
+			check.Warning("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count)
+		default:
+			summary.Critical += pgStatus.Count
+			check.Error(fmt.Errorf("\tPgState: %s, PgCount: %d", pgStatus.StateName, pgStatus.Count))
+		}
+	}
+	return summary
+}
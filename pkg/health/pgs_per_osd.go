@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+)
+
+// defaultMinPgsPerOsd and defaultMaxPgsPerOsd are the recommended bounds of the number of pgs per osd. Too few pgs
+// balance the data poorly, too many slow down the peering and the recovery and use more memory.
+const (
+	defaultMinPgsPerOsd = 30
+	defaultMaxPgsPerOsd = 200
+)
+
+type osdDf struct {
+	Nodes []osdDfNode `json:"nodes"`
+}
+
+type osdDfNode struct {
+	Name     string  `json:"name"`
+	Reweight float64 `json:"reweight"`
+	Pgs      int     `json:"pgs"`
+}
+
+func checkPgsPerOsd(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	osdDfOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "df", "--format", "json"})
+	var df osdDf
+	err := json.Unmarshal([]byte(osdDfOut), &df)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd df output. %v", err))
+		return
+	}
+
+	opts := check.options()
+	average, outOfBounds := getPgsPerOsdOutOfBounds(df.Nodes, opts.MinPgsPerOsd, opts.MaxPgsPerOsd)
+	if average == 0 {
+		check.Warning("\tno osd is in, the pgs per osd can't be computed")
+		return
+	}
+	check.Info("\tAverage of %d pgs per osd", average)
+	for _, osd := range outOfBounds {
+		check.Warning("\t%s has %d pgs, the recommended range is %d to %d pgs per osd", osd.Name, osd.Pgs, opts.MinPgsPerOsd, opts.MaxPgsPerOsd)
+	}
+}
+
+// getPgsPerOsdOutOfBounds returns the average number of pgs of the osds that are in, and the osds
+// with a number of pgs outside of the bounds
+func getPgsPerOsdOutOfBounds(osds []osdDfNode, minPgs, maxPgs int) (int, []osdDfNode) {
+	var inOsds, totalPgs int
+	var outOfBounds []osdDfNode
+	for _, osd := range osds {
+		// the osds that are out don't hold any pg
+		if osd.Reweight == 0 {
+			continue
+		}
+		inOsds++
+		totalPgs += osd.Pgs
+		if osd.Pgs < minPgs || osd.Pgs > maxPgs {
+			outOfBounds = append(outOfBounds, osd)
+		}
+	}
+	if inOsds == 0 {
+		return 0, nil
+	}
+	return totalPgs / inOsds, outOfBounds
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// intentionalReplicaSizeAnnotation marks a CephBlockPool whose size or min_size of 1 is intentional, such as in test clusters
+const intentionalReplicaSizeAnnotation = "kubectl-rook-ceph/intentional-replica-size"
+
+type poolDetail struct {
+	PoolName           string `json:"pool_name"`
+	Type               int    `json:"type"`
+	Size               int    `json:"size"`
+	MinSize            int    `json:"min_size"`
+	CrushRule          int    `json:"crush_rule"`
+	ErasureCodeProfile string `json:"erasure_code_profile"`
+}
+
+func checkPoolReplication(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	poolsOut := runCephCommand(ctx, check, clientsets, operatorNamespace, clusterNamespace, []string{"osd", "pool", "ls", "detail", "--format", "json"})
+	var pools []poolDetail
+	err := json.Unmarshal([]byte(poolsOut), &pools)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph osd pool ls detail output. %v", err))
+		return
+	}
+
+	blockPools, err := clientsets.Rook.CephV1().CephBlockPools(clusterNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Warning("failed to list the CephBlockPools, the pools with an intentional replica size can't be excluded. %v", err)
+	}
+	var intentional map[string]bool
+	if blockPools != nil {
+		intentional = getIntentionalReplicaSizePools(blockPools.Items)
+	}
+
+	unsafePools := findUnsafeReplicationPools(pools, intentional)
+	for _, pool := range unsafePools {
+		check.Warning("pool %s has size %d and min_size %d, a single failure can lose its data or block its io", pool.PoolName, pool.Size, pool.MinSize)
+	}
+	if len(unsafePools) == 0 {
+		check.Info("no pool has a size or min_size of 1")
+	}
+}
+
+// findUnsafeReplicationPools returns the pools with a size or min_size of 1, except the intentional ones
+func findUnsafeReplicationPools(pools []poolDetail, intentional map[string]bool) []poolDetail {
+	var unsafePools []poolDetail
+	for _, pool := range pools {
+		if (pool.Size == 1 || pool.MinSize == 1) && !intentional[pool.PoolName] {
+			unsafePools = append(unsafePools, pool)
+		}
+	}
+	return unsafePools
+}
+
+// getIntentionalReplicaSizePools returns the ceph pool names of the CephBlockPools annotated with an intentional replica size
+func getIntentionalReplicaSizePools(blockPools []cephv1.CephBlockPool) map[string]bool {
+	intentional := map[string]bool{}
+	for i := range blockPools {
+		if blockPools[i].Annotations[intentionalReplicaSizeAnnotation] != "true" {
+			continue
+		}
+		poolName := blockPools[i].Spec.Name
+		if poolName == "" {
+			poolName = blockPools[i].Name
+		}
+		intentional[poolName] = true
+	}
+	return intentional
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// HealthReport is the structured result of the health checks
+type HealthReport struct {
+	Cluster string `json:"cluster"`
+	// CephCluster is the name of the CephCluster passed with --cluster-name
+	CephCluster string         `json:"cephCluster,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Status      string         `json:"status"`
+	Checks      []*CheckResult `json:"checks"`
+	// PgStates is the number of pgs by category of the pg-status check
+	PgStates *PgStateSummary `json:"pgStates,omitempty"`
+
+	// opts are the options of the run that created the report
+	opts HealthOptions
+	// batchOutputs are the outputs of the batched queries of the run that succeeded, by their arguments. It's nil
+	// when the queries are not batched.
+	batchOutputs map[string]string
+}
+
+// CheckResult is the result of a single health check. The status is the most severe status of its messages.
+type CheckResult struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Messages []string `json:"messages,omitempty"`
+
+	errs []error
+	// report is the report the check was added to
+	report *HealthReport
+}
+
+func newHealthReport(clusterNamespace string, opts HealthOptions) *HealthReport {
+	return &HealthReport{
+		Cluster:     clusterNamespace,
+		CephCluster: opts.ClusterName,
+		Timestamp:   time.Now().UTC(),
+		Status:      StatusOK,
+		opts:        opts,
+	}
+}
+
+// newCheck logs the description of the check and adds it to the report
+func (r *HealthReport) newCheck(name, description string) *CheckResult {
+	logging.Info(description)
+	check := &CheckResult{Name: name, Status: StatusOK, report: r}
+	r.Checks = append(r.Checks, check)
+	return check
+}
+
+// finalize sets the overall status of the report to the most severe status of the checks
+func (r *HealthReport) finalize() {
+	r.Status = StatusOK
+	for _, check := range r.Checks {
+		r.Status = mostSevere(r.Status, check.Status)
+	}
+}
+
+// Summary returns the number of checks by status and the names of the failing checks, such as
+// "10 checks passed, 1 warning, 2 errors. warning: osd-count. errors: mon-quorum, pg-status"
+func (r *HealthReport) Summary() string {
+	var warnings, errs []string
+	for _, check := range r.Checks {
+		switch check.Status {
+		case StatusWarning:
+			warnings = append(warnings, check.Name)
+		case StatusError:
+			errs = append(errs, check.Name)
+		}
+	}
+	passed := len(r.Checks) - len(warnings) - len(errs)
+	summary := fmt.Sprintf("%d %s passed, %d %s, %d %s", passed, plural(passed, "check"), len(warnings), plural(len(warnings), "warning"),
+		len(errs), plural(len(errs), "error"))
+	if len(warnings) != 0 {
+		summary += fmt.Sprintf(". %s: %s", plural(len(warnings), "warning"), strings.Join(warnings, ", "))
+	}
+	if len(errs) != 0 {
+		summary += fmt.Sprintf(". %s: %s", plural(len(errs), "error"), strings.Join(errs, ", "))
+	}
+	return summary
+}
+
+// Err returns a single error joining the errors of the failing checks, or nil when no check failed. The warnings
+// are not failures.
+func (r *HealthReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Status != StatusError {
+			continue
+		}
+		// a report read back from json has the status of the checks but not their errors
+		if len(check.errs) == 0 {
+			errs = append(errs, fmt.Errorf("check %s failed", check.Name))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("check %s failed: %w", check.Name, errors.Join(check.errs...)))
+	}
+	return errors.Join(errs...)
+}
+
+// printSummary prints the summary of the report with the severity of its status
+func (r *HealthReport) printSummary() {
+	switch r.Status {
+	case StatusError:
+		logging.Error(fmt.Errorf("%s", r.Summary()))
+	case StatusWarning:
+		logging.Warning(r.Summary())
+	default:
+		logging.Info(r.Summary())
+	}
+}
+
+func plural(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// options returns the options of the run the check belongs to, or the default options for a check that was not
+// added to a report
+func (c *CheckResult) options() HealthOptions {
+	if c.report == nil {
+		return NewHealthOptions()
+	}
+	return c.report.opts
+}
+
+func (c *CheckResult) Info(output string, args ...interface{}) {
+	logging.Info(output, args...)
+	c.add(StatusOK, fmt.Sprintf(output, args...))
+}
+
+func (c *CheckResult) Warning(output string, args ...interface{}) {
+	logging.Warning(output, args...)
+	c.add(StatusWarning, fmt.Sprintf(output, args...))
+}
+
+func (c *CheckResult) Error(err error) {
+	logging.Error(err)
+	c.errs = append(c.errs, err)
+	c.add(StatusError, err.Error())
+}
+
+func (c *CheckResult) add(status, message string) {
+	c.Status = mostSevere(c.Status, status)
+	if message = strings.TrimSpace(message); message != "" {
+		c.Messages = append(c.Messages, message)
+	}
+}
+
+func severity(status string) int {
+	switch status {
+	case StatusError:
+		return 2
+	case StatusWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func mostSevere(a, b string) string {
+	if severity(b) > severity(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resourceDaemonTypes are the daemons whose resources are checked, a flapping mon, mgr or osd affects the cluster
+var resourceDaemonTypes = []string{"mon", "mgr", "osd"}
+
+// minimumDaemonMemory is the memory rook recommends for each daemon, it warns in the operator logs below it
+var minimumDaemonMemory = map[string]int64{
+	"mon": 1024 << 20,
+	"mgr": 512 << 20,
+	"osd": 2048 << 20,
+}
+
+func checkDaemonResources(ctx context.Context, check *CheckResult, k8sclientset kubernetes.Interface, clusterNamespace string) {
+	var warnings []string
+	for _, daemonType := range resourceDaemonTypes {
+		opts := metav1.ListOptions{LabelSelector: check.options().Selectors[daemonType]}
+		pods, err := k8sutil.ListPods(ctx, k8sclientset, clusterNamespace, opts)
+		if err != nil {
+			check.Error(fmt.Errorf("failed to list %s pods with label %s: %v", daemonType, opts.LabelSelector, err))
+			return
+		}
+		warnings = append(warnings, findDaemonResourceIssues(daemonType, pods)...)
+	}
+	for _, warning := range warnings {
+		check.Warning("%s", warning)
+	}
+	if len(warnings) == 0 {
+		check.Info("the mon, mgr and osd pods have memory requests and limits above the minimum recommended by rook, and a cpu request")
+	}
+}
+
+// findDaemonResourceIssues returns the pods of the daemon type without memory request, memory limit or cpu request,
+// with a memory limit below the memory rook recommends, and the pods whose daemon was OOM killed. A pod without
+// requests is the first evicted under node pressure, and a pod without memory limit can take the memory of the
+// node. The cpu limits are not required since they throttle the daemons, which slows down the io and the recovery.
+func findDaemonResourceIssues(daemonType string, pods []v1.Pod) []string {
+	issues := map[string][]string{}
+	var oomKilled []string
+	var issueOrder []string
+	addIssue := func(issue, podName string) {
+		if _, ok := issues[issue]; !ok {
+			issueOrder = append(issueOrder, issue)
+		}
+		issues[issue] = append(issues[issue], podName)
+	}
+
+	minimum := minimumDaemonMemory[daemonType]
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, pod := range pods {
+		container := getDaemonContainer(pod, daemonType)
+		if container == nil {
+			continue
+		}
+		requests, limits := container.Resources.Requests, container.Resources.Limits
+		if _, ok := requests[v1.ResourceMemory]; !ok {
+			addIssue("have no memory request, they are the first evicted when the node runs out of memory", pod.Name)
+		}
+		if _, ok := requests[v1.ResourceCPU]; !ok {
+			addIssue("have no cpu request, their cpu time is not guaranteed when the node is busy", pod.Name)
+		}
+		if limit, ok := limits[v1.ResourceMemory]; !ok {
+			addIssue("have no memory limit, a memory leak can take the memory of the node", pod.Name)
+		} else if limit.Value() < minimum {
+			addIssue(fmt.Sprintf("have a memory limit of %s, below the %s recommended by rook, the daemons are likely OOM killed",
+				output.Bytes(uint64(limit.Value())), output.Bytes(uint64(minimum))), pod.Name)
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container.Name && status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.Reason == "OOMKilled" {
+				oomKilled = append(oomKilled, fmt.Sprintf("%s (%d restarts)", pod.Name, status.RestartCount))
+			}
+		}
+	}
+
+	var warnings []string
+	for _, issue := range issueOrder {
+		warnings = append(warnings, fmt.Sprintf("%s pods %s: %s", daemonType, issue, strings.Join(issues[issue], ", ")))
+	}
+	if len(oomKilled) != 0 {
+		warnings = append(warnings, fmt.Sprintf("%s pods were OOM killed, raise their memory limit: %s", daemonType, strings.Join(oomKilled, ", ")))
+	}
+	return warnings
+}
+
+// getDaemonContainer returns the container running the daemon, the pods also run sidecars such as the log collector
+func getDaemonContainer(pod v1.Pod, daemonType string) *v1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == daemonType {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	return &pod.Spec.Containers[0]
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/mgr"
+	"github.com/rook/kubectl-rook-ceph/pkg/version"
+)
+
+// AcceptedUpgradeWarnings are the health warnings that don't block an upgrade, they report past events or settings
+// unrelated to the availability of the data while the daemons restart
+var AcceptedUpgradeWarnings = []string{
+	"RECENT_CRASH",
+	"RECENT_MGR_MODULE_CRASH",
+	"POOL_APP_NOT_ENABLED",
+	"BLUESTORE_NO_PER_POOL_OMAP",
+	"BLUESTORE_NO_PER_PG_OMAP",
+}
+
+type healthCheckDetail struct {
+	Severity string `json:"severity"`
+	Summary  struct {
+		Message string `json:"message"`
+	} `json:"summary"`
+}
+
+type fsDump struct {
+	Standbys    []fsDumpMds `json:"standbys"`
+	Filesystems []struct {
+		MdsMap struct {
+			FsName string               `json:"fs_name"`
+			Info   map[string]fsDumpMds `json:"info"`
+		} `json:"mdsmap"`
+	} `json:"filesystems"`
+}
+
+type fsDumpMds struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+func UpgradeCheck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, acceptedWarnings []string) {
+	err := upgradeCheck(ctx, clientsets, operatorNamespace, clusterNamespace, acceptedWarnings)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func upgradeCheck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, acceptedWarnings []string) error {
+	report := newHealthReport(clusterNamespace, NewHealthOptions())
+
+	statusOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"status", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	status, err := parseCephStatus(statusOut)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph status output. %v", err)
+	}
+
+	check := report.newCheck("ceph-health", "Checking if the ceph health is ok or only has warnings accepted for an upgrade")
+	evaluateUpgradeHealth(check, status.Health, append(append([]string{}, AcceptedUpgradeWarnings...), acceptedWarnings...))
+
+	fmt.Println()
+	check = report.newCheck("recovery", "Checking if all the pgs are active and clean")
+	evaluateUpgradePgStates(check, status.PgMap.PgsByState)
+
+	fmt.Println()
+	check = report.newCheck("ceph-versions", "Checking if all daemons are running the same ceph version")
+	checkUpgradeVersions(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	fmt.Println()
+	check = report.newCheck("standby-daemons", "Checking if a standby mgr and standby mds daemons can take over while the daemons restart")
+	checkStandbyDaemons(ctx, check, clientsets, operatorNamespace, clusterNamespace)
+
+	report.finalize()
+	fmt.Println()
+	report.printSummary()
+	switch report.Status {
+	case StatusError:
+		return fmt.Errorf("no-go: fix the errors above before upgrading")
+	case StatusWarning:
+		logging.Warning("go: the cluster can be upgraded, check the warnings above first")
+	default:
+		logging.Info("go: the cluster is ready to be upgraded")
+	}
+	return nil
+}
+
+// evaluateUpgradeHealth fails on the raised health checks, except the accepted warnings. A muted check is not
+// raised, it's reported as a warning since it may hide a failure during the upgrade.
+func evaluateUpgradeHealth(check *CheckResult, cephHealth healthStatus, acceptedWarnings []string) {
+	for _, warning := range getMuteWarnings(cephHealth) {
+		check.Warning(warning)
+	}
+	if cephHealth.Status == "HEALTH_OK" {
+		check.Info(cephHealth.Status)
+		return
+	}
+
+	accepted := map[string]bool{}
+	for _, code := range acceptedWarnings {
+		accepted[code] = true
+	}
+	var codes []string
+	for code := range cephHealth.Checks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		var detail healthCheckDetail
+		err := json.Unmarshal(cephHealth.Checks[code], &detail)
+		if err != nil {
+			check.Error(fmt.Errorf("failed to parse health check %s. %v", code, err))
+			continue
+		}
+		if detail.Severity == "HEALTH_WARN" && accepted[code] {
+			check.Warning("\t%s: %s, accepted for the upgrade", code, detail.Summary.Message)
+			continue
+		}
+		check.Error(fmt.Errorf("\t%s: %s", code, detail.Summary.Message))
+	}
+}
+
+// evaluateUpgradePgStates fails when a pg is not active and clean, the osds restarted by the upgrade would make the
+// degraded pgs unavailable and restart their recovery. The scrubs and the snap trims don't block the upgrade.
+func evaluateUpgradePgStates(check *CheckResult, pgStates []PgStateEntry) {
+	unsettled := 0
+	for _, pgState := range pgStates {
+		if classifyPgState(pgState.StateName) == PgCategoryHealthy {
+			check.Info("\tPgState: %s, PgCount: %d", pgState.StateName, pgState.Count)
+			continue
+		}
+		unsettled += pgState.Count
+		check.Error(fmt.Errorf("\tPgState: %s, PgCount: %d", pgState.StateName, pgState.Count))
+	}
+	if unsettled != 0 {
+		check.Error(fmt.Errorf("%d pgs are not active+clean, wait for the recovery to finish", unsettled))
+	}
+}
+
+// checkUpgradeVersions fails when the daemons run several ceph versions, a previous upgrade is not finished
+func checkUpgradeVersions(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	versions, err := version.GetDaemonVersions(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		check.Error(err)
+		return
+	}
+	overall := versions.Overall()
+	if len(overall) <= 1 {
+		check.Info("all daemons are running the same ceph version")
+		return
+	}
+	check.Error(fmt.Errorf("daemons are running %d ceph versions, wait for the upgrade in progress to finish", len(overall)))
+	for _, v := range overall {
+		check.Info("\t%d daemons: %s", versions.OverallCount(v), v)
+	}
+}
+
+// checkStandbyDaemons checks a standby can take over the active mgr and the active mds of each filesystem while
+// they restart. Without a standby mgr the dashboard and the metrics are down during the restart, while without a
+// standby mds the cephfs clients hang until the mds is back.
+func checkStandbyDaemons(ctx context.Context, check *CheckResult, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	stat, err := mgr.GetStat(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		check.Error(err)
+	} else if stat.NumStandby == 0 {
+		check.Warning("no standby mgr, the mgr modules are unavailable while the mgr %s restarts", stat.ActiveName)
+	} else {
+		check.Info("%d standby mgrs", stat.NumStandby)
+	}
+
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"fs", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+	var dump fsDump
+	err = json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		check.Error(fmt.Errorf("failed to parse ceph fs dump output. %v", err))
+		return
+	}
+	if len(dump.Filesystems) == 0 {
+		check.Info("no filesystem found")
+		return
+	}
+	missing := findFilesystemsWithoutStandby(dump)
+	for _, fsName := range missing {
+		check.Error(fmt.Errorf("filesystem %s has no standby mds, set activeStandby in the CephFilesystem so its clients don't hang while the mds restarts", fsName))
+	}
+	if len(missing) == 0 {
+		check.Info("%d standby mds for %d filesystems", len(dump.Standbys), len(dump.Filesystems))
+	}
+}
+
+// findFilesystemsWithoutStandby returns the filesystems with neither a standby-replay mds nor a standby mds to take
+// over their active mds
+func findFilesystemsWithoutStandby(dump fsDump) []string {
+	var missing []string
+	for _, fs := range dump.Filesystems {
+		hasStandby := len(dump.Standbys) != 0
+		for _, mds := range fs.MdsMap.Info {
+			if mds.State == "up:standby-replay" {
+				hasStandby = true
+			}
+		}
+		if !hasStandby {
+			missing = append(missing, fs.MdsMap.FsName)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookRetries  = 3
+	webhookInterval = 2 * time.Second
+)
+
+// SendWebhook posts the health report to the url when the report status is at least as severe as webhookOn,
+// which is either "warn" or "err"
+func SendWebhook(report *HealthReport, url, webhookOn string) error {
+	minStatus, err := webhookMinStatus(webhookOn)
+	if err != nil {
+		return err
+	}
+
+	if severity(report.Status) < severity(minStatus) {
+		logging.Info("health status is %q, skipping the webhook", report.Status)
+		return nil
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the health report. %v", err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	for i := 1; i <= webhookRetries; i++ {
+		err = postReport(client, url, body)
+		if err == nil {
+			logging.Info("health report sent to the webhook")
+			return nil
+		}
+		logging.Warning("attempt %d/%d to send the health report failed. %v", i, webhookRetries, err)
+		if i < webhookRetries {
+			time.Sleep(webhookInterval * time.Duration(i))
+		}
+	}
+	return fmt.Errorf("failed to send the health report to the webhook. %v", err)
+}
+
+func postReport(client *http.Client, url string, body []byte) error {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// ValidateWebhookOn returns an error for an invalid --webhook-on value, so it fails before the checks run
+func ValidateWebhookOn(webhookOn string) error {
+	_, err := webhookMinStatus(webhookOn)
+	return err
+}
+
+func webhookMinStatus(webhookOn string) (string, error) {
+	switch webhookOn {
+	case "warn":
+		return StatusWarning, nil
+	case "err":
+		return StatusError, nil
+	default:
+		return "", fmt.Errorf("invalid webhook-on value %q, supported values are warn and err", webhookOn)
+	}
+}
@@ -17,12 +17,24 @@ limitations under the License.
 package k8sutil
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
 )
 
+// DefaultCephClusterNamespace is the namespace of the CephCluster when none is set
+const DefaultCephClusterNamespace = "rook-ceph"
+
 type Clientsets struct {
 	// The Kubernetes config used for these client sets
 	KubeConfig *rest.Config
@@ -32,4 +44,149 @@ type Clientsets struct {
 
 	// Rook is a typed connection to the rook API
 	Rook rookclient.Interface
+
+	// Dynamic is an untyped connection to the Kubernetes API, for the resources of any kind
+	Dynamic dynamic.Interface
+}
+
+// Options are the settings a Context is built with, the cli sets them from its global flags
+type Options struct {
+	// KubeConfig is the path of the kubeconfig, the default loading rules of kubectl apply when it's empty
+	KubeConfig string
+	// KubeContext is the context of the kubeconfig to use instead of its current context
+	KubeContext string
+	// InCluster uses the service account of the pod instead of a kubeconfig
+	InCluster bool
+
+	// Impersonate is the user the requests are made as, with the ImpersonateGroups
+	Impersonate       string
+	ImpersonateGroups []string
+
+	// Timeout of the requests to the kubernetes api, zero means no timeout
+	Timeout time.Duration
+
+	// OperatorNamespace defaults to the CephClusterNamespace, which defaults to DefaultCephClusterNamespace
+	OperatorNamespace    string
+	CephClusterNamespace string
+}
+
+// Context is the clientsets and the namespaces the commands run with
+type Context struct {
+	Clientsets           *Clientsets
+	OperatorNamespace    string
+	CephClusterNamespace string
+}
+
+// NewContext creates the clientsets of the cluster from the options. The cluster is not contacted, so the
+// connection issues are returned by the first request.
+func NewContext(opts Options) (*Context, error) {
+	c := &Context{
+		OperatorNamespace:    opts.OperatorNamespace,
+		CephClusterNamespace: opts.CephClusterNamespace,
+	}
+	if c.CephClusterNamespace == "" {
+		c.CephClusterNamespace = DefaultCephClusterNamespace
+	}
+	if c.OperatorNamespace == "" {
+		c.OperatorNamespace = c.CephClusterNamespace
+	}
+
+	config, err := newRestConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Clientsets = &Clientsets{KubeConfig: config}
+	c.Clientsets.Rook, err = rookclient.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the rook clientset. %v", err)
+	}
+	c.Clientsets.Kube, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the kubernetes clientset. %v", err)
+	}
+	c.Clientsets.Dynamic, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the dynamic client. %v", err)
+	}
+	return c, nil
+}
+
+// Preflight checks the api server answers within the timeout and the namespaces of the context exist, so a command
+// fails fast with a clear error instead of failing deep inside with an opaque one
+func (c *Context) Preflight(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	namespaces := []struct{ kind, name string }{{"operator", c.OperatorNamespace}, {"CephCluster", c.CephClusterNamespace}}
+	for i, namespace := range namespaces {
+		if i > 0 && namespace.name == namespaces[0].name {
+			// the operator and the cluster share the namespace
+			continue
+		}
+		_, err := c.Clientsets.Kube.CoreV1().Namespaces().Get(ctx, namespace.name, v1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if kerrors.IsNotFound(err) {
+			return fmt.Errorf("%s namespace %q does not exist, set it with --%s", namespace.kind, namespace.name, namespaceFlags[namespace.kind])
+		}
+		// the api server answers with a status, any other error means it was not reached
+		var status kerrors.APIStatus
+		if !errors.As(err, &status) {
+			host := ""
+			if c.Clientsets.KubeConfig != nil {
+				host = c.Clientsets.KubeConfig.Host + " "
+			}
+			return fmt.Errorf("the kubernetes api server %sis not reachable within %s, check the kubeconfig, the --context and the network. %v", host, timeout, err)
+		}
+		return fmt.Errorf("failed to get the %s namespace %q. %v", namespace.kind, namespace.name, err)
+	}
+	return nil
+}
+
+// namespaceFlags are the cli flags setting the namespaces, by kind of namespace
+var namespaceFlags = map[string]string{"operator": "operator-namespace", "CephCluster": "namespace"}
+
+func newRestConfig(opts Options) (*rest.Config, error) {
+	// the impersonation is checked before loading the config, it's the most common mistake in the options
+	impersonation, err := getImpersonationConfig(opts.Impersonate, opts.ImpersonateGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	// the in-cluster config doesn't read a kubeconfig, its options would be silently ignored
+	if opts.InCluster && (opts.KubeConfig != "" || opts.KubeContext != "") {
+		return nil, fmt.Errorf("--in-cluster uses the service account of the pod, it can't be combined with --kubeconfig or --context")
+	}
+
+	var config *rest.Config
+	if opts.InCluster {
+		config, err = rest.InClusterConfig()
+	} else {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = opts.KubeConfig
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules,
+			&clientcmd.ConfigOverrides{CurrentContext: opts.KubeContext},
+		).ClientConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the impersonation is set on the config so it also applies to the exec in the pods
+	config.Impersonate = impersonation
+	config.Timeout = opts.Timeout
+	return config, nil
+}
+
+func getImpersonationConfig(userName string, groups []string) (rest.ImpersonationConfig, error) {
+	if userName == "" && len(groups) != 0 {
+		return rest.ImpersonationConfig{}, fmt.Errorf("--as-group requires --as to impersonate a user")
+	}
+	return rest.ImpersonationConfig{UserName: userName, Groups: groups}, nil
 }
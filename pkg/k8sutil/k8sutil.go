@@ -19,13 +19,17 @@ package k8sutil
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
 
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -44,21 +48,27 @@ func RestartDeployment(ctx context.Context, k8sclientset kubernetes.Interface, n
 
 func WaitForPodToRun(ctx context.Context, k8sclientset kubernetes.Interface, namespace, labelSelector string) (corev1.Pod, error) {
 	opts := v1.ListOptions{LabelSelector: labelSelector}
+	progress := logging.NewProgress("waiting for pod with label %q in namespace %q to be running", labelSelector, namespace)
 	for i := 0; i < 60; i++ {
 		pod, err := k8sclientset.CoreV1().Pods(namespace).List(ctx, opts)
 		if err != nil {
+			progress.Done("failed")
 			return corev1.Pod{}, fmt.Errorf("failed to list pods with labels matching %s", labelSelector)
 		}
+		state := "no pod found"
 		if len(pod.Items) != 0 {
 			if pod.Items[0].Status.Phase == corev1.PodRunning && pod.Items[0].DeletionTimestamp.IsZero() {
+				progress.Done(fmt.Sprintf("pod %s is running", pod.Items[0].Name))
 				return pod.Items[0], nil
 			}
+			state = fmt.Sprintf("pod %s is %s", pod.Items[0].Name, pod.Items[0].Status.Phase)
 		}
 
-		logging.Info("waiting for pod with label %q in namespace %q to be running", labelSelector, namespace)
+		progress.Update(state)
 		time.Sleep(time.Second * 5)
 	}
 
+	progress.Done("timed out")
 	return corev1.Pod{}, fmt.Errorf("No pod with labels matching %s", labelSelector)
 }
 
@@ -104,3 +114,130 @@ func GetDeployment(ctx context.Context, k8sclientset kubernetes.Interface, clust
 	logging.Info("deployment %s exists\n", deploymentName)
 	return deployment, nil
 }
+
+// GetCephCluster returns the CephCluster with the name, or the first CephCluster of the namespace when the name is
+// empty. Rook runs a single CephCluster by namespace.
+func GetCephCluster(ctx context.Context, rookclientset rookclient.Interface, clusterNamespace, clusterName string) (*cephv1.CephCluster, error) {
+	if clusterName != "" {
+		cephCluster, err := rookclientset.CephV1().CephClusters(clusterNamespace).Get(ctx, clusterName, v1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("CephCluster %q not found in namespace %q, set the namespace of the cluster with --namespace", clusterName, clusterNamespace)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the CephCluster %q. %v", clusterName, err)
+		}
+		return cephCluster, nil
+	}
+
+	cephClusters, err := rookclientset.CephV1().CephClusters(clusterNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the CephClusters. %v", err)
+	}
+	if len(cephClusters.Items) == 0 {
+		return nil, fmt.Errorf("no CephCluster found in namespace %q", clusterNamespace)
+	}
+	return &cephClusters.Items[0], nil
+}
+
+// GetOsdPodByID returns the pod of the osd with the given id, preferring a running pod when the osd
+// has multiple pods such as during a restart. A NotFound error is returned when the osd has no pod.
+func GetOsdPodByID(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string, osdID int) (*corev1.Pod, error) {
+	labelSelector := fmt.Sprintf("ceph-osd-id=%d", osdID)
+	pods, err := k8sclientset.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods with label %s. %v", labelSelector, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, kerrors.NewNotFound(corev1.Resource("pods"), fmt.Sprintf("osd.%d", osdID))
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].DeletionTimestamp.IsZero() {
+			return &pods.Items[i], nil
+		}
+	}
+	return &pods.Items[0], nil
+}
+
+// PodListPageSize is the number of pods returned by each request when listing pods, so that the pods of a large
+// namespace are listed in several smaller requests
+const PodListPageSize = 500
+
+// ListPods lists the pods matching the options, one page of PodListPageSize pods after the other
+func ListPods(ctx context.Context, k8sclientset kubernetes.Interface, namespace string, opts v1.ListOptions) ([]corev1.Pod, error) {
+	return listPodPages(ctx, opts, k8sclientset.CoreV1().Pods(namespace).List)
+}
+
+func listPodPages(ctx context.Context, opts v1.ListOptions, list func(context.Context, v1.ListOptions) (*corev1.PodList, error)) ([]corev1.Pod, error) {
+	opts.Limit = PodListPageSize
+	var pods []corev1.Pod
+	for {
+		page, err := list(ctx, opts)
+		if err != nil {
+			// the continue token expires when the pods change a lot while they are listed, the pods are then listed
+			// again in a single request to get a consistent list
+			if kerrors.IsResourceExpired(err) && opts.Continue != "" {
+				opts.Limit, opts.Continue = 0, ""
+				page, err = list(ctx, opts)
+				if err != nil {
+					return nil, err
+				}
+				return page.Items, nil
+			}
+			return nil, err
+		}
+		pods = append(pods, page.Items...)
+		if page.Continue == "" {
+			return pods, nil
+		}
+		opts.Continue = page.Continue
+	}
+}
+
+// GroupPodsByNode returns the pods grouped by the node they are scheduled on, and the sorted node names
+func GroupPodsByNode(pods []corev1.Pod) (map[string][]corev1.Pod, []string) {
+	podsByNode := make(map[string][]corev1.Pod)
+	var nodeNames []string
+	for i := range pods {
+		nodeName := pods[i].Spec.NodeName
+		if _, ok := podsByNode[nodeName]; !ok {
+			nodeNames = append(nodeNames, nodeName)
+		}
+		podsByNode[nodeName] = append(podsByNode[nodeName], pods[i])
+	}
+	sort.Strings(nodeNames)
+	return podsByNode, nodeNames
+}
+
+// ToolboxSelectors are the labels of the toolbox pods deployed from the rook examples and charts across rook
+// versions, in the order they are tried
+var ToolboxSelectors = []string{
+	"app=rook-ceph-tools",
+	"app.kubernetes.io/name=rook-ceph-tools",
+	"app=rook-ceph-tools-operator-image",
+}
+
+// GetToolboxPod returns the first running toolbox pod found with the toolbox selectors. When the toolbox pods are
+// not running yet, it waits for the pod of the first selector matching a pod.
+func GetToolboxPod(ctx context.Context, k8sclientset kubernetes.Interface, namespace string) (corev1.Pod, error) {
+	pendingSelector := ""
+	for _, selector := range ToolboxSelectors {
+		pods, err := k8sclientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return corev1.Pod{}, fmt.Errorf("failed to list the toolbox pods with label %s. %v", selector, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() {
+				return pod, nil
+			}
+		}
+		if len(pods.Items) != 0 && pendingSelector == "" {
+			pendingSelector = selector
+		}
+	}
+
+	if pendingSelector == "" {
+		return corev1.Pod{}, fmt.Errorf("no toolbox pod found in namespace %q with the labels %v", namespace, ToolboxSelectors)
+	}
+	return WaitForPodToRun(ctx, k8sclientset, namespace, pendingSelector)
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetCephCluster(t *testing.T) {
+	ctx := context.TODO()
+	rook := rookfake.NewSimpleClientset(
+		&cephv1.CephCluster{ObjectMeta: v1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"}},
+	)
+
+	cephCluster, err := GetCephCluster(ctx, rook, "rook-ceph", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-cluster", cephCluster.Name)
+	_, err = GetCephCluster(ctx, rook, "other", "")
+	assert.EqualError(t, err, `no CephCluster found in namespace "other"`)
+
+	cephCluster, err = GetCephCluster(ctx, rook, "rook-ceph", "my-cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-cluster", cephCluster.Name)
+	_, err = GetCephCluster(ctx, rook, "rook-ceph", "other-cluster")
+	assert.EqualError(t, err, `CephCluster "other-cluster" not found in namespace "rook-ceph", set the namespace of the cluster with --namespace`)
+}
+
+func TestGetOsdPodByID(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset()
+
+	for name, phase := range map[string]corev1.PodPhase{"rook-ceph-osd-0-old": corev1.PodPending, "rook-ceph-osd-0-new": corev1.PodRunning} {
+		pod := &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"ceph-osd-id": "0"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		_, err := k8s.CoreV1().Pods(ns).Create(ctx, pod, v1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	pod, err := GetOsdPodByID(ctx, k8s, ns, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph-osd-0-new", pod.Name)
+
+	_, err = GetOsdPodByID(ctx, k8s, ns, 1)
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestGroupPodsByNode(t *testing.T) {
+	newPod := func(name, nodeName string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}, Spec: corev1.PodSpec{NodeName: nodeName}}
+	}
+	pods := []corev1.Pod{newPod("mon-a", "node-2"), newPod("mon-b", "node-1"), newPod("mon-c", "node-2")}
+
+	podsByNode, nodeNames := GroupPodsByNode(pods)
+	assert.Equal(t, []string{"node-1", "node-2"}, nodeNames)
+	assert.Len(t, podsByNode["node-1"], 1)
+	assert.Len(t, podsByNode["node-2"], 2)
+	assert.Equal(t, "mon-a", podsByNode["node-2"][0].Name)
+	assert.Equal(t, "mon-c", podsByNode["node-2"][1].Name)
+}
+
+func TestGetToolboxPod(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset()
+	deleted := v1.Now()
+
+	_, err := GetToolboxPod(ctx, k8s, ns)
+	assert.Error(t, err)
+
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-tools-terminating", Namespace: ns, Labels: map[string]string{"app": "rook-ceph-tools"}, DeletionTimestamp: &deleted},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-tools-operator-image", Namespace: ns, Labels: map[string]string{"app": "rook-ceph-tools-operator-image"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+	for _, pod := range pods {
+		_, err = k8s.CoreV1().Pods(ns).Create(ctx, pod, v1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	pod, err := GetToolboxPod(ctx, k8s, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph-tools-operator-image", pod.Name)
+}
+
+func TestNewContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+- name: staging
+  cluster:
+    server: https://staging.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+- name: staging
+  context:
+    cluster: staging
+current-context: prod
+`), 0o600)
+	assert.NoError(t, err)
+
+	t.Run("defaults", func(t *testing.T) {
+		c, err := NewContext(Options{KubeConfig: kubeconfig})
+		assert.NoError(t, err)
+		assert.Equal(t, "rook-ceph", c.CephClusterNamespace)
+		assert.Equal(t, "rook-ceph", c.OperatorNamespace)
+		assert.Equal(t, "https://prod.example.com:6443", c.Clientsets.KubeConfig.Host)
+		assert.Equal(t, time.Duration(0), c.Clientsets.KubeConfig.Timeout)
+		assert.NotNil(t, c.Clientsets.Kube)
+		assert.NotNil(t, c.Clientsets.Rook)
+	})
+
+	t.Run("operator namespace defaults to the cluster namespace", func(t *testing.T) {
+		c, err := NewContext(Options{KubeConfig: kubeconfig, CephClusterNamespace: "storage"})
+		assert.NoError(t, err)
+		assert.Equal(t, "storage", c.CephClusterNamespace)
+		assert.Equal(t, "storage", c.OperatorNamespace)
+
+		c, err = NewContext(Options{KubeConfig: kubeconfig, CephClusterNamespace: "storage", OperatorNamespace: "rook-operator"})
+		assert.NoError(t, err)
+		assert.Equal(t, "rook-operator", c.OperatorNamespace)
+	})
+
+	t.Run("context, timeout and impersonation", func(t *testing.T) {
+		c, err := NewContext(Options{
+			KubeConfig:        kubeconfig,
+			KubeContext:       "staging",
+			Timeout:           30 * time.Second,
+			Impersonate:       "system:serviceaccount:rook-ceph:plugin",
+			ImpersonateGroups: []string{"admins"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://staging.example.com:6443", c.Clientsets.KubeConfig.Host)
+		assert.Equal(t, 30*time.Second, c.Clientsets.KubeConfig.Timeout)
+		assert.Equal(t, "system:serviceaccount:rook-ceph:plugin", c.Clientsets.KubeConfig.Impersonate.UserName)
+		assert.Equal(t, []string{"admins"}, c.Clientsets.KubeConfig.Impersonate.Groups)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewContext(Options{KubeConfig: kubeconfig, ImpersonateGroups: []string{"admins"}})
+		assert.Error(t, err)
+
+		_, err = NewContext(Options{KubeConfig: kubeconfig, KubeContext: "dev"})
+		assert.Error(t, err)
+	})
+}
+
+func TestPreflight(t *testing.T) {
+	ctx := context.TODO()
+	newContext := func(namespaces ...string) *Context {
+		kube := fake.NewSimpleClientset()
+		for _, ns := range namespaces {
+			_, err := kube.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: ns}}, v1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+		return &Context{Clientsets: &Clientsets{Kube: kube}, OperatorNamespace: "rook-operator", CephClusterNamespace: "rook-ceph"}
+	}
+
+	t.Run("namespaces exist", func(t *testing.T) {
+		assert.NoError(t, newContext("rook-operator", "rook-ceph").Preflight(ctx, time.Second))
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		err := newContext("rook-ceph").Preflight(ctx, time.Second)
+		assert.EqualError(t, err, `operator namespace "rook-operator" does not exist, set it with --operator-namespace`)
+
+		err = newContext("rook-operator").Preflight(ctx, time.Second)
+		assert.EqualError(t, err, `CephCluster namespace "rook-ceph" does not exist, set it with --namespace`)
+	})
+
+	t.Run("api server not reachable", func(t *testing.T) {
+		c := newContext("rook-operator", "rook-ceph")
+		c.Clientsets.KubeConfig = &rest.Config{Host: "https://prod.example.com:6443"}
+		c.Clientsets.Kube.(*fake.Clientset).PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, context.DeadlineExceeded
+		})
+		err := c.Preflight(ctx, time.Second)
+		assert.ErrorContains(t, err, "the kubernetes api server https://prod.example.com:6443 is not reachable within 1s")
+	})
+
+	t.Run("api server error", func(t *testing.T) {
+		c := newContext("rook-operator", "rook-ceph")
+		c.Clientsets.Kube.(*fake.Clientset).PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, kerrors.NewForbidden(corev1.Resource("namespaces"), "rook-operator", fmt.Errorf("denied"))
+		})
+		err := c.Preflight(ctx, time.Second)
+		assert.ErrorContains(t, err, `failed to get the operator namespace "rook-operator"`)
+	})
+}
+
+func TestListPodPages(t *testing.T) {
+	ctx := context.TODO()
+	var allPods []corev1.Pod
+	for i := 0; i < 2*PodListPageSize+10; i++ {
+		allPods = append(allPods, corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+	}
+
+	// the pages are served from the offset in the continue token, like the api server
+	var requests []v1.ListOptions
+	list := func(_ context.Context, opts v1.ListOptions) (*corev1.PodList, error) {
+		requests = append(requests, opts)
+		start := 0
+		if opts.Continue != "" {
+			start, _ = strconv.Atoi(opts.Continue)
+		}
+		end := len(allPods)
+		if opts.Limit != 0 && start+int(opts.Limit) < end {
+			end = start + int(opts.Limit)
+		}
+		page := &corev1.PodList{Items: allPods[start:end]}
+		if end < len(allPods) {
+			page.Continue = strconv.Itoa(end)
+		}
+		return page, nil
+	}
+
+	pods, err := listPodPages(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"}, list)
+	assert.NoError(t, err)
+	assert.Equal(t, allPods, pods)
+	assert.Len(t, requests, 3)
+	for _, opts := range requests {
+		assert.Equal(t, "app=rook-ceph-osd", opts.LabelSelector)
+		assert.Equal(t, int64(PodListPageSize), opts.Limit)
+	}
+	assert.Equal(t, []string{"", "500", "1000"}, []string{requests[0].Continue, requests[1].Continue, requests[2].Continue})
+
+	t.Run("expired continue token", func(t *testing.T) {
+		requests = nil
+		expiring := func(ctx context.Context, opts v1.ListOptions) (*corev1.PodList, error) {
+			if opts.Continue != "" {
+				requests = append(requests, opts)
+				return nil, kerrors.NewResourceExpired("the continue token has expired")
+			}
+			return list(ctx, opts)
+		}
+		pods, err := listPodPages(ctx, v1.ListOptions{}, expiring)
+		assert.NoError(t, err)
+		assert.Equal(t, allPods, pods)
+		// the first page, the expired page and the full list
+		assert.Len(t, requests, 3)
+		assert.Equal(t, int64(0), requests[2].Limit)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := listPodPages(ctx, v1.ListOptions{}, func(context.Context, v1.ListOptions) (*corev1.PodList, error) {
+			return nil, kerrors.NewForbidden(corev1.Resource("pods"), "", fmt.Errorf("denied"))
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AssumeYes skips the confirmation of the destructive commands, it's set with the --yes flag
+var AssumeYes bool
+
+// stdin is shared by the prompts, a reader by prompt would buffer and lose the answers of the next prompts when
+// they are piped
+var stdin = bufio.NewReader(os.Stdin)
+
+// Confirm asks the user to type the cluster name before a destructive operation, and returns an
+// error when the answer doesn't match. The prompt is skipped with --yes or ROOK_PLUGIN_SKIP_PROMPTS=true.
+func Confirm(clusterName, operation string, args ...interface{}) error {
+	return confirm(stdin, clusterName, fmt.Sprintf(operation, args...))
+}
+
+func confirm(in *bufio.Reader, clusterName, operation string) error {
+	if AssumeYes {
+		Info("skipped prompt since --yes was passed")
+		return nil
+	}
+	if skip, ok := os.LookupEnv("ROOK_PLUGIN_SKIP_PROMPTS"); ok && skip == "true" {
+		Info("skipped prompt since ROOK_PLUGIN_SKIP_PROMPTS=true")
+		return nil
+	}
+
+	Warning("%s. Type the cluster name %q to confirm, or pass --yes to skip this prompt", operation, clusterName)
+	answer, _ := in.ReadString('\n')
+	if strings.TrimSpace(answer) != clusterName {
+		return fmt.Errorf("cancelled")
+	}
+	return nil
+}
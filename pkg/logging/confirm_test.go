@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	t.Setenv("ROOK_PLUGIN_SKIP_PROMPTS", "false")
+
+	assert.NoError(t, confirm(newReader("rook-ceph\n"), "rook-ceph", "Purging osd 0"))
+	assert.Error(t, confirm(newReader("yes\n"), "rook-ceph", "Purging osd 0"))
+	assert.Error(t, confirm(newReader(""), "rook-ceph", "Purging osd 0"))
+
+	AssumeYes = true
+	defer func() { AssumeYes = false }()
+	assert.NoError(t, confirm(newReader(""), "rook-ceph", "Purging osd 0"))
+	AssumeYes = false
+
+	t.Setenv("ROOK_PLUGIN_SKIP_PROMPTS", "true")
+	assert.NoError(t, confirm(newReader(""), "rook-ceph", "Purging osd 0"))
+}
+
+func TestConfirmReadsEachAnswer(t *testing.T) {
+	t.Setenv("ROOK_PLUGIN_SKIP_PROMPTS", "false")
+
+	// the answers of the next prompts are piped with the first one
+	in := newReader("rook-ceph\nrook-ceph\nno\n")
+	assert.NoError(t, confirm(in, "rook-ceph", "Purging osd 0"))
+	assert.NoError(t, confirm(in, "rook-ceph", "Purging osd 1"))
+	assert.Error(t, confirm(in, "rook-ceph", "Purging osd 2"))
+	assert.Error(t, confirm(in, "rook-ceph", "Purging osd 3"))
+}
+
+func newReader(input string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(input))
+}
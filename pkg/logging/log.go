@@ -21,13 +21,14 @@ import (
 	"os"
 
 	"github.com/fatih/color"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
 )
 
 func Info(output string, args ...interface{}) {
 	blue := color.New(color.FgBlue).SprintFunc()
 	if output != "" {
 		fmt.Fprintf(os.Stderr, blue("Info: "))
-		fmt.Fprintf(os.Stderr, output, args...)
+		printAnonymized(output, args...)
 	}
 
 	fmt.Fprintf(os.Stderr, "\n")
@@ -36,21 +37,26 @@ func Info(output string, args ...interface{}) {
 func Warning(output string, args ...interface{}) {
 	yellow := color.New(color.FgYellow).SprintFunc()
 	fmt.Fprintf(os.Stderr, yellow("Warning: "))
-	fmt.Fprintf(os.Stderr, output, args...)
+	printAnonymized(output, args...)
 	fmt.Fprintf(os.Stderr, "\n")
 }
 
 func Error(err error, args ...interface{}) {
 	red := color.New(color.FgRed).SprintFunc()
 	fmt.Fprintf(os.Stderr, red("Error: "))
-	fmt.Fprintf(os.Stderr, err.Error(), args...)
+	printAnonymized(err.Error(), args...)
 	fmt.Fprintf(os.Stderr, "\n")
 }
 
 func Fatal(err error, args ...interface{}) {
 	red := color.New(color.FgRed).SprintFunc()
 	fmt.Fprintf(os.Stderr, red("Error: "))
-	fmt.Fprintf(os.Stderr, err.Error(), args...)
+	printAnonymized(err.Error(), args...)
 	fmt.Fprintf(os.Stderr, "\n")
 	os.Exit(1)
 }
+
+// printAnonymized prints the message to stderr, with the identifiers masked when the output is anonymized
+func printAnonymized(format string, args ...interface{}) {
+	fmt.Fprint(os.Stderr, output.Anonymize(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	initialProgressInterval = 5 * time.Second
+	maxProgressInterval     = time.Minute
+)
+
+// Progress reports the elapsed time and current state of a long running wait. On a terminal the
+// state is printed on a single updating line, otherwise a line is printed at exponentially
+// increasing intervals to avoid flooding the logs.
+type Progress struct {
+	message    string
+	start      time.Time
+	isTerminal bool
+	reported   bool
+	nextReport time.Duration
+	interval   time.Duration
+	// now returns the current time, it's replaced in the tests
+	now func() time.Time
+}
+
+func NewProgress(message string, args ...interface{}) *Progress {
+	return newProgress(fmt.Sprintf(message, args...), isatty.IsTerminal(os.Stderr.Fd()), time.Now)
+}
+
+func newProgress(message string, isTerminal bool, now func() time.Time) *Progress {
+	return &Progress{
+		message:    message,
+		start:      now(),
+		isTerminal: isTerminal,
+		interval:   initialProgressInterval,
+		now:        now,
+	}
+}
+
+// Update reports the current state of the wait
+func (p *Progress) Update(state string) {
+	elapsed := p.now().Sub(p.start)
+	if p.isTerminal {
+		blue := color.New(color.FgBlue).SprintFunc()
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", blue("Info: "))
+		printAnonymized("%s (%s elapsed): %s", p.message, elapsed.Round(time.Second), state)
+		p.reported = true
+		return
+	}
+
+	if elapsed < p.nextReport {
+		return
+	}
+	Info("%s (%s elapsed): %s", p.message, elapsed.Round(time.Second), state)
+	p.reported = true
+	p.nextReport = elapsed + p.interval
+	p.interval *= 2
+	if p.interval > maxProgressInterval {
+		p.interval = maxProgressInterval
+	}
+}
+
+// Done reports the final state of the wait. Nothing is printed if the wait finished before
+// any progress was reported.
+func (p *Progress) Done(state string) {
+	if !p.reported {
+		return
+	}
+	if p.isTerminal {
+		p.Update(state)
+		fmt.Fprintf(os.Stderr, "\n")
+		return
+	}
+	Info("%s (%s elapsed): %s", p.message, p.now().Sub(p.start).Round(time.Second), state)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureStderr returns what the function printed to stderr
+func captureStderr(t *testing.T, f func()) string {
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	stderr := os.Stderr
+	os.Stderr = file
+	f()
+	os.Stderr = stderr
+
+	_, err = file.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	out, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	return string(out)
+}
+
+func TestProgressSchedule(t *testing.T) {
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	progress := newProgress("waiting for the osd", false, func() time.Time { return now })
+
+	// an update is reported at 0s, then at intervals doubling from 5s up to 1m
+	var reported []time.Duration
+	for elapsed := time.Duration(0); elapsed <= 4*time.Minute; elapsed += time.Second {
+		now = time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC).Add(elapsed)
+		if captureStderr(t, func() { progress.Update("pending") }) != "" {
+			reported = append(reported, elapsed)
+		}
+	}
+	assert.Equal(t, []time.Duration{0, 5 * time.Second, 15 * time.Second, 35 * time.Second, 75 * time.Second,
+		135 * time.Second, 195 * time.Second}, reported)
+
+	out := captureStderr(t, func() { progress.Done("running") })
+	assert.Contains(t, out, "waiting for the osd (4m0s elapsed): running")
+}
+
+func TestProgressDone(t *testing.T) {
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	// a wait that finished before any progress was reported prints nothing
+	assert.Empty(t, captureStderr(t, func() { newProgress("waiting for the osd", false, clock).Done("running") }))
+	assert.Empty(t, captureStderr(t, func() { newProgress("waiting for the osd", true, clock).Done("running") }))
+
+	progress := newProgress("waiting for the osd", true, clock)
+	now = now.Add(2 * time.Second)
+	out := captureStderr(t, func() {
+		progress.Update("pending")
+		progress.Done("running")
+	})
+	assert.Contains(t, out, "\r\033[K")
+	assert.Contains(t, out, "waiting for the osd (2s elapsed): running\n")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// Stat is the summary of the mgr map of `ceph mgr stat`, it's served by the mons so it's known even when the
+// active mgr is hung
+type Stat struct {
+	Epoch      int    `json:"epoch"`
+	Available  bool   `json:"available"`
+	ActiveName string `json:"active_name"`
+	NumStandby int    `json:"num_standby"`
+}
+
+// GetStat returns the active mgr and the number of standby mgrs
+func GetStat(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*Stat, error) {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"mgr", "stat", "--format", "json"}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the mgr status. %v", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to get the mgr status. %s", strings.TrimSpace(result.Stderr))
+	}
+	return parseStat(result.Stdout)
+}
+
+func parseStat(statOut string) (*Stat, error) {
+	var stat Stat
+	err := json.Unmarshal([]byte(statOut), &stat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph mgr stat output. %v", err)
+	}
+	return &stat, nil
+}
+
+// Failover fails the active mgr over to a standby mgr, and waits for the standby to become active
+func Failover(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, timeout time.Duration) {
+	err := failover(ctx, clientsets, operatorNamespace, clusterNamespace, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func failover(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, timeout time.Duration) error {
+	stat, err := GetStat(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	err = checkFailover(stat)
+	if err != nil {
+		return err
+	}
+
+	logging.Info("mgr.%s is active with %d standby mgrs", stat.ActiveName, stat.NumStandby)
+	err = logging.Confirm(clusterNamespace, "The active mgr %s is failed over to a standby mgr", stat.ActiveName)
+	if err != nil {
+		return fmt.Errorf("mgr failover cancelled")
+	}
+
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"mgr", "fail", stat.ActiveName}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to fail the mgr %s. %s", stat.ActiveName, strings.TrimSpace(result.Stderr))
+	}
+
+	progress := logging.NewProgress("waiting for a standby mgr to replace mgr.%s", stat.ActiveName)
+	for start := time.Now(); ; time.Sleep(pollInterval) {
+		newStat, err := GetStat(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err == nil && isFailedOver(stat, newStat) {
+			progress.Done("done")
+			logging.Info("mgr.%s is now active, mgr.%s restarts as a standby", newStat.ActiveName, stat.ActiveName)
+			return nil
+		}
+		if time.Since(start) >= timeout {
+			progress.Done("timed out")
+			return fmt.Errorf("timed out after %s waiting for a standby mgr to become active, check the logs of the mgr pods", timeout)
+		}
+		progress.Update("waiting")
+	}
+}
+
+// checkFailover returns an error when there is no active mgr to fail or no standby to fail over to
+func checkFailover(stat *Stat) error {
+	if !stat.Available || stat.ActiveName == "" {
+		return fmt.Errorf("no mgr is active, there is no mgr to fail over")
+	}
+	if stat.NumStandby == 0 {
+		return fmt.Errorf("mgr.%s has no standby mgr to fail over to, set spec.mgr.count to 2 in the CephCluster to run a standby mgr", stat.ActiveName)
+	}
+	return nil
+}
+
+// isFailedOver returns whether another mgr became active since the mgr map of the active mgr that was failed
+func isFailedOver(before, after *Stat) bool {
+	return after.Available && after.ActiveName != "" && after.ActiveName != before.ActiveName && after.Epoch > before.Epoch
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverStat(t *testing.T) {
+	stat, err := parseStat(`{"epoch": 12, "available": true, "active_name": "a", "num_standby": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, &Stat{Epoch: 12, Available: true, ActiveName: "a", NumStandby: 1}, stat)
+	assert.NoError(t, checkFailover(stat))
+
+	_, err = parseStat("")
+	assert.Error(t, err)
+
+	assert.EqualError(t, checkFailover(&Stat{Epoch: 12, Available: true, ActiveName: "a"}),
+		"mgr.a has no standby mgr to fail over to, set spec.mgr.count to 2 in the CephCluster to run a standby mgr")
+	assert.Error(t, checkFailover(&Stat{Epoch: 12}))
+
+	assert.False(t, isFailedOver(stat, &Stat{Epoch: 13, Available: false}))
+	assert.False(t, isFailedOver(stat, &Stat{Epoch: 12, Available: true, ActiveName: "a", NumStandby: 1}))
+	assert.True(t, isFailedOver(stat, &Stat{Epoch: 14, Available: true, ActiveName: "b"}))
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+const (
+	ModuleDashboard  = "dashboard"
+	ModulePrometheus = "prometheus"
+)
+
+// the interval `ceph mgr services` is polled at until the service of a module is up
+const pollInterval = 5 * time.Second
+
+type moduleList struct {
+	AlwaysOnModules []string `json:"always_on_modules"`
+	EnabledModules  []string `json:"enabled_modules"`
+	DisabledModules []struct {
+		Name        string `json:"name"`
+		CanRun      bool   `json:"can_run"`
+		ErrorString string `json:"error_string"`
+	} `json:"disabled_modules"`
+}
+
+// SetModule enables or disables the mgr module, and waits for the active mgr to start or stop its service
+func SetModule(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, module string, enable bool, timeout time.Duration) {
+	err := setModule(ctx, clientsets, operatorNamespace, clusterNamespace, module, enable, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func setModule(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, module string, enable bool, timeout time.Duration) error {
+	lsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"mgr", "module", "ls", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	modules, err := parseModuleList(lsOut)
+	if err != nil {
+		return err
+	}
+	enabled, err := modules.isEnabled(module, enable)
+	if err != nil {
+		return err
+	}
+
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err == nil {
+		if warning := getOperatorOverride(*cephCluster, module, enable); warning != "" {
+			logging.Warning(warning)
+		}
+	}
+
+	action := "enable"
+	if !enable {
+		action = "disable"
+	}
+	if enabled == enable {
+		logging.Info("the mgr module %s is already %sd", module, action)
+	} else {
+		if !enable {
+			err = logging.Confirm(clusterNamespace, "The mgr module %s is disabled and its service is stopped", module)
+			if err != nil {
+				return fmt.Errorf("disabling the mgr module %s cancelled", module)
+			}
+		}
+		result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"mgr", "module", action, module}, operatorNamespace, clusterNamespace)
+		if err != nil {
+			return err
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("failed to %s the mgr module %s. %s", action, module, strings.TrimSpace(result.Stderr))
+		}
+		logging.Info("mgr module %s %sd", module, action)
+	}
+
+	endpoint, err := waitForService(ctx, clientsets, operatorNamespace, clusterNamespace, module, enable, timeout)
+	if err != nil {
+		return err
+	}
+	if enable {
+		logging.Info("the mgr module %s is serving at %s", module, endpoint)
+	} else {
+		logging.Info("the mgr module %s stopped its service", module)
+	}
+	return nil
+}
+
+func parseModuleList(lsOut string) (*moduleList, error) {
+	var modules moduleList
+	err := json.Unmarshal([]byte(lsOut), &modules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph mgr module ls output. %v", err)
+	}
+	return &modules, nil
+}
+
+// isEnabled returns whether the module is enabled, or an error when it can't be set to the state
+func (m *moduleList) isEnabled(module string, enable bool) (bool, error) {
+	for _, name := range m.AlwaysOnModules {
+		if name != module {
+			continue
+		}
+		if !enable {
+			return false, fmt.Errorf("the mgr module %s is always on and can't be disabled", module)
+		}
+		return true, nil
+	}
+	for _, name := range m.EnabledModules {
+		if name == module {
+			return true, nil
+		}
+	}
+	for _, disabled := range m.DisabledModules {
+		if disabled.Name != module {
+			continue
+		}
+		if enable && !disabled.CanRun {
+			return false, fmt.Errorf("the mgr module %s can't run: %s", module, disabled.ErrorString)
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("mgr module %s not found", module)
+}
+
+// getOperatorOverride returns a warning when the operator sets the module back to the state of the CephCluster spec
+// on its next reconcile
+func getOperatorOverride(cephCluster cephv1.CephCluster, module string, enable bool) string {
+	for _, specModule := range cephCluster.Spec.Mgr.Modules {
+		if specModule.Name == module && specModule.Enabled != enable {
+			return fmt.Sprintf("the CephCluster %s has the mgr module %s with enabled: %t, the operator sets it back on its next reconcile. Update spec.mgr.modules to keep the change",
+				cephCluster.Name, module, specModule.Enabled)
+		}
+	}
+	switch {
+	case module == ModuleDashboard && cephCluster.Spec.Dashboard.Enabled != enable:
+		return fmt.Sprintf("the CephCluster %s has dashboard.enabled: %t, the operator sets the dashboard module back on its next reconcile. Update spec.dashboard.enabled to keep the change, the operator also creates the dashboard service and credentials once it's enabled",
+			cephCluster.Name, cephCluster.Spec.Dashboard.Enabled)
+	case module == ModulePrometheus && !enable:
+		return fmt.Sprintf("the operator enables the prometheus module of the CephCluster %s on its next reconcile", cephCluster.Name)
+	}
+	return ""
+}
+
+// waitForService polls `ceph mgr services` until the service of the module is up, or down when it's disabled, and
+// returns its endpoint
+func waitForService(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, module string, up bool, timeout time.Duration) (string, error) {
+	state := "up"
+	if !up {
+		state = "down"
+	}
+	progress := logging.NewProgress("waiting for the %s service of the active mgr to be %s", module, state)
+	for start := time.Now(); ; time.Sleep(pollInterval) {
+		servicesOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"mgr", "services", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+		// the mgr services are not available while the mgr restarts to load the module
+		endpoint, err := getServiceEndpoint(servicesOut, module)
+		if err == nil && (endpoint != "") == up {
+			progress.Done("done")
+			return endpoint, nil
+		}
+		if time.Since(start) >= timeout {
+			progress.Done("timed out")
+			return "", fmt.Errorf("timed out after %s waiting for the %s service to be %s, check the logs of the active mgr", timeout, module, state)
+		}
+		progress.Update("waiting")
+	}
+}
+
+// getServiceEndpoint returns the endpoint of the module in the `ceph mgr services` output, or "" when the module
+// has no service
+func getServiceEndpoint(servicesOut, module string) (string, error) {
+	var services map[string]string
+	err := json.Unmarshal([]byte(servicesOut), &services)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ceph mgr services output. %v", err)
+	}
+	return services[module], nil
+}
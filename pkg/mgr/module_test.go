@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestModuleListIsEnabled(t *testing.T) {
+	modules, err := parseModuleList(`{
+"always_on_modules": ["balancer", "crash"],
+"enabled_modules": ["dashboard", "iostat"],
+"disabled_modules": [
+	{"name": "prometheus", "can_run": true, "error_string": ""},
+	{"name": "diskprediction_local", "can_run": false, "error_string": "No module named 'sklearn'"}
+]}`)
+	assert.NoError(t, err)
+
+	enabled, err := modules.isEnabled("dashboard", false)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = modules.isEnabled("prometheus", true)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = modules.isEnabled("balancer", true)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	_, err = modules.isEnabled("balancer", false)
+	assert.EqualError(t, err, "the mgr module balancer is always on and can't be disabled")
+
+	_, err = modules.isEnabled("diskprediction_local", true)
+	assert.EqualError(t, err, "the mgr module diskprediction_local can't run: No module named 'sklearn'")
+	_, err = modules.isEnabled("diskprediction_local", false)
+	assert.NoError(t, err)
+
+	_, err = modules.isEnabled("unknown", true)
+	assert.Error(t, err)
+
+	_, err = parseModuleList("not json")
+	assert.Error(t, err)
+}
+
+func TestGetOperatorOverride(t *testing.T) {
+	cephCluster := cephv1.CephCluster{ObjectMeta: v1.ObjectMeta{Name: "my-cluster"}}
+	cephCluster.Spec.Dashboard.Enabled = true
+
+	assert.Empty(t, getOperatorOverride(cephCluster, ModuleDashboard, true))
+	assert.Contains(t, getOperatorOverride(cephCluster, ModuleDashboard, false), "the CephCluster my-cluster has dashboard.enabled: true")
+
+	assert.Empty(t, getOperatorOverride(cephCluster, ModulePrometheus, true))
+	assert.Contains(t, getOperatorOverride(cephCluster, ModulePrometheus, false), "the operator enables the prometheus module")
+
+	cephCluster.Spec.Mgr.Modules = []cephv1.Module{{Name: "pg_autoscaler", Enabled: true}}
+	assert.Empty(t, getOperatorOverride(cephCluster, "pg_autoscaler", true))
+	assert.Contains(t, getOperatorOverride(cephCluster, "pg_autoscaler", false), "the mgr module pg_autoscaler with enabled: true")
+	assert.Empty(t, getOperatorOverride(cephCluster, "iostat", false))
+}
+
+func TestGetServiceEndpoint(t *testing.T) {
+	servicesOut := `{"dashboard": "https://10.244.0.5:8443/", "prometheus": "http://10.244.0.5:9283/"}`
+	endpoint, err := getServiceEndpoint(servicesOut, ModuleDashboard)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.244.0.5:8443/", endpoint)
+
+	endpoint, err = getServiceEndpoint(`{}`, ModulePrometheus)
+	assert.NoError(t, err)
+	assert.Empty(t, endpoint)
+
+	_, err = getServiceEndpoint("", ModulePrometheus)
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const dataDirCheckApp = "rook-ceph-mon-datadir-check"
+
+// dataDirPodTimeout is how long to wait for the pod listing the data dirs of a node to run, the nodes that are not
+// ready are skipped rather than waited for
+const dataDirPodTimeout = 90 * time.Second
+
+func CheckDataDirs(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) {
+	err := checkDataDirs(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func checkDataDirs(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	dataDirHostPath := cephCluster.Spec.DataDirHostPath
+	if dataDirHostPath == "" {
+		return fmt.Errorf("dataDirHostPath is not set in CephCluster %s", cephCluster.Name)
+	}
+
+	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	monEndpoints, err := parseMonEndpoints(monCm.Data["data"])
+	if err != nil {
+		return err
+	}
+
+	monPods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-mon"})
+	if err != nil || len(monPods.Items) == 0 {
+		return fmt.Errorf("failed to list mon pods. %v", err)
+	}
+	monNodes := map[string]string{}
+	for _, pod := range monPods.Items {
+		monNodes[pod.Labels["ceph_daemon_id"]] = pod.Spec.NodeName
+	}
+	image := monPods.Items[0].Spec.Containers[0].Image
+
+	nodes, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes. %v", err)
+	}
+
+	monDirsByNode := map[string][]string{}
+	for _, node := range nodes.Items {
+		if reason := nodeUnavailableReason(node); reason != "" {
+			logging.Warning("skipping node %s since it's %s, its mon data dirs are not checked", node.Name, reason)
+			continue
+		}
+		monDirs, err := listMonDataDirs(ctx, clientsets, clusterNamespace, node.Name, dataDirHostPath, image)
+		if err != nil {
+			logging.Error(fmt.Errorf("failed to list mon data dirs on node %s. %v", node.Name, err))
+			continue
+		}
+		monDirsByNode[node.Name] = monDirs
+		for _, dir := range monDirs {
+			fmt.Printf("%s\t%s/%s\n", node.Name, dataDirHostPath, dir)
+		}
+	}
+	fmt.Println()
+
+	issues := findStaleMonDataDirs(monDirsByNode, monEndpoints, monNodes, dataDirHostPath)
+	if len(issues) == 0 {
+		logging.Info("no stale or duplicate mon data dirs found in %s", dataDirHostPath)
+		return nil
+	}
+	for _, issue := range issues {
+		logging.Warning(issue)
+	}
+	return nil
+}
+
+// findStaleMonDataDirs reports the mon data dirs of mons that are not in the mon endpoints configmap,
+// and the data dirs of current mons found on a node other than the node running the mon
+func findStaleMonDataDirs(monDirsByNode map[string][]string, monEndpoints, monNodes map[string]string, dataDirHostPath string) []string {
+	var nodeNames []string
+	for nodeName := range monDirsByNode {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	var issues []string
+	for _, nodeName := range nodeNames {
+		for _, dir := range monDirsByNode[nodeName] {
+			monName := strings.TrimPrefix(dir, "mon-")
+			path := fmt.Sprintf("%s/%s", dataDirHostPath, dir)
+			if _, ok := monEndpoints[monName]; !ok {
+				issues = append(issues, fmt.Sprintf("stale data dir %s on node %s: mon %s is not in the configmap %s", path, nodeName, monName, MonConfigMap))
+			} else if monNode, ok := monNodes[monName]; ok && monNode != nodeName {
+				issues = append(issues, fmt.Sprintf("duplicate data dir %s on node %s: mon %s is running on node %s", path, nodeName, monName, monNode))
+			}
+		}
+	}
+	return issues
+}
+
+// listMonDataDirs lists the mon data dirs in the dataDirHostPath of the node
+func listMonDataDirs(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, nodeName, dataDirHostPath, image string) ([]string, error) {
+	output, err := runInDataDirPod(ctx, clientsets, clusterNamespace, nodeName, dataDirHostPath, image, true, "ls", []string{"-1", dataDirHostPath})
+	if err != nil {
+		return nil, err
+	}
+
+	var monDirs []string
+	for _, dir := range strings.Split(output, "\n") {
+		if strings.HasPrefix(dir, "mon-") {
+			monDirs = append(monDirs, dir)
+		}
+	}
+	return monDirs, nil
+}
+
+// nodeUnavailableReason returns why the pod listing the data dirs can't run on the node, or an empty string if it can
+func nodeUnavailableReason(node corev1.Node) string {
+	if node.Spec.Unschedulable {
+		return "unschedulable"
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue {
+			return "not ready"
+		}
+	}
+	return ""
+}
+
+// runInDataDirPod starts a pod on the node mounting the dataDirHostPath, runs the command in it and deletes it. The
+// pod name is generated so that a pod left behind doesn't block the next run.
+func runInDataDirPod(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, nodeName, dataDirHostPath, image string, readOnly bool, cmd string, args []string) (string, error) {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			GenerateName: dataDirCheckApp + "-",
+			Namespace:    clusterNamespace,
+			Labels:       map[string]string{"app": dataDirCheckApp},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:         "check",
+				Image:        image,
+				Command:      []string{"sleep", "infinity"},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data-dir", MountPath: dataDirHostPath, ReadOnly: readOnly}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "data-dir",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: dataDirHostPath}},
+			}},
+		},
+	}
+
+	pod, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).Create(ctx, pod, v1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the data dir pod on node %s. %v", nodeName, err)
+	}
+	podName := pod.Name
+	defer func() {
+		err := clientsets.Kube.CoreV1().Pods(clusterNamespace).Delete(ctx, podName, v1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			logging.Warning("failed to delete pod %s, please delete it manually. %v", podName, err)
+		}
+	}()
+
+	err = waitForDataDirPod(ctx, clientsets, clusterNamespace, podName)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := exec.RunCommandInPodWithResult(ctx, clientsets, podName, "check", cmd, args, clusterNamespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s in pod %s. %v", cmd, podName, err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s failed in pod %s with exit code %d: %s", cmd, podName, result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+func waitForDataDirPod(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, podName string) error {
+	for start := time.Now(); time.Since(start) < dataDirPodTimeout; time.Sleep(2 * time.Second) {
+		pod, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).Get(ctx, podName, v1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s. %v", podName, err)
+		}
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return fmt.Errorf("pod %s is %s", podName, pod.Status.Phase)
+		}
+	}
+	return fmt.Errorf("timed out after %s waiting for pod %s to run", dataDirPodTimeout, podName)
+}
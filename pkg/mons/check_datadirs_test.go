@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFindStaleMonDataDirs(t *testing.T) {
+	monEndpoints := map[string]string{"a": "10.0.0.1:6789", "b": "10.0.0.2:6789", "c": "10.0.0.3:6789"}
+	monNodes := map[string]string{"a": "node-1", "b": "node-2", "c": "node-3"}
+
+	monDirsByNode := map[string][]string{
+		"node-1": {"mon-a"},
+		"node-2": {"mon-b"},
+		"node-3": {"mon-c"},
+	}
+	assert.Empty(t, findStaleMonDataDirs(monDirsByNode, monEndpoints, monNodes, "/var/lib/rook"))
+
+	monDirsByNode = map[string][]string{
+		"node-3": {"mon-c", "mon-d"},
+		"node-1": {"mon-a", "mon-b"},
+		"node-2": {"mon-b"},
+		"node-4": {},
+	}
+	assert.Equal(t, []string{
+		"duplicate data dir /var/lib/rook/mon-b on node node-1: mon b is running on node node-2",
+		"stale data dir /var/lib/rook/mon-d on node node-3: mon d is not in the configmap rook-ceph-mon-endpoints",
+	}, findStaleMonDataDirs(monDirsByNode, monEndpoints, monNodes, "/var/lib/rook"))
+
+	// a mon of the configmap without a pod is not reported as duplicate
+	delete(monNodes, "b")
+	assert.Empty(t, findStaleMonDataDirs(map[string][]string{"node-1": {"mon-b"}}, monEndpoints, monNodes, "/var/lib/rook"))
+}
+
+func TestNodeUnavailableReason(t *testing.T) {
+	ready := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}
+	notReady := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}
+
+	assert.Equal(t, "", nodeUnavailableReason(corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{ready}}}))
+	assert.Equal(t, "not ready", nodeUnavailableReason(corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{notReady}}}))
+	assert.Equal(t, "unschedulable", nodeUnavailableReason(corev1.Node{
+		Spec:   corev1.NodeSpec{Unschedulable: true},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{ready}},
+	}))
+}
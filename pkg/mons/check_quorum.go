@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type quorumStatus struct {
+	QuorumNames []string `json:"quorum_names"`
+	MonMap      monMap   `json:"monmap"`
+}
+
+type monMap struct {
+	Mons []monMapEntry `json:"mons"`
+}
+
+type monMapEntry struct {
+	Name       string `json:"name"`
+	PublicAddr string `json:"public_addr"`
+}
+
+// monSources are the mon names known by ceph and by kubernetes
+type monSources struct {
+	monMap    []string
+	quorum    []string
+	configMap []string
+	pods      []string
+}
+
+func CheckQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	err := checkQuorum(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func checkQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) error {
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	sources := monSources{quorum: status.QuorumNames}
+	for _, mon := range status.MonMap.Mons {
+		sources.monMap = append(sources.monMap, mon.Name)
+	}
+
+	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	monEndpoints, err := parseMonEndpoints(monCm.Data["data"])
+	if err != nil {
+		return err
+	}
+	for name := range monEndpoints {
+		sources.configMap = append(sources.configMap, name)
+	}
+
+	pods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-mon"})
+	if err != nil {
+		return fmt.Errorf("failed to list mon pods. %v", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() {
+			sources.pods = append(sources.pods, pod.Labels["ceph_daemon_id"])
+		}
+	}
+
+	fmt.Printf("MON\tMONMAP\tQUORUM\tCONFIGMAP\tPOD\n")
+	for _, name := range sources.allMons() {
+		fmt.Printf("%s\t%t\t%t\t%t\t%t\n", name, contains(sources.monMap, name), contains(sources.quorum, name), contains(sources.configMap, name), contains(sources.pods, name))
+	}
+	fmt.Println()
+
+	issues := sources.compare()
+	if len(issues) == 0 {
+		logging.Info("mon quorum, monmap, configmap %s and mon pods are consistent", MonConfigMap)
+		return nil
+	}
+	for _, issue := range issues {
+		logging.Warning(issue)
+	}
+	return nil
+}
+
+// allMons returns the sorted names of the mons found in any of the sources
+func (s monSources) allMons() []string {
+	names := map[string]bool{}
+	for _, list := range [][]string{s.monMap, s.quorum, s.configMap, s.pods} {
+		for _, name := range list {
+			names[name] = true
+		}
+	}
+
+	var allMons []string
+	for name := range names {
+		allMons = append(allMons, name)
+	}
+	sort.Strings(allMons)
+	return allMons
+}
+
+// compare reports every mon that is missing from one of the sources
+func (s monSources) compare() []string {
+	var issues []string
+	for _, name := range s.allMons() {
+		if !contains(s.monMap, name) {
+			issues = append(issues, fmt.Sprintf("mon %s is not in the ceph monmap", name))
+		}
+		if contains(s.monMap, name) && !contains(s.quorum, name) {
+			issues = append(issues, fmt.Sprintf("mon %s is not in quorum", name))
+		}
+		if !contains(s.configMap, name) {
+			issues = append(issues, fmt.Sprintf("mon %s is not in the configmap %s", name, MonConfigMap))
+		}
+		if !contains(s.pods, name) {
+			issues = append(issues, fmt.Sprintf("mon %s has no running pod", name))
+		}
+	}
+	return issues
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getQuorumStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (*quorumStatus, error) {
+	quorumOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"quorum_status", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+
+	var status quorumStatus
+	err := json.Unmarshal([]byte(quorumOut), &status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mon quorum status. %v", err)
+	}
+	return &status, nil
+}
+
+func getQuorumNames(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]string, error) {
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return status.QuorumNames, nil
+}
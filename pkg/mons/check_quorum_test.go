@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareMonSources(t *testing.T) {
+	sources := monSources{
+		monMap:    []string{"a", "b", "c"},
+		quorum:    []string{"a", "b", "c"},
+		configMap: []string{"c", "b", "a"},
+		pods:      []string{"a", "b", "c"},
+	}
+	assert.Empty(t, sources.compare())
+
+	sources = monSources{
+		monMap:    []string{"a", "b", "c"},
+		quorum:    []string{"a", "b"},
+		configMap: []string{"a", "b", "d"},
+		pods:      []string{"a", "b", "d"},
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, sources.allMons())
+	assert.Equal(t, []string{
+		"mon c is not in quorum",
+		"mon c is not in the configmap rook-ceph-mon-endpoints",
+		"mon c has no running pod",
+		"mon d is not in the ceph monmap",
+	}, sources.compare())
+}
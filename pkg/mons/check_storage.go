@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func CheckStorage(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) {
+	warnings, err := checkStorage(ctx, k8sclientset, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	for _, warning := range warnings {
+		logging.Warning(warning)
+	}
+}
+
+// checkStorage prints the storage class and size of the mon PVCs, and returns warnings when they differ between the mons
+func checkStorage(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace string) ([]string, error) {
+	pvcs, err := k8sclientset.CoreV1().PersistentVolumeClaims(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-mon"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the mon PVCs. %v", err)
+	}
+	if len(pvcs.Items) == 0 {
+		logging.Info("no mon PVCs found, the mons are storing their data on the host")
+		return nil, nil
+	}
+
+	storageClasses := map[string][]string{}
+	sizes := map[string][]string{}
+	fmt.Printf("PVC\tSTORAGECLASS\tSIZE\n")
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		storageClass := getStorageClassName(pvc)
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		fmt.Printf("%s\t%s\t%s\n", pvc.Name, storageClass, size.String())
+		storageClasses[storageClass] = append(storageClasses[storageClass], pvc.Name)
+		sizes[size.String()] = append(sizes[size.String()], pvc.Name)
+	}
+	fmt.Println()
+
+	var warnings []string
+	if len(storageClasses) > 1 {
+		warnings = append(warnings, fmt.Sprintf("the mon PVCs use %d storage classes %s, which can cause uneven mon performance", len(storageClasses), formatGroups(storageClasses)))
+	}
+	if len(sizes) > 1 {
+		warnings = append(warnings, fmt.Sprintf("the mon PVCs have %d sizes %s", len(sizes), formatGroups(sizes)))
+	}
+	if len(warnings) == 0 {
+		logging.Info("all %d mon PVCs use the same storage class and size", len(pvcs.Items))
+	}
+	return warnings, nil
+}
+
+func getStorageClassName(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return "<default>"
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// formatGroups formats the PVC names grouped by value, such as "[gp2: a,b] [gp3: c]"
+func formatGroups(groups map[string][]string) string {
+	var values []string
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var formatted string
+	for _, value := range values {
+		sort.Strings(groups[value])
+		formatted += fmt.Sprintf("[%s: %v] ", value, groups[value])
+	}
+	return formatted[:len(formatted)-1]
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckStorage(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	newPvc := func(name, storageClass, size string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": "rook-ceph-mon"}},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &storageClass,
+				Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+			},
+		}
+	}
+
+	warnings, err := checkStorage(ctx, fake.NewSimpleClientset(), ns)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	k8s := fake.NewSimpleClientset(newPvc("rook-ceph-mon-a", "gp3", "10Gi"), newPvc("rook-ceph-mon-b", "gp3", "10Gi"), newPvc("rook-ceph-mon-c", "gp3", "10Gi"))
+	warnings, err = checkStorage(ctx, k8s, ns)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	k8s = fake.NewSimpleClientset(newPvc("rook-ceph-mon-a", "gp3", "10Gi"), newPvc("rook-ceph-mon-b", "gp2", "10Gi"), newPvc("rook-ceph-mon-c", "gp3", "20Gi"))
+	warnings, err = checkStorage(ctx, k8s, ns)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "[gp2: [rook-ceph-mon-b]] [gp3: [rook-ceph-mon-a rook-ceph-mon-c]]")
+	assert.Contains(t, warnings[1], "[10Gi: [rook-ceph-mon-a rook-ceph-mon-b]] [20Gi: [rook-ceph-mon-c]]")
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// maxMonCount is the maximum mon count of the CephCluster CRD
+	maxMonCount = 9
+	// stretchMonCount is the mon count rook requires for a stretch cluster, two mons in each zone and the arbiter
+	stretchMonCount = 5
+)
+
+// ShowCount prints the mon count of the CephCluster spec, and the mons of the monmap and of the quorum
+func ShowCount(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	err := showCount(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func showCount(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("spec:\t%d\n", cephCluster.Spec.Mon.Count)
+	fmt.Printf("monmap:\t%d\n", len(status.MonMap.Mons))
+	fmt.Printf("quorum:\t%d (%s)\n", len(status.QuorumNames), strings.Join(status.QuorumNames, ","))
+	if len(status.MonMap.Mons) != cephCluster.Spec.Mon.Count {
+		logging.Warning("the monmap has %d mons while the CephCluster spec has %d, the operator adds or removes mons until they match",
+			len(status.MonMap.Mons), cephCluster.Spec.Mon.Count)
+	}
+	return nil
+}
+
+// SetCount sets the mon count of the CephCluster spec, after validating the count
+func SetCount(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, count int) {
+	err := setCount(ctx, clientsets.Rook, clusterNamespace, count)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func setCount(ctx context.Context, rookclientset rookclient.Interface, clusterNamespace string, count int) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, rookclientset, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	warnings, err := validateMonCount(count, cephCluster.Spec.Mon)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		logging.Warning(warning)
+	}
+
+	current := cephCluster.Spec.Mon.Count
+	if current == count {
+		logging.Info("the mon count of CephCluster %s is already %d", cephCluster.Name, count)
+		return nil
+	}
+	if count < current {
+		err = logging.Confirm(clusterNamespace, "The mon count of CephCluster %s is lowered from %d to %d, the operator removes %d mons", cephCluster.Name, current, count, current-count)
+		if err != nil {
+			return fmt.Errorf("setting the mon count cancelled")
+		}
+	}
+
+	err = patchMonCount(ctx, rookclientset, cephCluster, count)
+	if err != nil {
+		return err
+	}
+	logging.Info("mon count of CephCluster %s set from %d to %d, check the mons join the quorum with `kubectl rook-ceph mons count`", cephCluster.Name, current, count)
+	return nil
+}
+
+// validateMonCount returns an error when rook doesn't support the count, and warnings when it's supported but
+// not recommended
+func validateMonCount(count int, spec cephv1.MonSpec) ([]string, error) {
+	if count < 1 || count > maxMonCount {
+		return nil, fmt.Errorf("invalid mon count %d, the count must be between 1 and %d", count, maxMonCount)
+	}
+	if spec.StretchCluster != nil && count != stretchMonCount {
+		return nil, fmt.Errorf("invalid mon count %d, a stretch cluster requires %d mons", count, stretchMonCount)
+	}
+
+	var warnings []string
+	if count%2 == 0 {
+		warnings = append(warnings, fmt.Sprintf("an even mon count is not recommended, %d mons tolerate as many mon failures as %d mons", count, count-1))
+	}
+	if count == 1 {
+		warnings = append(warnings, "a single mon is a single point of failure, losing it loses the cluster")
+	}
+	return warnings, nil
+}
+
+func patchMonCount(ctx context.Context, rookclientset rookclient.Interface, cephCluster *cephv1.CephCluster, count int) error {
+	patch := fmt.Sprintf(`{"spec":{"mon":{"count":%d}}}`, count)
+	_, err := rookclientset.CephV1().CephClusters(cephCluster.Namespace).Patch(ctx, cephCluster.Name, types.MergePatchType, []byte(patch), v1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to set the mon count of CephCluster %s. %v", cephCluster.Name, err)
+	}
+	return nil
+}
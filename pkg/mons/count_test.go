@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateMonCount(t *testing.T) {
+	warnings, err := validateMonCount(3, cephv1.MonSpec{})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = validateMonCount(4, cephv1.MonSpec{})
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "even")
+
+	warnings, err = validateMonCount(1, cephv1.MonSpec{})
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	for _, count := range []int{-1, 0, 10} {
+		_, err = validateMonCount(count, cephv1.MonSpec{})
+		assert.Error(t, err, count)
+	}
+
+	stretch := cephv1.MonSpec{StretchCluster: &cephv1.StretchClusterSpec{}}
+	_, err = validateMonCount(3, stretch)
+	assert.Error(t, err)
+	_, err = validateMonCount(5, stretch)
+	assert.NoError(t, err)
+}
+
+func TestSetCount(t *testing.T) {
+	ctx := context.TODO()
+	rook := rookfake.NewSimpleClientset(&cephv1.CephCluster{
+		ObjectMeta: v1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+		Spec:       cephv1.ClusterSpec{Mon: cephv1.MonSpec{Count: 3, AllowMultiplePerNode: true}},
+	})
+
+	assert.NoError(t, setCount(ctx, rook, "rook-ceph", 5))
+	cephCluster, err := rook.CephV1().CephClusters("rook-ceph").Get(ctx, "my-cluster", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cephCluster.Spec.Mon.Count)
+	// the rest of the mon spec is kept
+	assert.True(t, cephCluster.Spec.Mon.AllowMultiplePerNode)
+
+	assert.Error(t, setCount(ctx, rook, "rook-ceph", 0))
+	assert.Error(t, setCount(ctx, rook, "other", 3))
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
 
@@ -41,3 +42,36 @@ func GetMonEndpoint(ctx context.Context, k8sclientset kubernetes.Interface, clus
 	}
 	return reg.ReplaceAllLiteralString(monData, "")
 }
+
+// parseMonEndpoints parses the "data" field of the mon endpoints configmap, such as
+// "a=10.96.52.53:6789,b=10.96.52.54:6789", into a map of mon name to endpoint.
+func parseMonEndpoints(monData string) (map[string]string, error) {
+	endpoints := map[string]string{}
+	for _, m := range strings.Split(monData, ",") {
+		if m == "" {
+			continue
+		}
+		monName, monEndpoint, ok := strings.Cut(m, "=")
+		if !ok {
+			return nil, fmt.Errorf("failed to parse mon endpoint %q", m)
+		}
+		endpoints[monName] = monEndpoint
+	}
+	return endpoints, nil
+}
+
+// monNameToId returns the id of a mon named by rook, the names are the ids in base 26 such as "a" for 0 and "aa" for
+// 26, like the mon ids of the "maxMonId" field of the mon endpoints configmap
+func monNameToId(name string) (int, error) {
+	if name == "" {
+		return -1, fmt.Errorf("empty mon name")
+	}
+	id := 0
+	for _, c := range name {
+		if c < 'a' || c > 'z' {
+			return -1, fmt.Errorf("invalid char %q in mon name %q", c, name)
+		}
+		id = id*26 + int(c-'a') + 1
+	}
+	return id - 1, nil
+}
@@ -54,3 +54,16 @@ func TestParseMonEndpoint(t *testing.T) {
 	assert.NotEqual(t, "10.96.52.54:6789", monData)
 
 }
+
+func TestParseMonEndpoints(t *testing.T) {
+	endpoints, err := parseMonEndpoints("a=10.96.52.53:6789,b=10.96.52.54:6789")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "10.96.52.53:6789", "b": "10.96.52.54:6789"}, endpoints)
+
+	endpoints, err = parseMonEndpoints("")
+	assert.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	_, err = parseMonEndpoints("10.96.52.53:6789")
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/debug"
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func RebuildStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monName string, dryRun bool) {
+	err := rebuildStore(ctx, clientsets, operatorNamespace, clusterNamespace, monName, dryRun)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func rebuildStore(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, monName string, dryRun bool) error {
+	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+
+	monEndpoints, err := parseMonEndpoints(monCm.Data["data"])
+	if err != nil {
+		return err
+	}
+
+	if _, ok := monEndpoints[monName]; !ok {
+		return fmt.Errorf("mon %s not found in configmap %s", monName, MonConfigMap)
+	}
+
+	quorumNames, err := getQuorumNames(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	// the store can only be reset from the peers when they are able to serve the latest maps
+	var peerHosts []string
+	for _, name := range quorumNames {
+		if name != monName {
+			peerHosts = append(peerHosts, monEndpoints[name])
+		}
+	}
+	if len(peerHosts) == 0 || len(peerHosts) <= len(monEndpoints)/2 {
+		return fmt.Errorf("mon store can only be rebuilt when the surviving mons are in quorum, current quorum: %v", quorumNames)
+	}
+	sort.Strings(peerHosts)
+
+	monDataPath := fmt.Sprintf("/var/lib/ceph/mon/ceph-%s", monName)
+	backupPath := fmt.Sprintf("%s/store.db.corrupt-%d", monDataPath, time.Now().Unix())
+	script := rebuildStoreScript(monName, monDataPath, backupPath, strings.Join(peerHosts, ","))
+
+	logging.Info("Rebuilding the store of mon %s from the peer mons %s\n", monName, strings.Join(peerHosts, ","))
+	logging.Info("The current store will be moved to %s\n", backupPath)
+	logging.Info("The following script will be run in the debug pod of mon %s:\n%s", monName, script)
+
+	if dryRun {
+		logging.Info("dry-run: no changes were made")
+		return nil
+	}
+
+	err = logging.Confirm(clusterNamespace, "Rebuilding the store of mon %s replaces its store with the store rebuilt from the mons in quorum", monName)
+	if err != nil {
+		return fmt.Errorf("rebuilding the store of mon %s cancelled", monName)
+	}
+	logging.Info("proceeding with rebuilding the mon store")
+
+	logging.Info("Waiting for operator pod to stop")
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 0)
+	if err != nil {
+		return fmt.Errorf("failed to stop deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled down")
+
+	// the debug pod is stopped and the operator restarted even when the script fails, so the mon is not left in
+	// its debug deployment
+	deploymentName := fmt.Sprintf("rook-ceph-mon-%s", monName)
+	scriptErr := debug.StartDebugWithError(ctx, clientsets.Kube, clusterNamespace, deploymentName, "")
+	if scriptErr == nil {
+		scriptErr = runRebuildStoreScript(ctx, clientsets, clusterNamespace, deploymentName, script)
+	}
+
+	logging.Info("Stopping the debug pod for mon %s.\n", monName)
+	if err := debug.StopDebugWithError(ctx, clientsets.Kube, clusterNamespace, deploymentName); err != nil {
+		logging.Warning("failed to stop the debug pod of mon %s, restore it with `kubectl rook-ceph debug stop %s`. %v", monName, deploymentName, err)
+	}
+
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 1)
+	if err != nil {
+		return fmt.Errorf("failed to start deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled up")
+
+	if scriptErr != nil {
+		return fmt.Errorf("failed to rebuild the store of mon %s, the original store is in %s/store.db, or in %s if the script already moved it. %v",
+			monName, monDataPath, backupPath, scriptErr)
+	}
+	logging.Info("The store of mon %s was rebuilt, the mon will sync the latest maps from its peers when it joins the quorum", monName)
+	logging.Info("Remove %s once the mon is healthy", backupPath)
+	return nil
+}
+
+// runRebuildStoreScript runs the script in the debug pod of the mon, and returns an error when the pod doesn't run or
+// the script fails
+func runRebuildStoreScript(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, deploymentName, script string) error {
+	debugDeploymentSpec, err := k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, fmt.Sprintf("%s-debug", deploymentName))
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %s-debug. %v", deploymentName, err)
+	}
+
+	labelSelector := fmt.Sprintf("ceph_daemon_type=%s,ceph_daemon_id=%s", debugDeploymentSpec.Spec.Template.Labels["ceph_daemon_type"], debugDeploymentSpec.Spec.Template.Labels["ceph_daemon_id"])
+	_, err = k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("failed to start deployment %s-debug. %v", deploymentName, err)
+	}
+
+	logging.Info("Started debug pod, rebuilding the mon store in the debug pod")
+	result, err := exec.RunCommandInLabeledPodWithResult(ctx, clientsets, labelSelector, "mon", "/bin/sh", []string{"-c", script}, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	fmt.Print(result.Stdout)
+	fmt.Print(result.Stderr)
+	if result.ExitCode != 0 {
+		return fmt.Errorf("the rebuild script failed with exit code %d", result.ExitCode)
+	}
+	return nil
+}
+
+// rebuildStoreScript creates a fresh store from the monmap served by the peers. The new
+// store is validated with ceph-monstore-tool before the corrupted store is moved aside.
+func rebuildStoreScript(monName, monDataPath, backupPath, peerHosts string) string {
+	keyring := "/etc/ceph/keyring-store/keyring"
+	newStorePath := "/tmp/mon-rebuild"
+	return strings.Join([]string{
+		"set -e",
+		fmt.Sprintf("ceph mon getmap --name=mon. --keyring=%s --mon-host=%s --connect-timeout=10 -o /tmp/monmap", keyring, peerHosts),
+		fmt.Sprintf("rm -rf %s", newStorePath),
+		fmt.Sprintf("ceph-mon --mkfs --id=%s --mon-data=%s --monmap=/tmp/monmap --keyring=%s", monName, newStorePath, keyring),
+		fmt.Sprintf("ceph-monstore-tool %s get monmap -- --out /tmp/monmap.verify", newStorePath),
+		fmt.Sprintf("mv %s/store.db %s", monDataPath, backupPath),
+		fmt.Sprintf("cp -r %s/store.db %s/store.db", newStorePath, monDataPath),
+		fmt.Sprintf("chown -R ceph:ceph %s/store.db", monDataPath),
+	}, "\n")
+}
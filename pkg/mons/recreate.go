@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// quorumWaitTimeout is how long to wait for the operator to create the new mon and for it to join the quorum
+	quorumWaitTimeout  = 15 * time.Minute
+	quorumWaitInterval = 10 * time.Second
+)
+
+func RecreateMon(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, mon string) {
+	err := recreateMon(ctx, clientsets, operatorNamespace, clusterNamespace, mon)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func recreateMon(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, mon string) error {
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	err = checkQuorumWithoutMon(status, mon)
+	if err != nil {
+		return err
+	}
+	monCount := len(status.MonMap.Mons)
+
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	dataDirHostPath := cephCluster.Spec.DataDirHostPath
+
+	// the node and image of the mon are needed to remove its data dir once its pod is deleted
+	monPods, err := clientsets.Kube.CoreV1().Pods(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: fmt.Sprintf("app=rook-ceph-mon,ceph_daemon_id=%s", mon)})
+	if err != nil {
+		return fmt.Errorf("failed to list the pods of mon %s. %v", mon, err)
+	}
+	_, err = clientsets.Kube.CoreV1().PersistentVolumeClaims(clusterNamespace).Get(ctx, fmt.Sprintf("rook-ceph-mon-%s", mon), v1.GetOptions{})
+	onPVC := err == nil
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the pvc of mon %s. %v", mon, err)
+	}
+
+	logging.Info("mons in quorum: %s", strings.Join(status.QuorumNames, ","))
+	err = logging.Confirm(clusterNamespace, "Mon %s is removed and the operator re-creates a mon with a fresh data dir", mon)
+	if err != nil {
+		return fmt.Errorf("recreating mon %s cancelled", mon)
+	}
+
+	// the operator is stopped so it doesn't reconcile the mon while its resources are removed
+	logging.Info("Waiting for operator pod to stop")
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 0)
+	if err != nil {
+		return fmt.Errorf("failed to stop deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled down")
+
+	// the operator is restarted even when the removal fails, it's not left scaled down
+	removeErr := removeMonForRecreate(ctx, clientsets, operatorNamespace, clusterNamespace, mon, dataDirHostPath, onPVC, monPods.Items)
+
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 1)
+	if err != nil {
+		return fmt.Errorf("failed to start deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled up")
+	if removeErr != nil {
+		return fmt.Errorf("recreating mon %s failed. %v", mon, removeErr)
+	}
+
+	logging.Info("waiting for the operator to create a new mon")
+
+	return waitForQuorumSize(ctx, clientsets, operatorNamespace, clusterNamespace, monCount)
+}
+
+// removeMonForRecreate removes the mon from the monmap, its resources, its data dir on the host and the mon endpoints
+// configmap, so the operator creates a new mon with a fresh data dir
+func removeMonForRecreate(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, mon, dataDirHostPath string, onPVC bool, monPods []corev1.Pod) error {
+	logging.Info("removing mon %s from the monmap", mon)
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"mon", "remove", mon}, operatorNamespace, clusterNamespace)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove mon %s from the monmap. %v", mon, err)
+	}
+
+	err = removeBadMonsResources(ctx, clientsets.Kube, clusterNamespace, []string{mon})
+	if err != nil {
+		return err
+	}
+
+	if !onPVC && dataDirHostPath != "" && len(monPods) != 0 {
+		nodeName := monPods[0].Spec.NodeName
+		monDataDir := fmt.Sprintf("%s/mon-%s", dataDirHostPath, mon)
+		logging.Info("removing the data dir %s of mon %s on node %s", monDataDir, mon, nodeName)
+		_, err = runInDataDirPod(ctx, clientsets, clusterNamespace, nodeName, dataDirHostPath, monPods[0].Spec.Containers[0].Image, false, "rm", []string{"-rf", monDataDir})
+		if err != nil {
+			return fmt.Errorf("failed to remove the data dir %s on node %s. %v", monDataDir, nodeName, err)
+		}
+	}
+
+	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	err = removeMonFromConfigMap(monCm.Data, mon)
+	if err != nil {
+		return err
+	}
+	_, err = clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update mon configmap %s %v", MonConfigMap, err)
+	}
+	logging.Info("mon %s removed from the configmap %s", mon, MonConfigMap)
+	return nil
+}
+
+// checkQuorumWithoutMon checks the mon is in the monmap and the other mons in quorum are a majority of the monmap without the mon
+func checkQuorumWithoutMon(status *quorumStatus, mon string) error {
+	var monMap []string
+	for _, m := range status.MonMap.Mons {
+		monMap = append(monMap, m.Name)
+	}
+	if !contains(monMap, mon) {
+		return fmt.Errorf("mon %s is not in the monmap %v", mon, monMap)
+	}
+
+	remainingInQuorum := 0
+	for _, name := range status.QuorumNames {
+		if name != mon {
+			remainingInQuorum++
+		}
+	}
+	remainingMons := len(monMap) - 1
+	if remainingInQuorum*2 <= remainingMons || remainingInQuorum == 0 {
+		return fmt.Errorf("only %d of the %d remaining mons are in quorum, removing mon %s would lose the quorum", remainingInQuorum, remainingMons, mon)
+	}
+	return nil
+}
+
+// removeMonFromConfigMap removes the mon from the endpoints and the node mapping of the mon endpoints configmap data
+func removeMonFromConfigMap(data map[string]string, mon string) error {
+	var endpoints []string
+	for _, m := range strings.Split(data["data"], ",") {
+		if m != "" && !strings.HasPrefix(m, mon+"=") {
+			endpoints = append(endpoints, m)
+		}
+	}
+	data["data"] = strings.Join(endpoints, ",")
+
+	if data["mapping"] == "" {
+		return nil
+	}
+	var mapping map[string]map[string]json.RawMessage
+	err := json.Unmarshal([]byte(data["mapping"]), &mapping)
+	if err != nil {
+		return fmt.Errorf("failed to parse the mon mapping of the configmap %s. %v", MonConfigMap, err)
+	}
+	delete(mapping["node"], mon)
+	out, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the mon mapping. %v", err)
+	}
+	data["mapping"] = string(out)
+	return nil
+}
+
+func waitForQuorumSize(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, monCount int) error {
+	progress := logging.NewProgress("waiting for %d mons to be in quorum", monCount)
+	for start := time.Now(); time.Since(start) < quorumWaitTimeout; time.Sleep(quorumWaitInterval) {
+		quorum, err := getQuorumNames(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err == nil && len(quorum) >= monCount {
+			progress.Done(fmt.Sprintf("mons %s are in quorum", strings.Join(quorum, ",")))
+			return nil
+		}
+		progress.Update(fmt.Sprintf("%d mons in quorum", len(quorum)))
+	}
+	progress.Done("timed out")
+	return fmt.Errorf("timed out waiting for %d mons in quorum, check the operator logs", monCount)
+}
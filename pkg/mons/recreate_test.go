@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuorumWithoutMon(t *testing.T) {
+	newStatus := func(quorum []string, mons ...string) *quorumStatus {
+		status := &quorumStatus{QuorumNames: quorum}
+		for _, mon := range mons {
+			status.MonMap.Mons = append(status.MonMap.Mons, monMapEntry{Name: mon})
+		}
+		return status
+	}
+
+	assert.NoError(t, checkQuorumWithoutMon(newStatus([]string{"a", "b", "c"}, "a", "b", "c"), "c"))
+	// the mon out of quorum can be recreated
+	assert.NoError(t, checkQuorumWithoutMon(newStatus([]string{"a", "b"}, "a", "b", "c"), "c"))
+	// removing b would leave a single mon of the two remaining in quorum
+	assert.Error(t, checkQuorumWithoutMon(newStatus([]string{"a", "b"}, "a", "b", "c"), "b"))
+	assert.NoError(t, checkQuorumWithoutMon(newStatus([]string{"a", "b", "c", "d"}, "a", "b", "c", "d", "e"), "e"))
+	assert.Error(t, checkQuorumWithoutMon(newStatus([]string{"a", "b", "c"}, "a", "b", "c"), "d"))
+}
+
+func TestRemoveMonFromConfigMap(t *testing.T) {
+	data := map[string]string{
+		"data":     "a=10.0.0.1:6789,b=10.0.0.2:6789,c=10.0.0.3:6789",
+		"mapping":  `{"node":{"a":{"Name":"node-1"},"b":{"Name":"node-2"},"c":{"Name":"node-3"}}}`,
+		"maxMonId": "2",
+	}
+	assert.NoError(t, removeMonFromConfigMap(data, "b"))
+	assert.Equal(t, "a=10.0.0.1:6789,c=10.0.0.3:6789", data["data"])
+	assert.JSONEq(t, `{"node":{"a":{"Name":"node-1"},"c":{"Name":"node-3"}}}`, data["mapping"])
+	assert.Equal(t, "2", data["maxMonId"])
+
+	data = map[string]string{"data": "a=10.0.0.1:6789"}
+	assert.NoError(t, removeMonFromConfigMap(data, "a"))
+	assert.Equal(t, "", data["data"])
+}
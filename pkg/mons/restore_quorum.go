@@ -19,7 +19,6 @@ package mons
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -71,24 +70,16 @@ func restoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operator
 	logging.Info("printing fsid secret %s\n", cephFsid)
 	logging.Info("Check for the running toolbox")
 
-	_, err = k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, "rook-ceph-tools")
-	if err != nil {
-		return fmt.Errorf("failed to deployment rook-ceph-tools. %v", err)
-	}
-
-	toolBox, err := k8sutil.WaitForPodToRun(ctx, clientsets.Kube, clusterNamespace, "app=rook-ceph-tools")
+	toolBox, err := k8sutil.GetToolboxPod(ctx, clientsets.Kube, clusterNamespace)
 	if err != nil || toolBox.Name == "" {
-		return fmt.Errorf("failed to get the running toolbox")
+		return fmt.Errorf("failed to get the running toolbox. %v", err)
 	}
 
 	logging.Info("Restoring mon quorum to mon %s %s\n", goodMon, goodMonPublicIp)
 	logging.Info("The mons to discard are: %s\n", badMons)
 	logging.Info("The cluster fsid is %s\n", cephFsid)
 
-	var answer string
-	logging.Warning("Are you sure you want to restore the quorum to mon %s? If so, enter 'yes-really-restore'\n", goodMon)
-	fmt.Scanf("%s", &answer)
-	err = PromptToContinueOrCancel("restore-quorum", "yes-really-restore", answer)
+	err = logging.Confirm(clusterNamespace, "Restoring the quorum to mon %s discards the mons %s", goodMon, badMons)
 	if err != nil {
 		return fmt.Errorf("restoring the mon quorum to mon %s cancelled", goodMon)
 	}
@@ -149,13 +140,7 @@ func restoreQuorum(ctx context.Context, clientsets *k8sutil.Clientsets, operator
 
 	logging.Info("Mon quorum was successfully restored to mon %s\n", goodMon)
 	logging.Info("Only a single mon is currently running")
-	logging.Info("Press Enter to start the operator and expand to full mon quorum again")
-
-	err = PromptToContinueOrCancel("restore-quorum", "yes-really-restore", answer)
-	if err != nil {
-		return fmt.Errorf("skipping operator start to expand full mon quorum.")
-	}
-	logging.Info("proceeding with resorting quorum")
+	logging.Info("Starting the operator to expand to full mon quorum again")
 
 	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 1)
 	if err != nil {
@@ -278,16 +263,3 @@ func getMonDetails(goodMon string, monEndpoints []string) ([]string, string, str
 	}
 	return badMons, goodMonPublicIp, goodMonPort, nil
 }
-
-func PromptToContinueOrCancel(inputSource, expectedAnswer, answer string) error {
-	if skip, ok := os.LookupEnv("ROOK_PLUGIN_SKIP_PROMPTS"); ok && skip == "true" {
-		logging.Info("skipped prompt since ROOK_PLUGIN_SKIP_PROMPTS=true")
-		return nil
-	}
-
-	if answer == expectedAnswer {
-		return nil
-	}
-
-	return fmt.Errorf("cancelled")
-}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const scalePollInterval = 10 * time.Second
+
+// scaleProgress is the state of the new mons while the operator adds them
+type scaleProgress struct {
+	// Joined are the new mons in quorum
+	Joined []string
+	// Pending are the new mons in the monmap but not in quorum
+	Pending []string
+	Done    bool
+}
+
+// Scale raises the mon count of the CephCluster, waits for the new mons to join the quorum, and rolls the count
+// back when they don't join it within the timeout
+func Scale(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, count int, timeout time.Duration) {
+	err := scale(ctx, clientsets, operatorNamespace, clusterNamespace, count, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func scale(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, count int, timeout time.Duration) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	current := cephCluster.Spec.Mon.Count
+	if count <= current {
+		return fmt.Errorf("the mon count of CephCluster %s is %d, mons scale only adds mons. Lower the count with `kubectl rook-ceph mons count %d`", cephCluster.Name, current, count)
+	}
+	warnings, err := validateMonCount(count, cephCluster.Spec.Mon)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		logging.Warning(warning)
+	}
+	if !cephCluster.Spec.Mon.AllowMultiplePerNode {
+		err = checkSchedulableNodes(ctx, clientsets, count)
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	// adding mons to a quorum missing mons can leave it half-scaled, the operator would rather replace the mons
+	// out of quorum
+	var existing []string
+	for _, mon := range status.MonMap.Mons {
+		existing = append(existing, mon.Name)
+	}
+	for _, name := range existing {
+		if !contains(status.QuorumNames, name) {
+			return fmt.Errorf("mon %s is out of quorum, restore the quorum before adding mons. Check the mons with `kubectl rook-ceph mons check-quorum`", name)
+		}
+	}
+
+	err = patchMonCount(ctx, clientsets.Rook, cephCluster, count)
+	if err != nil {
+		return err
+	}
+	logging.Info("mon count of CephCluster %s set from %d to %d", cephCluster.Name, current, count)
+
+	var progress scaleProgress
+	waiting := logging.NewProgress("waiting for %d new mons to join the quorum", count-current)
+	for start := time.Now(); ; time.Sleep(scalePollInterval) {
+		status, err := getQuorumStatus(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err == nil {
+			newProgress := getScaleProgress(status, existing, count)
+			for _, name := range newProgress.Joined {
+				if !contains(progress.Joined, name) {
+					logging.Info("mon %s joined the quorum", name)
+				}
+			}
+			progress = newProgress
+		}
+		if progress.Done {
+			waiting.Done("done")
+			logging.Info("the %d mons of CephCluster %s are in quorum", count, cephCluster.Name)
+			return nil
+		}
+		if time.Since(start) >= timeout {
+			waiting.Done("timed out")
+			break
+		}
+		waiting.Update(fmt.Sprintf("%d of %d joined", len(progress.Joined), count-current))
+	}
+
+	logging.Error(fmt.Errorf("timed out after %s waiting for the new mons to join the quorum. joined: %s, not in quorum: %s",
+		timeout, formatMons(progress.Joined), formatMons(progress.Pending)))
+	err = patchMonCount(ctx, clientsets.Rook, cephCluster, current)
+	if err != nil {
+		return fmt.Errorf("failed to roll back the mon count to %d, set it with `kubectl rook-ceph mons count %d`. %v", current, current, err)
+	}
+	return fmt.Errorf("mon count of CephCluster %s rolled back to %d, the operator removes the extra mons. Check the logs of the operator and of the new mon pods for why they didn't join the quorum", cephCluster.Name, current)
+}
+
+// checkSchedulableNodes returns an error when there are fewer schedulable nodes than mons, the operator places
+// each mon on a different node unless allowMultiplePerNode is set
+func checkSchedulableNodes(ctx context.Context, clientsets *k8sutil.Clientsets, count int) error {
+	nodes, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the nodes. %v", err)
+	}
+	schedulable := 0
+	for _, node := range nodes.Items {
+		if !node.Spec.Unschedulable {
+			schedulable++
+		}
+	}
+	if schedulable < count {
+		return fmt.Errorf("only %d nodes are schedulable for %d mons, the mons must run on different nodes unless allowMultiplePerNode is set", schedulable, count)
+	}
+	return nil
+}
+
+// getScaleProgress returns the new mons of the monmap in and out of quorum, the scale is done once the monmap has
+// the count of mons and all of them are in quorum
+func getScaleProgress(status *quorumStatus, existing []string, count int) scaleProgress {
+	var progress scaleProgress
+	allInQuorum := true
+	for _, mon := range status.MonMap.Mons {
+		inQuorum := contains(status.QuorumNames, mon.Name)
+		if !inQuorum {
+			allInQuorum = false
+		}
+		if contains(existing, mon.Name) {
+			continue
+		}
+		if inQuorum {
+			progress.Joined = append(progress.Joined, mon.Name)
+		} else {
+			progress.Pending = append(progress.Pending, mon.Name)
+		}
+	}
+	sort.Strings(progress.Joined)
+	sort.Strings(progress.Pending)
+	progress.Done = allInQuorum && len(status.MonMap.Mons) == count
+	return progress
+}
+
+func formatMons(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
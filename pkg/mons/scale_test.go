@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetScaleProgress(t *testing.T) {
+	existing := []string{"a", "b", "c"}
+	status := &quorumStatus{
+		QuorumNames: []string{"a", "b", "c", "d"},
+		MonMap:      monMap{Mons: []monMapEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}},
+	}
+	progress := getScaleProgress(status, existing, 5)
+	assert.Equal(t, []string{"d"}, progress.Joined)
+	assert.Equal(t, []string{"e"}, progress.Pending)
+	assert.False(t, progress.Done)
+
+	status.QuorumNames = append(status.QuorumNames, "e")
+	progress = getScaleProgress(status, existing, 5)
+	assert.Equal(t, []string{"d", "e"}, progress.Joined)
+	assert.Empty(t, progress.Pending)
+	assert.True(t, progress.Done)
+
+	// the operator has not added all the mons to the monmap yet
+	status.MonMap.Mons = status.MonMap.Mons[:4]
+	assert.False(t, getScaleProgress(status, existing, 5).Done)
+}
+
+func TestScaleValidation(t *testing.T) {
+	ctx := context.TODO()
+	clientsets := &k8sutil.Clientsets{
+		Rook: rookfake.NewSimpleClientset(&cephv1.CephCluster{
+			ObjectMeta: v1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+			Spec:       cephv1.ClusterSpec{Mon: cephv1.MonSpec{Count: 3}},
+		}),
+		Kube: fake.NewSimpleClientset(
+			&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-a"}},
+			&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-b"}},
+			&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-c"}},
+			&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-d"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+		),
+	}
+
+	err := scale(ctx, clientsets, "rook-ceph", "rook-ceph", 3, time.Minute)
+	assert.ErrorContains(t, err, "mons scale only adds mons")
+	err = scale(ctx, clientsets, "rook-ceph", "rook-ceph", 10, time.Minute)
+	assert.ErrorContains(t, err, "invalid mon count 10")
+	// the cordoned node can't run a mon
+	err = scale(ctx, clientsets, "rook-ceph", "rook-ceph", 5, time.Minute)
+	assert.EqualError(t, err, "only 3 nodes are schedulable for 5 mons, the mons must run on different nodes unless allowMultiplePerNode is set")
+
+	cephCluster, err := clientsets.Rook.CephV1().CephClusters("rook-ceph").Get(ctx, "my-cluster", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cephCluster.Spec.Mon.Count)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type monDump struct {
+	Mons []monDumpEntry `json:"mons"`
+}
+
+type monDumpEntry struct {
+	Name        string `json:"name"`
+	PublicAddr  string `json:"public_addr"`
+	PublicAddrs struct {
+		AddrVec []struct {
+			Type string `json:"type"`
+			Addr string `json:"addr"`
+		} `json:"addrvec"`
+	} `json:"public_addrs"`
+}
+
+func SyncEndpoints(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, dryRun bool) {
+	err := syncEndpoints(ctx, clientsets, operatorNamespace, clusterNamespace, dryRun)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func syncEndpoints(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, dryRun bool) error {
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"mon", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var dump monDump
+	err := json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph mon dump output. %v", err)
+	}
+	if len(dump.Mons) == 0 {
+		return fmt.Errorf("no mon found in the ceph monmap")
+	}
+
+	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, MonConfigMap, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get mon configmap %s %v", MonConfigMap, err)
+	}
+	if monCm.Data == nil {
+		monCm.Data = map[string]string{}
+	}
+	currentEndpoints, err := parseMonEndpoints(monCm.Data["data"])
+	if err != nil {
+		return err
+	}
+
+	monMapEndpoints := getMonMapEndpoints(dump.Mons, currentEndpoints)
+	changes := diffMonEndpoints(currentEndpoints, monMapEndpoints)
+	if len(changes) == 0 {
+		logging.Info("the configmap %s matches the ceph monmap", MonConfigMap)
+		return nil
+	}
+
+	logging.Info("the configmap %s differs from the ceph monmap:", MonConfigMap)
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	fmt.Println()
+	logging.Info("before: %s", monCm.Data["data"])
+	logging.Info("after:  %s", formatMonEndpoints(monMapEndpoints))
+	if dryRun {
+		logging.Info("dry-run: no changes were made, pass --dry-run=false to update the configmap %s", MonConfigMap)
+		return nil
+	}
+
+	err = logging.Confirm(clusterNamespace, "The mon endpoints of the configmap %s are replaced by the mons of the ceph monmap", MonConfigMap)
+	if err != nil {
+		return fmt.Errorf("syncing the mon endpoints cancelled")
+	}
+
+	err = setMonEndpoints(monCm.Data, monMapEndpoints)
+	if err != nil {
+		return err
+	}
+	_, err = clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Update(ctx, monCm, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update mon configmap %s %v", MonConfigMap, err)
+	}
+	logging.Info("configmap %s updated, restart the operator with `kubectl rook-ceph operator restart` to reconcile the mons", MonConfigMap)
+	return nil
+}
+
+// getMonMapEndpoints returns the endpoint of each mon of the monmap
+func getMonMapEndpoints(mons []monDumpEntry, currentEndpoints map[string]string) map[string]string {
+	endpoints := map[string]string{}
+	for _, mon := range mons {
+		endpoints[mon.Name] = getMonMapEndpoint(mon, currentEndpoints[mon.Name])
+	}
+	return endpoints
+}
+
+// getMonMapEndpoint keeps the endpoint of the configmap when it's still an address of the mon, so the msgr2 port is
+// not replaced by the v1 port, otherwise the v1 address is used as rook does by default
+func getMonMapEndpoint(mon monDumpEntry, currentEndpoint string) string {
+	var v1Addr, v2Addr string
+	for _, addr := range mon.PublicAddrs.AddrVec {
+		if addr.Addr == currentEndpoint {
+			return currentEndpoint
+		}
+		if addr.Type == "v1" {
+			v1Addr = addr.Addr
+		} else if addr.Type == "v2" {
+			v2Addr = addr.Addr
+		}
+	}
+	if v1Addr != "" {
+		return v1Addr
+	}
+	if v2Addr != "" {
+		return v2Addr
+	}
+	// the public_addr has a nonce such as 10.96.52.53:6789/0
+	endpoint, _, _ := strings.Cut(mon.PublicAddr, "/")
+	return endpoint
+}
+
+// diffMonEndpoints returns the mons removed (-) and added (+) to update the endpoints to the monmap endpoints
+func diffMonEndpoints(currentEndpoints, monMapEndpoints map[string]string) []string {
+	var changes []string
+	for _, name := range sortedMonNames(currentEndpoints, monMapEndpoints) {
+		current, inConfigMap := currentEndpoints[name]
+		expected, inMonMap := monMapEndpoints[name]
+		if inConfigMap && inMonMap && current == expected {
+			continue
+		}
+		if inConfigMap {
+			changes = append(changes, fmt.Sprintf("- %s=%s", name, current))
+		}
+		if inMonMap {
+			changes = append(changes, fmt.Sprintf("+ %s=%s", name, expected))
+		}
+	}
+	return changes
+}
+
+// setMonEndpoints replaces the endpoints of the configmap data, raises the maxMonId to the highest id of the mons so
+// the operator doesn't name a new mon like a mon of the monmap, and removes the mons not in the endpoints from the
+// node mapping so the operator doesn't schedule them
+func setMonEndpoints(data map[string]string, endpoints map[string]string) error {
+	data["data"] = formatMonEndpoints(endpoints)
+
+	maxMonId, err := strconv.Atoi(data["maxMonId"])
+	if err != nil {
+		maxMonId = -1
+	}
+	for name := range endpoints {
+		id, err := monNameToId(name)
+		if err != nil {
+			logging.Warning("mon %s is not named by rook, it's not counted in maxMonId. %v", name, err)
+			continue
+		}
+		if id > maxMonId {
+			maxMonId = id
+		}
+	}
+	if maxMonId >= 0 {
+		data["maxMonId"] = strconv.Itoa(maxMonId)
+	}
+
+	if data["mapping"] == "" {
+		return nil
+	}
+	var mapping map[string]map[string]json.RawMessage
+	err = json.Unmarshal([]byte(data["mapping"]), &mapping)
+	if err != nil {
+		return fmt.Errorf("failed to parse the mon mapping of the configmap %s. %v", MonConfigMap, err)
+	}
+	for name := range mapping["node"] {
+		if _, ok := endpoints[name]; !ok {
+			delete(mapping["node"], name)
+		}
+	}
+	out, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the mon mapping. %v", err)
+	}
+	data["mapping"] = string(out)
+	return nil
+}
+
+// formatMonEndpoints formats the endpoints like the "data" field of the mon endpoints configmap
+func formatMonEndpoints(endpoints map[string]string) string {
+	var monEndpoints []string
+	for _, name := range sortedMonNames(endpoints) {
+		monEndpoints = append(monEndpoints, fmt.Sprintf("%s=%s", name, endpoints[name]))
+	}
+	return strings.Join(monEndpoints, ",")
+}
+
+func sortedMonNames(endpoints ...map[string]string) []string {
+	names := map[string]bool{}
+	for _, e := range endpoints {
+		for name := range e {
+			names[name] = true
+		}
+	}
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
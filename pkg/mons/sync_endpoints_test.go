@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mons
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncEndpoints(t *testing.T) {
+	var dump monDump
+	err := json.Unmarshal([]byte(`{"mons":[
+{"name":"a","public_addrs":{"addrvec":[{"type":"v2","addr":"10.96.52.53:3300","nonce":0},{"type":"v1","addr":"10.96.52.53:6789","nonce":0}]},"public_addr":"10.96.52.53:6789/0"},
+{"name":"b","public_addrs":{"addrvec":[{"type":"v2","addr":"10.96.52.54:3300","nonce":0}]},"public_addr":"10.96.52.54:3300/0"},
+{"name":"d","public_addrs":{"addrvec":[{"type":"v2","addr":"10.96.52.56:3300","nonce":0},{"type":"v1","addr":"10.96.52.56:6789","nonce":0}]},"public_addr":"10.96.52.56:6789/0"},
+{"name":"e","public_addr":"10.96.52.57:6789/0"}]}`), &dump)
+	assert.NoError(t, err)
+
+	current, err := parseMonEndpoints("a=10.96.52.53:3300,b=10.96.52.50:6789,c=10.96.52.55:6789")
+	assert.NoError(t, err)
+
+	endpoints := getMonMapEndpoints(dump.Mons, current)
+	assert.Equal(t, map[string]string{"a": "10.96.52.53:3300", "b": "10.96.52.54:3300", "d": "10.96.52.56:6789", "e": "10.96.52.57:6789"}, endpoints)
+	assert.Equal(t, []string{"- b=10.96.52.50:6789", "+ b=10.96.52.54:3300", "- c=10.96.52.55:6789", "+ d=10.96.52.56:6789", "+ e=10.96.52.57:6789"},
+		diffMonEndpoints(current, endpoints))
+	assert.Empty(t, diffMonEndpoints(endpoints, endpoints))
+
+	data := map[string]string{
+		"data":     "a=10.96.52.53:3300,b=10.96.52.50:6789,c=10.96.52.55:6789",
+		"mapping":  `{"node":{"a":{"Name":"node-1"},"b":{"Name":"node-2"},"c":{"Name":"node-3"}}}`,
+		"maxMonId": "2",
+	}
+	assert.NoError(t, setMonEndpoints(data, endpoints))
+	assert.Equal(t, "a=10.96.52.53:3300,b=10.96.52.54:3300,d=10.96.52.56:6789,e=10.96.52.57:6789", data["data"])
+	assert.JSONEq(t, `{"node":{"a":{"Name":"node-1"},"b":{"Name":"node-2"}}}`, data["mapping"])
+	// the maxMonId is raised to the id of the mon e of the monmap
+	assert.Equal(t, "4", data["maxMonId"])
+
+	// the maxMonId is not lowered when the mons with the highest ids were removed
+	data = map[string]string{"data": "a=10.96.52.53:6789", "maxMonId": "7"}
+	assert.NoError(t, setMonEndpoints(data, map[string]string{"a": "10.96.52.53:6789", "c": "10.96.52.55:6789"}))
+	assert.Equal(t, "7", data["maxMonId"])
+}
+
+func TestMonNameToId(t *testing.T) {
+	for name, expected := range map[string]int{"a": 0, "c": 2, "z": 25, "aa": 26, "az": 51, "ba": 52} {
+		id, err := monNameToId(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, id, name)
+	}
+	_, err := monNameToId("mon-1")
+	assert.Error(t, err)
+	_, err = monNameToId("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ModeOut       = "out"
+	ModeScaleDown = "scale-down"
+
+	// drainedNodeAnnotation records on the scaled down osd deployments the node they were drained
+	// from, since their pods can't be mapped to the node anymore
+	drainedNodeAnnotation = "kubectl-rook-ceph/drained-node"
+)
+
+func DrainOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, nodeName, mode string) {
+	err := drainOsds(ctx, clientsets, operatorNamespace, clusterNamespace, nodeName, mode)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func drainOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, nodeName, mode string) error {
+	if mode != ModeOut && mode != ModeScaleDown {
+		return fmt.Errorf("invalid mode %q, supported modes are %s and %s", mode, ModeOut, ModeScaleDown)
+	}
+
+	osdIDs, err := getOsdIDsOnNode(ctx, clientsets.Kube, clusterNamespace, nodeName)
+	if err != nil {
+		return err
+	}
+	if len(osdIDs) == 0 {
+		return fmt.Errorf("no osd pods found on node %s", nodeName)
+	}
+	logging.Info("osds on node %s: %s", nodeName, strings.Join(osdIDs, ","))
+
+	// ok-to-stop fails if stopping the osds would make pgs unavailable, which exits before any change
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"osd", "ok-to-stop"}, osdIDs...), operatorNamespace, clusterNamespace, true, true)
+	logging.Info("osds %s are ok to stop", strings.Join(osdIDs, ","))
+
+	// noout is set on the osds only, so draining another node doesn't depend on this node's undrain
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"osd", "add-noout"}, osdIDs...), operatorNamespace, clusterNamespace, false, true)
+
+	switch mode {
+	case ModeOut:
+		exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"osd", "out"}, osdIDs...), operatorNamespace, clusterNamespace, false, true)
+	case ModeScaleDown:
+		for _, osdID := range osdIDs {
+			deploymentName := fmt.Sprintf("rook-ceph-osd-%s", osdID)
+			err = setDrainedNodeAnnotation(ctx, clientsets.Kube, clusterNamespace, deploymentName, nodeName)
+			if err != nil {
+				return err
+			}
+			err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, deploymentName, 0)
+			if err != nil {
+				return fmt.Errorf("failed to scale down deployment %s. %v", deploymentName, err)
+			}
+			logging.Info("deployment %s scaled down", deploymentName)
+		}
+	}
+
+	logging.Info("node %s is drained, run `undrain-osds %s` after the maintenance", nodeName, nodeName)
+	return nil
+}
+
+func UndrainOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, nodeName string) {
+	err := undrainOsds(ctx, clientsets, operatorNamespace, clusterNamespace, nodeName)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func undrainOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, nodeName string) error {
+	osdIDs, err := getOsdIDsOnNode(ctx, clientsets.Kube, clusterNamespace, nodeName)
+	if err != nil {
+		return err
+	}
+
+	drainedDeployments, err := getDrainedDeployments(ctx, clientsets.Kube, clusterNamespace, nodeName)
+	if err != nil {
+		return err
+	}
+	for osdID, deploymentName := range drainedDeployments {
+		err = setDrainedNodeAnnotation(ctx, clientsets.Kube, clusterNamespace, deploymentName, "")
+		if err != nil {
+			return err
+		}
+		err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, clusterNamespace, deploymentName, 1)
+		if err != nil {
+			return fmt.Errorf("failed to scale up deployment %s. %v", deploymentName, err)
+		}
+		logging.Info("deployment %s scaled up", deploymentName)
+		osdIDs = append(osdIDs, osdID)
+	}
+	osdIDs = uniqueSortedIDs(osdIDs)
+	if len(osdIDs) == 0 {
+		return fmt.Errorf("no osds found on node %s", nodeName)
+	}
+
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"osd", "in"}, osdIDs...), operatorNamespace, clusterNamespace, false, true)
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"osd", "rm-noout"}, osdIDs...), operatorNamespace, clusterNamespace, false, true)
+	logging.Info("osds %s on node %s are undrained", strings.Join(osdIDs, ","), nodeName)
+	return nil
+}
+
+// getOsdIDsOnNode returns the sorted ids of the osds with a pod on the node
+func getOsdIDsOnNode(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, nodeName string) ([]string, error) {
+	pods, err := k8sutil.ListPods(ctx, k8sclientset, clusterNamespace, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list osd pods. %v", err)
+	}
+
+	podsByNode, _ := k8sutil.GroupPodsByNode(pods)
+	var osdIDs []string
+	for _, pod := range podsByNode[nodeName] {
+		if osdID, ok := pod.Labels["ceph-osd-id"]; ok {
+			osdIDs = append(osdIDs, osdID)
+		}
+	}
+	return uniqueSortedIDs(osdIDs), nil
+}
+
+// getDrainedDeployments returns the osd deployments scaled down when draining the node, by osd id
+func getDrainedDeployments(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, nodeName string) (map[string]string, error) {
+	deployments, err := k8sclientset.AppsV1().Deployments(clusterNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list osd deployments. %v", err)
+	}
+
+	drained := map[string]string{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if deployment.Annotations[drainedNodeAnnotation] == nodeName {
+			drained[deployment.Labels["ceph-osd-id"]] = deployment.Name
+		}
+	}
+	return drained, nil
+}
+
+// setDrainedNodeAnnotation sets the drained node annotation on the deployment, or removes it if nodeName is empty
+func setDrainedNodeAnnotation(ctx context.Context, k8sclientset kubernetes.Interface, clusterNamespace, deploymentName, nodeName string) error {
+	deployment, err := k8sclientset.AppsV1().Deployments(clusterNamespace).Get(ctx, deploymentName, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %s. %v", deploymentName, err)
+	}
+
+	if nodeName == "" {
+		delete(deployment.Annotations, drainedNodeAnnotation)
+	} else {
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[drainedNodeAnnotation] = nodeName
+	}
+
+	_, err = k8sclientset.AppsV1().Deployments(clusterNamespace).Update(ctx, deployment, v1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update deployment %s. %v", deploymentName, err)
+	}
+	return nil
+}
+
+func uniqueSortedIDs(osdIDs []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, osdID := range osdIDs {
+		if !seen[osdID] {
+			seen[osdID] = true
+			unique = append(unique, osdID)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		a, errA := strconv.Atoi(unique[i])
+		b, errB := strconv.Atoi(unique[j])
+		if errA != nil || errB != nil {
+			return unique[i] < unique[j]
+		}
+		return a < b
+	})
+	return unique
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetOsdIDsOnNode(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	newOsdPod := func(name, osdID, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": "rook-ceph-osd", "ceph-osd-id": osdID}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+	k8s := fake.NewSimpleClientset(
+		newOsdPod("rook-ceph-osd-10", "10", "node-1"),
+		newOsdPod("rook-ceph-osd-2", "2", "node-1"),
+		newOsdPod("rook-ceph-osd-3", "3", "node-2"),
+	)
+
+	osdIDs, err := getOsdIDsOnNode(ctx, k8s, ns, "node-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "10"}, osdIDs)
+
+	osdIDs, err = getOsdIDsOnNode(ctx, k8s, ns, "node-3")
+	assert.NoError(t, err)
+	assert.Empty(t, osdIDs)
+}
+
+func TestDrainedNodeAnnotation(t *testing.T) {
+	ctx := context.TODO()
+	ns := "rook-ceph"
+	k8s := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-osd-2", Namespace: ns, Labels: map[string]string{"app": "rook-ceph-osd", "ceph-osd-id": "2"}},
+	})
+
+	err := setDrainedNodeAnnotation(ctx, k8s, ns, "rook-ceph-osd-2", "node-1")
+	assert.NoError(t, err)
+	drained, err := getDrainedDeployments(ctx, k8s, ns, "node-1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"2": "rook-ceph-osd-2"}, drained)
+
+	err = setDrainedNodeAnnotation(ctx, k8s, ns, "rook-ceph-osd-2", "")
+	assert.NoError(t, err)
+	drained, err = getDrainedDeployments(ctx, k8s, ns, "node-1")
+	assert.NoError(t, err)
+	assert.Empty(t, drained)
+}
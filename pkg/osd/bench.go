@@ -0,0 +1,206 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// an osd below this ratio of the median throughput of the osds is slow
+const slowBenchRatio = 0.5
+
+// osdBench is the output of ceph tell osd.<id> bench
+type osdBench struct {
+	BytesWritten uint64  `json:"bytes_written"`
+	Blocksize    uint64  `json:"blocksize"`
+	ElapsedSec   float64 `json:"elapsed_sec"`
+	BytesPerSec  float64 `json:"bytes_per_sec"`
+	Iops         float64 `json:"iops"`
+}
+
+type benchResult struct {
+	Id    int
+	Bench osdBench
+	// Error is the reason the bench failed on the osd, empty when it succeeded
+	Error string
+	Slow  bool
+}
+
+func Bench(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, deviceClass, size, blockSize string) {
+	err := bench(ctx, clientsets, operatorNamespace, clusterNamespace, deviceClass, size, blockSize)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func bench(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, deviceClass, size, blockSize string) error {
+	sizeBytes, err := parseBenchSize("size", size)
+	if err != nil {
+		return err
+	}
+	blockSizeBytes, err := parseBenchSize("block-size", blockSize)
+	if err != nil {
+		return err
+	}
+
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	upOsds, downOsds, err := getOsdsByState(dumpOut)
+	if err != nil {
+		return err
+	}
+	if deviceClass != "" {
+		classOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "crush", "class", "ls-osd", deviceClass, "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+		var classOsds []int
+		err = json.Unmarshal([]byte(classOut), &classOsds)
+		if err != nil {
+			return fmt.Errorf("failed to parse ceph osd crush class ls-osd output. %v", err)
+		}
+		upOsds, downOsds = filterOsds(upOsds, classOsds), filterOsds(downOsds, classOsds)
+	}
+	if len(upOsds) == 0 && len(downOsds) == 0 {
+		return fmt.Errorf("no osd found to bench")
+	}
+
+	// the osds are benched one at a time, the osds of a host would slow each other down
+	var results []benchResult
+	for _, id := range downOsds {
+		results = append(results, benchResult{Id: id, Error: "the osd is down"})
+	}
+	benchArgs := []string{"bench", strconv.FormatInt(sizeBytes, 10), strconv.FormatInt(blockSizeBytes, 10), "--format", "json"}
+	for _, id := range upOsds {
+		start := time.Now()
+		progress := logging.NewProgress("running 'ceph tell osd.%d bench %s %s'", id, size, blockSize)
+		result := parseBenchResult(tellOsd(ctx, clientsets, operatorNamespace, clusterNamespace, id, benchArgs))
+		progress.Done(fmt.Sprintf("done in %s", time.Since(start).Round(time.Second)))
+		results = append(results, result)
+	}
+
+	rankBenchResults(results)
+	printBenchResults(os.Stdout, results)
+	fmt.Println()
+	for _, result := range results {
+		if result.Error != "" {
+			logging.Warning("osd.%d: bench failed: %s", result.Id, result.Error)
+		} else if result.Slow {
+			logging.Warning("osd.%d is slow, its throughput of %s/s is below %d%% of the median throughput", result.Id, output.Bytes(uint64(result.Bench.BytesPerSec)), int(slowBenchRatio*100))
+		}
+	}
+	return nil
+}
+
+func parseBenchSize(name, size string) (int64, error) {
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil || quantity.Value() <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, pass a size such as 4Mi or 1Gi", name, size)
+	}
+	return quantity.Value(), nil
+}
+
+// filterOsds returns the osds of the ids that are in the osds
+func filterOsds(ids, osds []int) []int {
+	included := map[int]bool{}
+	for _, osd := range osds {
+		included[osd] = true
+	}
+	var filtered []int
+	for _, id := range ids {
+		if included[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
+func parseBenchResult(result tellResult) benchResult {
+	if result.Error != "" {
+		return benchResult{Id: result.Id, Error: result.Error}
+	}
+	var bench osdBench
+	err := json.Unmarshal([]byte(result.Output), &bench)
+	if err != nil {
+		return benchResult{Id: result.Id, Error: fmt.Sprintf("failed to parse the bench output. %v", err)}
+	}
+	return benchResult{Id: result.Id, Bench: bench}
+}
+
+// rankBenchResults sorts the results by throughput, the failed osds last, and flags the osds below the ratio of the
+// median throughput as slow
+func rankBenchResults(results []benchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Error == "") != (results[j].Error == "") {
+			return results[i].Error == ""
+		}
+		if results[i].Error != "" {
+			return results[i].Id < results[j].Id
+		}
+		return results[i].Bench.BytesPerSec > results[j].Bench.BytesPerSec
+	})
+
+	var throughputs []float64
+	for _, result := range results {
+		if result.Error == "" {
+			throughputs = append(throughputs, result.Bench.BytesPerSec)
+		}
+	}
+	if len(throughputs) < 2 {
+		return
+	}
+	// the throughputs are sorted in descending order
+	median := throughputs[len(throughputs)/2]
+	if len(throughputs)%2 == 0 {
+		median = (throughputs[len(throughputs)/2-1] + throughputs[len(throughputs)/2]) / 2
+	}
+	for i := range results {
+		if results[i].Error == "" && results[i].Bench.BytesPerSec < median*slowBenchRatio {
+			results[i].Slow = true
+		}
+	}
+}
+
+func printBenchResults(out io.Writer, results []benchResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RANK\tOSD\tTHROUGHPUT\tIOPS\tELAPSED\tSTATUS")
+	rank := 0
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "-\tosd.%d\t-\t-\t-\tfailed\n", result.Id)
+			continue
+		}
+		rank++
+		status := "ok"
+		if result.Slow {
+			status = "slow"
+		}
+		fmt.Fprintf(w, "%d\tosd.%d\t%s/s\t%.0f\t%.2fs\t%s\n", rank, result.Id, output.Bytes(uint64(result.Bench.BytesPerSec)), result.Bench.Iops, result.Bench.ElapsedSec, status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankBenchResults(t *testing.T) {
+	newResult := func(id int, bytesPerSec float64) tellResult {
+		return tellResult{Id: id, Output: fmt.Sprintf(`{"bytes_written": 1073741824, "blocksize": 4194304, "elapsed_sec": 5.5, "bytes_per_sec": %f, "iops": 47.2}`, bytesPerSec)}
+	}
+	results := []benchResult{
+		{Id: 3, Error: "the osd is down"},
+		parseBenchResult(newResult(0, 200<<20)),
+		parseBenchResult(newResult(1, 180<<20)),
+		parseBenchResult(newResult(2, 60<<20)),
+		parseBenchResult(newResult(4, 220<<20)),
+		parseBenchResult(tellResult{Id: 5, Output: "not json"}),
+	}
+	rankBenchResults(results)
+
+	var ids []int
+	for _, result := range results {
+		ids = append(ids, result.Id)
+	}
+	assert.Equal(t, []int{4, 0, 1, 2, 3, 5}, ids)
+	// the median throughput is 190MiB/s
+	assert.False(t, results[2].Slow)
+	assert.True(t, results[3].Slow)
+	assert.Contains(t, results[5].Error, "failed to parse the bench output")
+
+	var out bytes.Buffer
+	printBenchResults(&out, results)
+	assert.Regexp(t, `1\s+osd.4\s+220.0 MiB/s\s+47\s+5.50s\s+ok`, out.String())
+	assert.Regexp(t, `4\s+osd.2\s+60.0 MiB/s\s+47\s+5.50s\s+slow`, out.String())
+	assert.Regexp(t, `-\s+osd.3\s+-\s+-\s+-\s+failed`, out.String())
+
+	assert.Equal(t, []int{1, 3}, filterOsds([]int{0, 1, 2, 3}, []int{3, 1, 5}))
+	size, err := parseBenchSize("size", "1Gi")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<30), size)
+	_, err = parseBenchSize("size", "0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/rook"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// downStampLayout is the layout of the down_stamp of the osds in the ceph osd dump output
+const downStampLayout = "2006-01-02T15:04:05.999999-0700"
+
+// downOsd is an osd that is down and out of the cluster
+type downOsd struct {
+	Id      int
+	DownFor time.Duration
+	// NotSafe is the reason ceph reports the osd is not safe to destroy, empty when it's safe
+	NotSafe string
+}
+
+func CleanupDown(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, downFor time.Duration, dryRun, force bool) {
+	err := cleanupDown(ctx, clientsets, operatorNamespace, clusterNamespace, downFor, dryRun, force)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func cleanupDown(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, downFor time.Duration, dryRun, force bool) error {
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	osds, err := findDownOsds(dumpOut, time.Now(), downFor)
+	if err != nil {
+		return err
+	}
+	if len(osds) == 0 {
+		logging.Info("no osd is down and out for more than %s", downFor)
+		return nil
+	}
+
+	for i := range osds {
+		osds[i].NotSafe = checkSafeToDestroy(ctx, clientsets, operatorNamespace, clusterNamespace, osds[i].Id)
+	}
+	logging.Info("%d osds are down and out for more than %s", len(osds), downFor)
+	printDownOsds(os.Stdout, osds)
+	if dryRun {
+		logging.Info("dry-run: no osd was purged, pass --dry-run=false to purge them")
+		return nil
+	}
+
+	var failed []string
+	for _, osd := range osds {
+		if osd.NotSafe != "" && !force {
+			logging.Warning("skipping osd.%d since it's not safe to destroy, pass --force to purge it anyway: %s", osd.Id, osd.NotSafe)
+			continue
+		}
+		err := logging.Confirm(clusterNamespace, "Purging osd.%d, down for %s, permanently removes it from the cluster and deletes its deployment", osd.Id, formatDownFor(osd.DownFor))
+		if err != nil {
+			logging.Info("skipping osd.%d", osd.Id)
+			continue
+		}
+		err = purgeDownOsd(ctx, clientsets, operatorNamespace, clusterNamespace, osd.Id, force)
+		if err != nil {
+			logging.Error(err)
+			failed = append(failed, fmt.Sprintf("osd.%d", osd.Id))
+			continue
+		}
+		logging.Info("osd.%d purged", osd.Id)
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to purge %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// findDownOsds returns the osds down and out of the osd map for longer than downFor, sorted by id. A down osd
+// that is still in the cluster is not returned, since ceph marks it out before it rebalances its data.
+func findDownOsds(dumpOut string, now time.Time, downFor time.Duration) ([]downOsd, error) {
+	var dump osdDump
+	err := json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph osd dump output. %v", err)
+	}
+	downStamps := map[int]string{}
+	for _, info := range dump.OsdXinfo {
+		downStamps[info.Osd] = info.DownStamp
+	}
+
+	var osds []downOsd
+	for _, osd := range dump.Osds {
+		if osd.Up != 0 || osd.In != 0 {
+			continue
+		}
+		// an osd that never booted has no down stamp, it can't be told how long it has been down
+		downStamp, err := time.Parse(downStampLayout, downStamps[osd.Osd])
+		if err != nil || downStamp.Year() < 2000 {
+			continue
+		}
+		if d := now.Sub(downStamp); d >= downFor {
+			osds = append(osds, downOsd{Id: osd.Osd, DownFor: d})
+		}
+	}
+	sort.Slice(osds, func(i, j int) bool { return osds[i].Id < osds[j].Id })
+	return osds, nil
+}
+
+// checkSafeToDestroy returns why ceph reports the osd is not safe to destroy, such as pgs that still need its
+// data, or an empty string when it's safe
+func checkSafeToDestroy(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int) string {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"osd", "safe-to-destroy", strconv.Itoa(id)}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err.Error()
+	}
+	if result.ExitCode != 0 {
+		return strings.TrimSpace(result.Stderr)
+	}
+	return ""
+}
+
+// purgeDownOsd removes the osd with rook, and deletes its deployment if rook left it behind
+func purgeDownOsd(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, force bool) error {
+	err := rook.RemoveOsds(ctx, clientsets, operatorNamespace, clusterNamespace, strconv.Itoa(id), strconv.FormatBool(force))
+	if err != nil {
+		return err
+	}
+
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	up, down, err := getOsdsByState(dumpOut)
+	if err != nil {
+		return err
+	}
+	for _, remaining := range append(up, down...) {
+		if remaining == id {
+			return fmt.Errorf("osd.%d is still in the osd map after its removal", id)
+		}
+	}
+
+	deploymentName := fmt.Sprintf("rook-ceph-osd-%d", id)
+	err = clientsets.Kube.AppsV1().Deployments(clusterNamespace).Delete(ctx, deploymentName, v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete deployment %s. %v", deploymentName, err)
+	}
+	return nil
+}
+
+func printDownOsds(out io.Writer, osds []downOsd) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "OSD\tDOWN FOR\tSAFE TO DESTROY")
+	for _, osd := range osds {
+		safe := "yes"
+		if osd.NotSafe != "" {
+			safe = fmt.Sprintf("no: %s", osd.NotSafe)
+		}
+		fmt.Fprintf(w, "osd.%d\t%s\t%s\n", osd.Id, formatDownFor(osd.DownFor), safe)
+	}
+	w.Flush()
+}
+
+func formatDownFor(d time.Duration) string {
+	return d.Round(time.Minute).String()
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDownOsds(t *testing.T) {
+	dumpOut := `{"osds":[
+		{"osd":0,"up":1,"in":1},
+		{"osd":1,"up":0,"in":0},
+		{"osd":2,"up":0,"in":1},
+		{"osd":3,"up":0,"in":0},
+		{"osd":4,"up":0,"in":0}
+	],"osd_xinfo":[
+		{"osd":0,"down_stamp":"0.000000"},
+		{"osd":1,"down_stamp":"2023-10-08T10:00:00.123456+0000"},
+		{"osd":2,"down_stamp":"2023-10-08T10:00:00.123456+0000"},
+		{"osd":3,"down_stamp":"2023-10-10T09:00:00.000000+0000"},
+		{"osd":4,"down_stamp":"0.000000"}
+	]}`
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	osds, err := findDownOsds(dumpOut, now, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, osds, 1)
+	assert.Equal(t, 1, osds[0].Id)
+	assert.Equal(t, "48h0m0s", formatDownFor(osds[0].DownFor))
+
+	// a shorter threshold also returns the osds that went down recently
+	osds, err = findDownOsds(dumpOut, now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, []int{osds[0].Id, osds[1].Id})
+
+	_, err = findDownOsds("not json", now, time.Hour)
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/debug"
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// maxFsckMessages is the number of fsck errors and warnings printed in the summary
+const maxFsckMessages = 10
+
+// fsckResult is the result decoded from the ceph-bluestore-tool fsck output
+type fsckResult struct {
+	Success  bool
+	Errors   []string
+	Warnings []string
+	// Status is the summary line of a failed fsck, such as "remaining 2 error(s) and warning(s)"
+	Status string
+}
+
+func Fsck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, osdID int, deep bool) {
+	err := fsck(ctx, clientsets, operatorNamespace, clusterNamespace, osdID, deep)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func fsck(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, osdID int, deep bool) error {
+	osd := fmt.Sprintf("osd.%d", osdID)
+	deploymentName := fmt.Sprintf("rook-ceph-osd-%d", osdID)
+	_, err := k8sutil.GetDeployment(ctx, clientsets.Kube, clusterNamespace, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to get the deployment of %s. %v", osd, err)
+	}
+
+	// the fsck needs exclusive access to the device, the osd is stopped while it runs
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"osd", "ok-to-stop", osd}, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s is not ok to stop, stopping it for the fsck would make pgs unavailable. %s", osd, strings.TrimSpace(result.Stderr))
+	}
+
+	fsckArgs := []string{"fsck", "--path", fmt.Sprintf("/var/lib/ceph/osd/ceph-%d", osdID)}
+	if deep {
+		fsckArgs = append(fsckArgs, "--deep", "true")
+	}
+	logging.Warning("%s is stopped while `ceph-bluestore-tool %s` runs in its debug pod, its pgs are degraded until it's restarted. A deep fsck reads all the data of the osd and can take hours", osd, strings.Join(fsckArgs, " "))
+	err = logging.Confirm(clusterNamespace, "%s is stopped to check its bluestore with fsck", osd)
+	if err != nil {
+		return fmt.Errorf("fsck of %s cancelled", osd)
+	}
+
+	// the operator would restart the osd, and noout prevents the data of the osd from being rebalanced meanwhile
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 0)
+	if err != nil {
+		return fmt.Errorf("failed to stop deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled down")
+
+	// noout, the debug pod and the operator are restored even when a step fails, so the osd is not left stopped
+	var fsckOut *exec.CommandResult
+	fsckErr := setOsdNoout(ctx, clientsets, operatorNamespace, clusterNamespace, "add-noout", osd)
+	if fsckErr == nil {
+		fsckOut, fsckErr = runFsckInDebugPod(ctx, clientsets, clusterNamespace, deploymentName, osdID, fsckArgs)
+
+		logging.Info("restarting %s", osd)
+		if err := debug.StopDebugWithError(ctx, clientsets.Kube, clusterNamespace, deploymentName); err != nil {
+			logging.Warning("failed to stop the debug pod of %s, restore it with `kubectl rook-ceph debug stop %s`. %v", osd, deploymentName, err)
+		}
+		if err := setOsdNoout(ctx, clientsets, operatorNamespace, clusterNamespace, "rm-noout", osd); err != nil {
+			logging.Warning("failed to unset noout on %s, unset it with `ceph osd rm-noout %s`. %v", osd, osd, err)
+		}
+	}
+
+	err = k8sutil.SetDeploymentScale(ctx, clientsets.Kube, operatorNamespace, "rook-ceph-operator", 1)
+	if err != nil {
+		return fmt.Errorf("failed to start deployment rook-ceph-operator. %v", err)
+	}
+	logging.Info("rook-ceph-operator deployment scaled up")
+
+	if fsckErr != nil {
+		return fmt.Errorf("failed to run fsck on %s. %v", osd, fsckErr)
+	}
+	fmt.Print(fsckOut.Stdout)
+	fmt.Print(fsckOut.Stderr)
+	fmt.Println()
+	return printFsckResult(osd, parseFsckOutput(fsckOut.Stdout+"\n"+fsckOut.Stderr), fsckOut.ExitCode)
+}
+
+// runFsckInDebugPod starts the debug pod of the osd and runs ceph-bluestore-tool in it
+func runFsckInDebugPod(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, deploymentName string, osdID int, fsckArgs []string) (*exec.CommandResult, error) {
+	err := debug.StartDebugWithError(ctx, clientsets.Kube, clusterNamespace, deploymentName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the debug pod keeps the labels of the osd pod, it's the running pod of the osd
+	debugPod, err := k8sutil.GetOsdPodByID(ctx, clientsets.Kube, clusterNamespace, osdID)
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("running fsck in the debug pod %s of osd.%d", debugPod.Name, osdID)
+	return exec.RunCommandInPodWithResult(ctx, clientsets, debugPod.Name, "osd", "ceph-bluestore-tool", fsckArgs, clusterNamespace)
+}
+
+// setOsdNoout runs `ceph osd add-noout` or `ceph osd rm-noout` on the osd
+func setOsdNoout(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, command, osd string) error {
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", []string{"osd", command, osd}, operatorNamespace, clusterNamespace)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run `ceph osd %s %s`. %v", command, osd, err)
+	}
+	return nil
+}
+
+// parseFsckOutput decodes the errors and the warnings logged by ceph-bluestore-tool fsck, such as
+// "2023-10-10T10:00:00.000+0000 7f1c -1 bluestore(/var/lib/ceph/osd/ceph-0) fsck error: #1:a8e1f7b6:::obj:head# extent 0x1000 or a subset is already allocated"
+func parseFsckOutput(output string) fsckResult {
+	var result fsckResult
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if _, message, found := strings.Cut(line, "fsck error: "); found {
+			result.Errors = append(result.Errors, message)
+		} else if _, message, found := strings.Cut(line, "fsck warning: "); found {
+			result.Warnings = append(result.Warnings, message)
+		} else if strings.HasPrefix(line, "fsck success") {
+			result.Success = true
+		} else if status, found := strings.CutPrefix(line, "fsck status: "); found {
+			result.Status = status
+		}
+	}
+	return result
+}
+
+func printFsckResult(osd string, result fsckResult, exitCode int) error {
+	printMessages := func(kind string, messages []string) {
+		if len(messages) == 0 {
+			return
+		}
+		logging.Warning("%d fsck %s on %s", len(messages), kind, osd)
+		for i, message := range messages {
+			if i == maxFsckMessages {
+				logging.Warning("\t... %d more", len(messages)-maxFsckMessages)
+				break
+			}
+			logging.Warning("\t%s", message)
+		}
+	}
+	printMessages("errors", result.Errors)
+	printMessages("warnings", result.Warnings)
+
+	if result.Success && exitCode == 0 {
+		logging.Info("bluestore fsck of %s succeeded", osd)
+		return nil
+	}
+	if result.Status != "" {
+		return fmt.Errorf("bluestore fsck of %s failed: %s. Run `ceph-bluestore-tool repair` in the debug pod of the osd to repair it, or replace the osd", osd, result.Status)
+	}
+	return fmt.Errorf("bluestore fsck of %s failed with exit code %d", osd, exitCode)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFsckOutput(t *testing.T) {
+	result := parseFsckOutput("fsck success\n")
+	assert.Equal(t, fsckResult{Success: true}, result)
+	assert.NoError(t, printFsckResult("osd.0", result, 0))
+
+	result = parseFsckOutput(`2023-10-10T10:00:00.000+0000 7f1c5d -1 bluestore(/var/lib/ceph/osd/ceph-0) fsck error: #1:a8e1f7b6:::obj1:head# extent 0x1000~1000 or a subset is already allocated (misreferenced)
+2023-10-10T10:00:01.000+0000 7f1c5d -1 bluestore(/var/lib/ceph/osd/ceph-0) fsck error: #1:c2b0e8a1:::obj2:head# lextent at 0x0~1000 spans a shard boundary
+2023-10-10T10:00:02.000+0000 7f1c5d  0 bluestore(/var/lib/ceph/osd/ceph-0) fsck warning: legacy statfs record found, removing
+fsck status: remaining 3 error(s) and warning(s)
+`)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{
+		"#1:a8e1f7b6:::obj1:head# extent 0x1000~1000 or a subset is already allocated (misreferenced)",
+		"#1:c2b0e8a1:::obj2:head# lextent at 0x0~1000 spans a shard boundary",
+	}, result.Errors)
+	assert.Equal(t, []string{"legacy statfs record found, removing"}, result.Warnings)
+	assert.Equal(t, "remaining 3 error(s) and warning(s)", result.Status)
+
+	err := printFsckResult("osd.0", result, 1)
+	assert.ErrorContains(t, err, "bluestore fsck of osd.0 failed: remaining 3 error(s) and warning(s)")
+
+	// the tool fails without a status when it can't open the osd
+	err = printFsckResult("osd.0", parseFsckOutput("error from fsck: (11) Resource temporarily unavailable\n"), 1)
+	assert.EqualError(t, err, "bluestore fsck of osd.0 failed with exit code 1")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// networkDump is the output of the dump_osd_network admin socket command, the heartbeat ping times of an osd to
+// its peers on the front and the back networks, in milliseconds
+type networkDump struct {
+	Entries []networkLink `json:"entries"`
+}
+
+type networkLink struct {
+	From      int     `json:"from osd"`
+	To        int     `json:"to osd"`
+	Interface string  `json:"interface"`
+	Stale     bool    `json:"stale"`
+	Last      float64 `json:"last"`
+	Average   struct {
+		OneMin     float64 `json:"1min"`
+		FiveMin    float64 `json:"5min"`
+		FifteenMin float64 `json:"15min"`
+	} `json:"average"`
+}
+
+// NetworkTest prints the heartbeat ping times between the osds on the front and the back networks, and reports the
+// links slower than the threshold, the stale links and the osds that can't be reached
+func NetworkTest(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, thresholdMs float64, all bool) {
+	err := networkTest(ctx, clientsets, operatorNamespace, clusterNamespace, thresholdMs, all)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func networkTest(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, thresholdMs float64, all bool) error {
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	upOsds, downOsds, err := getOsdsByState(dumpOut)
+	if err != nil {
+		return err
+	}
+	if len(upOsds) == 0 {
+		return fmt.Errorf("no osd is up to report its network ping times")
+	}
+
+	var links []networkLink
+	var failed []string
+	for _, id := range downOsds {
+		failed = append(failed, fmt.Sprintf("osd.%d is down", id))
+	}
+	for _, id := range upOsds {
+		// a threshold of 0 dumps the ping times to all the peers of the osd, not only the slow ones
+		result := tellOsd(ctx, clientsets, operatorNamespace, clusterNamespace, id, []string{"dump_osd_network", "0"})
+		if result.Error != "" {
+			failed = append(failed, fmt.Sprintf("osd.%d can't be reached: %s", id, result.Error))
+			continue
+		}
+		var dump networkDump
+		err := json.Unmarshal([]byte(result.Output), &dump)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("failed to parse the dump_osd_network output of osd.%d. %v", id, err))
+			continue
+		}
+		links = append(links, dump.Entries...)
+	}
+
+	slow, stale := findSlowLinks(links, thresholdMs)
+	printed := append(slow, stale...)
+	if all {
+		printed = links
+	}
+	if len(printed) != 0 {
+		sortNetworkLinks(printed)
+		printNetworkLinks(os.Stdout, printed)
+		fmt.Println()
+	}
+
+	for _, message := range failed {
+		logging.Warning(message)
+	}
+	for _, link := range stale {
+		logging.Warning("osd.%d to osd.%d on the %s network is stale, osd.%d didn't get heartbeat replies recently", link.From, link.To, link.Interface, link.From)
+	}
+	if len(slow) != 0 {
+		logging.Warning("%d of %d links are slower than %gms over the last minute, check the network between the nodes of these osds", len(slow), len(links), thresholdMs)
+	}
+	if len(slow) == 0 && len(stale) == 0 && len(failed) == 0 {
+		logging.Info("the %d links between the %d osds are below %gms over the last minute", len(links), len(upOsds), thresholdMs)
+	}
+	return nil
+}
+
+// findSlowLinks returns the links with an average ping time over the last minute above the threshold, and the stale
+// links, whose ping times are no longer updated
+func findSlowLinks(links []networkLink, thresholdMs float64) ([]networkLink, []networkLink) {
+	var slow, stale []networkLink
+	for _, link := range links {
+		if link.Stale {
+			stale = append(stale, link)
+		} else if link.Average.OneMin > thresholdMs {
+			slow = append(slow, link)
+		}
+	}
+	return slow, stale
+}
+
+func sortNetworkLinks(links []networkLink) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		if links[i].To != links[j].To {
+			return links[i].To < links[j].To
+		}
+		return links[i].Interface < links[j].Interface
+	})
+}
+
+func printNetworkLinks(out io.Writer, links []networkLink) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "FROM\tTO\tNETWORK\t1MIN (MS)\t5MIN (MS)\t15MIN (MS)\tLAST (MS)\tSTALE")
+	for _, link := range links {
+		fmt.Fprintf(w, "osd.%d\tosd.%d\t%s\t%.3f\t%.3f\t%.3f\t%.3f\t%t\n", link.From, link.To, link.Interface,
+			link.Average.OneMin, link.Average.FiveMin, link.Average.FifteenMin, link.Last, link.Stale)
+	}
+	w.Flush()
+}
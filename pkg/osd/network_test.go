@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSlowLinks(t *testing.T) {
+	dumpOut := `{
+"threshold": 0,
+"entries": [
+	{"last update": "Tue Oct 10 10:00:00 2023", "stale": false, "from osd": 0, "to osd": 2, "interface": "back",
+	 "average": {"1min": 0.5, "5min": 0.4, "15min": 0.4}, "last": 0.6},
+	{"last update": "Tue Oct 10 10:00:00 2023", "stale": false, "from osd": 0, "to osd": 1, "interface": "front",
+	 "average": {"1min": 1500.2, "5min": 900.1, "15min": 300.5}, "last": 1800},
+	{"last update": "Tue Oct 10 09:50:00 2023", "stale": true, "from osd": 0, "to osd": 1, "interface": "back",
+	 "average": {"1min": 0, "5min": 0, "15min": 0.3}, "last": 0}
+]}`
+	var dump networkDump
+	assert.NoError(t, json.Unmarshal([]byte(dumpOut), &dump))
+	assert.Len(t, dump.Entries, 3)
+
+	slow, stale := findSlowLinks(dump.Entries, 1000)
+	assert.Len(t, slow, 1)
+	assert.Equal(t, 1, slow[0].To)
+	assert.Equal(t, "front", slow[0].Interface)
+	assert.Equal(t, 1500.2, slow[0].Average.OneMin)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "back", stale[0].Interface)
+
+	slow, _ = findSlowLinks(dump.Entries, 0.1)
+	assert.Len(t, slow, 2)
+
+	sortNetworkLinks(dump.Entries)
+	var out strings.Builder
+	printNetworkLinks(&out, dump.Entries)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[1], "osd.0   osd.1   back "))
+	assert.True(t, strings.HasPrefix(lines[2], "osd.0   osd.1   front "))
+	assert.Contains(t, lines[2], "1500.200")
+}
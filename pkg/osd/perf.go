@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+type osdPerf struct {
+	OsdStats     *osdPerfInfos `json:"osdstats"`
+	OsdPerfInfos []osdPerfInfo `json:"osd_perf_infos"`
+}
+
+type osdPerfInfos struct {
+	OsdPerfInfos []osdPerfInfo `json:"osd_perf_infos"`
+}
+
+type osdPerfInfo struct {
+	Id        int       `json:"id"`
+	PerfStats perfStats `json:"perf_stats"`
+}
+
+type perfStats struct {
+	CommitLatencyMs int `json:"commit_latency_ms"`
+	ApplyLatencyMs  int `json:"apply_latency_ms"`
+}
+
+func Perf(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string, thresholdMs int) {
+	err := perf(ctx, clientsets, operatorNamespace, clusterNamespace, sortBy, thresholdMs)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func perf(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string, thresholdMs int) error {
+	perfOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "perf", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+	perfInfos, err := unMarshalOsdPerf(perfOut)
+	if err != nil {
+		return fmt.Errorf("failed to parse osd perf output. %v", err)
+	}
+
+	err = sortOsdPerf(perfInfos, sortBy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("OSD\tCOMMIT_LATENCY(ms)\tAPPLY_LATENCY(ms)\n")
+	var slowOsds []int
+	for _, info := range perfInfos {
+		fmt.Printf("%d\t%d\t%d\n", info.Id, info.PerfStats.CommitLatencyMs, info.PerfStats.ApplyLatencyMs)
+		if info.PerfStats.CommitLatencyMs > thresholdMs || info.PerfStats.ApplyLatencyMs > thresholdMs {
+			slowOsds = append(slowOsds, info.Id)
+		}
+	}
+
+	fmt.Println()
+	if len(slowOsds) != 0 {
+		logging.Warning("osds with latency above %dms: %v", thresholdMs, slowOsds)
+	} else {
+		logging.Info("no osd with latency above %dms", thresholdMs)
+	}
+	return nil
+}
+
+func unMarshalOsdPerf(perfOut string) ([]osdPerfInfo, error) {
+	var perf osdPerf
+	err := json.Unmarshal([]byte(perfOut), &perf)
+	if err != nil {
+		return nil, err
+	}
+
+	// newer ceph versions nest the perf infos under "osdstats"
+	if perf.OsdStats != nil {
+		return perf.OsdStats.OsdPerfInfos, nil
+	}
+	return perf.OsdPerfInfos, nil
+}
+
+func sortOsdPerf(perfInfos []osdPerfInfo, sortBy string) error {
+	switch sortBy {
+	case "id":
+		sort.SliceStable(perfInfos, func(i, j int) bool { return perfInfos[i].Id < perfInfos[j].Id })
+	case "commit":
+		sort.SliceStable(perfInfos, func(i, j int) bool {
+			return perfInfos[i].PerfStats.CommitLatencyMs > perfInfos[j].PerfStats.CommitLatencyMs
+		})
+	case "apply":
+		sort.SliceStable(perfInfos, func(i, j int) bool {
+			return perfInfos[i].PerfStats.ApplyLatencyMs > perfInfos[j].PerfStats.ApplyLatencyMs
+		})
+	default:
+		return fmt.Errorf("invalid sort key %q, supported keys are id, commit and apply", sortBy)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOsdPerf(t *testing.T) {
+	// the output of `ceph osd perf --format json` nests the perf infos under osdstats since octopus
+	perfOut := `{"osdstats":{"osd_perf_infos":[
+{"id":2,"perf_stats":{"commit_latency_ms":3,"apply_latency_ms":3,"commit_latency_ns":3000000,"apply_latency_ns":3000000}},
+{"id":0,"perf_stats":{"commit_latency_ms":12,"apply_latency_ms":1,"commit_latency_ns":12000000,"apply_latency_ns":1000000}},
+{"id":1,"perf_stats":{"commit_latency_ms":5,"apply_latency_ms":40,"commit_latency_ns":5000000,"apply_latency_ns":40000000}}]}}`
+	perfInfos, err := unMarshalOsdPerf(perfOut)
+	assert.NoError(t, err)
+	assert.Len(t, perfInfos, 3)
+
+	ids := func(infos []osdPerfInfo) []int {
+		var ids []int
+		for _, info := range infos {
+			ids = append(ids, info.Id)
+		}
+		return ids
+	}
+	assert.NoError(t, sortOsdPerf(perfInfos, "id"))
+	assert.Equal(t, []int{0, 1, 2}, ids(perfInfos))
+	assert.NoError(t, sortOsdPerf(perfInfos, "commit"))
+	assert.Equal(t, []int{0, 1, 2}, ids(perfInfos))
+	assert.NoError(t, sortOsdPerf(perfInfos, "apply"))
+	assert.Equal(t, []int{1, 2, 0}, ids(perfInfos))
+	assert.Equal(t, perfStats{CommitLatencyMs: 5, ApplyLatencyMs: 40}, perfInfos[0].PerfStats)
+	assert.Error(t, sortOsdPerf(perfInfos, "latency"))
+
+	// older ceph versions print the perf infos at the top level
+	perfInfos, err = unMarshalOsdPerf(`{"osd_perf_infos":[{"id":0,"perf_stats":{"commit_latency_ms":7,"apply_latency_ms":7}}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []osdPerfInfo{{Id: 0, PerfStats: perfStats{CommitLatencyMs: 7, ApplyLatencyMs: 7}}}, perfInfos)
+
+	_, err = unMarshalOsdPerf("")
+	assert.Error(t, err)
+}
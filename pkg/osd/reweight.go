@@ -0,0 +1,206 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// defaultReweight is the override reweight of an osd that is in and was never reweighted
+const defaultReweight = 1.0
+
+type osdTree struct {
+	Nodes []osdTreeNode `json:"nodes"`
+}
+
+type osdTreeNode struct {
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	DeviceClass string  `json:"device_class"`
+	CrushWeight float64 `json:"crush_weight"`
+	Reweight    float64 `json:"reweight"`
+	Status      string  `json:"status"`
+}
+
+// isOut checks if the osd is out, ceph sets the reweight of an out osd to 0
+func (n osdTreeNode) isOut() bool {
+	return n.Reweight == 0
+}
+
+// isReweighted checks if the override reweight of an osd that is in was changed with `ceph osd reweight`
+func (n osdTreeNode) isReweighted() bool {
+	return !n.isOut() && n.Reweight != defaultReweight
+}
+
+func ReweightList(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	osds, err := getOsdReweights(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if len(osds) == 0 {
+		logging.Info("no osd found in the osd tree")
+		return
+	}
+	printOsdReweights(os.Stdout, osds)
+
+	reweighted := 0
+	for _, osd := range osds {
+		if osd.isReweighted() {
+			reweighted++
+		}
+	}
+	fmt.Println()
+	if reweighted == 0 {
+		logging.Info("no osd has a manual reweight")
+		return
+	}
+	logging.Warning("%d osds have a manual reweight which skews the data placement, reset them with `kubectl rook-ceph osd reweight reset`", reweighted)
+}
+
+func ReweightReset(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, osdIds []int) {
+	err := reweightReset(ctx, clientsets, operatorNamespace, clusterNamespace, osdIds)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func reweightReset(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, osdIds []int) error {
+	osds, err := getOsdReweights(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	toReset, err := selectReweightedOsds(osds, osdIds)
+	if err != nil {
+		return err
+	}
+	if len(toReset) == 0 {
+		logging.Info("no osd has a manual reweight to reset")
+		return nil
+	}
+
+	printOsdReweights(os.Stdout, toReset)
+	err = logging.Confirm(clusterNamespace, "The reweight of %d osds is reset to %.1f, which moves data back to them", len(toReset), defaultReweight)
+	if err != nil {
+		return fmt.Errorf("osd reweight reset cancelled")
+	}
+
+	var failed []string
+	for _, osd := range toReset {
+		args := []string{"osd", "reweight", strconv.Itoa(osd.Id), strconv.FormatFloat(defaultReweight, 'f', 1, 64)}
+		result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", args, operatorNamespace, clusterNamespace)
+		if err == nil && result.ExitCode != 0 {
+			err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+		}
+		if err != nil {
+			logging.Error(fmt.Errorf("failed to reset the reweight of %s. %v", osd.Name, err))
+			failed = append(failed, osd.Name)
+			continue
+		}
+		logging.Info("%s reweight reset from %.5f to %.1f", osd.Name, osd.Reweight, defaultReweight)
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to reset the reweight of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func getOsdReweights(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]osdTreeNode, error) {
+	treeOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "tree", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	return parseOsdReweights(treeOut)
+}
+
+// parseOsdReweights returns the osds of the osd tree sorted by id
+func parseOsdReweights(treeOut string) ([]osdTreeNode, error) {
+	var tree osdTree
+	err := json.Unmarshal([]byte(treeOut), &tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph osd tree output. %v", err)
+	}
+	var osds []osdTreeNode
+	for _, node := range tree.Nodes {
+		if node.Type == "osd" {
+			osds = append(osds, node)
+		}
+	}
+	sort.Slice(osds, func(i, j int) bool { return osds[i].Id < osds[j].Id })
+	return osds, nil
+}
+
+// selectReweightedOsds returns the reweighted osds, only among the ids passed if any. The out osds are not reset,
+// marking them in is not the same as clearing a reweight.
+func selectReweightedOsds(osds []osdTreeNode, osdIds []int) ([]osdTreeNode, error) {
+	if len(osdIds) == 0 {
+		var reweighted []osdTreeNode
+		for _, osd := range osds {
+			if osd.isReweighted() {
+				reweighted = append(reweighted, osd)
+			}
+		}
+		return reweighted, nil
+	}
+
+	byId := map[int]osdTreeNode{}
+	for _, osd := range osds {
+		byId[osd.Id] = osd
+	}
+	var reweighted []osdTreeNode
+	for _, id := range osdIds {
+		osd, ok := byId[id]
+		if !ok {
+			return nil, fmt.Errorf("osd.%d not found in the osd tree", id)
+		}
+		switch {
+		case osd.isOut():
+			logging.Warning("skipping %s since it's out, mark it in with `ceph osd in %d` instead", osd.Name, osd.Id)
+		case !osd.isReweighted():
+			logging.Info("skipping %s since it has no manual reweight", osd.Name)
+		default:
+			reweighted = append(reweighted, osd)
+		}
+	}
+	return reweighted, nil
+}
+
+func printOsdReweights(out io.Writer, osds []osdTreeNode) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "OSD\tCLASS\tSTATUS\tCRUSH WEIGHT\tREWEIGHT\tMANUAL")
+	for _, osd := range osds {
+		status := osd.Status
+		if osd.isOut() {
+			status += ",out"
+		}
+		manual := "no"
+		if osd.isReweighted() {
+			manual = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.5f\t%.5f\t%s\n", osd.Name, osd.DeviceClass, status, osd.CrushWeight, osd.Reweight, manual)
+	}
+	w.Flush()
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testOsdTree = `{"nodes":[
+{"id":-1,"name":"default","type":"root","type_id":11,"children":[-3]},
+{"id":-3,"name":"node-a","type":"host","type_id":1,"children":[2,1,0]},
+{"id":2,"device_class":"hdd","name":"osd.2","type":"osd","type_id":0,"crush_weight":0.0977,"depth":2,"pool_weights":{},"exists":1,"status":"down","reweight":0,"primary_affinity":1},
+{"id":1,"device_class":"hdd","name":"osd.1","type":"osd","type_id":0,"crush_weight":0.0977,"depth":2,"pool_weights":{},"exists":1,"status":"up","reweight":0.85000610351562,"primary_affinity":1},
+{"id":0,"device_class":"hdd","name":"osd.0","type":"osd","type_id":0,"crush_weight":0.0977,"depth":2,"pool_weights":{},"exists":1,"status":"up","reweight":1,"primary_affinity":1}],
+"stray":[]}`
+
+func TestParseOsdReweights(t *testing.T) {
+	osds, err := parseOsdReweights(testOsdTree)
+	assert.NoError(t, err)
+	assert.Len(t, osds, 3)
+	assert.Equal(t, 0, osds[0].Id)
+	assert.False(t, osds[0].isReweighted())
+	assert.True(t, osds[1].isReweighted())
+	// an out osd has a reweight of 0, it's not a manual reweight
+	assert.True(t, osds[2].isOut())
+	assert.False(t, osds[2].isReweighted())
+
+	var out bytes.Buffer
+	printOsdReweights(&out, osds)
+	assert.Equal(t, `OSD     CLASS   STATUS     CRUSH WEIGHT   REWEIGHT   MANUAL
+osd.0   hdd     up         0.09770        1.00000    no
+osd.1   hdd     up         0.09770        0.85001    yes
+osd.2   hdd     down,out   0.09770        0.00000    no
+`, out.String())
+
+	_, err = parseOsdReweights("not json")
+	assert.Error(t, err)
+}
+
+func TestSelectReweightedOsds(t *testing.T) {
+	osds, err := parseOsdReweights(testOsdTree)
+	assert.NoError(t, err)
+
+	selected, err := selectReweightedOsds(osds, nil)
+	assert.NoError(t, err)
+	assert.Len(t, selected, 1)
+	assert.Equal(t, "osd.1", selected[0].Name)
+
+	// the out osd and the osd without a manual reweight are skipped
+	selected, err = selectReweightedOsds(osds, []int{0, 2})
+	assert.NoError(t, err)
+	assert.Empty(t, selected)
+
+	_, err = selectReweightedOsds(osds, []int{5})
+	assert.EqualError(t, err, "osd.5 not found in the osd tree")
+}
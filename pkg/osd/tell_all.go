@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+type osdDump struct {
+	Osds []struct {
+		Osd int `json:"osd"`
+		Up  int `json:"up"`
+		In  int `json:"in"`
+	} `json:"osds"`
+	OsdXinfo []struct {
+		Osd       int    `json:"osd"`
+		DownStamp string `json:"down_stamp"`
+	} `json:"osd_xinfo"`
+}
+
+// tellResult is the result of the ceph tell command on an osd
+type tellResult struct {
+	Id     int
+	Output string
+	// Error is the reason the command failed on the osd, empty when it succeeded
+	Error string
+}
+
+func TellAll(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) {
+	err := tellAll(ctx, clientsets, operatorNamespace, clusterNamespace, args)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func tellAll(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) error {
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	upOsds, downOsds, err := getOsdsByState(dumpOut)
+	if err != nil {
+		return err
+	}
+	if len(upOsds) == 0 && len(downOsds) == 0 {
+		return fmt.Errorf("no osd found in the osd map")
+	}
+
+	// ceph tell waits for a down osd to come back, so they are reported as failed rather than told
+	var results []tellResult
+	for _, id := range downOsds {
+		results = append(results, tellResult{Id: id, Error: "the osd is down"})
+	}
+	for _, id := range upOsds {
+		logging.Info("running 'ceph tell osd.%d %s'", id, strings.Join(args, " "))
+		results = append(results, tellOsd(ctx, clientsets, operatorNamespace, clusterNamespace, id, args))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Id < results[j].Id })
+
+	printTellResults(os.Stdout, results)
+	return getTellError(results)
+}
+
+func tellOsd(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, id int, args []string) tellResult {
+	tellArgs := append([]string{"tell", fmt.Sprintf("osd.%d", id)}, args...)
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, "ceph", tellArgs, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return tellResult{Id: id, Error: err.Error()}
+	}
+	if result.ExitCode != 0 {
+		return tellResult{Id: id, Output: result.Stdout, Error: fmt.Sprintf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))}
+	}
+	return tellResult{Id: id, Output: result.Stdout}
+}
+
+// getOsdsByState returns the ids of the up and the down osds of the osd dump
+func getOsdsByState(dumpOut string) ([]int, []int, error) {
+	var dump osdDump
+	err := json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse ceph osd dump output. %v", err)
+	}
+	var up, down []int
+	for _, osd := range dump.Osds {
+		if osd.Up == 1 {
+			up = append(up, osd.Osd)
+		} else {
+			down = append(down, osd.Osd)
+		}
+	}
+	return up, down, nil
+}
+
+// printTellResults prints the output of each osd, on the line of the osd when it's a single line
+func printTellResults(out io.Writer, results []tellResult) {
+	for _, result := range results {
+		output := strings.TrimSpace(result.Output)
+		if result.Error != "" {
+			fmt.Fprintf(out, "osd.%d: FAILED: %s\n", result.Id, result.Error)
+			if output == "" {
+				continue
+			}
+		}
+		if strings.Contains(output, "\n") {
+			fmt.Fprintf(out, "osd.%d:\n", result.Id)
+			for _, line := range strings.Split(output, "\n") {
+				fmt.Fprintf(out, "    %s\n", line)
+			}
+		} else if result.Error == "" {
+			fmt.Fprintf(out, "osd.%d: %s\n", result.Id, output)
+		} else {
+			fmt.Fprintf(out, "    %s\n", output)
+		}
+	}
+}
+
+// getTellError returns an error listing the osds the command failed on
+func getTellError(results []tellResult) error {
+	var failed []string
+	for _, result := range results {
+		if result.Error != "" {
+			failed = append(failed, fmt.Sprintf("osd.%d", result.Id))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("ceph tell failed on %d of %d osds: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+	logging.Info("ceph tell succeeded on the %d osds", len(results))
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOsdsByState(t *testing.T) {
+	up, down, err := getOsdsByState(`{"epoch":42,"osds":[{"osd":0,"up":1,"in":1},{"osd":1,"up":0,"in":1},{"osd":2,"up":1,"in":0}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, up)
+	assert.Equal(t, []int{1}, down)
+
+	_, _, err = getOsdsByState("not json")
+	assert.Error(t, err)
+}
+
+func TestPrintTellResults(t *testing.T) {
+	results := []tellResult{
+		{Id: 0, Output: "{}\n"},
+		{Id: 1, Error: "the osd is down"},
+		{Id: 2, Output: "{\n    \"bytes_per_sec\": 104857600\n}\n"},
+		{Id: 3, Output: "partial\n", Error: "exit code 22: invalid argument"},
+	}
+	var out bytes.Buffer
+	printTellResults(&out, results)
+	assert.Equal(t, `osd.0: {}
+osd.1: FAILED: the osd is down
+osd.2:
+    {
+        "bytes_per_sec": 104857600
+    }
+osd.3: FAILED: exit code 22: invalid argument
+    partial
+`, out.String())
+
+	err := getTellError(results)
+	assert.EqualError(t, err, "ceph tell failed on 2 of 4 osds: osd.1, osd.3")
+	assert.NoError(t, getTellError(results[:1]))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ipv4Regexp = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// anonymizer is set when the output is anonymized with --anonymize
+var anonymizer *Anonymizer
+
+// Anonymizer masks the node names, the ips and the fsid of a cluster. Each identifier is replaced
+// by a salted hash so the same identifier is masked the same way within a run, which preserves
+// the topology, while the salt changes for each run so the hashes can't be reversed by guessing.
+type Anonymizer struct {
+	salt       []byte
+	namesKind  map[string]string
+	namesRegex *regexp.Regexp
+	masked     map[string]string
+	mu         sync.Mutex
+}
+
+// NewAnonymizer returns an anonymizer of the ips, the node names and the fsid
+func NewAnonymizer(nodeNames []string, fsid string) *Anonymizer {
+	salt := make([]byte, 16)
+	// the salt is only used to make the hashes harder to reverse, a failure leaves it empty
+	_, _ = rand.Read(salt)
+
+	a := &Anonymizer{salt: salt, namesKind: map[string]string{}, masked: map[string]string{}}
+	for _, name := range nodeNames {
+		if name != "" {
+			a.namesKind[name] = "node"
+		}
+	}
+	if fsid != "" {
+		a.namesKind[fsid] = "fsid"
+	}
+	if len(a.namesKind) != 0 {
+		// the longest names are matched first so a name is not masked partially by a shorter one
+		names := make([]string, 0, len(a.namesKind))
+		for name := range a.namesKind {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		a.namesRegex = regexp.MustCompile(`\b(?:` + strings.Join(names, "|") + `)\b`)
+	}
+	return a
+}
+
+// EnableAnonymize anonymizes the output of the formatters and the logs for the rest of the run
+func EnableAnonymize(a *Anonymizer) {
+	anonymizer = a
+}
+
+// Anonymize masks the identifiers in the text when the output is anonymized
+func Anonymize(text string) string {
+	if anonymizer == nil {
+		return text
+	}
+	return anonymizer.Anonymize(text)
+}
+
+// Anonymize masks the node names, the fsid and the ips in the text
+func (a *Anonymizer) Anonymize(text string) string {
+	if a.namesRegex != nil {
+		text = a.namesRegex.ReplaceAllStringFunc(text, func(name string) string {
+			return a.mask(a.namesKind[name], name)
+		})
+	}
+	return ipv4Regexp.ReplaceAllStringFunc(text, func(ip string) string {
+		return a.mask("ip", ip)
+	})
+}
+
+func (a *Anonymizer) mask(kind, identifier string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if masked, ok := a.masked[identifier]; ok {
+		return masked
+	}
+	hash := sha256.Sum256(append(a.salt, identifier...))
+	masked := kind + "-" + hex.EncodeToString(hash[:])[:8]
+	a.masked[identifier] = masked
+	return masked
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.yaml")
+	assert.NoError(t, WriteNewFile(path, []byte("kind: CephCluster\n")))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// an existing file is not overwritten
+	err = WriteNewFile(path, []byte("kind: CephBlockPool\n"))
+	assert.EqualError(t, err, "the file "+path+" already exists, pass a file that doesn't exist")
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "kind: CephCluster\n", string(content))
+
+	assert.Error(t, WriteNewFile(filepath.Join(t.TempDir(), "missing", "backup.yaml"), nil))
+}
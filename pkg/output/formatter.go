@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package output renders the structured results of the commands in the format of the --output flag.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Formatter renders a value to its writer in a given format
+type Formatter interface {
+	Render(v any) error
+}
+
+// NewFormatter returns the formatter writing to w in the format, json or yaml
+func NewFormatter(format string, w io.Writer) (Formatter, error) {
+	switch format {
+	case JSON:
+		return &jsonFormatter{w: w}, nil
+	case YAML:
+		return &yamlFormatter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format %q, expected %s or %s", format, JSON, YAML)
+	}
+}
+
+type jsonFormatter struct {
+	w io.Writer
+}
+
+func (f *jsonFormatter) Render(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the output to json: %v", err)
+	}
+	_, err = fmt.Fprintln(f.w, Anonymize(string(out)))
+	return err
+}
+
+type yamlFormatter struct {
+	w io.Writer
+}
+
+func (f *yamlFormatter) Render(v any) error {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the output to yaml: %v", err)
+	}
+	_, err = fmt.Fprintln(f.w, Anonymize(strings.TrimSuffix(string(out), "\n")))
+	return err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter(t *testing.T) {
+	type check struct {
+		Name     string   `json:"name"`
+		Status   string   `json:"status"`
+		Messages []string `json:"messages,omitempty"`
+	}
+	v := []check{{Name: "mgr-pods", Status: "warning", Messages: []string{"At least one mgr pod should be running"}}}
+
+	var out bytes.Buffer
+	formatter, err := NewFormatter(YAML, &out)
+	assert.NoError(t, err)
+	assert.NoError(t, formatter.Render(v))
+	assert.Equal(t, `- messages:
+  - At least one mgr pod should be running
+  name: mgr-pods
+  status: warning
+`, out.String())
+
+	out.Reset()
+	formatter, err = NewFormatter(JSON, &out)
+	assert.NoError(t, err)
+	assert.NoError(t, formatter.Render(v))
+	assert.Equal(t, `[
+  {
+    "name": "mgr-pods",
+    "status": "warning",
+    "messages": [
+      "At least one mgr pod should be running"
+    ]
+  }
+]
+`, out.String())
+
+	_, err = NewFormatter("table", &out)
+	assert.Error(t, err)
+}
+
+func TestAnonymize(t *testing.T) {
+	a := NewAnonymizer([]string{"node-1", "node-10"}, "b8e84a4c-b9e0-4ac4-a0f6-7d6b9d70ecf5")
+	out := a.Anonymize("rook-ceph-mon-a on node-1 10.0.0.1, node-10 10.0.0.1 fsid b8e84a4c-b9e0-4ac4-a0f6-7d6b9d70ecf5")
+	assert.NotContains(t, out, "node-1 ")
+	assert.NotContains(t, out, "node-10")
+	assert.NotContains(t, out, "10.0.0.1")
+	assert.NotContains(t, out, "b8e84a4c")
+	assert.Regexp(t, `^rook-ceph-mon-a on node-[0-9a-f]{8} ip-[0-9a-f]{8}, node-[0-9a-f]{8} ip-[0-9a-f]{8} fsid fsid-[0-9a-f]{8}$`, out)
+
+	// the same identifier is masked the same way within a run
+	assert.Equal(t, a.Anonymize("node-1"), a.Anonymize("node-1"))
+	assert.NotEqual(t, a.Anonymize("node-1"), a.Anonymize("node-10"))
+	assert.Equal(t, a.Anonymize("10.0.0.1"), a.Anonymize("10.0.0.1"))
+
+	var buf bytes.Buffer
+	formatter, err := NewFormatter(JSON, &buf)
+	assert.NoError(t, err)
+	EnableAnonymize(a)
+	defer EnableAnonymize(nil)
+	assert.NoError(t, formatter.Render(map[string]string{"node": "node-1"}))
+	assert.Contains(t, buf.String(), a.Anonymize("node-1"))
+	assert.NotContains(t, buf.String(), `"node-1"`)
+}
+
+func TestBar(t *testing.T) {
+	assert.Equal(t, "[..............................]   0.0%", Bar(0, 30))
+	assert.Equal(t, "[===============...............]  50.0%", Bar(0.5, 30))
+	assert.Equal(t, "[==============================] 100.0%", Bar(1.2, 30))
+	assert.Equal(t, "[==........]  25.0%", Bar(0.25, 10))
+}
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, "10.0 GiB", Bytes(10737418240))
+	assert.Equal(t, "512 B", Bytes(512))
+	assert.Equal(t, "1.5 KiB", Bytes(1536))
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// domain is a failure domain and the daemons running on its nodes
+type domain struct {
+	name  string
+	nodes []string
+	osds  int
+	mons  int
+}
+
+func Check(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, failureDomainLabel string) {
+	err := check(ctx, clientsets, clusterNamespace, failureDomainLabel)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func check(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace, failureDomainLabel string) error {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	nodes, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the nodes. %v", err)
+	}
+	osdPods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, v1.ListOptions{LabelSelector: "app=rook-ceph-osd"})
+	if err != nil {
+		return fmt.Errorf("failed to list the osd pods. %v", err)
+	}
+	monPods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, v1.ListOptions{LabelSelector: "app=rook-ceph-mon"})
+	if err != nil {
+		return fmt.Errorf("failed to list the mon pods. %v", err)
+	}
+
+	spec := &cephCluster.Spec
+	expectedOsdNodes := getExpectedOsdNodes(spec, nodes.Items)
+	domains, warnings := findPlacementGaps(nodes.Items, osdPods, monPods, expectedOsdNodes, spec.Mon.Count, failureDomainLabel)
+
+	fmt.Printf("FAILURE_DOMAIN\tNODES\tOSDS\tMONS\n")
+	for _, d := range domains {
+		fmt.Printf("%s\t%s\t%d\t%d\n", d.name, strings.Join(d.nodes, ","), d.osds, d.mons)
+	}
+	fmt.Println()
+
+	if expectedOsdNodes == nil {
+		logging.Info("the CephCluster doesn't list the osd nodes, only the spread of the running osds is checked")
+	}
+	for _, warning := range warnings {
+		logging.Warning(warning)
+	}
+	if len(warnings) == 0 {
+		logging.Info("the osds and mons are placed as expected across the %q failure domains", failureDomainLabel)
+	}
+	return nil
+}
+
+// getExpectedOsdNodes returns the nodes that should host osds according to the CephCluster storage
+// spec, or nil when they can't be known such as with osds on PVCs
+func getExpectedOsdNodes(spec *cephv1.ClusterSpec, nodes []corev1.Node) []string {
+	var expected []string
+	if spec.Storage.UseAllNodes {
+		for i := range nodes {
+			if !nodes[i].Spec.Unschedulable {
+				expected = append(expected, nodes[i].Name)
+			}
+		}
+		return expected
+	}
+	for _, node := range spec.Storage.Nodes {
+		expected = append(expected, node.Name)
+	}
+	return expected
+}
+
+// findPlacementGaps groups the osds and mons by failure domain, and returns the warnings for the
+// expected osd nodes without osds and the mons that are not spread across the failure domains
+func findPlacementGaps(nodes []corev1.Node, osdPods, monPods []corev1.Pod, expectedOsdNodes []string, monCount int, failureDomainLabel string) ([]*domain, []string) {
+	domainsByName := map[string]*domain{}
+	nodeDomains := map[string]string{}
+	for i := range nodes {
+		name, ok := nodes[i].Labels[failureDomainLabel]
+		if !ok {
+			name = "<unlabeled>"
+		}
+		if _, ok := domainsByName[name]; !ok {
+			domainsByName[name] = &domain{name: name}
+		}
+		domainsByName[name].nodes = append(domainsByName[name].nodes, nodes[i].Name)
+		nodeDomains[nodes[i].Name] = name
+	}
+
+	osdsByNode, _ := k8sutil.GroupPodsByNode(osdPods)
+	monsByNode, _ := k8sutil.GroupPodsByNode(monPods)
+	for nodeName, domainName := range nodeDomains {
+		domainsByName[domainName].osds += len(osdsByNode[nodeName])
+		domainsByName[domainName].mons += len(monsByNode[nodeName])
+	}
+
+	var domains []*domain
+	for _, d := range domainsByName {
+		sort.Strings(d.nodes)
+		domains = append(domains, d)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].name < domains[j].name })
+
+	var warnings []string
+	for _, nodeName := range expectedOsdNodes {
+		if len(osdsByNode[nodeName]) == 0 {
+			warnings = append(warnings, fmt.Sprintf("node %s should host osds but has no osd pod", nodeName))
+		}
+	}
+	for _, d := range domains {
+		if d.osds == 0 && containsAny(expectedOsdNodes, d.nodes) {
+			warnings = append(warnings, fmt.Sprintf("failure domain %s has no osd", d.name))
+		}
+	}
+
+	// the mons should be spread across as many failure domains as there are mons
+	domainsWithMons := 0
+	for _, d := range domains {
+		if d.mons > 0 {
+			domainsWithMons++
+		}
+		if d.mons > 1 {
+			warnings = append(warnings, fmt.Sprintf("failure domain %s is running %d mons, losing it could break the mon quorum", d.name, d.mons))
+		}
+	}
+	expectedMonDomains := monCount
+	if len(domains) < expectedMonDomains {
+		expectedMonDomains = len(domains)
+	}
+	if domainsWithMons < expectedMonDomains {
+		warnings = append(warnings, fmt.Sprintf("the mons are running in %d failure domains while %d are expected", domainsWithMons, expectedMonDomains))
+	}
+	return domains, warnings
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
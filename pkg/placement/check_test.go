@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindPlacementGaps(t *testing.T) {
+	zoneLabel := "topology.kubernetes.io/zone"
+	newNode := func(name, zone string) corev1.Node {
+		return corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{zoneLabel: zone}}}
+	}
+	newPod := func(name, nodeName string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}, Spec: corev1.PodSpec{NodeName: nodeName}}
+	}
+	nodes := []corev1.Node{newNode("node-1", "a"), newNode("node-2", "b"), newNode("node-3", "c"), newNode("node-4", "a")}
+
+	// the daemons are spread as expected
+	osdPods := []corev1.Pod{newPod("osd-0", "node-1"), newPod("osd-1", "node-2"), newPod("osd-2", "node-3")}
+	monPods := []corev1.Pod{newPod("mon-a", "node-1"), newPod("mon-b", "node-2"), newPod("mon-c", "node-3")}
+	domains, warnings := findPlacementGaps(nodes, osdPods, monPods, []string{"node-1", "node-2", "node-3"}, 3, zoneLabel)
+	assert.Empty(t, warnings)
+	assert.Len(t, domains, 3)
+	assert.Equal(t, []string{"node-1", "node-4"}, domains[0].nodes)
+	assert.Equal(t, 1, domains[0].osds)
+
+	// node-3 has no osd, and two mons are in zone a
+	osdPods = []corev1.Pod{newPod("osd-0", "node-1"), newPod("osd-1", "node-2")}
+	monPods = []corev1.Pod{newPod("mon-a", "node-1"), newPod("mon-b", "node-2"), newPod("mon-c", "node-4")}
+	_, warnings = findPlacementGaps(nodes, osdPods, monPods, []string{"node-1", "node-2", "node-3"}, 3, zoneLabel)
+	assert.Equal(t, []string{
+		"node node-3 should host osds but has no osd pod",
+		"failure domain c has no osd",
+		"failure domain a is running 2 mons, losing it could break the mon quorum",
+		"the mons are running in 2 failure domains while 3 are expected",
+	}, warnings)
+}
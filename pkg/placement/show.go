@@ -0,0 +1,330 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonTypes are the daemons of the CephCluster whose placement is shown
+var DaemonTypes = []string{"mon", "mgr", "osd"}
+
+// getSpecPlacement returns the placement rook applies to the daemon, its placement merged with the "all" placement
+func getSpecPlacement(spec cephv1.PlacementSpec, daemonType string) cephv1.Placement {
+	switch daemonType {
+	case "mon":
+		return cephv1.GetMonPlacement(spec)
+	case "mgr":
+		return cephv1.GetMgrPlacement(spec)
+	default:
+		return cephv1.GetOSDPlacement(spec)
+	}
+}
+
+func Show(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, daemonTypes []string) {
+	err := show(ctx, clientsets, clusterNamespace, daemonTypes)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func show(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, daemonTypes []string) error {
+	for _, daemonType := range daemonTypes {
+		if !isDaemonType(daemonType) {
+			return fmt.Errorf("invalid daemon type %q, the daemon types are %s", daemonType, strings.Join(DaemonTypes, ", "))
+		}
+	}
+	if len(daemonTypes) == 0 {
+		daemonTypes = DaemonTypes
+	}
+
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return err
+	}
+	nodeList, err := clientsets.Kube.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the nodes. %v", err)
+	}
+	nodes := map[string]*corev1.Node{}
+	for i := range nodeList.Items {
+		nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+
+	var warnings []string
+	for i, daemonType := range daemonTypes {
+		pods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, v1.ListOptions{LabelSelector: fmt.Sprintf("app=rook-ceph-%s", daemonType)})
+		if err != nil {
+			return fmt.Errorf("failed to list the %s pods. %v", daemonType, err)
+		}
+		if i != 0 {
+			fmt.Println()
+		}
+		placement := getSpecPlacement(cephCluster.Spec.Placement, daemonType)
+		logging.Info("placement of the %s pods in the CephCluster spec", daemonType)
+		printSpecPlacement(os.Stdout, placement)
+		printPodPlacement(os.Stdout, pods, nodes, placement)
+		warnings = append(warnings, findPlacementMismatches(daemonType, pods, nodes, placement)...)
+	}
+
+	fmt.Println()
+	for _, warning := range warnings {
+		logging.Warning("%s", warning)
+	}
+	if len(warnings) == 0 {
+		logging.Info("the pods are scheduled and run on nodes matching the placement of the CephCluster spec")
+	}
+	return nil
+}
+
+func isDaemonType(daemonType string) bool {
+	for _, t := range DaemonTypes {
+		if t == daemonType {
+			return true
+		}
+	}
+	return false
+}
+
+func printSpecPlacement(out io.Writer, placement cephv1.Placement) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "node affinity:\t%s\n", formatNodeAffinity(placement.NodeAffinity))
+	fmt.Fprintf(w, "tolerations:\t%s\n", formatTolerations(placement.Tolerations))
+	fmt.Fprintf(w, "pod anti-affinity:\t%s\n", formatPodAntiAffinity(placement.PodAntiAffinity))
+	fmt.Fprintf(w, "topology spread:\t%s\n", formatTopologySpread(placement.TopologySpreadConstraints))
+	w.Flush()
+	fmt.Fprintln(out)
+}
+
+func printPodPlacement(out io.Writer, pods []corev1.Pod, nodes map[string]*corev1.Node, placement cephv1.Placement) {
+	if len(pods) == 0 {
+		fmt.Fprintln(out, "no pod found")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POD\tPHASE\tNODE\tNODE SELECTOR\tMATCHES SPEC")
+	for i := range pods {
+		pod := &pods[i]
+		nodeName, matches := pod.Spec.NodeName, "-"
+		if nodeName == "" {
+			nodeName = "<unscheduled>"
+		} else if node, ok := nodes[nodeName]; ok {
+			matches = strconv.FormatBool(nodeMatchesAffinity(node, placement.NodeAffinity))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pod.Name, pod.Status.Phase, nodeName, formatLabels(pod.Spec.NodeSelector), matches)
+	}
+	w.Flush()
+}
+
+// findPlacementMismatches returns the pods that can't be scheduled, with the reason of the scheduler, and the pods
+// running on nodes that don't match the node affinity of the spec anymore, such as after the spec or the node
+// labels changed. Both strand the daemons until their placement or the nodes are fixed.
+func findPlacementMismatches(daemonType string, pods []corev1.Pod, nodes map[string]*corev1.Node, placement cephv1.Placement) []string {
+	var warnings []string
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" {
+			reason := "it's not scheduled yet"
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Message != "" {
+					reason = condition.Message
+				}
+			}
+			warnings = append(warnings, fmt.Sprintf("%s pod %s has no node: %s", daemonType, pod.Name, reason))
+			continue
+		}
+		node, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s pod %s runs on node %s which no longer exists", daemonType, pod.Name, pod.Spec.NodeName))
+			continue
+		}
+		if !nodeMatchesAffinity(node, placement.NodeAffinity) {
+			warnings = append(warnings, fmt.Sprintf("%s pod %s runs on node %s which doesn't match the node affinity of the spec: %s",
+				daemonType, pod.Name, node.Name, formatNodeAffinity(placement.NodeAffinity)))
+		}
+	}
+	return warnings
+}
+
+// nodeMatchesAffinity returns whether the node matches one of the required node selector terms, like the scheduler
+func nodeMatchesAffinity(node *corev1.Node, affinity *corev1.NodeAffinity) bool {
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if nodeMatchesTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeMatchesTerm(node *corev1.Node, term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, requirement := range term.MatchExpressions {
+		value, ok := node.Labels[requirement.Key]
+		if !matchesRequirement(requirement, value, ok) {
+			return false
+		}
+	}
+	// metadata.name is the only field supported by the scheduler
+	for _, requirement := range term.MatchFields {
+		if requirement.Key != "metadata.name" || !matchesRequirement(requirement, node.Name, true) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesRequirement(requirement corev1.NodeSelectorRequirement, value string, exists bool) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return exists && contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(requirement.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		expected, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return actual > expected
+		}
+		return actual < expected
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNodeAffinity(affinity *corev1.NodeAffinity) string {
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return "-"
+	}
+	var terms []string
+	for _, term := range affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		var requirements []string
+		for _, requirement := range append(term.MatchExpressions, term.MatchFields...) {
+			requirements = append(requirements, formatRequirement(requirement))
+		}
+		terms = append(terms, strings.Join(requirements, " and "))
+	}
+	return strings.Join(terms, " or ")
+}
+
+func formatRequirement(requirement corev1.NodeSelectorRequirement) string {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+		return fmt.Sprintf("%s %s", requirement.Key, requirement.Operator)
+	}
+	return fmt.Sprintf("%s %s [%s]", requirement.Key, requirement.Operator, strings.Join(requirement.Values, ","))
+}
+
+func formatTolerations(tolerations []corev1.Toleration) string {
+	if len(tolerations) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for _, toleration := range tolerations {
+		key := toleration.Key
+		if key == "" {
+			key = "*"
+		}
+		if toleration.Value != "" {
+			key = fmt.Sprintf("%s=%s", key, toleration.Value)
+		}
+		if toleration.Effect != "" {
+			key = fmt.Sprintf("%s:%s", key, toleration.Effect)
+		}
+		formatted = append(formatted, key)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatPodAntiAffinity(antiAffinity *corev1.PodAntiAffinity) string {
+	if antiAffinity == nil {
+		return "-"
+	}
+	var formatted []string
+	for _, term := range antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		formatted = append(formatted, fmt.Sprintf("required on %s", term.TopologyKey))
+	}
+	for _, term := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		formatted = append(formatted, fmt.Sprintf("preferred on %s", term.PodAffinityTerm.TopologyKey))
+	}
+	if len(formatted) == 0 {
+		return "-"
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatTopologySpread(constraints []corev1.TopologySpreadConstraint) string {
+	if len(constraints) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for _, constraint := range constraints {
+		formatted = append(formatted, fmt.Sprintf("%s max skew %d %s", constraint.TopologyKey, constraint.MaxSkew, constraint.WhenUnsatisfiable))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for key, value := range labels {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindPlacementMismatches(t *testing.T) {
+	newNode := func(name, role string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{"role": role, "disks": "4"}}}
+	}
+	newPod := func(name, nodeName string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}, Spec: corev1.PodSpec{NodeName: nodeName}}
+	}
+	nodes := map[string]*corev1.Node{"node-1": newNode("node-1", "storage"), "node-2": newNode("node-2", "compute")}
+	placement := cephv1.Placement{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "role", Operator: corev1.NodeSelectorOpIn, Values: []string{"storage"}}},
+		}}},
+	}}
+
+	// the pods run on nodes matching the spec
+	assert.Empty(t, findPlacementMismatches("osd", []corev1.Pod{newPod("osd-0", "node-1")}, nodes, placement))
+	// without node affinity all the nodes match
+	assert.Empty(t, findPlacementMismatches("osd", []corev1.Pod{newPod("osd-0", "node-2")}, nodes, cephv1.Placement{}))
+
+	// a pod on a node not matching, on a node removed, and a pod not scheduled
+	unscheduled := newPod("osd-3", "")
+	unscheduled.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Message: "0/2 nodes are available"}}
+	pods := []corev1.Pod{newPod("osd-0", "node-1"), newPod("osd-1", "node-2"), newPod("osd-2", "node-3"), unscheduled}
+	assert.Equal(t, []string{
+		"osd pod osd-1 runs on node node-2 which doesn't match the node affinity of the spec: role In [storage]",
+		"osd pod osd-2 runs on node node-3 which no longer exists",
+		"osd pod osd-3 has no node: 0/2 nodes are available",
+	}, findPlacementMismatches("osd", pods, nodes, placement))
+
+	// the operators of the requirements
+	node := nodes["node-1"]
+	requirement := func(key string, op corev1.NodeSelectorOperator, values ...string) *corev1.NodeAffinity {
+		return &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: key, Operator: op, Values: values}},
+		}}}}
+	}
+	assert.True(t, nodeMatchesAffinity(node, requirement("role", corev1.NodeSelectorOpNotIn, "compute")))
+	assert.False(t, nodeMatchesAffinity(node, requirement("role", corev1.NodeSelectorOpDoesNotExist)))
+	assert.True(t, nodeMatchesAffinity(node, requirement("zone", corev1.NodeSelectorOpDoesNotExist)))
+	assert.True(t, nodeMatchesAffinity(node, requirement("disks", corev1.NodeSelectorOpGt, "2")))
+	assert.False(t, nodeMatchesAffinity(node, requirement("disks", corev1.NodeSelectorOpLt, "2")))
+	byName := &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-2"}}},
+	}}}}
+	assert.False(t, nodeMatchesAffinity(node, byName))
+	assert.True(t, nodeMatchesAffinity(nodes["node-2"], byName))
+}
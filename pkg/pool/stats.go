@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+type poolStats struct {
+	PoolName string `json:"pool_name"`
+	// the rates are omitted by ceph when the pool is idle
+	ClientIoRate struct {
+		ReadBytesSec  uint64 `json:"read_bytes_sec"`
+		WriteBytesSec uint64 `json:"write_bytes_sec"`
+		ReadOpPerSec  uint64 `json:"read_op_per_sec"`
+		WriteOpPerSec uint64 `json:"write_op_per_sec"`
+	} `json:"client_io_rate"`
+}
+
+func (p poolStats) throughput() uint64 {
+	return p.ClientIoRate.ReadBytesSec + p.ClientIoRate.WriteBytesSec
+}
+
+func (p poolStats) iops() uint64 {
+	return p.ClientIoRate.ReadOpPerSec + p.ClientIoRate.WriteOpPerSec
+}
+
+func Stats(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string) {
+	err := stats(ctx, clientsets, operatorNamespace, clusterNamespace, sortBy)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func stats(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string) error {
+	statsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "stats", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var pools []poolStats
+	err := json.Unmarshal([]byte(statsOut), &pools)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph osd pool stats output. %v", err)
+	}
+
+	err = sortPoolStats(pools, sortBy)
+	if err != nil {
+		return err
+	}
+	printPoolStats(os.Stdout, pools)
+	return nil
+}
+
+func sortPoolStats(pools []poolStats, sortBy string) error {
+	switch sortBy {
+	case "name":
+		sort.SliceStable(pools, func(i, j int) bool { return pools[i].PoolName < pools[j].PoolName })
+	case "throughput":
+		sort.SliceStable(pools, func(i, j int) bool { return pools[i].throughput() > pools[j].throughput() })
+	case "iops":
+		sort.SliceStable(pools, func(i, j int) bool { return pools[i].iops() > pools[j].iops() })
+	default:
+		return fmt.Errorf("invalid sort key %q, supported keys are throughput, iops and name", sortBy)
+	}
+	return nil
+}
+
+func printPoolStats(out io.Writer, pools []poolStats) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tREAD OPS\tWRITE OPS\tREAD\tWRITE")
+	for _, pool := range pools {
+		fmt.Fprintf(w, "%s\t%d/s\t%d/s\t%s/s\t%s/s\n", pool.PoolName, pool.ClientIoRate.ReadOpPerSec, pool.ClientIoRate.WriteOpPerSec,
+			output.Bytes(pool.ClientIoRate.ReadBytesSec), output.Bytes(pool.ClientIoRate.WriteBytesSec))
+	}
+	w.Flush()
+}
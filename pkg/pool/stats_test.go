@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortPoolStats(t *testing.T) {
+	var pools []poolStats
+	err := json.Unmarshal([]byte(`[
+{"pool_name":".mgr","pool_id":1,"recovery":{},"recovery_rate":{},"client_io_rate":{}},
+{"pool_name":"replicapool","pool_id":2,"recovery":{},"recovery_rate":{},"client_io_rate":{"read_bytes_sec":1048576,"write_bytes_sec":4194304,"read_op_per_sec":10,"write_op_per_sec":40}},
+{"pool_name":"myfs-data0","pool_id":3,"recovery":{},"recovery_rate":{},"client_io_rate":{"write_bytes_sec":8388608,"write_op_per_sec":8}}]`), &pools)
+	assert.NoError(t, err)
+
+	names := func() string {
+		var names []string
+		for _, pool := range pools {
+			names = append(names, pool.PoolName)
+		}
+		return strings.Join(names, ",")
+	}
+
+	assert.NoError(t, sortPoolStats(pools, "throughput"))
+	assert.Equal(t, "myfs-data0,replicapool,.mgr", names())
+	assert.NoError(t, sortPoolStats(pools, "iops"))
+	assert.Equal(t, "replicapool,myfs-data0,.mgr", names())
+	assert.NoError(t, sortPoolStats(pools, "name"))
+	assert.Equal(t, ".mgr,myfs-data0,replicapool", names())
+	assert.Error(t, sortPoolStats(pools, "latency"))
+
+	var out bytes.Buffer
+	printPoolStats(&out, pools)
+	assert.Contains(t, out.String(), "replicapool   10/s       40/s        1.0 MiB/s   4.0 MiB/s")
+}
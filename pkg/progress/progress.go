@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+const progressBarWidth = 30
+
+type progressEvents struct {
+	Events    []progressEvent `json:"events"`
+	Completed []progressEvent `json:"completed"`
+}
+
+type progressEvent struct {
+	Id             string  `json:"id"`
+	Message        string  `json:"message"`
+	Progress       float64 `json:"progress"`
+	StartedAt      float64 `json:"started_at"`
+	FinishedAt     float64 `json:"finished_at"`
+	Failed         bool    `json:"failed"`
+	FailureMessage string  `json:"failure_message"`
+}
+
+func List(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, showCompleted bool) {
+	err := list(ctx, clientsets, operatorNamespace, clusterNamespace, showCompleted)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func list(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, showCompleted bool) error {
+	progressOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"progress", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var events progressEvents
+	err := json.Unmarshal([]byte(progressOut), &events)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph progress json output. %v", err)
+	}
+
+	if len(events.Events) == 0 {
+		logging.Info("no background operation is in progress")
+	}
+	now := time.Now()
+	for _, event := range events.Events {
+		fmt.Printf("%s %s\n", output.Bar(event.Progress, progressBarWidth), event.Message)
+		fmt.Printf("\tstarted %s ago\n", formatElapsed(event.StartedAt, now))
+	}
+
+	if !showCompleted {
+		return nil
+	}
+	fmt.Println()
+	logging.Info("%d completed events", len(events.Completed))
+	for _, event := range events.Completed {
+		state := "completed"
+		if event.Failed {
+			state = fmt.Sprintf("failed: %s", event.FailureMessage)
+		}
+		fmt.Printf("%s\n\t%s %s ago\n", event.Message, state, formatElapsed(event.FinishedAt, now))
+	}
+	return nil
+}
+
+func Clear(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"progress", "clear"}, operatorNamespace, clusterNamespace, false, true)
+	logging.Info("progress events cleared, the events of the operations still running are re-created by the mgr")
+}
+
+// formatElapsed prints the time elapsed since the epoch timestamp in seconds reported by the mgr
+func formatElapsed(timestamp float64, now time.Time) string {
+	if timestamp <= 0 {
+		return "unknown time"
+	}
+	start := time.Unix(0, int64(timestamp*float64(time.Second)))
+	return now.Sub(start).Round(time.Second).String()
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/filesystem"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+type cephDf struct {
+	Pools []struct {
+		Name  string `json:"name"`
+		Stats struct {
+			Stored  uint64 `json:"stored"`
+			Objects uint64 `json:"objects"`
+		} `json:"stats"`
+	} `json:"pools"`
+}
+
+type poolQuota struct {
+	PoolName   string `json:"pool_name"`
+	MaxObjects uint64 `json:"quota_max_objects"`
+	MaxBytes   uint64 `json:"quota_max_bytes"`
+}
+
+type subvolumeInfo struct {
+	// the quota is "infinite" when the subvolume has no quota
+	BytesQuota json.RawMessage `json:"bytes_quota"`
+	BytesUsed  uint64          `json:"bytes_used"`
+}
+
+// PoolUsage is the quota and the usage of a pool, a quota of 0 is no quota
+type PoolUsage struct {
+	Name       string
+	MaxBytes   uint64
+	Stored     uint64
+	MaxObjects uint64
+	Objects    uint64
+}
+
+// SubvolumeUsage is the quota and the usage of a cephfs subvolume, such as the subvolume of a csi volume
+type SubvolumeUsage struct {
+	Filesystem string
+	Group      string
+	Name       string
+	MaxBytes   uint64
+	Used       uint64
+}
+
+func Quota(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, subvolumes bool, nearRatio float64) {
+	err := quota(ctx, clientsets, operatorNamespace, clusterNamespace, subvolumes, nearRatio)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func quota(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, subvolumes bool, nearRatio float64) error {
+	if nearRatio <= 0 || nearRatio > 1 {
+		return fmt.Errorf("invalid near ratio %v, it must be above 0 and at most 1", nearRatio)
+	}
+	runCeph := func(args ...string) string {
+		return runCephJson(ctx, clientsets, operatorNamespace, clusterNamespace, args...)
+	}
+
+	var df cephDf
+	err := json.Unmarshal([]byte(runCeph("df")), &df)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph df output. %v", err)
+	}
+	var pools []PoolUsage
+	for _, pool := range df.Pools {
+		var q poolQuota
+		err := json.Unmarshal([]byte(runCeph("osd", "pool", "get-quota", pool.Name)), &q)
+		if err != nil {
+			return fmt.Errorf("failed to parse the quota of pool %s. %v", pool.Name, err)
+		}
+		pools = append(pools, PoolUsage{Name: pool.Name, MaxBytes: q.MaxBytes, Stored: pool.Stats.Stored, MaxObjects: q.MaxObjects, Objects: pool.Stats.Objects})
+	}
+	printPoolUsage(os.Stdout, pools)
+	warnings := getPoolQuotaWarnings(pools, nearRatio)
+
+	if subvolumes {
+		usage, err := getSubvolumeUsage(ctx, clientsets, operatorNamespace, clusterNamespace)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		printSubvolumeUsage(os.Stdout, usage)
+		warnings = append(warnings, getSubvolumeQuotaWarnings(usage, nearRatio)...)
+	}
+
+	fmt.Println()
+	for _, warning := range warnings {
+		logging.Warning("%s", warning)
+	}
+	if len(warnings) == 0 {
+		logging.Info("no quota is above %d%% of its limit", int(nearRatio*100))
+	}
+	return nil
+}
+
+// getSubvolumeUsage returns the quota and the usage of the subvolumes of all the filesystems, in their groups
+// and outside of any group
+func getSubvolumeUsage(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]SubvolumeUsage, error) {
+	runCeph := func(args ...string) string {
+		return runCephJson(ctx, clientsets, operatorNamespace, clusterNamespace, args...)
+	}
+	fsNames, err := filesystem.ListFilesystems(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []SubvolumeUsage
+	for _, fsName := range fsNames {
+		var groups []struct {
+			Name string `json:"name"`
+		}
+		err := json.Unmarshal([]byte(runCeph("fs", "subvolumegroup", "ls", fsName)), &groups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the subvolume groups of filesystem %s. %v", fsName, err)
+		}
+		// the subvolumes created without a group are listed without --group_name
+		groupNames := []string{""}
+		for _, group := range groups {
+			groupNames = append(groupNames, group.Name)
+		}
+
+		for _, group := range groupNames {
+			groupArgs := []string{}
+			if group != "" {
+				groupArgs = []string{"--group_name", group}
+			}
+			var subvolumes []struct {
+				Name string `json:"name"`
+			}
+			err := json.Unmarshal([]byte(runCeph(append([]string{"fs", "subvolume", "ls", fsName}, groupArgs...)...)), &subvolumes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list the subvolumes of filesystem %s. %v", fsName, err)
+			}
+			for _, subvolume := range subvolumes {
+				infoOut := runCeph(append([]string{"fs", "subvolume", "info", fsName, subvolume.Name}, groupArgs...)...)
+				subvolumeUsage, err := parseSubvolumeInfo(infoOut)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse the info of subvolume %s. %v", subvolume.Name, err)
+				}
+				subvolumeUsage.Filesystem, subvolumeUsage.Group, subvolumeUsage.Name = fsName, group, subvolume.Name
+				usage = append(usage, subvolumeUsage)
+			}
+		}
+	}
+	return usage, nil
+}
+
+func runCephJson(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args ...string) string {
+	return exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append(args, "--format", "json"), operatorNamespace, clusterNamespace, true, true)
+}
+
+func parseSubvolumeInfo(infoOut string) (SubvolumeUsage, error) {
+	var info subvolumeInfo
+	err := json.Unmarshal([]byte(infoOut), &info)
+	if err != nil {
+		return SubvolumeUsage{}, err
+	}
+	usage := SubvolumeUsage{Used: info.BytesUsed}
+	if maxBytes, err := strconv.ParseUint(string(info.BytesQuota), 10, 64); err == nil {
+		usage.MaxBytes = maxBytes
+	}
+	return usage, nil
+}
+
+// getPoolQuotaWarnings returns the pools near or at their quota. Ceph raises POOL_FULL once a pool reaches its
+// quota and the writes to the pool fail, while the reads keep working, which makes the clients hang in confusing ways.
+func getPoolQuotaWarnings(pools []PoolUsage, nearRatio float64) []string {
+	var warnings []string
+	for _, pool := range pools {
+		if warning := getQuotaWarning(fmt.Sprintf("pool %s", pool.Name), "bytes", pool.Stored, pool.MaxBytes, nearRatio, output.Bytes); warning != "" {
+			warnings = append(warnings, warning)
+		}
+		if warning := getQuotaWarning(fmt.Sprintf("pool %s", pool.Name), "objects", pool.Objects, pool.MaxObjects, nearRatio, formatCount); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// getSubvolumeQuotaWarnings returns the subvolumes near or at their quota, the writes to a full subvolume fail
+// with "Disk quota exceeded"
+func getSubvolumeQuotaWarnings(subvolumes []SubvolumeUsage, nearRatio float64) []string {
+	var warnings []string
+	for _, subvolume := range subvolumes {
+		if warning := getQuotaWarning(fmt.Sprintf("subvolume %s", subvolume.path()), "bytes", subvolume.Used, subvolume.MaxBytes, nearRatio, output.Bytes); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+func getQuotaWarning(name, unit string, used, max uint64, nearRatio float64, format func(uint64) string) string {
+	if max == 0 {
+		return ""
+	}
+	ratio := float64(used) / float64(max)
+	usage := fmt.Sprintf("%s of %s, %s", format(used), format(max), formatUsedRatio(used, max))
+	if ratio >= 1 {
+		return fmt.Sprintf("%s reached its %s quota: %s. Its writes fail until the quota is raised or data is deleted", name, unit, usage)
+	}
+	if ratio >= nearRatio {
+		return fmt.Sprintf("%s is near its %s quota: %s", name, unit, usage)
+	}
+	return ""
+}
+
+func (s SubvolumeUsage) path() string {
+	if s.Group == "" {
+		return fmt.Sprintf("%s/%s", s.Filesystem, s.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.Filesystem, s.Group, s.Name)
+}
+
+func printPoolUsage(out io.Writer, pools []PoolUsage) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tSTORED\tMAX BYTES\tBYTES USED\tOBJECTS\tMAX OBJECTS\tOBJECTS USED")
+	for _, pool := range pools {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", pool.Name, output.Bytes(pool.Stored), formatMaxBytes(pool.MaxBytes), formatUsedRatio(pool.Stored, pool.MaxBytes),
+			pool.Objects, formatMaxObjects(pool.MaxObjects), formatUsedRatio(pool.Objects, pool.MaxObjects))
+	}
+	w.Flush()
+}
+
+func printSubvolumeUsage(out io.Writer, subvolumes []SubvolumeUsage) {
+	if len(subvolumes) == 0 {
+		logging.Info("no cephfs subvolume found")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "FILESYSTEM\tGROUP\tSUBVOLUME\tUSED\tQUOTA\tQUOTA USED")
+	for _, subvolume := range subvolumes {
+		group := subvolume.Group
+		if group == "" {
+			group = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", subvolume.Filesystem, group, subvolume.Name, output.Bytes(subvolume.Used),
+			formatMaxBytes(subvolume.MaxBytes), formatUsedRatio(subvolume.Used, subvolume.MaxBytes))
+	}
+	w.Flush()
+}
+
+func formatMaxBytes(max uint64) string {
+	if max == 0 {
+		return "-"
+	}
+	return output.Bytes(max)
+}
+
+func formatMaxObjects(max uint64) string {
+	if max == 0 {
+		return "-"
+	}
+	return formatCount(max)
+}
+
+func formatCount(count uint64) string {
+	return strconv.FormatUint(count, 10)
+}
+
+func formatUsedRatio(used, max uint64) string {
+	if max == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(used)/float64(max)*100)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPoolQuotaWarnings(t *testing.T) {
+	pools := []PoolUsage{
+		{Name: "no-quota", Stored: 10 << 30},
+		{Name: "near", MaxBytes: 10 << 30, Stored: 9 << 30},
+		{Name: "full", MaxObjects: 100, Objects: 100},
+		{Name: "below", MaxBytes: 10 << 30, Stored: 1 << 30, MaxObjects: 100, Objects: 10},
+	}
+	assert.Equal(t, []string{
+		"pool near is near its bytes quota: 9.0 GiB of 10.0 GiB, 90.0%",
+		"pool full reached its objects quota: 100 of 100, 100.0%. Its writes fail until the quota is raised or data is deleted",
+	}, getPoolQuotaWarnings(pools, 0.8))
+	assert.Len(t, getPoolQuotaWarnings(pools, 0.95), 1)
+}
+
+func TestSubvolumeQuota(t *testing.T) {
+	usage, err := parseSubvolumeInfo(`{"bytes_quota": 1073741824, "bytes_used": 1073741824, "bytes_pcent": "100.00"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, SubvolumeUsage{MaxBytes: 1 << 30, Used: 1 << 30}, usage)
+
+	// a subvolume without a quota is infinite
+	usage, err = parseSubvolumeInfo(`{"bytes_quota": "infinite", "bytes_used": 4096, "bytes_pcent": "undefined"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, SubvolumeUsage{Used: 4096}, usage)
+
+	subvolumes := []SubvolumeUsage{
+		{Filesystem: "myfs", Group: "csi", Name: "csi-vol-1", MaxBytes: 1 << 30, Used: 1 << 30},
+		{Filesystem: "myfs", Name: "data", Used: 4096},
+	}
+	assert.Equal(t, []string{
+		"subvolume myfs/csi/csi-vol-1 reached its bytes quota: 1.0 GiB of 1.0 GiB, 100.0%. Its writes fail until the quota is raised or data is deleted",
+	}, getSubvolumeQuotaWarnings(subvolumes, 0.8))
+}
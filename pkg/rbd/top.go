@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+const (
+	SortByProvisioned = "provisioned"
+	SortByUsed        = "used"
+)
+
+type poolDetail struct {
+	PoolName            string                     `json:"pool_name"`
+	ApplicationMetadata map[string]json.RawMessage `json:"application_metadata"`
+}
+
+type imageUsage struct {
+	Pool            string `json:"-"`
+	Name            string `json:"name"`
+	Snapshot        string `json:"snapshot"`
+	ProvisionedSize uint64 `json:"provisioned_size"`
+	UsedSize        uint64 `json:"used_size"`
+}
+
+// topImages keeps the largest images seen so far in a min-heap, so the memory is bounded by the
+// number of images printed rather than the number of images in the cluster
+type topImages struct {
+	limit  int
+	sortBy string
+	images []imageUsage
+}
+
+func (t *topImages) size(image imageUsage) uint64 {
+	if t.sortBy == SortByProvisioned {
+		return image.ProvisionedSize
+	}
+	return image.UsedSize
+}
+
+func (t *topImages) Len() int           { return len(t.images) }
+func (t *topImages) Less(i, j int) bool { return t.size(t.images[i]) < t.size(t.images[j]) }
+func (t *topImages) Swap(i, j int)      { t.images[i], t.images[j] = t.images[j], t.images[i] }
+func (t *topImages) Push(x any)         { t.images = append(t.images, x.(imageUsage)) }
+func (t *topImages) Pop() any {
+	last := t.images[len(t.images)-1]
+	t.images = t.images[:len(t.images)-1]
+	return last
+}
+
+func (t *topImages) add(image imageUsage) {
+	if t.Len() < t.limit {
+		heap.Push(t, image)
+		return
+	}
+	if t.size(image) > t.size(t.images[0]) {
+		t.images[0] = image
+		heap.Fix(t, 0)
+	}
+}
+
+// sorted returns the images from the largest to the smallest
+func (t *topImages) sorted() []imageUsage {
+	sorted := append([]imageUsage{}, t.images...)
+	sort.SliceStable(sorted, func(i, j int) bool { return t.size(sorted[i]) > t.size(sorted[j]) })
+	return sorted
+}
+
+func Top(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string, limit int) {
+	err := top(ctx, clientsets, operatorNamespace, clusterNamespace, sortBy, limit)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func top(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, sortBy string, limit int) error {
+	if sortBy != SortByProvisioned && sortBy != SortByUsed {
+		return fmt.Errorf("invalid sort %q, the images can be sorted by %q or %q", sortBy, SortByProvisioned, SortByUsed)
+	}
+	if limit <= 0 {
+		return fmt.Errorf("invalid limit %d, the limit must be positive", limit)
+	}
+
+	poolsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "pool", "ls", "detail", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var pools []poolDetail
+	err := json.Unmarshal([]byte(poolsOut), &pools)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph osd pool ls detail output. %v", err)
+	}
+
+	images := &topImages{limit: limit, sortBy: sortBy}
+	for _, pool := range getRbdPools(pools) {
+		logging.Info("checking the images of pool %s", pool)
+		err = streamPoolImages(ctx, clientsets, operatorNamespace, clusterNamespace, pool, images.add)
+		if err != nil {
+			logging.Warning("failed to get the usage of the images of pool %s. %v", pool, err)
+		}
+	}
+
+	if images.Len() == 0 {
+		logging.Info("no rbd image found")
+		return nil
+	}
+	printImages(os.Stdout, images.sorted())
+	return nil
+}
+
+// getRbdPools returns the pools with the rbd application enabled
+func getRbdPools(pools []poolDetail) []string {
+	var rbdPools []string
+	for _, pool := range pools {
+		if _, ok := pool.ApplicationMetadata["rbd"]; ok {
+			rbdPools = append(rbdPools, pool.PoolName)
+		}
+	}
+	return rbdPools
+}
+
+// streamPoolImages decodes the rbd du output of the pool while it's received instead of buffering it
+func streamPoolImages(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pool string, addImage func(imageUsage)) error {
+	reader, writer := io.Pipe()
+	go func() {
+		err := exec.StreamCommandInOperatorPod(ctx, clientsets, "rbd", []string{"du", "--pool", pool, "--format", "json"}, operatorNamespace, clusterNamespace, writer)
+		writer.CloseWithError(err)
+	}()
+
+	err := decodeImages(reader, pool, addImage)
+	// unblock the command if the output could not be decoded
+	reader.CloseWithError(err)
+	return err
+}
+
+// decodeImages calls addImage for each image of the rbd du json output, the usage of the snapshots is skipped
+func decodeImages(r io.Reader, pool string, addImage func(imageUsage)) error {
+	decoder := json.NewDecoder(r)
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if token != "images" {
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = expectDelim(decoder, '[')
+		if err != nil {
+			return err
+		}
+		for decoder.More() {
+			var image imageUsage
+			err = decoder.Decode(&image)
+			if err != nil {
+				return err
+			}
+			if image.Snapshot != "" {
+				continue
+			}
+			image.Pool = pool
+			addImage(image)
+		}
+		err = expectDelim(decoder, ']')
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected %v in rbd du output, expected %v", token, delim)
+	}
+	return nil
+}
+
+func printImages(out io.Writer, images []imageUsage) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tIMAGE\tPROVISIONED\tUSED")
+	for _, image := range images {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", image.Pool, image.Name, output.Bytes(image.ProvisionedSize), output.Bytes(image.UsedSize))
+	}
+	w.Flush()
+}
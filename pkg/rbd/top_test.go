@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopImages(t *testing.T) {
+	duOut := `{"images":[
+{"name":"csi-vol-1","id":"1","provisioned_size":10737418240,"used_size":1073741824},
+{"name":"csi-vol-1","id":"1","snapshot":"snap-1","provisioned_size":10737418240,"used_size":5368709120},
+{"name":"csi-vol-2","id":"2","provisioned_size":1073741824,"used_size":1048576},
+{"name":"csi-vol-3","id":"3","provisioned_size":5368709120,"used_size":4294967296}],
+"total_provisioned_size":17179869184,"total_used_size":5369757696}`
+
+	images := &topImages{limit: 2, sortBy: SortByUsed}
+	assert.NoError(t, decodeImages(strings.NewReader(duOut), "replicapool", images.add))
+	sorted := images.sorted()
+	assert.Len(t, sorted, 2)
+	assert.Equal(t, "csi-vol-3", sorted[0].Name)
+	assert.Equal(t, "csi-vol-1", sorted[1].Name)
+	assert.Equal(t, "replicapool", sorted[0].Pool)
+
+	images = &topImages{limit: 10, sortBy: SortByProvisioned}
+	assert.NoError(t, decodeImages(strings.NewReader(duOut), "replicapool", images.add))
+	sorted = images.sorted()
+	assert.Len(t, sorted, 3)
+	assert.Equal(t, []string{"csi-vol-1", "csi-vol-3", "csi-vol-2"}, []string{sorted[0].Name, sorted[1].Name, sorted[2].Name})
+
+	assert.Error(t, decodeImages(strings.NewReader(`{"images":[{"name":`), "replicapool", images.add))
+	assert.Error(t, decodeImages(strings.NewReader(`[]`), "replicapool", images.add))
+}
+
+func TestGetRbdPools(t *testing.T) {
+	var pools []poolDetail
+	err := json.Unmarshal([]byte(`[{"pool_name":".mgr","application_metadata":{"mgr":{}}},
+{"pool_name":"replicapool","application_metadata":{"rbd":{}}},
+{"pool_name":"myfs-data0","application_metadata":{"cephfs":{"data":"myfs"}}}]`), &pools)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replicapool"}, getRbdPools(pools))
+}
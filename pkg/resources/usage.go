@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// daemonApps are the app labels of the ceph daemon pods created by rook
+var daemonApps = []string{
+	"rook-ceph-mon",
+	"rook-ceph-mgr",
+	"rook-ceph-osd",
+	"rook-ceph-mds",
+	"rook-ceph-rgw",
+	"rook-ceph-nfs",
+	"rook-ceph-rbd-mirror",
+	"rook-ceph-exporter",
+	"rook-ceph-crashcollector",
+}
+
+// nearLimitRatio is the ratio of the limit above which a container is reported, it's likely throttled on cpu or
+// close to be OOM killed
+const nearLimitRatio = 0.9
+
+type containerUsage struct {
+	Pod       string
+	Container string
+	Requests  corev1.ResourceList
+	Limits    corev1.ResourceList
+	// Usage is nil when the metrics API is not available
+	Usage corev1.ResourceList
+}
+
+func Usage(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) {
+	err := usage(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func usage(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) error {
+	opts := metav1.ListOptions{LabelSelector: fmt.Sprintf("app in (%s)", strings.Join(daemonApps, ","))}
+	pods, err := k8sutil.ListPods(ctx, clientsets.Kube, clusterNamespace, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list the ceph daemon pods. %v", err)
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no ceph daemon pods found in namespace %q", clusterNamespace)
+	}
+
+	podMetrics := getPodMetrics(ctx, clientsets, clusterNamespace, opts)
+	usages := getContainerUsages(pods, podMetrics)
+	printUsages(os.Stdout, usages)
+
+	for _, u := range usages {
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if isNearLimit(u, resourceName) {
+				logging.Warning("container %q of pod %q uses %s of its %s limit of %s", u.Container, u.Pod,
+					formatQuantity(resourceName, u.Usage[resourceName]), resourceName, formatQuantity(resourceName, u.Limits[resourceName]))
+			}
+		}
+	}
+	return nil
+}
+
+// getPodMetrics returns the usage of the containers by pod name, or nil when the metrics API is not available
+func getPodMetrics(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string, opts metav1.ListOptions) map[string]map[string]corev1.ResourceList {
+	client, err := metricsclient.NewForConfig(clientsets.KubeConfig)
+	if err != nil {
+		logging.Warning("failed to create the metrics client, the usage is not shown. %v", err)
+		return nil
+	}
+
+	list, err := client.MetricsV1beta1().PodMetricses(clusterNamespace).List(ctx, opts)
+	if err != nil {
+		logging.Warning("the metrics API is not available, the usage is not shown. Is metrics-server installed? %v", err)
+		return nil
+	}
+
+	podMetrics := map[string]map[string]corev1.ResourceList{}
+	for _, pod := range list.Items {
+		podMetrics[pod.Name] = map[string]corev1.ResourceList{}
+		for _, container := range pod.Containers {
+			podMetrics[pod.Name][container.Name] = container.Usage
+		}
+	}
+	return podMetrics
+}
+
+func getContainerUsages(pods []corev1.Pod, podMetrics map[string]map[string]corev1.ResourceList) []containerUsage {
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	var usages []containerUsage
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			u := containerUsage{
+				Pod:       pod.Name,
+				Container: container.Name,
+				Requests:  container.Resources.Requests,
+				Limits:    container.Resources.Limits,
+			}
+			if podMetrics != nil {
+				// a pod that just started may not have metrics yet, its usage is shown as zero
+				u.Usage = podMetrics[pod.Name][container.Name]
+				if u.Usage == nil {
+					u.Usage = corev1.ResourceList{}
+				}
+			}
+			usages = append(usages, u)
+		}
+	}
+	return usages
+}
+
+func isNearLimit(u containerUsage, resourceName corev1.ResourceName) bool {
+	limit, ok := u.Limits[resourceName]
+	if !ok || limit.IsZero() || u.Usage == nil {
+		return false
+	}
+	used := u.Usage[resourceName]
+	return float64(used.MilliValue()) >= nearLimitRatio*float64(limit.MilliValue())
+}
+
+func printUsages(out io.Writer, usages []containerUsage) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POD\tCONTAINER\tCPU REQUEST\tCPU LIMIT\tCPU USAGE\tMEMORY REQUEST\tMEMORY LIMIT\tMEMORY USAGE")
+	for _, u := range usages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", u.Pod, u.Container,
+			formatResource(u.Requests, corev1.ResourceCPU), formatResource(u.Limits, corev1.ResourceCPU), formatUsage(u, corev1.ResourceCPU),
+			formatResource(u.Requests, corev1.ResourceMemory), formatResource(u.Limits, corev1.ResourceMemory), formatUsage(u, corev1.ResourceMemory))
+	}
+	w.Flush()
+}
+
+func formatResource(resources corev1.ResourceList, resourceName corev1.ResourceName) string {
+	quantity, ok := resources[resourceName]
+	if !ok {
+		return "-"
+	}
+	return formatQuantity(resourceName, quantity)
+}
+
+func formatUsage(u containerUsage, resourceName corev1.ResourceName) string {
+	if u.Usage == nil {
+		return "n/a"
+	}
+	return formatQuantity(resourceName, u.Usage[resourceName])
+}
+
+// formatQuantity prints cpu in millicores and memory in MiB, the metrics API reports cpu in nanocores and memory in KiB
+func formatQuantity(resourceName corev1.ResourceName, quantity resource.Quantity) string {
+	if resourceName == corev1.ResourceCPU {
+		return fmt.Sprintf("%dm", quantity.MilliValue())
+	}
+	return fmt.Sprintf("%dMi", quantity.Value()/(1024*1024))
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetContainerUsages(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-osd-0"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "osd",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				},
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mon-a"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "mon"}}},
+		},
+	}
+
+	// without metrics the usage is not available
+	usages := getContainerUsages(pods, nil)
+	assert.Len(t, usages, 2)
+	assert.Equal(t, "rook-ceph-mon-a", usages[0].Pod)
+	assert.Nil(t, usages[1].Usage)
+	assert.False(t, isNearLimit(usages[1], corev1.ResourceCPU))
+
+	var out bytes.Buffer
+	printUsages(&out, usages)
+	assert.Contains(t, out.String(), "rook-ceph-mon-a")
+	assert.Regexp(t, `rook-ceph-osd-0\s+osd\s+500m\s+1000m\s+n/a\s+2048Mi\s+4096Mi\s+n/a`, out.String())
+
+	podMetrics := map[string]map[string]corev1.ResourceList{
+		"rook-ceph-osd-0": {"osd": {corev1.ResourceCPU: resource.MustParse("950000000n"), corev1.ResourceMemory: resource.MustParse("1048576Ki")}},
+	}
+	usages = getContainerUsages(pods, podMetrics)
+	assert.Equal(t, corev1.ResourceList{}, usages[0].Usage)
+	assert.True(t, isNearLimit(usages[1], corev1.ResourceCPU))
+	assert.False(t, isNearLimit(usages[1], corev1.ResourceMemory))
+	assert.Equal(t, "950m", formatUsage(usages[1], corev1.ResourceCPU))
+	assert.Equal(t, "1024Mi", formatUsage(usages[1], corev1.ResourceMemory))
+}
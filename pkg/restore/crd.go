@@ -26,7 +26,6 @@ import (
 	"github.com/rook/kubectl-rook-ceph/pkg/exec"
 	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
 	"github.com/rook/kubectl-rook-ceph/pkg/logging"
-	"github.com/rook/kubectl-rook-ceph/pkg/mons"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -48,10 +47,7 @@ func RestoreCrd(ctx context.Context, k8sclientset *k8sutil.Clientsets, operatorN
 	}
 
 	logging.Info("Restoring CR %s", crName)
-	var answer string
-	logging.Warning("The resource %s was found deleted. Do you want to restore it? yes | no\n", crName)
-	fmt.Scanf("%s", &answer)
-	err := mons.PromptToContinueOrCancel("restore-deleted", "yes", answer)
+	err := logging.Confirm(clusterNamespace, "The resource %s was found deleted, restoring it stops the operator and removes its finalizers while it's re-created", crName)
 	if err != nil {
 		logging.Fatal(fmt.Errorf("Restoring the resource %s cancelled", crName))
 	}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rgw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// syncState is the state of the metadata or data sync parsed from `radosgw-admin sync status`
+type syncState struct {
+	multisite bool
+	caughtUp  []string
+	behind    []string
+}
+
+func SyncStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	syncOut := exec.RunCommandInOperatorPod(ctx, clientsets, "radosgw-admin", []string{"sync", "status"}, operatorNamespace, clusterNamespace, true, true)
+	fmt.Println(syncOut)
+
+	state := parseSyncStatus(syncOut)
+	if !state.multisite {
+		logging.Info("not configured for multisite")
+		return
+	}
+	for _, line := range state.caughtUp {
+		logging.Info(line)
+	}
+	for _, line := range state.behind {
+		logging.Warning(line)
+	}
+	if len(state.behind) == 0 {
+		logging.Info("metadata and data sync are caught up")
+	}
+}
+
+// parseSyncStatus returns whether the zone syncs with other zones, and the caught up and behind
+// lines of the metadata and data sync
+func parseSyncStatus(syncOut string) syncState {
+	var state syncState
+	for _, line := range strings.Split(syncOut, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "data sync source:") || strings.Contains(line, "syncing from master") {
+			state.multisite = true
+		}
+		if strings.Contains(line, "is caught up with") {
+			state.caughtUp = append(state.caughtUp, line)
+		} else if strings.Contains(line, "is behind on") {
+			state.behind = append(state.behind, line)
+		}
+	}
+	if len(state.caughtUp) != 0 || len(state.behind) != 0 {
+		state.multisite = true
+	}
+	return state
+}
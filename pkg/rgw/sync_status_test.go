@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rgw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSyncStatus(t *testing.T) {
+	singleSite := `          realm  ()
+      zonegroup 5bd1ea2a-a727-4e6c-8e4f-6a8a733fe2fc (default)
+           zone 2ad2a7c2-7b12-4bd4-8ec6-53f0b4f47d62 (default)
+  metadata sync no sync (zone is master)`
+	state := parseSyncStatus(singleSite)
+	assert.False(t, state.multisite)
+
+	multisite := `          realm 1b4ed2c4-1f6f-4f55-8fcb-0f7f6b8e3b1a (gold)
+      zonegroup 5bd1ea2a-a727-4e6c-8e4f-6a8a733fe2fc (us)
+           zone 2ad2a7c2-7b12-4bd4-8ec6-53f0b4f47d62 (us-west)
+  metadata sync syncing
+                full sync: 0/64 shards
+                incremental sync: 64/64 shards
+                metadata is caught up with master
+      data sync source: 9b2c2c5e-5d0c-4bd1-9d2d-6f6e1c1b0a1e (us-east)
+                        syncing
+                        full sync: 0/128 shards
+                        incremental sync: 128/128 shards
+                        data is behind on 3 shards
+                        behind shards: [12,40,97]`
+	state = parseSyncStatus(multisite)
+	assert.True(t, state.multisite)
+	assert.Equal(t, []string{"metadata is caught up with master"}, state.caughtUp)
+	assert.Equal(t, []string{"data is behind on 3 shards"}, state.behind)
+}
@@ -29,9 +29,24 @@ import (
 )
 
 func PurgeOsd(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdId, flag string) {
+	err := logging.Confirm(clusterNamespace, "Purging osd %s permanently removes it from the cluster", osdId)
+	if err != nil {
+		logging.Fatal(fmt.Errorf("purging osd %s cancelled", osdId))
+	}
+
+	err = RemoveOsds(ctx, clientsets, operatorNamespace, clusterNamespace, osdId, flag)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// RemoveOsds runs the osd removal of rook in the operator pod, which purges the osds from ceph and deletes their
+// deployments. Unless the removal is forced, rook waits for each osd to be safe to destroy. The output of the
+// removal is printed once it completes, and an error is returned when it fails.
+func RemoveOsds(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, osdIds, force string) error {
 	monCm, err := clientsets.Kube.CoreV1().ConfigMaps(clusterNamespace).Get(ctx, mons.MonConfigMap, v1.GetOptions{})
 	if err != nil {
-		logging.Fatal(fmt.Errorf("failed to get mon configmap %s %v", mons.MonConfigMap, err))
+		return fmt.Errorf("failed to get mon configmap %s %v", mons.MonConfigMap, err)
 	}
 
 	monEndPoint := monCm.Data["data"]
@@ -41,15 +56,24 @@ func PurgeOsd(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNames
 
 	adminKey := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", cephArgs, operatorNamespace, clusterNamespace, true, false)
 	if adminKey == "" {
-		logging.Fatal(fmt.Errorf("failed to get ceph key"))
+		return fmt.Errorf("failed to get ceph key")
 	}
 
 	cmd := "/bin/sh"
 	args := []string{
 		"-c",
-		fmt.Sprintf("export ROOK_MON_ENDPOINTS=%s ROOK_CEPH_USERNAME=client.admin ROOK_CEPH_SECRET=%s ROOK_CONFIG_DIR=/var/lib/rook && rook ceph osd remove --osd-ids=%s --force-osd-removal=%s", monEndPoint, adminKey, osdId, flag),
+		fmt.Sprintf("export ROOK_MON_ENDPOINTS=%s ROOK_CEPH_USERNAME=client.admin ROOK_CEPH_SECRET=%s ROOK_CONFIG_DIR=/var/lib/rook && rook ceph osd remove --osd-ids=%s --force-osd-removal=%s", monEndPoint, adminKey, osdIds, force),
 	}
 	logging.Info("Running purge osd command")
 
-	exec.RunCommandInOperatorPod(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace, false, true)
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to remove osds %s. %v", osdIds, err)
+	}
+	fmt.Print(result.Stdout)
+	fmt.Print(result.Stderr)
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to remove osds %s, the removal exited with code %d", osdIds, result.ExitCode)
+	}
+	return nil
 }
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	clusterController = "ceph-cluster-controller"
+	// the interval the new operator pod is polled at
+	operatorPollInterval = 2 * time.Second
+)
+
+func Reconcile(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string, timeout time.Duration) {
+	err := reconcile(ctx, k8sclientset, operatorNamespace, clusterNamespace, timeout)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// reconcile restarts the operator and follows its logs until the CephCluster is reconciled. Rook ignores the updates
+// of the CephCluster CR that don't change its spec, so annotating the CR doesn't trigger a reconcile while the
+// operator reconciles all the clusters when it starts.
+func reconcile(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace, clusterNamespace string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := v1.ListOptions{LabelSelector: "app=rook-ceph-operator"}
+	pods, err := k8sclientset.CoreV1().Pods(operatorNamespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list the operator pods. %v", err)
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no operator pod found in namespace %s", operatorNamespace)
+	}
+	oldPods := map[string]bool{}
+	for _, pod := range pods.Items {
+		oldPods[pod.Name] = true
+		err = k8sclientset.CoreV1().Pods(operatorNamespace).Delete(ctx, pod.Name, v1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to delete the operator pod %s. %v", pod.Name, err)
+		}
+		logging.Info("deleted the operator pod %s to reconcile the CephCluster in namespace %s", pod.Name, clusterNamespace)
+	}
+
+	pod, err := waitForNewOperatorPod(ctx, k8sclientset, operatorNamespace, oldPods)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	logs, err := k8sclientset.CoreV1().Pods(operatorNamespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "rook-ceph-operator", Follow: true}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to follow the logs of the operator pod %s. %v", pod.Name, err)
+	}
+	defer logs.Close()
+
+	err = followReconcile(logs, os.Stdout, clusterNamespace)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s waiting for the CephCluster in namespace %s to be reconciled, check the operator logs", timeout, clusterNamespace)
+	}
+	if err != nil {
+		return err
+	}
+	logging.Info("the CephCluster in namespace %s was reconciled in %s", clusterNamespace, formatReconcileDuration(time.Since(start)))
+	return nil
+}
+
+// waitForNewOperatorPod waits for an operator pod replacing the deleted pods to run
+func waitForNewOperatorPod(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string, oldPods map[string]bool) (*corev1.Pod, error) {
+	progress := logging.NewProgress("waiting for the new operator pod to run")
+	for {
+		pods, err := k8sclientset.CoreV1().Pods(operatorNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-operator"})
+		if err != nil {
+			progress.Done("failed")
+			return nil, fmt.Errorf("failed to list the operator pods. %v", err)
+		}
+		state := "no new pod found"
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if oldPods[pod.Name] || !pod.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if pod.Status.Phase == corev1.PodRunning {
+				progress.Done(fmt.Sprintf("pod %s is running", pod.Name))
+				return pod, nil
+			}
+			state = fmt.Sprintf("pod %s is %s", pod.Name, pod.Status.Phase)
+		}
+		progress.Update(state)
+
+		select {
+		case <-ctx.Done():
+			progress.Done("timed out")
+			return nil, fmt.Errorf("timed out waiting for the new operator pod to run")
+		case <-time.After(operatorPollInterval):
+		}
+	}
+}
+
+// followReconcile prints the operator logs until the reconcile of the CephCluster of the namespace finishes, and
+// returns the error of the reconcile when it fails. The logs of the other clusters are not printed.
+func followReconcile(logs io.Reader, out io.Writer, clusterNamespace string) error {
+	clusterLog := fmt.Sprintf("ceph cluster in namespace %q", clusterNamespace)
+	// the errors name the CephCluster by its namespaced name, such as "rook-ceph/my-cluster"
+	clusterError := fmt.Sprintf("CephCluster \"%s/", clusterNamespace)
+	started := false
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		match := operatorLogLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		level, controller, message := match[2], match[3], match[4]
+		if strings.Contains(message, "ceph cluster in namespace") && !strings.Contains(message, clusterLog) {
+			continue
+		}
+		fmt.Fprintln(out, line)
+
+		if controller != clusterController {
+			continue
+		}
+		switch {
+		case message == "reconciling "+clusterLog:
+			started = true
+		case started && message == "done reconciling "+clusterLog:
+			return nil
+		case started && level == "E" && strings.Contains(message, "failed to reconcile "+clusterError):
+			return fmt.Errorf("the reconcile of the CephCluster in namespace %s failed: %s", clusterNamespace, message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the operator logs. %v", err)
+	}
+	return fmt.Errorf("the operator logs ended before the CephCluster in namespace %s was reconciled, the operator may have restarted", clusterNamespace)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowReconcile(t *testing.T) {
+	start := `2023-10-10 10:00:00.000000 I | rookcmd: starting Rook v1.12.8 with arguments '/usr/local/bin/rook ceph operator'
+2023-10-10 10:00:01.000000 I | ceph-cluster-controller: reconciling ceph cluster in namespace "other"
+2023-10-10 10:00:02.000000 I | ceph-cluster-controller: reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:00:05.000000 I | op-mon: parsing mon endpoints: a=10.96.52.53:6789
+`
+
+	// the reconcile of the cluster of the namespace succeeds, the logs of the other cluster are skipped
+	var out bytes.Buffer
+	logs := start + `2023-10-10 10:00:20.000000 I | ceph-cluster-controller: done reconciling ceph cluster in namespace "other"
+2023-10-10 10:00:30.000000 I | ceph-cluster-controller: done reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:00:31.000000 I | ceph-block-pool-controller: reconciling ceph block pool "replicapool"
+`
+	assert.NoError(t, followReconcile(strings.NewReader(logs), &out, "rook-ceph"))
+	assert.Contains(t, out.String(), "op-mon: parsing mon endpoints")
+	assert.Contains(t, out.String(), `done reconciling ceph cluster in namespace "rook-ceph"`)
+	assert.NotContains(t, out.String(), `"other"`)
+	assert.NotContains(t, out.String(), "replicapool")
+
+	// the reconcile of the other cluster fails
+	out.Reset()
+	logs = start + `2023-10-10 10:00:20.000000 E | ceph-cluster-controller: failed to reconcile CephCluster "other/other". failed to create cluster
+`
+	err := followReconcile(strings.NewReader(logs), &out, "rook-ceph")
+	assert.ErrorContains(t, err, "the operator logs ended before the CephCluster in namespace rook-ceph was reconciled")
+
+	// the reconcile of the cluster fails
+	logs = start + `2023-10-10 10:00:20.000000 E | ceph-cluster-controller: failed to reconcile CephCluster "rook-ceph/my-cluster". failed to create cluster: failed to start ceph monitors
+`
+	err = followReconcile(strings.NewReader(logs), &out, "rook-ceph")
+	assert.EqualError(t, err, `the reconcile of the CephCluster in namespace rook-ceph failed: failed to reconcile CephCluster "rook-ceph/my-cluster". failed to create cluster: failed to start ceph monitors`)
+}
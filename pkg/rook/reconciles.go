@@ -0,0 +1,230 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// operatorLogTimeLayout is the layout of the timestamps of the operator logs
+	operatorLogTimeLayout = "2006-01-02 15:04:05.999999"
+	// maxReconcileErrors is the number of most recent reconcile errors printed
+	maxReconcileErrors = 5
+)
+
+// operatorLogLine matches the operator log lines such as
+// 2023-10-10 10:00:00.123456 I | ceph-cluster-controller: reconciling ceph cluster in namespace "rook-ceph"
+var operatorLogLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d+) ([DIWE]) \| ([^:]+): (.*)$`)
+
+type reconcileStats struct {
+	Controller string
+	Count      int
+	Failed     int
+	Total      time.Duration
+	Max        time.Duration
+	Last       time.Duration
+	LastFailed bool
+	// InProgress is the start of a reconcile not finished at the end of the logs
+	InProgress *time.Time
+
+	started *time.Time
+}
+
+type reconcileError struct {
+	Time    time.Time
+	Message string
+}
+
+func Reconciles(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string, since time.Duration) {
+	err := reconciles(ctx, k8sclientset, operatorNamespace, since)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func reconciles(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string, since time.Duration) error {
+	pods, err := k8sclientset.CoreV1().Pods(operatorNamespace).List(ctx, v1.ListOptions{LabelSelector: "app=rook-ceph-operator"})
+	if err != nil {
+		return fmt.Errorf("failed to list the operator pods. %v", err)
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no operator pod found in namespace %s", operatorNamespace)
+	}
+
+	sinceSeconds := int64(since.Seconds())
+	for _, pod := range pods.Items {
+		logs, err := k8sclientset.CoreV1().Pods(operatorNamespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "rook-ceph-operator", SinceSeconds: &sinceSeconds}).Stream(ctx)
+		if err != nil {
+			logging.Warning("failed to get the logs of the operator pod %s. %v", pod.Name, err)
+			continue
+		}
+		stats, reconcileErrors, err := parseReconciles(logs)
+		logs.Close()
+		if err != nil {
+			logging.Warning("failed to read the logs of the operator pod %s. %v", pod.Name, err)
+			continue
+		}
+
+		logging.Info("reconciles of the operator pod %s in the last %s", pod.Name, since)
+		if len(stats) == 0 {
+			logging.Info("no reconcile found in the logs")
+			continue
+		}
+		printReconciles(os.Stdout, stats, time.Now())
+		printReconcileErrors(reconcileErrors)
+	}
+	return nil
+}
+
+// parseReconciles reads the operator logs and returns the reconciles by controller, sorted by controller, and the
+// reconcile errors. A reconcile starts at the "reconciling" line of its controller, and ends at its "done reconciling"
+// or "successfully reconciled" line, or at a "failed to reconcile" error.
+func parseReconciles(logs io.Reader) ([]*reconcileStats, []reconcileError, error) {
+	statsByController := map[string]*reconcileStats{}
+	var reconcileErrors []reconcileError
+
+	scanner := bufio.NewScanner(logs)
+	// the operator logs the full error of the ceph commands on a single line
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		match := operatorLogLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		timestamp, err := time.Parse(operatorLogTimeLayout, match[1])
+		if err != nil {
+			continue
+		}
+		level, controller, message := match[2], match[3], match[4]
+		if !strings.HasSuffix(controller, "-controller") {
+			continue
+		}
+
+		stats, ok := statsByController[controller]
+		if !ok {
+			stats = &reconcileStats{Controller: controller}
+			statsByController[controller] = stats
+		}
+
+		switch {
+		case strings.HasPrefix(message, "reconciling"):
+			stats.started = &timestamp
+		case strings.HasPrefix(message, "done reconciling") || strings.HasPrefix(message, "successfully reconciled"):
+			stats.finish(timestamp, false)
+		case level == "E" && strings.Contains(message, "failed to reconcile"):
+			stats.finish(timestamp, true)
+			reconcileErrors = append(reconcileErrors, reconcileError{Time: timestamp, Message: fmt.Sprintf("%s: %s", controller, message)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var stats []*reconcileStats
+	for _, s := range statsByController {
+		if s.started != nil {
+			s.InProgress = s.started
+		}
+		if s.Count != 0 || s.InProgress != nil {
+			stats = append(stats, s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Controller < stats[j].Controller })
+	return stats, reconcileErrors, nil
+}
+
+// finish ends the reconcile in progress, a reconcile that started before the scanned logs is not counted
+func (s *reconcileStats) finish(timestamp time.Time, failed bool) {
+	if s.started == nil {
+		return
+	}
+	duration := timestamp.Sub(*s.started)
+	s.started = nil
+	s.Count++
+	s.Total += duration
+	s.Last = duration
+	s.LastFailed = failed
+	if failed {
+		s.Failed++
+	}
+	if duration > s.Max {
+		s.Max = duration
+	}
+}
+
+func printReconciles(out io.Writer, stats []*reconcileStats, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTROLLER\tRECONCILES\tFAILED\tAVERAGE\tMAX\tLAST\tSTATUS")
+	for _, s := range stats {
+		average, max, last := "-", "-", "-"
+		if s.Count != 0 {
+			average = formatReconcileDuration(s.Total / time.Duration(s.Count))
+			max = formatReconcileDuration(s.Max)
+			last = formatReconcileDuration(s.Last)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", s.Controller, s.Count, s.Failed, average, max, last, getReconcileStatus(s, now))
+	}
+	w.Flush()
+}
+
+func getReconcileStatus(s *reconcileStats, now time.Time) string {
+	if s.InProgress != nil {
+		// the timestamps of the operator logs are in UTC
+		return fmt.Sprintf("reconciling for %s", formatReconcileDuration(now.Sub(*s.InProgress)))
+	}
+	if s.LastFailed {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+func printReconcileErrors(reconcileErrors []reconcileError) {
+	if len(reconcileErrors) == 0 {
+		return
+	}
+	fmt.Println()
+	logging.Warning("%d reconciles failed", len(reconcileErrors))
+	if len(reconcileErrors) > maxReconcileErrors {
+		reconcileErrors = reconcileErrors[len(reconcileErrors)-maxReconcileErrors:]
+	}
+	for _, e := range reconcileErrors {
+		logging.Warning("\t%s %s", e.Time.Format(time.RFC3339), e.Message)
+	}
+}
+
+func formatReconcileDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReconciles(t *testing.T) {
+	logs := `2023-10-10 10:00:00.000000 I | ceph-cluster-controller: done reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:00:01.000000 I | ceph-cluster-controller: reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:00:05.000000 I | op-mon: parsing mon endpoints: a=10.96.52.53:6789
+2023-10-10 10:00:31.000000 I | ceph-cluster-controller: done reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:01:00.000000 I | ceph-block-pool-controller: reconciling ceph block pool "replicapool"
+2023-10-10 10:01:02.500000 E | ceph-block-pool-controller: failed to reconcile CephBlockPool "rook-ceph/replicapool". failed to create pool "replicapool"
+2023-10-10 10:02:00.000000 I | ceph-cluster-controller: reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:02:10.000000 I | ceph-cluster-controller: done reconciling ceph cluster in namespace "rook-ceph"
+2023-10-10 10:03:00.000000 I | ceph-file-controller: reconciling ceph filesystem "myfs"
+not an operator log line`
+
+	stats, reconcileErrors, err := parseReconciles(strings.NewReader(logs))
+	assert.NoError(t, err)
+	assert.Len(t, stats, 3)
+
+	assert.Equal(t, "ceph-block-pool-controller", stats[0].Controller)
+	assert.Equal(t, 1, stats[0].Count)
+	assert.Equal(t, 1, stats[0].Failed)
+	assert.True(t, stats[0].LastFailed)
+	assert.Equal(t, 2500*time.Millisecond, stats[0].Last)
+
+	// the reconcile finished at the start of the logs is not counted
+	assert.Equal(t, "ceph-cluster-controller", stats[1].Controller)
+	assert.Equal(t, 2, stats[1].Count)
+	assert.Equal(t, 30*time.Second, stats[1].Max)
+	assert.Equal(t, 10*time.Second, stats[1].Last)
+	assert.Nil(t, stats[1].InProgress)
+
+	assert.Equal(t, "ceph-file-controller", stats[2].Controller)
+	assert.Equal(t, 0, stats[2].Count)
+	assert.NotNil(t, stats[2].InProgress)
+
+	assert.Len(t, reconcileErrors, 1)
+	assert.Contains(t, reconcileErrors[0].Message, `ceph-block-pool-controller: failed to reconcile CephBlockPool "rook-ceph/replicapool"`)
+
+	var out bytes.Buffer
+	now := time.Date(2023, 10, 10, 10, 5, 0, 0, time.UTC)
+	printReconciles(&out, stats, now)
+	assert.Contains(t, out.String(), "ceph-cluster-controller      2            0        20s       30s    10s    succeeded")
+	assert.Contains(t, out.String(), "failed")
+	assert.Contains(t, out.String(), "reconciling for 2m0s")
+}
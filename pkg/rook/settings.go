@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	operatorConfigMap = "rook-ceph-operator-config"
+
+	sourceConfigMap = "configmap"
+	sourceEnv       = "env"
+)
+
+// settingGroups are the groups the settings are printed in, a setting is in the first group matching its name
+var settingGroups = []struct {
+	name  string
+	match func(setting string) bool
+}{
+	{"CSI", func(s string) bool { return strings.HasPrefix(s, "CSI_") || strings.HasPrefix(s, "ROOK_CSI_") }},
+	{"Logging", func(s string) bool { return strings.Contains(s, "LOG_LEVEL") }},
+	{"Discovery", func(s string) bool { return strings.Contains(s, "DISCOVER") }},
+	{"Other", func(string) bool { return true }},
+}
+
+type setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+func Settings(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) {
+	err := settings(ctx, k8sclientset, operatorNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func settings(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string) error {
+	configMap, err := k8sclientset.CoreV1().ConfigMaps(operatorNamespace).Get(ctx, operatorConfigMap, v1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the %s configmap. %v", operatorConfigMap, err)
+	}
+	var data map[string]string
+	if err == nil {
+		data = configMap.Data
+	} else {
+		logging.Warning("the %s configmap is not found, only the settings of the operator env are printed", operatorConfigMap)
+	}
+
+	var env []corev1.EnvVar
+	deployment, err := k8sclientset.AppsV1().Deployments(operatorNamespace).Get(ctx, "rook-ceph-operator", v1.GetOptions{})
+	if err != nil {
+		logging.Warning("failed to get the rook-ceph-operator deployment, only the settings of the configmap are printed. %v", err)
+	} else if len(deployment.Spec.Template.Spec.Containers) != 0 {
+		env = deployment.Spec.Template.Spec.Containers[0].Env
+	}
+
+	groups := groupSettings(getSettings(data, env))
+	for _, group := range settingGroups {
+		if len(groups[group.name]) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", group.name)
+		for _, s := range groups[group.name] {
+			fmt.Printf("\t%s=%s (%s)\n", s.Name, formatSettingValue(s.Value), s.Source)
+		}
+	}
+	logging.Info("the settings of the configmap take precedence over the env of the operator, unset settings use the rook defaults")
+	return nil
+}
+
+// getSettings returns the settings of the configmap, and the ROOK_ and CSI_ env variables of the operator that
+// are not overridden by the configmap
+func getSettings(data map[string]string, env []corev1.EnvVar) []setting {
+	var settings []setting
+	for name, value := range data {
+		settings = append(settings, setting{Name: name, Value: value, Source: sourceConfigMap})
+	}
+	for _, e := range env {
+		if _, ok := data[e.Name]; ok {
+			continue
+		}
+		// the env from a configmap or a secret is not resolved
+		if e.ValueFrom != nil || !(strings.HasPrefix(e.Name, "ROOK_") || strings.HasPrefix(e.Name, "CSI_")) {
+			continue
+		}
+		settings = append(settings, setting{Name: e.Name, Value: e.Value, Source: sourceEnv})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+func groupSettings(settings []setting) map[string][]setting {
+	groups := map[string][]setting{}
+	for _, s := range settings {
+		for _, group := range settingGroups {
+			if group.match(s.Name) {
+				groups[group.name] = append(groups[group.name], s)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// formatSettingValue keeps the multi-line values such as the tolerations readable in the list of settings
+func formatSettingValue(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "\n") {
+		return value
+	}
+	return "\n\t\t" + strings.ReplaceAll(value, "\n", "\n\t\t") + "\n\t"
+}
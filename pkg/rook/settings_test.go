@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetSettings(t *testing.T) {
+	data := map[string]string{
+		"ROOK_CSI_ENABLE_RBD":          "true",
+		"ROOK_LOG_LEVEL":               "DEBUG",
+		"ROOK_ENABLE_DISCOVERY_DAEMON": "false",
+	}
+	env := []corev1.EnvVar{
+		{Name: "ROOK_LOG_LEVEL", Value: "INFO"},
+		{Name: "ROOK_CURRENT_NAMESPACE_ONLY", Value: "false"},
+		{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		{Name: "HOME", Value: "/root"},
+	}
+
+	settings := getSettings(data, env)
+	assert.Equal(t, []setting{
+		{Name: "ROOK_CSI_ENABLE_RBD", Value: "true", Source: sourceConfigMap},
+		{Name: "ROOK_CURRENT_NAMESPACE_ONLY", Value: "false", Source: sourceEnv},
+		{Name: "ROOK_ENABLE_DISCOVERY_DAEMON", Value: "false", Source: sourceConfigMap},
+		{Name: "ROOK_LOG_LEVEL", Value: "DEBUG", Source: sourceConfigMap},
+	}, settings)
+
+	groups := groupSettings(settings)
+	assert.Len(t, groups["CSI"], 1)
+	assert.Equal(t, "ROOK_LOG_LEVEL", groups["Logging"][0].Name)
+	assert.Equal(t, "ROOK_ENABLE_DISCOVERY_DAEMON", groups["Discovery"][0].Name)
+	assert.Equal(t, "ROOK_CURRENT_NAMESPACE_ONLY", groups["Other"][0].Name)
+}
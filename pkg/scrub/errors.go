@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scrub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// scrubHealthChecks are the health checks raised by the scrub errors and the damaged pgs
+var scrubHealthChecks = []string{"OSD_SCRUB_ERRORS", "PG_DAMAGED"}
+
+// inconsistentPgRegexp matches the pg id in the details of PG_DAMAGED, such as "pg 2.5 is active+clean+inconsistent, acting [1,0,2]"
+var inconsistentPgRegexp = regexp.MustCompile(`^pg (\S+) is \S*inconsistent`)
+
+type healthDetail struct {
+	Checks map[string]healthCheck `json:"checks"`
+}
+
+type healthCheck struct {
+	Severity string `json:"severity"`
+	Summary  struct {
+		Message string `json:"message"`
+	} `json:"summary"`
+	Detail []struct {
+		Message string `json:"message"`
+	} `json:"detail"`
+}
+
+type inconsistentObjects struct {
+	Inconsistents []inconsistentObject `json:"inconsistents"`
+}
+
+type inconsistentObject struct {
+	Object struct {
+		Name      string `json:"name"`
+		Namespace string `json:"nspace"`
+		Snap      any    `json:"snap"`
+	} `json:"object"`
+	Errors           []string `json:"errors"`
+	UnionShardErrors []string `json:"union_shard_errors"`
+}
+
+func ScrubErrors(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, repair bool) {
+	err := scrubErrors(ctx, clientsets, operatorNamespace, clusterNamespace, repair)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func scrubErrors(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, repair bool) error {
+	healthOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"health", "detail", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var detail healthDetail
+	err := json.Unmarshal([]byte(healthOut), &detail)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph health detail output. %v", err)
+	}
+
+	found := false
+	for _, name := range scrubHealthChecks {
+		if check, ok := detail.Checks[name]; ok {
+			found = true
+			logging.Warning("%s %s: %s", check.Severity, name, check.Summary.Message)
+		}
+	}
+	if !found {
+		logging.Info("no scrub error is reported")
+		return nil
+	}
+
+	pgIds := getInconsistentPgs(detail)
+	if len(pgIds) == 0 {
+		logging.Info("no inconsistent pg is reported, the pgs may need a deep-scrub to report the inconsistent objects")
+		return nil
+	}
+
+	for _, pgId := range pgIds {
+		fmt.Printf("pg %s\n", pgId)
+		objectsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "rados", []string{"list-inconsistent-obj", pgId, "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+		var objects inconsistentObjects
+		err := json.Unmarshal([]byte(objectsOut), &objects)
+		if err != nil {
+			logging.Warning("failed to parse the inconsistent objects of pg %s, the pg may need a deep-scrub. %v", pgId, err)
+			continue
+		}
+		for _, object := range objects.Inconsistents {
+			fmt.Printf("\tobject %s: %s\n", formatObjectName(object), strings.Join(getObjectErrors(object), ","))
+		}
+	}
+	fmt.Println()
+
+	if !repair {
+		logging.Info("pass --repair to repair the %d inconsistent pgs, or run 'ceph pg repair <pg>' for a single pg", len(pgIds))
+		return nil
+	}
+	err = logging.Confirm(clusterNamespace, "Repairing %d pgs overwrites the inconsistent copies with the authoritative copy chosen by ceph", len(pgIds))
+	if err != nil {
+		return fmt.Errorf("repairing the inconsistent pgs cancelled")
+	}
+	for _, pgId := range pgIds {
+		exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"pg", "repair", pgId}, operatorNamespace, clusterNamespace, false, true)
+	}
+	logging.Info("repair of %d pgs requested, the repair runs in the background with the next deep-scrub of the pgs", len(pgIds))
+	return nil
+}
+
+// getInconsistentPgs returns the ids of the inconsistent pgs of the PG_DAMAGED health check
+func getInconsistentPgs(detail healthDetail) []string {
+	var pgIds []string
+	for _, d := range detail.Checks["PG_DAMAGED"].Detail {
+		match := inconsistentPgRegexp.FindStringSubmatch(d.Message)
+		if match != nil {
+			pgIds = append(pgIds, match[1])
+		}
+	}
+	return pgIds
+}
+
+func formatObjectName(object inconsistentObject) string {
+	name := object.Object.Name
+	if object.Object.Namespace != "" {
+		name = object.Object.Namespace + "/" + name
+	}
+	if snap := fmt.Sprint(object.Object.Snap); snap != "" && snap != "head" && snap != "<nil>" {
+		name = fmt.Sprintf("%s@%s", name, snap)
+	}
+	return name
+}
+
+// getObjectErrors returns the errors of the object and the errors of its shards
+func getObjectErrors(object inconsistentObject) []string {
+	errors := append([]string{}, object.Errors...)
+	for _, shardError := range object.UnionShardErrors {
+		errors = append(errors, "shard "+shardError)
+	}
+	return errors
+}
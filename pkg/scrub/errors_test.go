@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scrub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInconsistentPgs(t *testing.T) {
+	var detail healthDetail
+	err := json.Unmarshal([]byte(`{"status":"HEALTH_ERR","checks":{
+"OSD_SCRUB_ERRORS":{"severity":"HEALTH_ERR","summary":{"message":"2 scrub errors","count":2},"detail":[]},
+"PG_DAMAGED":{"severity":"HEALTH_ERR","summary":{"message":"Possible data damage: 2 pgs inconsistent","count":2},"detail":[
+{"message":"pg 2.5 is active+clean+inconsistent, acting [1,0,2]"},
+{"message":"pg 3.1f is active+clean+scrubbing+deep+inconsistent+repair, acting [2,1,0]"},
+{"message":"pg 4.0 is down, acting [1]"}]}}}`), &detail)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.5", "3.1f"}, getInconsistentPgs(detail))
+
+	var objects inconsistentObjects
+	err = json.Unmarshal([]byte(`{"epoch":42,"inconsistents":[{"object":{"name":"rbd_data.1234","nspace":"","locator":"","snap":"head","version":7},
+"errors":["data_digest_mismatch"],"union_shard_errors":["read_error"]},
+{"object":{"name":"obj","nspace":"ns","snap":4},"errors":[],"union_shard_errors":[]}]}`), &objects)
+	assert.NoError(t, err)
+	assert.Equal(t, "rbd_data.1234", formatObjectName(objects.Inconsistents[0]))
+	assert.Equal(t, []string{"data_digest_mismatch", "shard read_error"}, getObjectErrors(objects.Inconsistents[0]))
+	assert.Equal(t, "ns/obj@4", formatObjectName(objects.Inconsistents[1]))
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceApps are the app labels of the services created by rook, the dashboard and the metrics services of the
+// mgr have the mgr label
+var serviceApps = []string{
+	"rook-ceph-mon",
+	"rook-ceph-mgr",
+	"rook-ceph-rgw",
+	"rook-ceph-nfs",
+	"rook-ceph-exporter",
+}
+
+type serviceEndpoints struct {
+	Service *corev1.Service
+	// Ready and NotReady are the pods, or the addresses without a pod, backing the service
+	Ready    []string
+	NotReady []string
+	// Missing is set when the service has no endpoints object
+	Missing bool
+}
+
+func Services(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) {
+	err := services(ctx, clientsets, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func services(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) error {
+	opts := metav1.ListOptions{LabelSelector: fmt.Sprintf("app in (%s)", strings.Join(serviceApps, ","))}
+	serviceList, err := clientsets.Kube.CoreV1().Services(clusterNamespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list the services. %v", err)
+	}
+	if len(serviceList.Items) == 0 {
+		return fmt.Errorf("no ceph service found in namespace %q", clusterNamespace)
+	}
+	sort.Slice(serviceList.Items, func(i, j int) bool { return serviceList.Items[i].Name < serviceList.Items[j].Name })
+
+	var services []serviceEndpoints
+	for i := range serviceList.Items {
+		service := &serviceList.Items[i]
+		endpoints, err := clientsets.Kube.CoreV1().Endpoints(clusterNamespace).Get(ctx, service.Name, metav1.GetOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the endpoints of service %s. %v", service.Name, err)
+		}
+		if kerrors.IsNotFound(err) {
+			endpoints = nil
+		}
+		services = append(services, getServiceEndpoints(service, endpoints))
+	}
+
+	printServices(os.Stdout, services)
+	warnings := findServicesWithoutEndpoints(services)
+	if len(warnings) != 0 {
+		fmt.Println()
+	}
+	for _, warning := range warnings {
+		logging.Warning("%s", warning)
+	}
+	return nil
+}
+
+func getServiceEndpoints(service *corev1.Service, endpoints *corev1.Endpoints) serviceEndpoints {
+	s := serviceEndpoints{Service: service, Missing: endpoints == nil}
+	if endpoints == nil {
+		return s
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			s.Ready = append(s.Ready, getAddressName(address))
+		}
+		for _, address := range subset.NotReadyAddresses {
+			s.NotReady = append(s.NotReady, getAddressName(address))
+		}
+	}
+	sort.Strings(s.Ready)
+	sort.Strings(s.NotReady)
+	return s
+}
+
+func getAddressName(address corev1.EndpointAddress) string {
+	if address.TargetRef != nil && address.TargetRef.Name != "" {
+		return address.TargetRef.Name
+	}
+	return address.IP
+}
+
+// findServicesWithoutEndpoints returns the services without a ready endpoint, their clients fail to connect
+// while the service and its cluster ip look fine
+func findServicesWithoutEndpoints(services []serviceEndpoints) []string {
+	var warnings []string
+	for _, s := range services {
+		if len(s.Ready) != 0 {
+			continue
+		}
+		switch {
+		case s.Missing:
+			warnings = append(warnings, fmt.Sprintf("service %s has no endpoints", s.Service.Name))
+		case len(s.NotReady) != 0:
+			warnings = append(warnings, fmt.Sprintf("service %s has no ready endpoint, its pods %s are not ready", s.Service.Name, strings.Join(s.NotReady, ",")))
+		default:
+			warnings = append(warnings, fmt.Sprintf("service %s has no ready endpoint, no running pod matches its selector %s", s.Service.Name, formatSelector(s.Service.Spec.Selector)))
+		}
+	}
+	return warnings
+}
+
+func printServices(out io.Writer, services []serviceEndpoints) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tTYPE\tCLUSTER-IP\tPORTS\tREADY\tENDPOINTS")
+	for _, s := range services {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\n", s.Service.Name, s.Service.Spec.Type, formatClusterIP(s.Service), formatPorts(s.Service.Spec.Ports),
+			len(s.Ready), len(s.Ready)+len(s.NotReady), formatEndpoints(s))
+	}
+	w.Flush()
+}
+
+func formatClusterIP(service *corev1.Service) string {
+	if service.Spec.ClusterIP == "" {
+		return "-"
+	}
+	return service.Spec.ClusterIP
+}
+
+// formatPorts formats the ports like kubectl get services, such as 80:30080/TCP for a node port
+func formatPorts(ports []corev1.ServicePort) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for _, port := range ports {
+		if port.NodePort != 0 {
+			formatted = append(formatted, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			formatted = append(formatted, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+	return strings.Join(formatted, ",")
+}
+
+func formatEndpoints(s serviceEndpoints) string {
+	if s.Missing || len(s.Ready)+len(s.NotReady) == 0 {
+		return "-"
+	}
+	endpoints := append([]string{}, s.Ready...)
+	for _, name := range s.NotReady {
+		endpoints = append(endpoints, name+" (not ready)")
+	}
+	return strings.Join(endpoints, ",")
+}
+
+func formatSelector(selector map[string]string) string {
+	if len(selector) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for key, value := range selector {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
+}
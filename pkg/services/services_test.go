@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceEndpoints(t *testing.T) {
+	newService := func(name string, ports ...corev1.ServicePort) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: "10.96.0.10",
+				Ports:     ports,
+				Selector:  map[string]string{"app": name, "rook_cluster": "rook-ceph"},
+			},
+		}
+	}
+	newAddress := func(pod string) corev1.EndpointAddress {
+		return corev1.EndpointAddress{IP: "10.244.0.5", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod}}
+	}
+
+	mon := getServiceEndpoints(newService("rook-ceph-mon-a", corev1.ServicePort{Port: 6789, Protocol: corev1.ProtocolTCP}, corev1.ServicePort{Port: 3300, Protocol: corev1.ProtocolTCP}),
+		&corev1.Endpoints{Subsets: []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{newAddress("rook-ceph-mon-a-5b8c9d6f7-abcde")}}}})
+	rgw := getServiceEndpoints(newService("rook-ceph-rgw-my-store", corev1.ServicePort{Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP}),
+		&corev1.Endpoints{Subsets: []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{newAddress("rook-ceph-rgw-my-store-a-7d8e9f0a1-cdefg")}}}})
+	dashboard := getServiceEndpoints(newService("rook-ceph-mgr-dashboard", corev1.ServicePort{Port: 8443, Protocol: corev1.ProtocolTCP}), &corev1.Endpoints{})
+	exporter := getServiceEndpoints(newService("rook-ceph-exporter"), nil)
+	services := []serviceEndpoints{exporter, dashboard, mon, rgw}
+
+	assert.Equal(t, []string{"rook-ceph-mon-a-5b8c9d6f7-abcde"}, mon.Ready)
+	assert.Equal(t, []string{"rook-ceph-rgw-my-store-a-7d8e9f0a1-cdefg"}, rgw.NotReady)
+	assert.True(t, exporter.Missing)
+
+	assert.Equal(t, []string{
+		"service rook-ceph-exporter has no endpoints",
+		"service rook-ceph-mgr-dashboard has no ready endpoint, no running pod matches its selector app=rook-ceph-mgr-dashboard,rook_cluster=rook-ceph",
+		"service rook-ceph-rgw-my-store has no ready endpoint, its pods rook-ceph-rgw-my-store-a-7d8e9f0a1-cdefg are not ready",
+	}, findServicesWithoutEndpoints(services))
+
+	var out bytes.Buffer
+	printServices(&out, services)
+	assert.Regexp(t, `rook-ceph-mon-a\s+ClusterIP\s+10.96.0.10\s+6789/TCP,3300/TCP\s+1/1\s+rook-ceph-mon-a-5b8c9d6f7-abcde`, out.String())
+	assert.Regexp(t, `rook-ceph-rgw-my-store\s+ClusterIP\s+10.96.0.10\s+80:30080/TCP\s+0/1\s+rook-ceph-rgw-my-store-a-7d8e9f0a1-cdefg \(not ready\)`, out.String())
+	assert.Regexp(t, `rook-ceph-exporter\s+ClusterIP\s+10.96.0.10\s+-\s+0/0\s+-`, out.String())
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoketest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	TypeRbd    = "rbd"
+	TypeCephFS = "cephfs"
+
+	mountPath = "/data"
+)
+
+// the interval the pvc and the pod are polled at, the tests shorten it
+var pollInterval = 2 * time.Second
+
+// Options are the settings of a smoke test
+type Options struct {
+	StorageClass string
+	Type         string
+	Namespace    string
+	Size         string
+	Image        string
+	Timeout      time.Duration
+}
+
+func SmokeTest(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace string, opts Options) {
+	err := smokeTest(ctx, clientsets.Kube, operatorNamespace, opts)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func smokeTest(ctx context.Context, k8sclientset kubernetes.Interface, operatorNamespace string, opts Options) error {
+	size, err := resource.ParseQuantity(opts.Size)
+	if err != nil {
+		return fmt.Errorf("invalid size %q. %v", opts.Size, err)
+	}
+
+	storageClass, err := k8sclientset.StorageV1().StorageClasses().Get(ctx, opts.StorageClass, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get storageclass %s. %v", opts.StorageClass, err)
+	}
+	err = checkProvisioner(storageClass.Provisioner, opts.Type)
+	if err != nil {
+		return err
+	}
+
+	if opts.Image == "" {
+		operator, err := k8sutil.GetDeployment(ctx, k8sclientset, operatorNamespace, "rook-ceph-operator")
+		if err != nil {
+			return fmt.Errorf("failed to get the operator image to run the test pod, pass --image to set it. %v", err)
+		}
+		opts.Image = operator.Spec.Template.Spec.Containers[0].Image
+	}
+
+	name := fmt.Sprintf("kubectl-rook-ceph-smoke-test-%d", time.Now().Unix())
+	start := time.Now()
+	logging.Info("testing storageclass %s with pvc %s and pod %s in namespace %s", opts.StorageClass, name, name, opts.Namespace)
+
+	// the resources are cleaned up even when the test fails, so a failing test can be run again
+	defer func() {
+		cleanupStart := time.Now()
+		err := cleanup(ctx, k8sclientset, opts.Namespace, name, opts.Timeout)
+		if err != nil {
+			logging.Warning("%v, please delete the pod and the pvc %s in namespace %s manually", err, name, opts.Namespace)
+			return
+		}
+		logging.Info("cleaned up the pod and the pvc in %s", formatDuration(time.Since(cleanupStart)))
+	}()
+
+	err = runSmokeTest(ctx, k8sclientset, name, size, opts)
+	if err != nil {
+		logging.Error(fmt.Errorf("smoke test of storageclass %s failed after %s. %v", opts.StorageClass, formatDuration(time.Since(start)), err))
+		return fmt.Errorf("smoke test failed")
+	}
+	logging.Info("smoke test of storageclass %s passed in %s", opts.StorageClass, formatDuration(time.Since(start)))
+	return nil
+}
+
+func runSmokeTest(ctx context.Context, k8sclientset kubernetes.Interface, name string, size resource.Quantity, opts Options) error {
+	stepStart := time.Now()
+	_, err := k8sclientset.CoreV1().PersistentVolumeClaims(opts.Namespace).Create(ctx, newPvc(name, opts.Namespace, opts.StorageClass, opts.Type, size), v1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create pvc %s. %v", name, err)
+	}
+	err = waitFor(fmt.Sprintf("pvc %s to be bound", name), opts.Timeout, func() (bool, string, error) {
+		pvc, err := k8sclientset.CoreV1().PersistentVolumeClaims(opts.Namespace).Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return false, "", err
+		}
+		return pvc.Status.Phase == corev1.ClaimBound, string(pvc.Status.Phase), nil
+	})
+	if err != nil {
+		return fmt.Errorf("the pvc was not provisioned, check the logs of the csi provisioner. %v", err)
+	}
+	logging.Info("pvc provisioned in %s", formatDuration(time.Since(stepStart)))
+
+	stepStart = time.Now()
+	_, err = k8sclientset.CoreV1().Pods(opts.Namespace).Create(ctx, newPod(name, opts.Namespace, opts.Image), v1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create pod %s. %v", name, err)
+	}
+	var phase corev1.PodPhase
+	err = waitFor(fmt.Sprintf("pod %s to write and read the volume", name), opts.Timeout, func() (bool, string, error) {
+		pod, err := k8sclientset.CoreV1().Pods(opts.Namespace).Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return false, "", err
+		}
+		phase = pod.Status.Phase
+		return phase == corev1.PodSucceeded || phase == corev1.PodFailed, string(phase), nil
+	})
+	if err != nil {
+		return fmt.Errorf("the volume was not mounted, check the events of the pod and the logs of the csi plugin. %v", err)
+	}
+	if phase == corev1.PodFailed {
+		logs, _ := k8sclientset.CoreV1().Pods(opts.Namespace).GetLogs(name, &corev1.PodLogOptions{}).DoRaw(ctx)
+		return fmt.Errorf("the pod failed to write and read the volume: %s", strings.TrimSpace(string(logs)))
+	}
+	logging.Info("volume mounted, written and read in %s", formatDuration(time.Since(stepStart)))
+	return nil
+}
+
+// checkProvisioner checks the storageclass is provisioned by the ceph csi driver of the type
+func checkProvisioner(provisioner, volumeType string) error {
+	switch volumeType {
+	case TypeRbd, TypeCephFS:
+	default:
+		return fmt.Errorf("invalid type %q, the supported types are %s and %s", volumeType, TypeRbd, TypeCephFS)
+	}
+	// the csi driver names are prefixed by the operator namespace, such as rook-ceph.rbd.csi.ceph.com
+	if !strings.HasSuffix(provisioner, fmt.Sprintf("%s.csi.ceph.com", volumeType)) {
+		return fmt.Errorf("the provisioner %s of the storageclass is not the ceph csi %s driver", provisioner, volumeType)
+	}
+	return nil
+}
+
+func newPvc(name, namespace, storageClass, volumeType string, size resource.Quantity) *corev1.PersistentVolumeClaim {
+	// a cephfs volume is shared by the pods, it's tested with the access mode it's used with
+	accessMode := corev1.ReadWriteOnce
+	if volumeType == TypeCephFS {
+		accessMode = corev1.ReadWriteMany
+	}
+	volumeMode := corev1.PersistentVolumeFilesystem
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "kubectl-rook-ceph-smoke-test"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{accessMode},
+			VolumeMode:       &volumeMode,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+}
+
+func newPod(name, namespace, image string) *corev1.Pod {
+	// the pod fails when the content read back is not the content written
+	script := fmt.Sprintf(`set -e
+echo %[1]s > %[2]s/smoke-test
+sync
+test "$(cat %[2]s/smoke-test)" = %[1]s
+rm %[2]s/smoke-test`, name, mountPath)
+
+	return &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "kubectl-rook-ceph-smoke-test"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:         "smoke-test",
+				Image:        image,
+				Command:      []string{"/bin/sh", "-c", script},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: mountPath}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+				},
+			}},
+		},
+	}
+}
+
+// cleanup deletes the pod and the pvc of the test, and waits for the pvc to be deleted so its volume is released
+func cleanup(ctx context.Context, k8sclientset kubernetes.Interface, namespace, name string, timeout time.Duration) error {
+	gracePeriod := int64(0)
+	err := k8sclientset.CoreV1().Pods(namespace).Delete(ctx, name, v1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod %s. %v", name, err)
+	}
+	err = waitFor(fmt.Sprintf("pod %s to be deleted", name), timeout, func() (bool, string, error) {
+		_, err := k8sclientset.CoreV1().Pods(namespace).Get(ctx, name, v1.GetOptions{})
+		return kerrors.IsNotFound(err), "terminating", nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = k8sclientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pvc %s. %v", name, err)
+	}
+	return waitFor(fmt.Sprintf("pvc %s to be deleted", name), timeout, func() (bool, string, error) {
+		_, err := k8sclientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, v1.GetOptions{})
+		return kerrors.IsNotFound(err), "terminating", nil
+	})
+}
+
+// waitFor polls the condition until it's done or the timeout expires, the state of the condition is shown while waiting
+func waitFor(description string, timeout time.Duration, condition func() (bool, string, error)) error {
+	progress := logging.NewProgress("waiting for %s", description)
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(pollInterval) {
+		done, state, err := condition()
+		if err != nil {
+			progress.Done("failed")
+			return err
+		}
+		if done {
+			progress.Done("done")
+			return nil
+		}
+		progress.Update(state)
+	}
+	progress.Done("timed out")
+	return fmt.Errorf("timed out after %s waiting for %s", timeout, description)
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
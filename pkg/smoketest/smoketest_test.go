@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoketest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckProvisioner(t *testing.T) {
+	assert.NoError(t, checkProvisioner("rook-ceph.rbd.csi.ceph.com", TypeRbd))
+	assert.NoError(t, checkProvisioner("rook-ceph.cephfs.csi.ceph.com", TypeCephFS))
+	assert.Error(t, checkProvisioner("rook-ceph.cephfs.csi.ceph.com", TypeRbd))
+	assert.Error(t, checkProvisioner("ebs.csi.aws.com", TypeRbd))
+	assert.Error(t, checkProvisioner("rook-ceph.rbd.csi.ceph.com", "nfs"))
+}
+
+func TestNewPvc(t *testing.T) {
+	size := resource.MustParse("1Gi")
+	pvc := newPvc("test", "default", "rook-ceph-block", TypeRbd, size)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, pvc.Spec.AccessModes)
+	assert.Equal(t, "rook-ceph-block", *pvc.Spec.StorageClassName)
+
+	pvc = newPvc("test", "default", "rook-cephfs", TypeCephFS, size)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, pvc.Spec.AccessModes)
+
+	pod := newPod("test", "default", "rook/ceph:v1.12.8")
+	assert.Equal(t, "test", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+}
+
+func TestSmokeTestCleanupOnFailure(t *testing.T) {
+	pollInterval = time.Millisecond
+	ctx := context.TODO()
+	k8s := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-block"}, Provisioner: "rook-ceph.rbd.csi.ceph.com"},
+		&appsv1.Deployment{
+			ObjectMeta: v1.ObjectMeta{Name: "rook-ceph-operator", Namespace: "rook-ceph"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "rook-ceph-operator", Image: "rook/ceph:v1.12.8"}},
+			}}},
+		},
+	)
+
+	// the pvc is never bound by the fake clientset, the test times out and the pvc is deleted
+	opts := Options{StorageClass: "rook-ceph-block", Type: TypeRbd, Namespace: "default", Size: "1Gi", Timeout: 10 * time.Millisecond}
+	assert.Error(t, smokeTest(ctx, k8s, "rook-ceph", opts))
+	pvcs, err := k8s.CoreV1().PersistentVolumeClaims("default").List(ctx, v1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, pvcs.Items)
+
+	opts.Size = "large"
+	assert.Error(t, smokeTest(ctx, k8s, "rook-ceph", opts))
+	opts.Size = "1Gi"
+	opts.StorageClass = "missing"
+	assert.Error(t, smokeTest(ctx, k8s, "rook-ceph", opts))
+}
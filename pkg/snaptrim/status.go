@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snaptrim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+// heavySnaptrimPercent is the percentage of the pgs trimming or waiting to trim snapshots above which the snap
+// trims are likely to add latency to the client io
+const heavySnaptrimPercent = 10
+
+type pgDump struct {
+	PgStats []pgStat `json:"pg_stats"`
+}
+
+type pgStat struct {
+	PgId          string `json:"pgid"`
+	State         string `json:"state"`
+	SnaptrimqLen  int    `json:"snaptrimq_len"`
+	ActingPrimary int    `json:"acting_primary"`
+}
+
+type lsPool struct {
+	PoolNum  int    `json:"poolnum"`
+	PoolName string `json:"poolname"`
+}
+
+// poolSnaptrim is the snap trim activity of the pgs of a pool
+type poolSnaptrim struct {
+	Name     string
+	Trimming int
+	Waiting  int
+	Errors   int
+	Queued   int
+}
+
+type snaptrimSummary struct {
+	Pgs      int
+	Trimming int
+	Waiting  int
+	Errors   int
+	// Queued is the number of snapshots queued to be trimmed by all the pgs
+	Queued int
+	Pools  []poolSnaptrim
+	// Busiest are the pgs with the longest snap trim queues
+	Busiest []pgStat
+}
+
+func Status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, top int) {
+	err := status(ctx, clientsets, operatorNamespace, clusterNamespace, top)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func status(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, top int) error {
+	pgDumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"pg", "dump", "pgs", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	pgStats, err := parsePgStats(pgDumpOut)
+	if err != nil {
+		return fmt.Errorf("failed to parse ceph pg dump output. %v", err)
+	}
+	if len(pgStats) == 0 {
+		return fmt.Errorf("no pg found in the pg dump")
+	}
+	poolsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "lspools", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+	poolNames := parsePoolNames(poolsOut)
+
+	summary := summarizeSnaptrim(pgStats, poolNames, top)
+	logging.Info("%d of %d pgs are trimming snapshots or waiting to: %d snaptrim, %d snaptrim_wait, %d snaptrim_error. %d snapshots are queued to be trimmed",
+		summary.Trimming+summary.Waiting+summary.Errors, summary.Pgs, summary.Trimming, summary.Waiting, summary.Errors, summary.Queued)
+	if summary.Trimming+summary.Waiting+summary.Errors == 0 && summary.Queued == 0 {
+		logging.Info("no snapshot is being trimmed")
+		return nil
+	}
+
+	fmt.Println()
+	printPools(os.Stdout, summary.Pools)
+	fmt.Println()
+	printBusiestPgs(os.Stdout, summary.Busiest)
+	fmt.Println()
+
+	if summary.Errors != 0 {
+		logging.Error(fmt.Errorf("%d pgs failed to trim their snapshots, check the logs of their primary osd", summary.Errors))
+	}
+	if isHeavySnaptrim(summary) {
+		logging.Warning("%d%% of the pgs are trimming snapshots, which can slow down the client io. Raise osd_snap_trim_sleep to throttle the snap trims", (summary.Trimming+summary.Waiting)*100/summary.Pgs)
+	}
+	return nil
+}
+
+// parsePgStats parses the pgs of `ceph pg dump pgs`, older ceph versions print the list of pg stats without the
+// wrapping object
+func parsePgStats(pgDumpOut string) ([]pgStat, error) {
+	if strings.HasPrefix(strings.TrimSpace(pgDumpOut), "[") {
+		var pgStats []pgStat
+		err := json.Unmarshal([]byte(pgDumpOut), &pgStats)
+		return pgStats, err
+	}
+	var dump pgDump
+	err := json.Unmarshal([]byte(pgDumpOut), &dump)
+	return dump.PgStats, err
+}
+
+// parsePoolNames returns the name of each pool id, the pools are shown by id when they can't be listed
+func parsePoolNames(poolsOut string) map[string]string {
+	var pools []lsPool
+	err := json.Unmarshal([]byte(poolsOut), &pools)
+	if err != nil {
+		logging.Warning("failed to parse ceph osd lspools output, the pools are shown by id. %v", err)
+	}
+	names := map[string]string{}
+	for _, pool := range pools {
+		names[strconv.Itoa(pool.PoolNum)] = pool.PoolName
+	}
+	return names
+}
+
+// summarizeSnaptrim counts the pgs of each snap trim state and their queued snapshots by pool, and returns the top
+// pgs with the longest snap trim queue. A pg can have queued snapshots before it's scheduled to trim them.
+func summarizeSnaptrim(pgStats []pgStat, poolNames map[string]string, top int) snaptrimSummary {
+	summary := snaptrimSummary{Pgs: len(pgStats)}
+	pools := map[string]*poolSnaptrim{}
+	var busiest []pgStat
+	for _, pg := range pgStats {
+		states := map[string]bool{}
+		for _, state := range strings.Split(pg.State, "+") {
+			states[state] = true
+		}
+		if !states["snaptrim"] && !states["snaptrim_wait"] && !states["snaptrim_error"] && pg.SnaptrimqLen == 0 {
+			continue
+		}
+
+		poolId, _, _ := strings.Cut(pg.PgId, ".")
+		pool, ok := pools[poolId]
+		if !ok {
+			name := poolNames[poolId]
+			if name == "" {
+				name = poolId
+			}
+			pool = &poolSnaptrim{Name: name}
+			pools[poolId] = pool
+		}
+		switch {
+		case states["snaptrim_error"]:
+			summary.Errors++
+			pool.Errors++
+		case states["snaptrim"]:
+			summary.Trimming++
+			pool.Trimming++
+		case states["snaptrim_wait"]:
+			summary.Waiting++
+			pool.Waiting++
+		}
+		summary.Queued += pg.SnaptrimqLen
+		pool.Queued += pg.SnaptrimqLen
+		busiest = append(busiest, pg)
+	}
+
+	for _, pool := range pools {
+		summary.Pools = append(summary.Pools, *pool)
+	}
+	sort.Slice(summary.Pools, func(i, j int) bool {
+		if summary.Pools[i].Queued != summary.Pools[j].Queued {
+			return summary.Pools[i].Queued > summary.Pools[j].Queued
+		}
+		return summary.Pools[i].Name < summary.Pools[j].Name
+	})
+	sort.SliceStable(busiest, func(i, j int) bool { return busiest[i].SnaptrimqLen > busiest[j].SnaptrimqLen })
+	if top > 0 && len(busiest) > top {
+		busiest = busiest[:top]
+	}
+	summary.Busiest = busiest
+	return summary
+}
+
+func isHeavySnaptrim(summary snaptrimSummary) bool {
+	return summary.Pgs != 0 && (summary.Trimming+summary.Waiting)*100/summary.Pgs >= heavySnaptrimPercent
+}
+
+func printPools(out io.Writer, pools []poolSnaptrim) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tSNAPTRIM\tSNAPTRIM_WAIT\tSNAPTRIM_ERROR\tQUEUED SNAPSHOTS")
+	for _, pool := range pools {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", pool.Name, pool.Trimming, pool.Waiting, pool.Errors, pool.Queued)
+	}
+	w.Flush()
+}
+
+func printBusiestPgs(out io.Writer, pgs []pgStat) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PG\tSTATE\tQUEUED SNAPSHOTS\tPRIMARY")
+	for _, pg := range pgs {
+		fmt.Fprintf(w, "%s\t%s\t%d\tosd.%d\n", pg.PgId, pg.State, pg.SnaptrimqLen, pg.ActingPrimary)
+	}
+	w.Flush()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snaptrim
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPgDump = `{"pg_ready":true,"pg_stats":[
+{"pgid":"2.0","state":"active+clean","snaptrimq_len":0,"acting_primary":0},
+{"pgid":"2.1","state":"active+clean+snaptrim","snaptrimq_len":12,"acting_primary":1},
+{"pgid":"2.2","state":"active+clean+snaptrim_wait","snaptrimq_len":30,"acting_primary":2},
+{"pgid":"3.0","state":"active+clean","snaptrimq_len":4,"acting_primary":0},
+{"pgid":"3.1","state":"active+clean+snaptrim_error","snaptrimq_len":1,"acting_primary":1}]}`
+
+func TestSummarizeSnaptrim(t *testing.T) {
+	pgStats, err := parsePgStats(testPgDump)
+	assert.NoError(t, err)
+	assert.Len(t, pgStats, 5)
+
+	poolNames := parsePoolNames(`[{"poolnum":2,"poolname":"replicapool"}]`)
+	summary := summarizeSnaptrim(pgStats, poolNames, 3)
+	assert.Equal(t, 5, summary.Pgs)
+	assert.Equal(t, 1, summary.Trimming)
+	assert.Equal(t, 1, summary.Waiting)
+	assert.Equal(t, 1, summary.Errors)
+	assert.Equal(t, 47, summary.Queued)
+	assert.True(t, isHeavySnaptrim(summary))
+
+	var out bytes.Buffer
+	printPools(&out, summary.Pools)
+	// the pool 3 is not listed by lspools, it's shown by id
+	assert.Equal(t, `POOL          SNAPTRIM   SNAPTRIM_WAIT   SNAPTRIM_ERROR   QUEUED SNAPSHOTS
+replicapool   1          1               0                42
+3             0          0               1                5
+`, out.String())
+
+	out.Reset()
+	printBusiestPgs(&out, summary.Busiest)
+	assert.Equal(t, `PG    STATE                        QUEUED SNAPSHOTS   PRIMARY
+2.2   active+clean+snaptrim_wait   30                 osd.2
+2.1   active+clean+snaptrim        12                 osd.1
+3.0   active+clean                 4                  osd.0
+`, out.String())
+}
+
+func TestParsePgStats(t *testing.T) {
+	// older ceph versions print the pg stats without the wrapping object
+	pgStats, err := parsePgStats(`[{"pgid":"1.0","state":"active+clean+snaptrim","snaptrimq_len":2}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []pgStat{{PgId: "1.0", State: "active+clean+snaptrim", SnaptrimqLen: 2}}, pgStats)
+
+	summary := summarizeSnaptrim([]pgStat{{PgId: "1.0", State: "active+clean"}}, nil, 10)
+	assert.Equal(t, 0, summary.Queued)
+	assert.Empty(t, summary.Busiest)
+	assert.False(t, isHeavySnaptrim(summary))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tune
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// minOsdMemoryTarget is the minimum value of osd_memory_target accepted by ceph
+	minOsdMemoryTarget = 896 * 1024 * 1024
+	// recommendedOsdMemoryTarget is the value below which ceph does not recommend to go
+	recommendedOsdMemoryTarget = 2 * 1024 * 1024 * 1024
+	maxOsdMemoryTarget         = 1024 * 1024 * 1024 * 1024
+)
+
+func OsdMemory(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) {
+	err := osdMemory(ctx, clientsets, operatorNamespace, clusterNamespace, args)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func osdMemory(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, args []string) error {
+	current := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "get", "osd", "osd_memory_target"}, operatorNamespace, clusterNamespace, true, false)
+	logging.Info("current osd_memory_target: %s", formatBytes(strings.TrimSpace(current)))
+	if len(args) == 0 {
+		return nil
+	}
+
+	value, err := parseOsdMemoryTarget(args[0])
+	if err != nil {
+		return err
+	}
+	if value < recommendedOsdMemoryTarget {
+		logging.Warning("osd_memory_target %d is below the recommended minimum of 2Gi", value)
+	}
+
+	exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "set", "osd", "osd_memory_target", fmt.Sprint(value)}, operatorNamespace, clusterNamespace, false, true)
+	logging.Info("osd_memory_target set to %s", formatBytes(fmt.Sprint(value)))
+	logging.Info("OSDs with memory limits set in the CephCluster CR keep the osd_memory_target derived from their limits")
+	return nil
+}
+
+// parseOsdMemoryTarget parses a byte count such as "4294967296", "4Gi" or "4G"
+func parseOsdMemoryTarget(value string) (int64, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid osd_memory_target %q, expected a byte count such as 4294967296 or 4Gi. %v", value, err)
+	}
+
+	bytes, ok := quantity.AsInt64()
+	if !ok || bytes < minOsdMemoryTarget || bytes > maxOsdMemoryTarget {
+		return 0, fmt.Errorf("invalid osd_memory_target %q, the value must be between 896Mi and 1Ti", value)
+	}
+	return bytes, nil
+}
+
+func formatBytes(value string) string {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return value
+	}
+	bytes, ok := quantity.AsInt64()
+	if !ok {
+		return value
+	}
+	return fmt.Sprintf("%d (%s)", bytes, resource.NewQuantity(bytes, resource.BinarySI).String())
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tune
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOsdMemoryTarget(t *testing.T) {
+	value, err := parseOsdMemoryTarget("4294967296")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4294967296), value)
+
+	value, err = parseOsdMemoryTarget("4Gi")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4294967296), value)
+
+	value, err = parseOsdMemoryTarget("4G")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4000000000), value)
+
+	_, err = parseOsdMemoryTarget("512Mi")
+	assert.Error(t, err)
+
+	_, err = parseOsdMemoryTarget("2Pi")
+	assert.Error(t, err)
+
+	_, err = parseOsdMemoryTarget("four")
+	assert.Error(t, err)
+
+	assert.Equal(t, "4294967296 (4Gi)", formatBytes("4294967296"))
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tune
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	PresetAggressive   = "aggressive"
+	PresetConservative = "conservative"
+
+	// with the mclock scheduler the osds ignore the recovery throttles unless this option is set
+	mclockOverrideOption = "osd_mclock_override_recovery_settings"
+)
+
+// recoveryOption is a recovery throttle and the range of the values accepted
+type recoveryOption struct {
+	Name    string
+	Integer bool
+	Min     float64
+	Max     float64
+}
+
+// recoveryOptions are the recovery throttles shown and set, in the order they are printed
+var recoveryOptions = []recoveryOption{
+	{Name: "osd_max_backfills", Integer: true, Min: 1, Max: 64},
+	// 0 uses osd_recovery_max_active_hdd or osd_recovery_max_active_ssd depending on the device
+	{Name: "osd_recovery_max_active", Integer: true, Min: 0, Max: 64},
+	// the sleep in seconds between the recovery ops
+	{Name: "osd_recovery_sleep", Min: 0, Max: 10},
+}
+
+// RecoveryOptionNames returns the names of the recovery throttles
+func RecoveryOptionNames() []string {
+	var names []string
+	for _, option := range recoveryOptions {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// recoveryPresets are values of the recovery throttles. The aggressive preset speeds a rebalance up at the cost of
+// the client io, the conservative preset favors the client io.
+var recoveryPresets = map[string]map[string]string{
+	PresetAggressive:   {"osd_max_backfills": "4", "osd_recovery_max_active": "8", "osd_recovery_sleep": "0"},
+	PresetConservative: {"osd_max_backfills": "1", "osd_recovery_max_active": "1", "osd_recovery_sleep": "0.1"},
+}
+
+// RecoveryTune prints the recovery throttles of the osds, then sets the values of the preset and the values passed,
+// or removes them so the osds use their defaults when reset is set
+func RecoveryTune(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, preset string, values map[string]string, reset bool) {
+	err := recoveryTune(ctx, clientsets, operatorNamespace, clusterNamespace, preset, values, reset)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func recoveryTune(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, preset string, values map[string]string, reset bool) error {
+	// the values are validated before anything is changed
+	settings, err := getRecoverySettings(preset, values)
+	if err != nil {
+		return err
+	}
+	if reset && len(settings) != 0 {
+		return fmt.Errorf("--reset can't be combined with a preset or a value")
+	}
+
+	getOption := func(name string) string {
+		value := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"config", "get", "osd", name}, operatorNamespace, clusterNamespace, true, false)
+		return strings.TrimSpace(value)
+	}
+	current := map[string]string{}
+	for _, option := range recoveryOptions {
+		current[option.Name] = getOption(option.Name)
+	}
+	mclock := getOption("osd_op_queue") == "mclock_scheduler"
+	logging.Info("current recovery throttles of the osds")
+	printRecoverySettings(os.Stdout, current)
+
+	setOption := func(args ...string) {
+		exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", append([]string{"config"}, args...), operatorNamespace, clusterNamespace, false, true)
+	}
+	if reset {
+		for _, option := range recoveryOptions {
+			setOption("rm", "osd", option.Name)
+		}
+		if mclock {
+			setOption("rm", "osd", mclockOverrideOption)
+		}
+		logging.Info("recovery throttles reset to the defaults of the osds")
+		return nil
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	if mclock {
+		logging.Warning("the osds use the mclock scheduler, %s is set so they apply the recovery throttles, and osd_recovery_sleep is ignored. Run `kubectl rook-ceph recovery tune --reset` once the rebalance is done", mclockOverrideOption)
+		setOption("set", "osd", mclockOverrideOption, "true")
+	}
+	for _, option := range recoveryOptions {
+		value, ok := settings[option.Name]
+		if !ok {
+			continue
+		}
+		setOption("set", "osd", option.Name, value)
+		logging.Info("%s set to %s, it was %s", option.Name, value, current[option.Name])
+	}
+	return nil
+}
+
+// getRecoverySettings returns the values of the preset overridden by the values passed, once validated
+func getRecoverySettings(preset string, values map[string]string) (map[string]string, error) {
+	settings := map[string]string{}
+	if preset != "" {
+		presetValues, ok := recoveryPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("invalid preset %q, the presets are %s and %s", preset, PresetAggressive, PresetConservative)
+		}
+		for name, value := range presetValues {
+			settings[name] = value
+		}
+	}
+
+	for name, value := range values {
+		option, ok := getRecoveryOption(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown recovery option %q", name)
+		}
+		err := option.validate(value)
+		if err != nil {
+			return nil, err
+		}
+		settings[name] = value
+	}
+	return settings, nil
+}
+
+func getRecoveryOption(name string) (recoveryOption, bool) {
+	for _, option := range recoveryOptions {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return recoveryOption{}, false
+}
+
+func (o recoveryOption) validate(value string) error {
+	number, err := strconv.ParseFloat(value, 64)
+	if o.Integer {
+		var integer int64
+		integer, err = strconv.ParseInt(value, 10, 64)
+		number = float64(integer)
+	}
+	if err != nil {
+		kind := "a number"
+		if o.Integer {
+			kind = "an integer"
+		}
+		return fmt.Errorf("invalid %s %q, expected %s", o.Name, value, kind)
+	}
+	if number < o.Min || number > o.Max {
+		return fmt.Errorf("invalid %s %q, the value must be between %g and %g", o.Name, value, o.Min, o.Max)
+	}
+	return nil
+}
+
+func printRecoverySettings(out io.Writer, settings map[string]string) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "OPTION\tVALUE")
+	for _, option := range recoveryOptions {
+		fmt.Fprintf(w, "%s\t%s\n", option.Name, settings[option.Name])
+	}
+	w.Flush()
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tune
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecoverySettings(t *testing.T) {
+	settings, err := getRecoverySettings("", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, settings)
+
+	settings, err = getRecoverySettings(PresetConservative, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"osd_max_backfills": "1", "osd_recovery_max_active": "1", "osd_recovery_sleep": "0.1"}, settings)
+
+	// the values passed override the preset
+	settings, err = getRecoverySettings(PresetAggressive, map[string]string{"osd_max_backfills": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"osd_max_backfills": "2", "osd_recovery_max_active": "8", "osd_recovery_sleep": "0"}, settings)
+
+	settings, err = getRecoverySettings("", map[string]string{"osd_recovery_sleep": "0.25", "osd_recovery_max_active": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"osd_recovery_sleep": "0.25", "osd_recovery_max_active": "0"}, settings)
+
+	_, err = getRecoverySettings("fast", nil)
+	assert.EqualError(t, err, `invalid preset "fast", the presets are aggressive and conservative`)
+
+	_, err = getRecoverySettings("", map[string]string{"osd_max_backfills": "1.5"})
+	assert.EqualError(t, err, `invalid osd_max_backfills "1.5", expected an integer`)
+
+	_, err = getRecoverySettings("", map[string]string{"osd_max_backfills": "0"})
+	assert.EqualError(t, err, `invalid osd_max_backfills "0", the value must be between 1 and 64`)
+
+	_, err = getRecoverySettings("", map[string]string{"osd_recovery_sleep": "slow"})
+	assert.EqualError(t, err, `invalid osd_recovery_sleep "slow", expected a number`)
+
+	_, err = getRecoverySettings("", map[string]string{"osd_recovery_sleep": "-1"})
+	assert.Error(t, err)
+
+	_, err = getRecoverySettings("", map[string]string{"osd_op_queue": "wpq"})
+	assert.Error(t, err)
+}
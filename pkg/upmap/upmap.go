@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+)
+
+const (
+	typeItems   = "pg_upmap_items"
+	typeUpmap   = "pg_upmap"
+	typePrimary = "pg_upmap_primary"
+)
+
+type osdDump struct {
+	Pools []struct {
+		Pool     int    `json:"pool"`
+		PoolName string `json:"pool_name"`
+	} `json:"pools"`
+	PgUpmap []struct {
+		PgId string `json:"pgid"`
+		Osds []int  `json:"osds"`
+	} `json:"pg_upmap"`
+	PgUpmapItems []struct {
+		PgId     string `json:"pgid"`
+		Mappings []struct {
+			From int `json:"from"`
+			To   int `json:"to"`
+		} `json:"mappings"`
+	} `json:"pg_upmap_items"`
+	PgUpmapPrimaries []struct {
+		PgId       string `json:"pgid"`
+		PrimaryOsd int    `json:"primary_osd"`
+	} `json:"pg_upmap_primaries"`
+}
+
+type upmapEntry struct {
+	PgId    string
+	Pool    string
+	Type    string
+	Mapping string
+}
+
+// rmCommands are the ceph commands removing the entries of each type
+var rmCommands = map[string]string{
+	typeItems:   "rm-pg-upmap-items",
+	typeUpmap:   "rm-pg-upmap",
+	typePrimary: "rm-pg-upmap-primary",
+}
+
+func List(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pool string) {
+	entries, err := getUpmapEntries(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+	if pool != "" {
+		entries = filterPool(entries, pool)
+	}
+	if len(entries) == 0 {
+		logging.Info("no upmap entry found")
+		return
+	}
+	printUpmapEntries(os.Stdout, entries)
+}
+
+func Remove(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pgId string) {
+	err := remove(ctx, clientsets, operatorNamespace, clusterNamespace, pgId)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func remove(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pgId string) error {
+	entries, err := getUpmapEntries(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+	var pgEntries []upmapEntry
+	for _, entry := range entries {
+		if entry.PgId == pgId {
+			pgEntries = append(pgEntries, entry)
+		}
+	}
+	if len(pgEntries) == 0 {
+		return fmt.Errorf("pg %s has no upmap entry", pgId)
+	}
+	printUpmapEntries(os.Stdout, pgEntries)
+	fmt.Println()
+
+	err = logging.Confirm(clusterNamespace, "The upmap entries of pg %s are removed, its data moves back to the osds chosen by the crush map", pgId)
+	if err != nil {
+		return fmt.Errorf("removing the upmap entries cancelled")
+	}
+	for _, entry := range pgEntries {
+		exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", rmCommands[entry.Type], pgId}, operatorNamespace, clusterNamespace, false, true)
+		logging.Info("removed the %s entry of pg %s", entry.Type, pgId)
+	}
+	logging.Info("the balancer in upmap mode can add new upmap entries for the pg, turn it off with `kubectl rook-ceph balancer off` to keep the crush placement")
+	return nil
+}
+
+func getUpmapEntries(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) ([]upmapEntry, error) {
+	dumpOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"osd", "dump", "--format", "json"}, operatorNamespace, clusterNamespace, true, true)
+	var dump osdDump
+	err := json.Unmarshal([]byte(dumpOut), &dump)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph osd dump output. %v", err)
+	}
+	return parseUpmapEntries(dump), nil
+}
+
+// parseUpmapEntries returns the upmap entries of the osd dump sorted by pg, with the name of their pool
+func parseUpmapEntries(dump osdDump) []upmapEntry {
+	poolNames := map[string]string{}
+	for _, pool := range dump.Pools {
+		poolNames[strconv.Itoa(pool.Pool)] = pool.PoolName
+	}
+	newEntry := func(pgId, entryType, mapping string) upmapEntry {
+		// the pg id is the pool id and the pg number, such as 1.7
+		poolId, _, _ := strings.Cut(pgId, ".")
+		pool, ok := poolNames[poolId]
+		if !ok {
+			pool = poolId
+		}
+		return upmapEntry{PgId: pgId, Pool: pool, Type: entryType, Mapping: mapping}
+	}
+
+	var entries []upmapEntry
+	for _, item := range dump.PgUpmapItems {
+		var mappings []string
+		for _, mapping := range item.Mappings {
+			mappings = append(mappings, fmt.Sprintf("osd.%d->osd.%d", mapping.From, mapping.To))
+		}
+		entries = append(entries, newEntry(item.PgId, typeItems, strings.Join(mappings, ",")))
+	}
+	for _, upmap := range dump.PgUpmap {
+		var osds []string
+		for _, osd := range upmap.Osds {
+			osds = append(osds, fmt.Sprintf("osd.%d", osd))
+		}
+		entries = append(entries, newEntry(upmap.PgId, typeUpmap, fmt.Sprintf("[%s]", strings.Join(osds, ","))))
+	}
+	for _, primary := range dump.PgUpmapPrimaries {
+		entries = append(entries, newEntry(primary.PgId, typePrimary, fmt.Sprintf("primary osd.%d", primary.PrimaryOsd)))
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return lessPgId(entries[i].PgId, entries[j].PgId) })
+	return entries
+}
+
+// lessPgId sorts the pgs by pool and by pg number, the pg number is hexadecimal
+func lessPgId(a, b string) bool {
+	poolA, pgA, _ := strings.Cut(a, ".")
+	poolB, pgB, _ := strings.Cut(b, ".")
+	if poolA != poolB {
+		idA, errA := strconv.Atoi(poolA)
+		idB, errB := strconv.Atoi(poolB)
+		if errA != nil || errB != nil {
+			return poolA < poolB
+		}
+		return idA < idB
+	}
+	numA, errA := strconv.ParseUint(pgA, 16, 64)
+	numB, errB := strconv.ParseUint(pgB, 16, 64)
+	if errA != nil || errB != nil {
+		return pgA < pgB
+	}
+	return numA < numB
+}
+
+func filterPool(entries []upmapEntry, pool string) []upmapEntry {
+	var filtered []upmapEntry
+	for _, entry := range entries {
+		if entry.Pool == pool {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func printUpmapEntries(out io.Writer, entries []upmapEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PG\tPOOL\tTYPE\tMAPPING")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.PgId, entry.Pool, entry.Type, entry.Mapping)
+	}
+	w.Flush()
+}
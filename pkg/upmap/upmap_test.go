@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUpmapEntries(t *testing.T) {
+	dumpOut := `{
+	"pools": [{"pool": 1, "pool_name": ".mgr"}, {"pool": 2, "pool_name": "replicapool"}],
+	"pg_upmap": [{"pgid": "2.1f", "osds": [0, 2, 4]}],
+	"pg_upmap_items": [{"pgid": "2.a", "mappings": [{"from": 3, "to": 5}, {"from": 1, "to": 2}]}, {"pgid": "1.0", "mappings": [{"from": 0, "to": 1}]}],
+	"pg_upmap_primaries": [{"pgid": "2.3", "primary_osd": 4}, {"pgid": "7.0", "primary_osd": 1}]
+}`
+	var dump osdDump
+	assert.NoError(t, json.Unmarshal([]byte(dumpOut), &dump))
+
+	entries := parseUpmapEntries(dump)
+	assert.Equal(t, []upmapEntry{
+		{PgId: "1.0", Pool: ".mgr", Type: typeItems, Mapping: "osd.0->osd.1"},
+		{PgId: "2.3", Pool: "replicapool", Type: typePrimary, Mapping: "primary osd.4"},
+		{PgId: "2.a", Pool: "replicapool", Type: typeItems, Mapping: "osd.3->osd.5,osd.1->osd.2"},
+		{PgId: "2.1f", Pool: "replicapool", Type: typeUpmap, Mapping: "[osd.0,osd.2,osd.4]"},
+		// the pool is not in the dump
+		{PgId: "7.0", Pool: "7", Type: typePrimary, Mapping: "primary osd.1"},
+	}, entries)
+	assert.Len(t, filterPool(entries, "replicapool"), 3)
+
+	var out bytes.Buffer
+	printUpmapEntries(&out, entries[:1])
+	assert.Regexp(t, `1.0\s+.mgr\s+pg_upmap_items\s+osd.0->osd.1`, out.String())
+}
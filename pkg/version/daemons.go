@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+// overallKey is the key of the `ceph versions` output counting the daemons of all types
+const overallKey = "overall"
+
+// DaemonVersions is the number of daemons running each ceph version, by daemon type
+type DaemonVersions map[string]map[string]int
+
+func Daemons(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) {
+	err := daemons(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func daemons(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) error {
+	versions, err := GetDaemonVersions(ctx, clientsets, operatorNamespace, clusterNamespace)
+	if err != nil {
+		return err
+	}
+
+	for _, daemonType := range versions.DaemonTypes() {
+		fmt.Println(daemonType)
+		for _, version := range sortedVersions(versions[daemonType]) {
+			fmt.Printf("\t%d\t%s\n", versions[daemonType][version], version)
+		}
+	}
+
+	fmt.Println()
+	overall := versions.Overall()
+	if len(overall) <= 1 {
+		logging.Info("all daemons are running the same ceph version")
+		return nil
+	}
+	if UpgradeInProgress(ctx, clientsets, clusterNamespace) {
+		logging.Info("daemons are running %d ceph versions, an upgrade is in progress", len(overall))
+	} else {
+		logging.Warning("daemons are running %d ceph versions while the CephCluster is not progressing, the upgrade may be stalled", len(overall))
+	}
+	return nil
+}
+
+// GetDaemonVersions returns the ceph versions of the daemons reported by `ceph versions`
+func GetDaemonVersions(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string) (DaemonVersions, error) {
+	versionsOut := exec.RunCommandInOperatorPod(ctx, clientsets, "ceph", []string{"versions", "--format", "json"}, operatorNamespace, clusterNamespace, true, false)
+	versions, err := unMarshalDaemonVersions(versionsOut)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ceph versions output. %v", err)
+	}
+	return versions, nil
+}
+
+// UpgradeInProgress returns whether the CephCluster is progressing, which is the case while rook upgrades the daemons
+func UpgradeInProgress(ctx context.Context, clientsets *k8sutil.Clientsets, clusterNamespace string) bool {
+	cephCluster, err := k8sutil.GetCephCluster(ctx, clientsets.Rook, clusterNamespace, "")
+	if err != nil {
+		return false
+	}
+	return cephCluster.Status.Phase == cephv1.ConditionProgressing
+}
+
+// DaemonTypes returns the sorted daemon types, without the overall count
+func (d DaemonVersions) DaemonTypes() []string {
+	var daemonTypes []string
+	for daemonType := range d {
+		if daemonType != overallKey {
+			daemonTypes = append(daemonTypes, daemonType)
+		}
+	}
+	sort.Strings(daemonTypes)
+	return daemonTypes
+}
+
+// Overall returns the sorted ceph versions run by any daemon
+func (d DaemonVersions) Overall() []string {
+	return sortedVersions(d[overallKey])
+}
+
+// OverallCount returns the number of daemons of any type running the ceph version
+func (d DaemonVersions) OverallCount(version string) int {
+	return d[overallKey][version]
+}
+
+func unMarshalDaemonVersions(versionsOut string) (DaemonVersions, error) {
+	var versions DaemonVersions
+	err := json.Unmarshal([]byte(versionsOut), &versions)
+	return versions, err
+}
+
+func sortedVersions(versionCounts map[string]int) []string {
+	versions := make([]string, 0, len(versionCounts))
+	for version := range versionCounts {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnMarshalDaemonVersions(t *testing.T) {
+	quincy := "ceph version 17.2.6 (d7ff0d10654d2280e08f1ab989c7cdf3064446a5) quincy (stable)"
+	reef := "ceph version 18.2.0 (5dd24139a1eada541a3bc16b6941c5dde975e26d) reef (stable)"
+	versionsOut := `{
+"mon": {"` + reef + `": 3},
+"mgr": {"` + reef + `": 1},
+"osd": {"` + quincy + `": 2, "` + reef + `": 1},
+"overall": {"` + quincy + `": 2, "` + reef + `": 5}}`
+
+	versions, err := unMarshalDaemonVersions(versionsOut)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mgr", "mon", "osd"}, versions.DaemonTypes())
+	assert.Equal(t, []string{quincy, reef}, versions.Overall())
+	assert.Equal(t, 2, versions["osd"][quincy])
+
+	versions, err = unMarshalDaemonVersions(`{"mon": {"` + reef + `": 3}, "overall": {"` + reef + `": 3}}`)
+	assert.NoError(t, err)
+	assert.Len(t, versions.Overall(), 1)
+}
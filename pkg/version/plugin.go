@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+)
+
+const clientGoModule = "k8s.io/client-go"
+
+// The build info of the plugin, set at build time such as
+// -ldflags "-X github.com/rook/kubectl-rook-ceph/pkg/version.version=v0.7.0 -X github.com/rook/kubectl-rook-ceph/pkg/version.gitCommit=$(git rev-parse HEAD)"
+// When they are not set, such as with `go install`, they are read from the build info of the binary.
+var (
+	version   string
+	gitCommit string
+	buildDate string
+)
+
+// PluginVersion is the version of the plugin and of the kubernetes client it's built with
+type PluginVersion struct {
+	Version           string `json:"version"`
+	GitCommit         string `json:"gitCommit"`
+	BuildDate         string `json:"buildDate,omitempty"`
+	GoVersion         string `json:"goVersion"`
+	Platform          string `json:"platform"`
+	ClientGoVersion   string `json:"clientGoVersion"`
+	KubernetesVersion string `json:"kubernetesVersion"`
+}
+
+// Plugin prints the version of the plugin, or renders it with the formatter when it's not nil
+func Plugin(formatter output.Formatter) {
+	pluginVersion := GetPluginVersion()
+	if formatter != nil {
+		err := formatter.Render(pluginVersion)
+		if err != nil {
+			logging.Fatal(err)
+		}
+		return
+	}
+	printPluginVersion(os.Stdout, pluginVersion)
+}
+
+// GetPluginVersion returns the version of the plugin from the ldflags, or from the build info of the binary
+func GetPluginVersion() PluginVersion {
+	buildInfo, _ := debug.ReadBuildInfo()
+	return getPluginVersion(buildInfo, version, gitCommit, buildDate)
+}
+
+func getPluginVersion(buildInfo *debug.BuildInfo, version, gitCommit, buildDate string) PluginVersion {
+	pluginVersion := PluginVersion{
+		Version:   version,
+		GitCommit: gitCommit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+
+	if buildInfo != nil {
+		// a binary built from a checkout has the "(devel)" version
+		if pluginVersion.Version == "" && buildInfo.Main.Version != "(devel)" {
+			pluginVersion.Version = buildInfo.Main.Version
+		}
+		if pluginVersion.GitCommit == "" {
+			pluginVersion.GitCommit = getVcsRevision(buildInfo.Settings)
+		}
+		for _, dep := range buildInfo.Deps {
+			if dep.Path != clientGoModule {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			pluginVersion.ClientGoVersion = dep.Version
+		}
+	}
+
+	pluginVersion.KubernetesVersion = getKubernetesVersion(pluginVersion.ClientGoVersion)
+	for _, field := range []*string{&pluginVersion.Version, &pluginVersion.GitCommit, &pluginVersion.ClientGoVersion, &pluginVersion.KubernetesVersion} {
+		if *field == "" {
+			*field = "unknown"
+		}
+	}
+	return pluginVersion
+}
+
+// getVcsRevision returns the commit the binary was built from, with a -dirty suffix when the checkout had changes
+func getVcsRevision(settings []debug.BuildSetting) string {
+	var revision string
+	var modified bool
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// getKubernetesVersion returns the kubernetes api version of a client-go version, client-go v0.28.4 is the client
+// of kubernetes v1.28.4
+func getKubernetesVersion(clientGoVersion string) string {
+	minor, ok := strings.CutPrefix(clientGoVersion, "v0.")
+	if !ok {
+		return ""
+	}
+	return "v1." + minor
+}
+
+func printPluginVersion(out io.Writer, pluginVersion PluginVersion) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Version:\t%s\n", pluginVersion.Version)
+	fmt.Fprintf(w, "Git commit:\t%s\n", pluginVersion.GitCommit)
+	if pluginVersion.BuildDate != "" {
+		fmt.Fprintf(w, "Build date:\t%s\n", pluginVersion.BuildDate)
+	}
+	fmt.Fprintf(w, "Go version:\t%s\n", pluginVersion.GoVersion)
+	fmt.Fprintf(w, "Platform:\t%s\n", pluginVersion.Platform)
+	fmt.Fprintf(w, "client-go version:\t%s\n", pluginVersion.ClientGoVersion)
+	fmt.Fprintf(w, "Kubernetes API version:\t%s\n", pluginVersion.KubernetesVersion)
+	w.Flush()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPluginVersion(t *testing.T) {
+	buildInfo := &debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/rook/kubectl-rook-ceph", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+			{Path: "k8s.io/client-go", Version: "v0.28.4"},
+		},
+		Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "3f2c1a9"}, {Key: "vcs.modified", Value: "true"}},
+	}
+
+	t.Run("ldflags", func(t *testing.T) {
+		v := getPluginVersion(buildInfo, "v0.7.0", "b8fd10e", "2023-12-01T10:00:00Z")
+		assert.Equal(t, "v0.7.0", v.Version)
+		assert.Equal(t, "b8fd10e", v.GitCommit)
+		assert.Equal(t, "2023-12-01T10:00:00Z", v.BuildDate)
+		assert.Equal(t, runtime.Version(), v.GoVersion)
+		assert.Equal(t, "v0.28.4", v.ClientGoVersion)
+		assert.Equal(t, "v1.28.4", v.KubernetesVersion)
+	})
+
+	t.Run("build info", func(t *testing.T) {
+		v := getPluginVersion(buildInfo, "", "", "")
+		assert.Equal(t, "unknown", v.Version)
+		assert.Equal(t, "3f2c1a9-dirty", v.GitCommit)
+		assert.Empty(t, v.BuildDate)
+
+		installed := *buildInfo
+		installed.Main.Version = "v0.7.0"
+		installed.Settings = nil
+		installed.Deps = []*debug.Module{{Path: "k8s.io/client-go", Version: "v0.28.4", Replace: &debug.Module{Path: "k8s.io/client-go", Version: "v0.28.5"}}}
+		v = getPluginVersion(&installed, "", "", "")
+		assert.Equal(t, "v0.7.0", v.Version)
+		assert.Equal(t, "unknown", v.GitCommit)
+		assert.Equal(t, "v0.28.5", v.ClientGoVersion)
+		assert.Equal(t, "v1.28.5", v.KubernetesVersion)
+	})
+
+	t.Run("no build info", func(t *testing.T) {
+		v := getPluginVersion(nil, "", "", "")
+		assert.Equal(t, "unknown", v.Version)
+		assert.Equal(t, "unknown", v.ClientGoVersion)
+		assert.Equal(t, "unknown", v.KubernetesVersion)
+	})
+}
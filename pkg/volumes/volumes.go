@@ -0,0 +1,369 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rook/kubectl-rook-ceph/pkg/exec"
+	"github.com/rook/kubectl-rook-ceph/pkg/k8sutil"
+	"github.com/rook/kubectl-rook-ceph/pkg/logging"
+	"github.com/rook/kubectl-rook-ceph/pkg/output"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	typeRbd    = "rbd"
+	typeCephFS = "cephfs"
+
+	// the csi driver creates the subvolumes in this group unless the storageclass sets another one
+	defaultSubvolumeGroup = "csi"
+	// the volume handle ends with the uuid of the image or subvolume, named csi-vol-<uuid>
+	volumeUuidLength = 36
+	volumeNamePrefix = "csi-vol-"
+
+	statusNotFound = "not found"
+)
+
+// volume is a pvc provisioned by the ceph csi drivers and the ceph image or subvolume backing it
+type volume struct {
+	Pvc   string
+	Pv    string
+	Phase string
+	Type  string
+
+	// Pool, RadosNamespace and Image are set for the rbd volumes
+	Pool           string
+	RadosNamespace string
+	Image          string
+	// FsName, SubvolumeGroup and Subvolume are set for the cephfs volumes
+	FsName         string
+	SubvolumeGroup string
+	Subvolume      string
+	// Static is set for the volumes of an existing image or cephfs path, the static cephfs volumes are not
+	// subvolumes and are not looked up in ceph
+	Static bool
+
+	Size       string
+	CephStatus string
+}
+
+type rbdInfo struct {
+	Size     uint64   `json:"size"`
+	Features []string `json:"features"`
+	Parent   *struct {
+		Pool     string `json:"pool"`
+		Image    string `json:"image"`
+		Snapshot string `json:"snapshot"`
+	} `json:"parent"`
+}
+
+type subvolumeInfo struct {
+	// BytesQuota is "infinite" when the subvolume has no quota
+	BytesQuota json.RawMessage `json:"bytes_quota"`
+	BytesUsed  uint64          `json:"bytes_used"`
+	State      string          `json:"state"`
+}
+
+func Volumes(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pvcNamespace string) {
+	err := volumes(ctx, clientsets, operatorNamespace, clusterNamespace, pvcNamespace)
+	if err != nil {
+		logging.Fatal(err)
+	}
+}
+
+func volumes(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace, pvcNamespace string) error {
+	pvs, err := clientsets.Kube.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the pvs. %v", err)
+	}
+	pvcs, err := clientsets.Kube.CoreV1().PersistentVolumeClaims(pvcNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the pvcs. %v", err)
+	}
+	storageClasses, err := clientsets.Kube.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the storageclasses. %v", err)
+	}
+	cephStorageClasses := map[string]string{}
+	for _, sc := range storageClasses.Items {
+		if volumeType := getDriverType(sc.Provisioner); volumeType != "" {
+			cephStorageClasses[sc.Name] = volumeType
+		}
+	}
+
+	vols := getVolumes(pvs.Items, pvcs.Items, cephStorageClasses, pvcNamespace)
+	if len(vols) == 0 {
+		logging.Info("no pvc provisioned by the ceph csi drivers found")
+		return nil
+	}
+	for i := range vols {
+		setCephStatus(ctx, clientsets, operatorNamespace, clusterNamespace, &vols[i])
+	}
+	printVolumes(os.Stdout, vols)
+
+	var missing []string
+	for _, v := range vols {
+		if v.CephStatus == statusNotFound {
+			missing = append(missing, v.Pv)
+		}
+	}
+	if len(missing) != 0 {
+		fmt.Println()
+		logging.Warning("the ceph volumes of the pvs %s are not found, the pods using them fail to mount them", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// getDriverType returns the type of the ceph csi driver, the driver names are prefixed by the operator namespace
+// such as rook-ceph.rbd.csi.ceph.com
+func getDriverType(driver string) string {
+	switch {
+	case strings.HasSuffix(driver, fmt.Sprintf("%s.csi.ceph.com", typeRbd)):
+		return typeRbd
+	case strings.HasSuffix(driver, fmt.Sprintf("%s.csi.ceph.com", typeCephFS)):
+		return typeCephFS
+	}
+	return ""
+}
+
+// getVolumes returns the volumes of the pvs of the ceph csi drivers claimed in the pvc namespace, and the pvcs of the
+// ceph storageclasses not bound to a pv yet, sorted by pvc
+func getVolumes(pvs []corev1.PersistentVolume, pvcs []corev1.PersistentVolumeClaim, cephStorageClasses map[string]string, pvcNamespace string) []volume {
+	var vols []volume
+	for i := range pvs {
+		v, ok := getVolume(&pvs[i])
+		if !ok {
+			continue
+		}
+		if pvcNamespace != "" && !strings.HasPrefix(v.Pvc, pvcNamespace+"/") {
+			continue
+		}
+		vols = append(vols, v)
+	}
+	for _, pvc := range pvcs {
+		if pvc.Spec.VolumeName != "" || pvc.Spec.StorageClassName == nil {
+			continue
+		}
+		volumeType, ok := cephStorageClasses[*pvc.Spec.StorageClassName]
+		if !ok {
+			continue
+		}
+		vols = append(vols, volume{
+			Pvc:   fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name),
+			Phase: string(pvc.Status.Phase),
+			Type:  volumeType,
+		})
+	}
+	sort.Slice(vols, func(i, j int) bool { return vols[i].Pvc < vols[j].Pvc })
+	return vols
+}
+
+// getVolume returns the ceph image or subvolume of the pv from the volume attributes set by the csi driver, the name
+// is derived from the volume handle when the attributes don't have it
+func getVolume(pv *corev1.PersistentVolume) (volume, bool) {
+	csi := pv.Spec.CSI
+	if csi == nil {
+		return volume{}, false
+	}
+	volumeType := getDriverType(csi.Driver)
+	if volumeType == "" {
+		return volume{}, false
+	}
+
+	v := volume{Pv: pv.Name, Phase: string(pv.Status.Phase), Type: volumeType, Pvc: "-"}
+	if claim := pv.Spec.ClaimRef; claim != nil {
+		v.Pvc = fmt.Sprintf("%s/%s", claim.Namespace, claim.Name)
+	}
+	attributes := csi.VolumeAttributes
+	v.Static = attributes["staticVolume"] == "true"
+
+	if volumeType == typeRbd {
+		v.Pool = attributes["pool"]
+		v.RadosNamespace = attributes["radosNamespace"]
+		v.Image = attributes["imageName"]
+		if v.Static {
+			// the handle of a static volume is the name of the image
+			v.Image = csi.VolumeHandle
+		}
+		if v.Image == "" {
+			v.Image = getVolumeName(csi.VolumeHandle)
+		}
+		return v, true
+	}
+
+	v.FsName = attributes["fsName"]
+	v.SubvolumeGroup = attributes["subvolumeGroup"]
+	if v.SubvolumeGroup == "" {
+		v.SubvolumeGroup = defaultSubvolumeGroup
+	}
+	v.Subvolume = attributes["subvolumeName"]
+	if v.Static {
+		// a static cephfs volume mounts a path of the filesystem rather than a subvolume
+		v.Subvolume = attributes["rootPath"]
+	}
+	if v.Subvolume == "" {
+		v.Subvolume = getVolumeName(csi.VolumeHandle)
+	}
+	return v, true
+}
+
+// getVolumeName returns the name of the image or the subvolume from the volume handle such as
+// 0001-0009-rook-ceph-0000000000000002-8a6f1f0e-6f4f-4e3b-9c3e-3c1b1bd10b6d
+func getVolumeName(volumeHandle string) string {
+	if len(volumeHandle) < volumeUuidLength {
+		return ""
+	}
+	return volumeNamePrefix + volumeHandle[len(volumeHandle)-volumeUuidLength:]
+}
+
+// setCephStatus sets the size and the status of the image or subvolume of the volume
+func setCephStatus(ctx context.Context, clientsets *k8sutil.Clientsets, operatorNamespace, clusterNamespace string, v *volume) {
+	if v.Pv == "" || (v.Static && v.Type == typeCephFS) {
+		return
+	}
+	var cmd string
+	var args []string
+	if v.Type == typeRbd {
+		if v.Pool == "" || v.Image == "" {
+			v.CephStatus = "unknown image"
+			return
+		}
+		cmd, args = "rbd", []string{"info", fmt.Sprintf("%s/%s", v.Pool, v.Image), "--format", "json"}
+		if v.RadosNamespace != "" {
+			args = append(args, "--namespace", v.RadosNamespace)
+		}
+	} else {
+		if v.FsName == "" || v.Subvolume == "" {
+			v.CephStatus = "unknown subvolume"
+			return
+		}
+		cmd, args = "ceph", []string{"fs", "subvolume", "info", v.FsName, v.Subvolume, "--group_name", v.SubvolumeGroup, "--format", "json"}
+	}
+
+	result, err := exec.RunCommandInOperatorPodWithResult(ctx, clientsets, cmd, args, operatorNamespace, clusterNamespace)
+	if err != nil {
+		v.CephStatus = fmt.Sprintf("error: %v", err)
+		return
+	}
+	if result.ExitCode != 0 {
+		v.CephStatus = getCommandError(result.Stderr)
+		return
+	}
+	if v.Type == typeRbd {
+		v.Size, v.CephStatus, err = parseRbdInfo(result.Stdout)
+	} else {
+		v.Size, v.CephStatus, err = parseSubvolumeInfo(result.Stdout)
+	}
+	if err != nil {
+		v.CephStatus = fmt.Sprintf("error: %v", err)
+	}
+}
+
+// getCommandError returns the status of a volume the rbd or ceph command failed for
+func getCommandError(stderr string) string {
+	// rbd and ceph fs subvolume info fail with ENOENT when the image or the subvolume doesn't exist
+	if strings.Contains(stderr, "No such file or directory") || strings.Contains(stderr, "does not exist") {
+		return statusNotFound
+	}
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	return fmt.Sprintf("error: %s", lines[len(lines)-1])
+}
+
+func parseRbdInfo(infoOut string) (string, string, error) {
+	var info rbdInfo
+	err := json.Unmarshal([]byte(infoOut), &info)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse rbd info output. %v", err)
+	}
+	status := "ok"
+	if info.Parent != nil {
+		// the image of a volume cloned from a snapshot depends on its parent until it's flattened
+		status = fmt.Sprintf("ok, clone of %s/%s@%s", info.Parent.Pool, info.Parent.Image, info.Parent.Snapshot)
+	}
+	return output.Bytes(info.Size), status, nil
+}
+
+func parseSubvolumeInfo(infoOut string) (string, string, error) {
+	var info subvolumeInfo
+	err := json.Unmarshal([]byte(infoOut), &info)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse ceph fs subvolume info output. %v", err)
+	}
+	size := "unlimited"
+	var quota uint64
+	if json.Unmarshal(info.BytesQuota, &quota) == nil {
+		size = output.Bytes(quota)
+	}
+	size = fmt.Sprintf("%s used of %s", output.Bytes(info.BytesUsed), size)
+	status := info.State
+	if status == "" {
+		status = "ok"
+	}
+	return size, status, nil
+}
+
+func printVolumes(out io.Writer, vols []volume) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PVC\tPV\tSTATUS\tTYPE\tCEPH VOLUME\tSIZE\tCEPH STATUS")
+	for _, v := range vols {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", v.Pvc, orDash(v.Pv), v.Phase, v.Type, formatCephVolume(v), orDash(v.Size), formatCephStatus(v))
+	}
+	w.Flush()
+}
+
+func formatCephVolume(v volume) string {
+	if v.Pv == "" {
+		return "-"
+	}
+	if v.Type == typeRbd {
+		if v.RadosNamespace != "" {
+			return fmt.Sprintf("%s/%s/%s", v.Pool, v.RadosNamespace, v.Image)
+		}
+		return fmt.Sprintf("%s/%s", v.Pool, v.Image)
+	}
+	if v.Static {
+		return fmt.Sprintf("%s:%s", v.FsName, v.Subvolume)
+	}
+	return fmt.Sprintf("%s/%s/%s", v.FsName, v.SubvolumeGroup, v.Subvolume)
+}
+
+func formatCephStatus(v volume) string {
+	switch {
+	case v.Pv == "":
+		return "not provisioned"
+	case v.Static && v.Type == typeCephFS:
+		return "static"
+	}
+	return orDash(v.CephStatus)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testVolumeHandle = "0001-0009-rook-ceph-0000000000000002-8a6f1f0e-6f4f-4e3b-9c3e-3c1b1bd10b6d"
+
+func newPv(name, driver string, attributes map[string]string, claimNamespace, claimName string) corev1.PersistentVolume {
+	pv := corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: testVolumeHandle, VolumeAttributes: attributes},
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+	if claimName != "" {
+		pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: claimNamespace, Name: claimName}
+	}
+	return pv
+}
+
+func TestGetVolume(t *testing.T) {
+	t.Run("rbd", func(t *testing.T) {
+		pv := newPv("pvc-1", "rook-ceph.rbd.csi.ceph.com", map[string]string{"pool": "replicapool", "imageName": "csi-vol-1234"}, "default", "data")
+		v, ok := getVolume(&pv)
+		assert.True(t, ok)
+		assert.Equal(t, "default/data", v.Pvc)
+		assert.Equal(t, typeRbd, v.Type)
+		assert.Equal(t, "replicapool/csi-vol-1234", formatCephVolume(v))
+	})
+
+	t.Run("rbd image from the volume handle", func(t *testing.T) {
+		pv := newPv("pvc-1", "rook-ceph.rbd.csi.ceph.com", map[string]string{"pool": "replicapool", "radosNamespace": "ns-a"}, "default", "data")
+		v, ok := getVolume(&pv)
+		assert.True(t, ok)
+		assert.Equal(t, "csi-vol-8a6f1f0e-6f4f-4e3b-9c3e-3c1b1bd10b6d", v.Image)
+		assert.Equal(t, "replicapool/ns-a/csi-vol-8a6f1f0e-6f4f-4e3b-9c3e-3c1b1bd10b6d", formatCephVolume(v))
+	})
+
+	t.Run("static rbd", func(t *testing.T) {
+		pv := newPv("static-pv", "rook-ceph.rbd.csi.ceph.com", map[string]string{"pool": "replicapool", "staticVolume": "true"}, "", "")
+		pv.Spec.CSI.VolumeHandle = "my-image"
+		v, ok := getVolume(&pv)
+		assert.True(t, ok)
+		assert.Equal(t, "-", v.Pvc)
+		assert.Equal(t, "my-image", v.Image)
+	})
+
+	t.Run("cephfs", func(t *testing.T) {
+		pv := newPv("pvc-2", "rook-ceph.cephfs.csi.ceph.com", map[string]string{"fsName": "myfs", "subvolumeName": "csi-vol-5678"}, "apps", "shared")
+		v, ok := getVolume(&pv)
+		assert.True(t, ok)
+		assert.Equal(t, typeCephFS, v.Type)
+		assert.Equal(t, "myfs/csi/csi-vol-5678", formatCephVolume(v))
+	})
+
+	t.Run("not ceph", func(t *testing.T) {
+		pv := newPv("pvc-3", "ebs.csi.aws.com", nil, "default", "other")
+		_, ok := getVolume(&pv)
+		assert.False(t, ok)
+
+		pv.Spec.CSI = nil
+		_, ok = getVolume(&pv)
+		assert.False(t, ok)
+	})
+}
+
+func TestGetVolumes(t *testing.T) {
+	pvs := []corev1.PersistentVolume{
+		newPv("pvc-2", "rook-ceph.rbd.csi.ceph.com", map[string]string{"pool": "replicapool"}, "b", "data"),
+		newPv("pvc-1", "rook-ceph.rbd.csi.ceph.com", map[string]string{"pool": "replicapool"}, "a", "data"),
+		newPv("pvc-3", "ebs.csi.aws.com", nil, "a", "other"),
+	}
+	ceph, other := "ceph-block", "gp2"
+	pvcs := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "data"}, Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1", StorageClassName: &ceph}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "pending"}, Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &ceph},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "gp2-pending"}, Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &other}},
+	}
+	storageClasses := map[string]string{ceph: typeRbd}
+
+	vols := getVolumes(pvs, pvcs, storageClasses, "")
+	assert.Len(t, vols, 3)
+	assert.Equal(t, "a/data", vols[0].Pvc)
+	assert.Equal(t, "a/pending", vols[1].Pvc)
+	assert.Equal(t, "", vols[1].Pv)
+	assert.Equal(t, "b/data", vols[2].Pvc)
+
+	vols = getVolumes(pvs, pvcs[:1], storageClasses, "b")
+	assert.Len(t, vols, 1)
+	assert.Equal(t, "pvc-2", vols[0].Pv)
+}
+
+func TestParseInfo(t *testing.T) {
+	size, status, err := parseRbdInfo(`{"name":"csi-vol-1234","size":10737418240,"features":["layering"]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0 GiB", size)
+	assert.Equal(t, "ok", status)
+
+	_, status, err = parseRbdInfo(`{"size":1073741824,"parent":{"pool":"replicapool","image":"csi-vol-1","snapshot":"csi-snap-1"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok, clone of replicapool/csi-vol-1@csi-snap-1", status)
+
+	size, status, err = parseSubvolumeInfo(`{"bytes_quota":1073741824,"bytes_used":536870912,"state":"complete"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "512.0 MiB used of 1.0 GiB", size)
+	assert.Equal(t, "complete", status)
+
+	size, _, err = parseSubvolumeInfo(`{"bytes_quota":"infinite","bytes_used":0,"state":"complete"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "0 B used of unlimited", size)
+
+	_, _, err = parseRbdInfo("not json")
+	assert.Error(t, err)
+
+	assert.Equal(t, statusNotFound, getCommandError("rbd: error opening image csi-vol-1: (2) No such file or directory\n"))
+	assert.Equal(t, "error: timed out", getCommandError("warning\ntimed out\n"))
+}
+
+func TestPrintVolumes(t *testing.T) {
+	var out bytes.Buffer
+	printVolumes(&out, []volume{
+		{Pvc: "a/data", Pv: "pvc-1", Phase: "Bound", Type: typeRbd, Pool: "replicapool", Image: "csi-vol-1", Size: "1.0 GiB", CephStatus: "ok"},
+		{Pvc: "a/pending", Phase: "Pending", Type: typeRbd},
+	})
+	assert.Equal(t, `PVC         PV      STATUS    TYPE   CEPH VOLUME             SIZE      CEPH STATUS
+a/data      pvc-1   Bound     rbd    replicapool/csi-vol-1   1.0 GiB   ok
+a/pending   -       Pending   rbd    -                       -         not provisioned
+`, out.String())
+}